@@ -146,11 +146,7 @@ func main() {
 	config.DB.Exec("SET UNIQUE_CHECKS = 1")
 	config.DB.Exec("SET AUTOCOMMIT = 1")
 
-	// Crear índices si no existen
-	fmt.Println("📇 Optimizando índices...")
-	config.DB.Exec("CREATE INDEX IF NOT EXISTS idx_codigo ON codigos_postales(d_codigo)")
-	config.DB.Exec("CREATE INDEX IF NOT EXISTS idx_estado_municipio ON codigos_postales(d_estado, d_mnpio)")
-	config.DB.Exec("CREATE INDEX IF NOT EXISTS idx_asenta ON codigos_postales(d_asenta)")
+	// Los índices de codigos_postales se crean en la migración 0003 (cmd/migrate)
 
 	fmt.Printf("\n🎉 Importación completada:\n")
 	fmt.Printf("   ✅ Registros importados: %d\n", count)
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"ues-egresados/internal/cifrado"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/importacion"
+	"ues-egresados/internal/repository"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	archivo := flag.String("archivo", "", "Ruta del archivo CSV o XLSX a importar")
+	confirmar := flag.Bool("confirmar", false, "Aplica los cambios (sin esta opción solo se valida)")
+	flag.Parse()
+
+	if *archivo == "" {
+		fmt.Println("Uso: go run ./cmd/import_egresados -archivo egresados.xlsx [-confirmar]")
+		os.Exit(1)
+	}
+
+	// Cargar variables de entorno
+	if err := godotenv.Load(); err != nil {
+		log.Println("No se encontró archivo .env")
+	}
+
+	// Conectar a la base de datos
+	if err := config.InitDB(); err != nil {
+		log.Fatal("❌ Error al conectar con la base de datos:", err)
+	}
+	defer config.CloseDB()
+
+	f, err := os.Open(*archivo)
+	if err != nil {
+		log.Fatal("❌ Error al abrir archivo:", err)
+	}
+	defer f.Close()
+
+	tabla, err := importacion.Leer(*archivo, f)
+	if err != nil {
+		log.Fatal("❌ Error al leer archivo:", err)
+	}
+
+	cifrador, err := cifrado.DesdeEntorno()
+	if err != nil {
+		log.Fatal("❌ Error en la configuración de cifrado:", err)
+	}
+
+	ctx := context.Background()
+	importador := importacion.NewImportador(repository.NewMySQL(config.DB, cifrador))
+
+	reporte, err := importador.Analizar(ctx, tabla, 0)
+	if err != nil {
+		log.Fatal("❌ Error al validar archivo:", err)
+	}
+
+	for _, fila := range reporte.Filas {
+		if fila.Accion != importacion.AccionError && fila.Accion != importacion.AccionConflicto {
+			continue
+		}
+		for _, e := range fila.Errores {
+			fmt.Printf("⚠️  Fila %d (%s): %s\n", fila.Fila, fila.Matricula, e)
+		}
+	}
+
+	fmt.Printf("📊 Filas: %d | Crear: %d | Actualizar: %d | Con errores: %d | En la papelera: %d\n",
+		reporte.Total, reporte.Crear, reporte.Actualizar, reporte.ConErrores, reporte.Conflictos)
+
+	if !*confirmar {
+		fmt.Println("ℹ️  Validación terminada sin cambios. Usa -confirmar para aplicar.")
+		return
+	}
+
+	if err := importador.Aplicar(ctx, reporte); err != nil {
+		log.Fatal("❌ No se aplicó la importación:", err)
+	}
+
+	fmt.Printf("✅ Importación aplicada: %d creados, %d actualizados\n", reporte.Crear, reporte.Actualizar)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/migrations"
+
+	"github.com/joho/godotenv"
+)
+
+const uso = `Uso: go run ./cmd/migrate <comando> [argumentos]
+
+Comandos:
+  up              Aplica todas las migraciones pendientes
+  down [n]        Revierte las últimas n migraciones (por defecto 1)
+  status          Muestra el estado de cada migración
+  baseline <n>    Marca como aplicadas, sin ejecutarlas, las migraciones hasta la n
+  create <nombre> Crea los archivos up/down de una nueva migración`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(uso)
+		os.Exit(1)
+	}
+
+	comando := os.Args[1]
+
+	// create no necesita base de datos
+	if comando == "create" {
+		if len(os.Args) < 3 {
+			log.Fatal("❌ Falta el nombre de la migración")
+		}
+		up, down, err := migrations.Create(migrations.Dir, os.Args[2])
+		if err != nil {
+			log.Fatal("❌ Error al crear migración:", err)
+		}
+		fmt.Printf("📝 Creado %s\n📝 Creado %s\n", up, down)
+		return
+	}
+
+	// Cargar variables de entorno
+	if err := godotenv.Load(); err != nil {
+		log.Println("No se encontró archivo .env")
+	}
+
+	// Conectar a la base de datos
+	if err := config.InitDB(); err != nil {
+		log.Fatal("❌ Error al conectar con la base de datos:", err)
+	}
+	defer config.CloseDB()
+
+	migrator, err := migrations.New(config.DB)
+	if err != nil {
+		log.Fatal("❌ Error al cargar migraciones:", err)
+	}
+
+	ctx := context.Background()
+
+	switch comando {
+	case "up":
+		n, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal("❌ Error al aplicar migraciones:", err)
+		}
+		fmt.Printf("✅ Migraciones aplicadas: %d\n", n)
+
+	case "down":
+		pasos := 1
+		if len(os.Args) > 2 {
+			pasos, err = strconv.Atoi(os.Args[2])
+			if err != nil || pasos < 1 {
+				log.Fatal("❌ Número de pasos inválido:", os.Args[2])
+			}
+		}
+		n, err := migrator.Down(ctx, pasos)
+		if err != nil {
+			log.Fatal("❌ Error al revertir migraciones:", err)
+		}
+		fmt.Printf("✅ Migraciones revertidas: %d\n", n)
+
+	case "baseline":
+		if len(os.Args) < 3 {
+			log.Fatal("❌ Falta la versión del esquema existente")
+		}
+		version, err := strconv.Atoi(os.Args[2])
+		if err != nil || version < 1 {
+			log.Fatal("❌ Versión inválida:", os.Args[2])
+		}
+		n, err := migrator.Baseline(ctx, version)
+		if err != nil {
+			log.Fatal("❌ Error al marcar migraciones:", err)
+		}
+		fmt.Printf("✅ Migraciones marcadas como aplicadas: %d\n", n)
+
+	case "status":
+		estados, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal("❌ Error al consultar migraciones:", err)
+		}
+		fmt.Println("📋 Estado de migraciones:")
+		for _, e := range estados {
+			if e.Aplicada {
+				fmt.Printf("   ✅ %04d_%s (%s)\n", e.Version, e.Nombre, e.AplicadaEn.Format("2006-01-02 15:04:05"))
+			} else {
+				fmt.Printf("   ⏳ %04d_%s (pendiente)\n", e.Version, e.Nombre)
+			}
+		}
+
+	default:
+		fmt.Println(uso)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,41 @@
+// mock_oidc es un proveedor OpenID Connect mínimo para probar el inicio de
+// sesión institucional en desarrollo. Publica discovery y JWKS, muestra un
+// formulario en lugar de pedir contraseña y exige PKCE S256 al canjear el
+// código. No usar en producción.
+//
+//	go run ./cmd/mock_oidc -puerto 9000
+//	OIDC_EMISOR=http://localhost:9000 OIDC_CLIENT_ID=ues-egresados go run ./cmd/server
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"ues-egresados/internal/sso/ssoprueba"
+)
+
+func main() {
+	puerto := flag.Int("puerto", 9000, "Puerto en el que escucha el proveedor")
+	emisor := flag.String("emisor", "", "Issuer publicado (por defecto http://localhost:<puerto>)")
+	clientID := flag.String("client-id", "ues-egresados", "client_id aceptado")
+	auto := flag.Bool("auto", false, "Aprueba sin mostrar el formulario, con -usuario y -correo")
+	usuario := flag.String("usuario", "", "preferred_username propuesto")
+	correo := flag.String("correo", "", "email propuesto")
+	flag.Parse()
+
+	if *emisor == "" {
+		*emisor = fmt.Sprintf("http://localhost:%d", *puerto)
+	}
+
+	p, err := ssoprueba.Nuevo(*emisor, *clientID)
+	if err != nil {
+		log.Fatal("❌ Error al generar la llave RSA:", err)
+	}
+	p.Auto = *auto
+	p.Usuario = *usuario
+	p.Correo = *correo
+
+	log.Printf("🔑 Proveedor OIDC de prueba en %s (client_id %s)", p.Emisor, p.ClientID)
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", *puerto), p))
+}
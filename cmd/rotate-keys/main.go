@@ -0,0 +1,128 @@
+// rotate-keys vuelve a cifrar los datos personales de los egresados con la
+// llave maestra activa (CIFRADO_LLAVE_ACTIVA), en lotes. También cifra las
+// filas que aún están en texto plano, por ejemplo después de aplicar la
+// migración 0015.
+//
+//	go run ./cmd/rotate-keys                  # muestra cuántas filas usan cada llave
+//	go run ./cmd/rotate-keys -confirmar       # recifra lo que no usa la llave activa
+//	go run ./cmd/rotate-keys -nueva-llave     # genera una llave para la configuración
+//
+// Para rotar: agregar la llave nueva a CIFRADO_LLAVES, marcarla como activa,
+// reiniciar el servidor, ejecutar este comando con -confirmar y, cuando ya no
+// quede ninguna fila con la llave anterior, quitarla de CIFRADO_LLAVES.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+	"ues-egresados/internal/cifrado"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/repository"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	confirmar := flag.Bool("confirmar", false, "Aplica los cambios (sin esta opción solo muestra el estado)")
+	lote := flag.Int("lote", 500, "Filas por transacción")
+	pausa := flag.Duration("pausa", 0, "Espera entre lotes para no saturar la base de datos (por ejemplo 200ms)")
+	todas := flag.Bool("todas", false, "Recifra también las filas que ya usan la llave activa (necesario al cambiar CIFRADO_LLAVE_INDICES)")
+	descifrar := flag.Bool("descifrar", false, "Deja los datos en texto plano, antes de revertir la migración 0015")
+	nuevaLlave := flag.Bool("nueva-llave", false, "Imprime una llave aleatoria en base64 y termina")
+	flag.Parse()
+
+	if *nuevaLlave {
+		llave, err := cifrado.NuevaLlave()
+		if err != nil {
+			log.Fatal("❌ Error al generar la llave:", err)
+		}
+		fmt.Println(llave)
+		return
+	}
+	if *lote <= 0 {
+		fmt.Println("Uso: go run ./cmd/rotate-keys [-confirmar] [-lote 500] [-pausa 200ms] [-todas | -descifrar] [-nueva-llave]")
+		os.Exit(1)
+	}
+
+	// Cargar variables de entorno
+	if err := godotenv.Load(); err != nil {
+		log.Println("No se encontró archivo .env")
+	}
+
+	cifrador, err := cifrado.DesdeEntorno()
+	if err != nil {
+		log.Fatal("❌ Error en la configuración de cifrado:", err)
+	}
+	if cifrador == nil {
+		log.Fatal("❌ Define CIFRADO_LLAVES y CIFRADO_LLAVE_INDICES para cifrar los datos")
+	}
+
+	// Conectar a la base de datos
+	if err := config.InitDB(); err != nil {
+		log.Fatal("❌ Error al conectar con la base de datos:", err)
+	}
+	defer config.CloseDB()
+
+	ctx := context.Background()
+	egresados := repository.NewEgresadoMySQL(config.DB, cifrador)
+
+	mostrarConteo(ctx, egresados, cifrador.LlaveActiva())
+	if !*confirmar {
+		fmt.Println("ℹ️  Sin cambios. Usa -confirmar para recifrar.")
+		return
+	}
+
+	op := repository.OpcionesRecifrado{Lote: *lote, Todas: *todas, Descifrar: *descifrar}
+	total := 0
+	ultima := ""
+	for {
+		n, siguiente, err := egresados.Recifrar(ctx, ultima, op)
+		if err != nil {
+			log.Fatalf("❌ Error después de %d filas (última matrícula %q): %v", total, ultima, err)
+		}
+		if siguiente == "" {
+			break
+		}
+		total += n
+		ultima = siguiente
+		fmt.Printf("🔐 %d filas procesadas (hasta %s)\n", total, ultima)
+		time.Sleep(*pausa)
+	}
+
+	if *descifrar {
+		fmt.Printf("✅ %d filas quedaron en texto plano\n", total)
+	} else {
+		fmt.Printf("✅ %d filas cifradas con la llave %s\n", total, cifrador.LlaveActiva())
+	}
+	mostrarConteo(ctx, egresados, cifrador.LlaveActiva())
+}
+
+func mostrarConteo(ctx context.Context, egresados *repository.EgresadoMySQL, activa string) {
+	conteo, err := egresados.ConteoLlaves(ctx)
+	if err != nil {
+		log.Fatal("❌ Error al consultar las llaves en uso:", err)
+	}
+
+	ids := make([]string, 0, len(conteo))
+	for id := range conteo {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Println("📊 Egresados por llave maestra:")
+	for _, id := range ids {
+		switch id {
+		case "":
+			fmt.Printf("   sin cifrar: %d\n", conteo[id])
+		case activa:
+			fmt.Printf("   %s (activa): %d\n", id, conteo[id])
+		default:
+			fmt.Printf("   %s: %d\n", id, conteo[id])
+		}
+	}
+}
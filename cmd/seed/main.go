@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/rand"
 	"strings"
 	"time"
+	"ues-egresados/internal/cifrado"
 	"ues-egresados/internal/config"
+	"ues-egresados/internal/matricula"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/joho/godotenv"
@@ -33,7 +38,7 @@ func main() {
 
 	// Configuración
 	cantidadEgresados := 500
-	plantel := "13" // Número fijo del plantel
+	plantel := matricula.PlantelDesdeEntorno() // PLANTEL_CLAVE, 13 por defecto
 	
 	fmt.Printf("📊 Generando %d egresados ficticios...\n", cantidadEgresados)
 	fmt.Printf("🏫 Plantel: %s\n\n", plantel)
@@ -43,8 +48,9 @@ func main() {
 	generaciones := obtenerGeneracionesCompletas()
 	estatus := obtenerEstatus()
 	codigosPostales := obtenerCodigosPostalesAleatorios(100)
+	idPlantel := obtenerPlantel(plantel)
 
-	if len(carreras) == 0 || len(generaciones) == 0 || len(estatus) == 0 {
+	if len(carreras) == 0 || len(generaciones) == 0 || len(estatus) == 0 || idPlantel == 0 {
 		log.Fatal("❌ Faltan catálogos en la base de datos")
 	}
 
@@ -55,17 +61,13 @@ func main() {
 	count := 0
 	duplicados := 0
 
-	stmt, err := config.DB.Prepare(`
-		INSERT INTO egresados 
-		(matricula, nombre_completo, genero, telefono, correo, 
-		codigo_postal, estado, municipio, asentamiento, calle, numero,
-		id_carrera, id_generacion, id_estatus)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`)
+	// Los datos personales se cifran igual que al capturarlos en el sistema
+	cifrador, err := cifrado.DesdeEntorno()
 	if err != nil {
-		log.Fatal("❌ Error al preparar statement:", err)
+		log.Fatal("❌ Error en la configuración de cifrado:", err)
 	}
-	defer stmt.Close()
+	egresados := repository.NewEgresadoMySQL(config.DB, cifrador)
+	ctx := context.Background()
 
 	for i := 0; i < cantidadEgresados; i++ {
 		// Seleccionar generación aleatoria
@@ -97,22 +99,23 @@ func main() {
 		estatusEgresado := estatus[rand.Intn(len(estatus))]
 
 		// Insertar en la BD
-		_, err := stmt.Exec(
-			matricula,
-			nombreCompleto,
-			genero,
-			telefono,
-			correo,
-			cp.CodigoPostal,
-			cp.Estado,
-			cp.Municipio,
-			cp.Asentamiento,
-			calle,
-			numero,
-			carrera,
-			generacion.ID,
-			estatusEgresado,
-		)
+		err := egresados.Create(ctx, &models.Egresado{
+			Matricula:      matricula,
+			NombreCompleto: nombreCompleto,
+			Genero:         &genero,
+			Telefono:       &telefono,
+			Correo:         &correo,
+			CodigoPostal:   &cp.CodigoPostal,
+			Estado:         &cp.Estado,
+			Municipio:      &cp.Municipio,
+			Asentamiento:   &cp.Asentamiento,
+			Calle:          &calle,
+			Numero:         &numero,
+			IDCarrera:      carrera,
+			IDGeneracion:   generacion.ID,
+			IDEstatus:      estatusEgresado,
+			IDPlantel:      idPlantel,
+		})
 
 		if err != nil {
 			log.Printf("⚠️ Error al insertar %s: %v\n", matricula, err)
@@ -162,22 +165,35 @@ type CodigoPostalData struct {
 // =====================================================
 
 func generarMatriculaUES(plantel string, anioInicio int, consecutivo int) string {
-	// Formato: PP-AA-NNNN
+	// Formato: PP-AA-NNNN (ver internal/matricula)
 	// Ejemplo: 13220030 = Plantel 13, Generación 2022, Alumno 30
-	
-	// Extraer los últimos 2 dígitos del año
-	anio2Digitos := anioInicio % 100
-	
-	// Formatear con ceros a la izquierda
-	matricula := fmt.Sprintf("%s%02d%04d", plantel, anio2Digitos, consecutivo)
-	
-	return matricula
+	m, err := matricula.Nueva(plantel, anioInicio, consecutivo)
+	if err != nil {
+		log.Fatalf("❌ No se pudo generar la matrícula: %v", err)
+	}
+	return m.String()
 }
 
 // =====================================================
 // OBTENER CATÁLOGOS
 // =====================================================
 
+// obtenerPlantel registra el plantel si aún no existe y devuelve su ID
+func obtenerPlantel(clave string) int {
+	_, err := config.DB.Exec("INSERT IGNORE INTO planteles (clave, nombre) VALUES (?, ?)", clave, "Plantel "+clave)
+	if err != nil {
+		log.Printf("⚠️ Error al registrar plantel: %v\n", err)
+		return 0
+	}
+
+	var id int
+	if err := config.DB.QueryRow("SELECT id_plantel FROM planteles WHERE clave = ?", clave).Scan(&id); err != nil {
+		log.Printf("⚠️ Error al obtener plantel: %v\n", err)
+		return 0
+	}
+	return id
+}
+
 func obtenerCarreras() []int {
 	rows, err := config.DB.Query("SELECT id_carrera FROM carreras")
 	if err != nil {
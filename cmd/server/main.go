@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
+	"ues-egresados/internal/autenticacion"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/cifrado"
 	"ues-egresados/internal/config"
 	"ues-egresados/internal/handlers"
 	"ues-egresados/internal/middleware"
+	"ues-egresados/internal/migrations"
+	"ues-egresados/internal/papelera"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -18,16 +27,53 @@ func main() {
 		log.Println("No se encontró archivo .env, usando variables del sistema")
 	}
 
+	// Encabezado del proxy con la IP del cliente
+	utils.ConfigurarProxy(utils.ProxyDesdeEntorno())
+
+	// Llave de los tokens CSRF de quien aún no inicia sesión
+	middleware.ConfigurarCSRF(middleware.LlaveCSRFDesdeEntorno())
+
 	// Conectar a la base de datos
 	if err := config.InitDB(); err != nil {
 		log.Fatal("Error al conectar con la base de datos:", err)
 	}
 	defer config.CloseDB()
 
-	// Inicializar sesiones
-	config.InitSession()
+	// Aplicar migraciones pendientes si está habilitado
+	if os.Getenv("AUTO_MIGRATE") == "true" {
+		migrator, err := migrations.New(config.DB)
+		if err != nil {
+			log.Fatal("Error al cargar migraciones:", err)
+		}
+		n, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatal("Error al aplicar migraciones:", err)
+		}
+		log.Printf("✅ Esquema actualizado (%d migraciones aplicadas)", n)
+	}
+
+	// Llaves para cifrar los datos personales de los egresados
+	cifrador, err := cifrado.DesdeEntorno()
+	if err != nil {
+		log.Fatal("Error en la configuración de cifrado:", err)
+	}
+
+	// Inicializar controladores con los repositorios de MySQL
+	repos := repository.NewMySQL(config.DB, cifrador)
+	h := handlers.NewHandler(repos)
+
+	// Crear o activar el administrador inicial si se indicó ADMIN_INICIAL_PASSWORD
+	autenticacion.AdministradorInicialDesdeEntorno(context.Background(), repos.Usuarios)
+
+	// Inicializar sesiones guardadas en MySQL y purgar las vencidas cada hora
+	config.InitSession(repos.Sesiones)
+	go config.SessionStore.Iniciar(context.Background(), time.Hour)
+	config.InitTokensAPI(repos.TokensAPI, repos.Usuarios)
 	log.Println("✅ Sesiones inicializadas")
 
+	// Purgar periódicamente la papelera según PAPELERA_RETENCION_DIAS
+	go papelera.NuevoPurgador(repos, papelera.RetencionDesdeEntorno()).Iniciar(context.Background(), 24*time.Hour)
+
 	// Inicializar router
 	r := mux.NewRouter()
 
@@ -36,55 +82,122 @@ func main() {
 		http.FileServer(http.Dir("web/static"))))
 
 	// Rutas públicas
-	r.HandleFunc("/", handlers.LoginPage).Methods("GET")
-	r.HandleFunc("/login", handlers.Login).Methods("POST")
-	r.HandleFunc("/logout", handlers.Logout).Methods("GET")
-
-	// Rutas protegidas (requieren autenticación)
+	r.HandleFunc("/", h.LoginPage).Methods("GET")
+	r.Handle("/login", middleware.CSRF(http.HandlerFunc(h.Login))).Methods("POST")
+	r.Handle("/login/2fa", middleware.CSRF(http.HandlerFunc(h.LoginDosFactores))).Methods("POST")
+	r.HandleFunc("/login/sso", h.LoginSSO).Methods("GET")
+	r.HandleFunc("/login/sso/callback", h.CallbackSSO).Methods("GET")
+	r.Handle("/logout", middleware.CSRF(http.HandlerFunc(h.Logout))).Methods("POST")
+	r.HandleFunc("/password/olvido", h.OlvidoPasswordPage).Methods("GET")
+	r.Handle("/password/olvido", middleware.CSRF(http.HandlerFunc(h.SolicitarRestablecimiento))).Methods("POST")
+	r.HandleFunc("/password/restablecer", h.RestablecerPasswordPage).Methods("GET")
+	r.Handle("/password/restablecer", middleware.CSRF(http.HandlerFunc(h.RestablecerPassword))).Methods("POST")
+
+	// Rutas protegidas (requieren autenticación y el permiso de cada ruta)
 	protected := r.PathPrefix("/").Subrouter()
 	protected.Use(middleware.AuthRequired)
+	protected.Use(middleware.ExigirDosFactores)
 
-	protected.HandleFunc("/dashboard", handlers.DashboardPage).Methods("GET")
-	protected.HandleFunc("/egresados", handlers.EgresadosPage).Methods("GET")
-	protected.HandleFunc("/administradores", handlers.AdministradoresPage).Methods("GET")
+	protected.Handle("/dashboard", middleware.WithPermission(auth.PermReportsView, h.DashboardPage)).Methods("GET")
+	protected.Handle("/egresados", middleware.WithPermission(auth.PermEgresadosRead, h.EgresadosPage)).Methods("GET")
+	protected.Handle("/administradores", middleware.WithPermission(auth.PermAdminsManage, h.AdministradoresPage)).Methods("GET")
+	protected.Handle("/catalogos", middleware.WithPermission(auth.PermCatalogosManage, h.CatalogosPage)).Methods("GET")
+	protected.HandleFunc("/seguridad", h.SeguridadPage).Methods("GET")
 
 	// API Routes
 	api := protected.PathPrefix("/api").Subrouter()
+	api.Use(middleware.CSRF)
+
+	// Sesiones del usuario actual
+	api.HandleFunc("/me/sesiones", h.GetMisSesiones).Methods("GET")
+	api.HandleFunc("/me/sesiones", h.CerrarOtrasSesiones).Methods("DELETE")
+	api.HandleFunc("/me/sesiones/{id}", h.RevocarSesion).Methods("DELETE")
+	api.HandleFunc("/me/password", h.CambiarPassword).Methods("POST")
+	api.HandleFunc("/me/tokens", h.GetTokensAPI).Methods("GET")
+	api.HandleFunc("/me/tokens", h.CrearTokenAPI).Methods("POST")
+	api.HandleFunc("/me/tokens/alcances", h.GetAlcancesTokenAPI).Methods("GET")
+	api.HandleFunc("/me/tokens/{id:[0-9]+}", h.RevocarTokenAPI).Methods("DELETE")
+
+	// Verificación en dos pasos del usuario actual
+	api.HandleFunc("/me/2fa", h.GetDosFactores).Methods("GET")
+	api.HandleFunc("/me/2fa", h.IniciarDosFactores).Methods("POST")
+	api.HandleFunc("/me/2fa", h.DesactivarDosFactores).Methods("DELETE")
+	api.HandleFunc("/me/2fa/qr.png", h.GetQRDosFactores).Methods("GET")
+	api.HandleFunc("/me/2fa/activar", h.ActivarDosFactores).Methods("POST")
+	api.HandleFunc("/me/2fa/codigos-recuperacion", h.RegenerarCodigosRecuperacion).Methods("POST")
 
 	// Administradores
-	api.HandleFunc("/administradores", handlers.GetAdministradores).Methods("GET")
-	api.HandleFunc("/administradores", handlers.CreateAdministrador).Methods("POST")
-	api.HandleFunc("/administradores/{id}", handlers.UpdateAdministrador).Methods("PUT")
-	api.HandleFunc("/administradores/{id}", handlers.DeleteAdministrador).Methods("DELETE")
+	api.Handle("/administradores", middleware.WithPermission(auth.PermAdminsManage, h.GetAdministradores)).Methods("GET")
+	api.Handle("/administradores", middleware.WithPermission(auth.PermAdminsManage, h.CreateAdministrador)).Methods("POST")
+	api.Handle("/administradores/{id}", middleware.WithPermission(auth.PermAdminsManage, h.UpdateAdministrador)).Methods("PUT")
+	api.Handle("/administradores/{id}", middleware.WithPermission(auth.PermAdminsManage, h.DeleteAdministrador)).Methods("DELETE")
+	api.Handle("/administradores/{id}/sesiones", middleware.WithPermission(auth.PermAdminsManage, h.CerrarSesionesAdministrador)).Methods("DELETE")
+	api.Handle("/administradores/{id}/2fa", middleware.WithPermission(auth.PermAdminsManage, h.RestablecerDosFactores)).Methods("DELETE")
+
+	// Intentos de inicio de sesión y bloqueos
+	api.Handle("/intentos-login", middleware.WithPermission(auth.PermAdminsManage, h.GetIntentosLogin)).Methods("GET")
+	api.Handle("/bloqueos-login", middleware.WithPermission(auth.PermAdminsManage, h.GetBloqueosLogin)).Methods("GET")
+	api.Handle("/bloqueos-login/{tipo}/{valor}", middleware.WithPermission(auth.PermAdminsManage, h.DesbloquearLogin)).Methods("DELETE")
+
+	// Papelera
+	api.Handle("/papelera", middleware.WithPermission(auth.PermEgresadosDelete, h.GetPapelera)).Methods("GET")
+	api.Handle("/papelera/egresados/{matricula}/restaurar", middleware.WithPermission(auth.PermEgresadosDelete, h.RestaurarEgresado)).Methods("POST")
+	api.Handle("/papelera/egresados/{matricula}", middleware.WithPermission(auth.PermPapeleraPurge, h.PurgarEgresado)).Methods("DELETE")
+	api.Handle("/papelera/usuarios/{id}/restaurar", middleware.WithPermission(auth.PermAdminsManage, h.RestaurarUsuario)).Methods("POST")
+	api.Handle("/papelera/usuarios/{id}", middleware.WithPermission(auth.PermPapeleraPurge, h.PurgarUsuario)).Methods("DELETE")
+
+	// Bitácora de cambios
+	api.Handle("/auditoria", middleware.WithPermission(auth.PermAuditoriaView, h.GetAuditoria)).Methods("GET")
 
 	// Egresados
-	api.HandleFunc("/egresados", handlers.GetEgresados).Methods("GET")
-	api.HandleFunc("/egresados/filtrados", handlers.GetEgresadosFiltrados).Methods("GET")
-	api.HandleFunc("/egresados", handlers.CreateEgresado).Methods("POST")
-	api.HandleFunc("/egresados/{matricula}", handlers.GetEgresado).Methods("GET")
-	api.HandleFunc("/egresados/{matricula}", handlers.UpdateEgresado).Methods("PUT")
-	api.HandleFunc("/egresados/{matricula}", handlers.DeleteEgresado).Methods("DELETE")
+	api.Handle("/egresados", middleware.WithPermission(auth.PermEgresadosRead, h.GetEgresados)).Methods("GET")
+	api.Handle("/egresados/filtrados", middleware.WithPermission(auth.PermEgresadosRead, h.GetEgresadosFiltrados)).Methods("GET")
+	api.Handle("/egresados", middleware.WithPermission(auth.PermEgresadosWrite, h.CreateEgresado)).Methods("POST")
+	api.Handle("/egresados/import", middleware.WithPermission(auth.PermEgresadosWrite, h.ImportarEgresados)).Methods("POST")
+	api.Handle("/egresados/export", middleware.WithPermission(auth.PermEgresadosRead, h.ExportarEgresados)).Methods("GET")
+	api.Handle("/egresados/report.pdf", middleware.WithPermission(auth.PermReportsView, h.GetReportePDF)).Methods("GET")
+	api.Handle("/egresados/siguiente-matricula", middleware.WithPermission(auth.PermEgresadosWrite, h.SiguienteMatricula)).Methods("GET")
+	api.Handle("/egresados/{matricula}", middleware.WithPermission(auth.PermEgresadosRead, h.GetEgresado)).Methods("GET")
+	api.Handle("/egresados/{matricula}/expediente.pdf", middleware.WithPermission(auth.PermEgresadosRead, h.GetExpedientePDF)).Methods("GET")
+	api.Handle("/egresados/{matricula}/historial", middleware.WithPermission(auth.PermEgresadosRead, h.GetHistorialEgresado)).Methods("GET")
+	api.Handle("/egresados/{matricula}", middleware.WithPermission(auth.PermEgresadosWrite, h.UpdateEgresado)).Methods("PUT")
+	api.Handle("/egresados/{matricula}", middleware.WithPermission(auth.PermEgresadosWrite, h.PatchEgresado)).Methods("PATCH")
+	api.Handle("/egresados/{matricula}", middleware.WithPermission(auth.PermEgresadosDelete, h.DeleteEgresado)).Methods("DELETE")
 
 	// Estadísticas de Egresados
-	api.HandleFunc("/egresados/stats/generaciones", handlers.GetGeneracionesStats).Methods("GET")
-	api.HandleFunc("/egresados/stats/carreras/{id_generacion}", handlers.GetCarrerasStatsByGeneracion).Methods("GET")
+	api.Handle("/egresados/stats/generaciones", middleware.WithPermission(auth.PermEgresadosRead, h.GetGeneracionesStats)).Methods("GET")
+	api.Handle("/egresados/stats/carreras/{id_generacion}", middleware.WithPermission(auth.PermEgresadosRead, h.GetCarrerasStatsByGeneracion)).Methods("GET")
+	api.Handle("/egresados/stats/estatus", middleware.WithPermission(auth.PermEgresadosRead, h.GetEstatusStats)).Methods("GET")
 
 	// Códigos Postales
-	api.HandleFunc("/codigo-postal/{cp}", handlers.BuscarPorCodigoPostal).Methods("GET")
-	api.HandleFunc("/estados", handlers.GetEstados).Methods("GET")
-	api.HandleFunc("/municipios/{estado}", handlers.GetMunicipiosPorEstado).Methods("GET")
-	api.HandleFunc("/asentamientos/{municipio}", handlers.GetAsentamientosPorMunicipio).Methods("GET")
+	api.Handle("/codigo-postal/{cp}", middleware.WithPermission(auth.PermEgresadosRead, h.BuscarPorCodigoPostal)).Methods("GET")
+	api.Handle("/estados", middleware.WithPermission(auth.PermEgresadosRead, h.GetEstados)).Methods("GET")
+	api.Handle("/municipios/{estado}", middleware.WithPermission(auth.PermEgresadosRead, h.GetMunicipiosPorEstado)).Methods("GET")
+	api.Handle("/asentamientos/{municipio}", middleware.WithPermission(auth.PermEgresadosRead, h.GetAsentamientosPorMunicipio)).Methods("GET")
 
 	// Catálogos
-	api.HandleFunc("/carreras", handlers.GetCarreras).Methods("GET")
-	api.HandleFunc("/generaciones", handlers.GetGeneraciones).Methods("GET")
-	api.HandleFunc("/estatus", handlers.GetEstatus).Methods("GET")
+	api.Handle("/carreras", middleware.WithPermission(auth.PermEgresadosRead, h.GetCarreras)).Methods("GET")
+	api.Handle("/generaciones", middleware.WithPermission(auth.PermEgresadosRead, h.GetGeneraciones)).Methods("GET")
+	api.Handle("/estatus", middleware.WithPermission(auth.PermEgresadosRead, h.GetEstatus)).Methods("GET")
+	api.Handle("/planteles", middleware.WithPermission(auth.PermEgresadosRead, h.GetPlanteles)).Methods("GET")
+	api.Handle("/carreras", middleware.WithPermission(auth.PermCatalogosManage, h.CreateCarrera)).Methods("POST")
+	api.Handle("/carreras/{id}", middleware.WithPermission(auth.PermCatalogosManage, h.UpdateCarrera)).Methods("PUT")
+	api.Handle("/carreras/{id}", middleware.WithPermission(auth.PermCatalogosManage, h.DeleteCarrera)).Methods("DELETE")
+	api.Handle("/generaciones", middleware.WithPermission(auth.PermCatalogosManage, h.CreateGeneracion)).Methods("POST")
+	api.Handle("/generaciones/{id}", middleware.WithPermission(auth.PermCatalogosManage, h.UpdateGeneracion)).Methods("PUT")
+	api.Handle("/generaciones/{id}", middleware.WithPermission(auth.PermCatalogosManage, h.DeleteGeneracion)).Methods("DELETE")
+	api.Handle("/estatus", middleware.WithPermission(auth.PermCatalogosManage, h.CreateEstatus)).Methods("POST")
+	api.Handle("/estatus/{id}", middleware.WithPermission(auth.PermCatalogosManage, h.UpdateEstatus)).Methods("PUT")
+	api.Handle("/estatus/{id}", middleware.WithPermission(auth.PermCatalogosManage, h.DeleteEstatus)).Methods("DELETE")
+	api.Handle("/planteles", middleware.WithPermission(auth.PermCatalogosManage, h.CreatePlantel)).Methods("POST")
+	api.Handle("/planteles/{id}", middleware.WithPermission(auth.PermCatalogosManage, h.UpdatePlantel)).Methods("PUT")
+	api.Handle("/planteles/{id}", middleware.WithPermission(auth.PermCatalogosManage, h.DeletePlantel)).Methods("DELETE")
 
 	// Rutas públicas sin autenticación
-	r.HandleFunc("/error404", handlers.Error404Handler).Methods("GET")
+	r.HandleFunc("/error404", h.Error404Handler).Methods("GET")
 
 	// Manejador 404 personalizado
-	r.NotFoundHandler = http.HandlerFunc(handlers.Error404Handler)
+	r.NotFoundHandler = http.HandlerFunc(h.Error404Handler)
 
 	// Iniciar servidor
 	port := os.Getenv("SERVER_PORT")
@@ -93,5 +206,8 @@ func main() {
 	}
 
 	log.Printf("🚀 Servidor iniciado en http://localhost:%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	// Los encabezados de seguridad envuelven todo el router para cubrir
+	// también los estáticos y el 404
+	seguridad := middleware.EncabezadosSeguridad(middleware.OpcionesSeguridadDesdeEntorno())
+	log.Fatal(http.ListenAndServe(":"+port, seguridad(r)))
 }
@@ -0,0 +1,141 @@
+package auditoria
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+)
+
+// Entidades auditadas
+const (
+	EntidadEgresado   = "egresado"
+	EntidadUsuario    = "usuario"
+	EntidadCarrera    = "carrera"
+	EntidadGeneracion = "generacion"
+	EntidadEstatus    = "estatus"
+	EntidadPlantel    = "plantel"
+	// EntidadBloqueoLogin registra los desbloqueos; entidad_id es "tipo:valor"
+	EntidadBloqueoLogin = "bloqueo_login"
+	// EntidadDosFactores registra la activación, desactivación y restablecimiento
+	// de la verificación en dos pasos; entidad_id es el ID del usuario
+	EntidadDosFactores = "dos_factores"
+	// EntidadTokenAPI registra la creación y revocación de tokens personales
+	EntidadTokenAPI = "token_api"
+)
+
+// Acciones registradas en la bitácora
+const (
+	AccionCrear      = "crear"
+	AccionActualizar = "actualizar"
+	AccionEliminar   = "eliminar"
+	AccionImportar   = "importar"
+	AccionExportar   = "exportar"
+	AccionRestaurar  = "restaurar"
+	AccionPurgar     = "purgar"
+	// AccionCerrarSesiones registra que un administrador revocó las sesiones de un usuario
+	AccionCerrarSesiones = "cerrar_sesiones"
+)
+
+// EntidadValida indica si el nombre corresponde a una entidad auditada
+func EntidadValida(entidad string) bool {
+	switch entidad {
+	case EntidadEgresado, EntidadUsuario, EntidadCarrera, EntidadGeneracion, EntidadEstatus, EntidadPlantel,
+		EntidadBloqueoLogin, EntidadDosFactores, EntidadTokenAPI:
+		return true
+	}
+	return false
+}
+
+// camposControl son metadatos que cambian en cada actualización y no
+// aportan información a la bitácora
+var camposControl = map[string]bool{"version": true, "updated_at": true}
+
+// Oculto reemplaza en la bitácora los valores que no deben guardarse
+const Oculto = "********"
+
+// camposPersonales son los datos personales que se guardan cifrados (LFPDPPP).
+// La bitácora solo registra que cambiaron, no su valor; el correo de los
+// usuarios también se oculta.
+var camposPersonales = map[string]bool{
+	"telefono":      true,
+	"correo":        true,
+	"codigo_postal": true,
+	"estado":        true,
+	"municipio":     true,
+	"asentamiento":  true,
+	"calle":         true,
+	"numero":        true,
+}
+
+// Cambio es el valor de un campo antes y después de la operación
+type Cambio struct {
+	Antes   interface{} `json:"antes"`
+	Despues interface{} `json:"despues"`
+}
+
+// Diff compara la representación JSON de dos valores y devuelve solo los
+// campos que cambiaron. Cualquiera de los dos puede ser nil (alta o baja).
+// Los campos con la etiqueta json:"-" (como contraseñas) nunca se incluyen y
+// los datos personales se guardan como Oculto.
+func Diff(antes, despues interface{}) (map[string]Cambio, error) {
+	a, err := aMapa(antes)
+	if err != nil {
+		return nil, err
+	}
+	d, err := aMapa(despues)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un campo ausente equivale a null; así un alta o baja no lista campos vacíos
+	cambios := map[string]Cambio{}
+	for _, m := range []map[string]interface{}{a, d} {
+		for campo := range m {
+			if camposControl[campo] {
+				continue
+			}
+			if !reflect.DeepEqual(a[campo], d[campo]) {
+				cambios[campo] = Cambio{Antes: ocultar(campo, a[campo]), Despues: ocultar(campo, d[campo])}
+			}
+		}
+	}
+	return cambios, nil
+}
+
+// ocultar reemplaza el valor de un dato personal; null se conserva para
+// distinguir un alta o una baja del dato
+func ocultar(campo string, valor interface{}) interface{} {
+	if camposPersonales[campo] && valor != nil {
+		return Oculto
+	}
+	return valor
+}
+
+// OcultarFiltros copia los filtros de una consulta de egresados sin los que
+// pueden contener datos personales (la búsqueda libre acepta correo y teléfono)
+func OcultarFiltros(filtros url.Values) url.Values {
+	copia := url.Values{}
+	for nombre, valores := range filtros {
+		if camposPersonales[nombre] || nombre == "q" {
+			valores = []string{Oculto}
+		}
+		copia[nombre] = valores
+	}
+	return copia
+}
+
+func aMapa(v interface{}) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return m, nil
+	}
+
+	datos, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(datos, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
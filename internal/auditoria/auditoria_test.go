@@ -0,0 +1,41 @@
+package auditoria
+
+import (
+	"net/url"
+	"testing"
+	"ues-egresados/internal/models"
+)
+
+func TestDiffOcultaDatosPersonales(t *testing.T) {
+	correo, nuevo, calle := "ana@correo.mx", "ana.lopez@correo.mx", "Av. Obregón 10"
+	antes := &models.Egresado{Matricula: "13201234", NombreCompleto: "Ana López", Correo: &correo}
+	despues := &models.Egresado{Matricula: "13201234", NombreCompleto: "Ana López Ruiz", Correo: &nuevo, Calle: &calle}
+
+	cambios, err := Diff(antes, despues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := cambios["correo"]; c.Antes != Oculto || c.Despues != Oculto {
+		t.Errorf("correo = %+v, se esperaba oculto", c)
+	}
+	if c := cambios["calle"]; c.Antes != nil || c.Despues != Oculto {
+		t.Errorf("calle = %+v, se esperaba null → oculto", c)
+	}
+	if c := cambios["nombre_completo"]; c.Despues != "Ana López Ruiz" {
+		t.Errorf("nombre_completo = %+v, se esperaba el valor", c)
+	}
+	if _, ok := cambios["telefono"]; ok {
+		t.Error("telefono no cambió y no debe aparecer")
+	}
+}
+
+func TestOcultarFiltros(t *testing.T) {
+	filtros := OcultarFiltros(url.Values{"q": {"6671234567"}, "estado": {"Sinaloa"}, "carrera": {"3"}})
+	if filtros.Get("q") != Oculto || filtros.Get("estado") != Oculto {
+		t.Errorf("filtros = %v, se esperaban q y estado ocultos", filtros)
+	}
+	if filtros.Get("carrera") != "3" {
+		t.Errorf("carrera = %q, se esperaba 3", filtros.Get("carrera"))
+	}
+}
@@ -0,0 +1,176 @@
+// Package autenticacion verifica el usuario y la contraseña del login contra
+// una cadena de autenticadores: primero la contraseña local (bcrypt) y después,
+// si está configurado, el directorio LDAP / Active Directory.
+package autenticacion
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/validacion"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Errores de la cadena
+var (
+	// ErrCredenciales indica que el autenticador no reconoce el usuario o la
+	// contraseña; la cadena sigue con el siguiente
+	ErrCredenciales = errors.New("usuario o contraseña incorrectos")
+	// ErrSinAcceso indica credenciales válidas en el directorio, pero sin un
+	// usuario o un rol en el sistema
+	ErrSinAcceso = errors.New("la cuenta no tiene acceso al sistema")
+)
+
+// Resultado es el usuario autenticado y, si el autenticador lo creó o
+// actualizó con datos externos, lo necesario para auditarlo
+type Resultado struct {
+	Usuario *models.Usuario
+	Origen  string
+	// Anterior son los datos previos a la sincronización (nil si no cambió nada)
+	Anterior *models.Usuario
+	Creado   bool
+}
+
+// Autenticador verifica un par usuario/contraseña
+type Autenticador interface {
+	Nombre() string
+	// Autenticar devuelve ErrCredenciales si no reconoce las credenciales. Si
+	// el usuario existe puede devolver también un Resultado, que se usa para
+	// registrar el intento fallido.
+	Autenticar(ctx context.Context, usuario, password string) (*Resultado, error)
+}
+
+// Cadena prueba los autenticadores en orden hasta que uno acepta
+type Cadena struct {
+	autenticadores []Autenticador
+}
+
+func NuevaCadena(autenticadores ...Autenticador) *Cadena {
+	return &Cadena{autenticadores: autenticadores}
+}
+
+// DesdeEntorno arma la cadena: contraseña local y, con LDAP_URL, el directorio
+func DesdeEntorno(repos *repository.Repositories) *Cadena {
+	autenticadores := []Autenticador{NuevoLocal(repos.Usuarios)}
+	if conf := LDAPDesdeEntorno(); conf != nil {
+		autenticadores = append(autenticadores, NuevoLDAP(*conf, repos.Usuarios, repos.Catalogos))
+	}
+	return NuevaCadena(autenticadores...)
+}
+
+// Autenticar devuelve el resultado del primer autenticador que acepta. Si
+// ninguno acepta devuelve ErrSinAcceso si alguno lo indicó, el primer error
+// inesperado (por ejemplo, el directorio no responde) o ErrCredenciales.
+func (c *Cadena) Autenticar(ctx context.Context, usuario, password string) (*Resultado, error) {
+	var conocido *Resultado
+	var errFinal error
+	for _, a := range c.autenticadores {
+		resultado, err := a.Autenticar(ctx, usuario, password)
+		if err == nil {
+			resultado.Origen = a.Nombre()
+			return resultado, nil
+		}
+		if conocido == nil && resultado != nil {
+			conocido = resultado
+		}
+
+		switch {
+		case errors.Is(err, ErrCredenciales):
+		case errors.Is(err, ErrSinAcceso):
+			errFinal = err
+		default:
+			log.Printf("Error en la autenticación %s: %v", a.Nombre(), err)
+			if errFinal == nil {
+				errFinal = err
+			}
+		}
+	}
+	if errFinal == nil {
+		errFinal = ErrCredenciales
+	}
+	return conocido, errFinal
+}
+
+// Local compara la contraseña con el hash bcrypt de usuarios
+type Local struct {
+	usuarios repository.UsuarioRepository
+}
+
+func NuevoLocal(usuarios repository.UsuarioRepository) *Local {
+	return &Local{usuarios: usuarios}
+}
+
+func (l *Local) Nombre() string {
+	return "local"
+}
+
+func (l *Local) Autenticar(ctx context.Context, usuario, password string) (*Resultado, error) {
+	u, err := l.usuarios.GetByUsuario(ctx, usuario)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCredenciales
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)); err != nil {
+		return &Resultado{Usuario: u}, ErrCredenciales
+	}
+	return &Resultado{Usuario: u}, nil
+}
+
+// Nombres reparte los datos de un proveedor externo entre nombre y apellidos.
+// apellidos puede traer los dos apellidos; si no hay nombre ni apellidos se
+// divide completo. apellido_paterno es obligatorio, así que a falta de él
+// queda "-" para corregirlo desde Administradores.
+func Nombres(nombre, apellidos, completo string) (string, string, string) {
+	nombre = strings.TrimSpace(nombre)
+	partes := strings.Fields(apellidos)
+	if nombre == "" && len(partes) == 0 {
+		palabras := strings.Fields(completo)
+		switch {
+		case len(palabras) >= 3:
+			nombre = strings.Join(palabras[:len(palabras)-2], " ")
+			partes = palabras[len(palabras)-2:]
+		case len(palabras) == 2:
+			nombre, partes = palabras[0], palabras[1:]
+		case len(palabras) == 1:
+			nombre = palabras[0]
+		}
+	}
+
+	paterno, materno := "-", ""
+	if len(partes) > 0 {
+		paterno = partes[0]
+		materno = strings.Join(partes[1:], " ")
+	}
+	return recortar(nombre), recortar(paterno), recortar(materno)
+}
+
+// recortar limita a las 100 letras de las columnas de nombre
+func recortar(s string) string {
+	if letras := []rune(s); len(letras) > 100 {
+		return string(letras[:100])
+	}
+	return s
+}
+
+// PasswordAleatoria genera una contraseña que cumple la política, para los
+// usuarios que entran con un proveedor externo
+func PasswordAleatoria(usuario string) (string, error) {
+	for {
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		password := "Ext" + hex.EncodeToString(b) + "9"
+		if validacion.Password(password, usuario) == "" {
+			return password, nil
+		}
+	}
+}
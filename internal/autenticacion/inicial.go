@@ -0,0 +1,79 @@
+package autenticacion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/validacion"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UsuarioInicialPorDefecto es la cuenta que se crea si no se indica ADMIN_INICIAL_USUARIO
+const UsuarioInicialPorDefecto = "admin"
+
+// AdministradorInicial crea la cuenta de administrador con la contraseña dada,
+// o se la asigna si la cuenta existe pero no tiene contraseña (por ejemplo, la
+// que dejó desactivada la migración 0018). Nunca reemplaza una contraseña ya
+// establecida. Devuelve true si creó o activó la cuenta.
+func AdministradorInicial(ctx context.Context, usuarios repository.UsuarioRepository, usuario, password string) (bool, error) {
+	if msg := validacion.Password(password, usuario); msg != "" {
+		return false, errors.New(msg)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, err
+	}
+
+	u, err := usuarios.GetByUsuario(ctx, usuario)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		if _, err := usuarios.Create(ctx, &models.Usuario{
+			Usuario:         usuario,
+			Nombre:          "Administrador",
+			ApellidoPaterno: "UES",
+			Password:        string(hash),
+			Rol:             auth.RolAdministrador,
+			Origen:          models.OrigenLocal,
+		}); err != nil {
+			return false, fmt.Errorf("error al crear el administrador inicial: %w", err)
+		}
+		return true, nil
+	case err != nil:
+		return false, err
+	case u.Password != "":
+		return false, nil
+	}
+
+	if err := usuarios.CambiarPassword(ctx, u.IDUsuario, string(hash)); err != nil {
+		return false, fmt.Errorf("error al activar el administrador inicial: %w", err)
+	}
+	return true, nil
+}
+
+// AdministradorInicialDesdeEntorno aplica AdministradorInicial con
+// ADMIN_INICIAL_USUARIO y ADMIN_INICIAL_PASSWORD. Sin contraseña no hace nada.
+func AdministradorInicialDesdeEntorno(ctx context.Context, usuarios repository.UsuarioRepository) {
+	password := os.Getenv("ADMIN_INICIAL_PASSWORD")
+	if password == "" {
+		return
+	}
+	usuario := strings.TrimSpace(os.Getenv("ADMIN_INICIAL_USUARIO"))
+	if usuario == "" {
+		usuario = UsuarioInicialPorDefecto
+	}
+
+	activado, err := AdministradorInicial(ctx, usuarios, usuario, password)
+	switch {
+	case err != nil:
+		log.Printf("⚠️  ADMIN_INICIAL_PASSWORD no se aplicó a %q: %v", usuario, err)
+	case activado:
+		log.Printf("✅ Administrador inicial %q listo; retire ADMIN_INICIAL_PASSWORD del entorno", usuario)
+	}
+}
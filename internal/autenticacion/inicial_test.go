@@ -0,0 +1,77 @@
+package autenticacion_test
+
+import (
+	"context"
+	"testing"
+	"ues-egresados/internal/autenticacion"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAdministradorInicialCreaLaCuenta(t *testing.T) {
+	repos := repository.NewMemory()
+
+	activado, err := autenticacion.AdministradorInicial(context.Background(), repos.Usuarios, "admin", "Inicio2026seguro")
+	if err != nil || !activado {
+		t.Fatalf("activado = %v, err = %v", activado, err)
+	}
+
+	u, err := repos.Usuarios.GetByUsuario(context.Background(), "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Rol != auth.RolAdministrador || u.Origen != models.OrigenLocal {
+		t.Errorf("rol = %q, origen = %q", u.Rol, u.Origen)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("Inicio2026seguro")) != nil {
+		t.Error("la contraseña no quedó asignada")
+	}
+}
+
+func TestAdministradorInicialActivaCuentaSinPassword(t *testing.T) {
+	repos := repository.NewMemory()
+	if _, err := repos.Usuarios.Create(context.Background(), &models.Usuario{
+		Usuario: "admin", Nombre: "Administrador", ApellidoPaterno: "UES", Rol: auth.RolAdministrador,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	activado, err := autenticacion.AdministradorInicial(context.Background(), repos.Usuarios, "admin", "Inicio2026seguro")
+	if err != nil || !activado {
+		t.Fatalf("activado = %v, err = %v", activado, err)
+	}
+	u, _ := repos.Usuarios.GetByUsuario(context.Background(), "admin")
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("Inicio2026seguro")) != nil {
+		t.Error("la contraseña no quedó asignada")
+	}
+}
+
+func TestAdministradorInicialNoReemplazaPassword(t *testing.T) {
+	repos := repository.NewMemory()
+	crearUsuario(t, repos, models.Usuario{
+		Usuario: "admin", Nombre: "Administrador", ApellidoPaterno: "UES", Rol: auth.RolAdministrador,
+	}, "Vigente2026clave")
+
+	activado, err := autenticacion.AdministradorInicial(context.Background(), repos.Usuarios, "admin", "Inicio2026seguro")
+	if err != nil || activado {
+		t.Fatalf("activado = %v, err = %v", activado, err)
+	}
+	u, _ := repos.Usuarios.GetByUsuario(context.Background(), "admin")
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("Vigente2026clave")) != nil {
+		t.Error("se reemplazó la contraseña existente")
+	}
+}
+
+func TestAdministradorInicialExigeLaPolitica(t *testing.T) {
+	repos := repository.NewMemory()
+
+	if _, err := autenticacion.AdministradorInicial(context.Background(), repos.Usuarios, "admin", "admin123"); err == nil {
+		t.Fatal("se aceptó una contraseña fuera de la política")
+	}
+	if _, err := repos.Usuarios.GetByUsuario(context.Background(), "admin"); err == nil {
+		t.Error("se creó la cuenta con una contraseña inválida")
+	}
+}
@@ -0,0 +1,391 @@
+package autenticacion
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+
+	"github.com/go-ldap/ldap/v3"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// Filtros por defecto; {usuario} es el nombre con el que se inicia sesión
+	// y {dn} el DN de la entrada del usuario
+	filtroUsuarioLDAP = "(|(uid={usuario})(sAMAccountName={usuario}))"
+	filtroGruposLDAP  = "(|(member={dn})(uniqueMember={dn})(memberUid={usuario}))"
+
+	// RolPorDefectoLDAP es el rol de los usuarios creados desde el directorio
+	// cuando no hay grupos mapeados
+	RolPorDefectoLDAP = auth.RolConsulta
+
+	tiempoEsperaLDAP = 10 * time.Second
+)
+
+// Los nombres de usuario se insertan sin escapar en el DN, así que solo se
+// aceptan los caracteres que también permite usuarios.usuario
+var patronUsuarioLDAP = regexp.MustCompile(`^[a-zA-Z0-9._\-]{3,50}$`)
+
+// GrupoRol asigna un rol a los miembros de un grupo del directorio. Grupo
+// puede ser el DN completo o solo el CN.
+type GrupoRol struct {
+	Grupo string
+	Rol   string
+}
+
+// ConfiguracionLDAP es el directorio contra el que se hace el bind
+type ConfiguracionLDAP struct {
+	URL      string // ldap://host:389 o ldaps://host:636
+	StartTLS bool
+	// PlantillaDN forma el DN del bind, por ejemplo
+	// "uid={usuario},ou=people,dc=ues,dc=mx" o "{usuario}@ues.local" en AD
+	PlantillaDN string
+	// BaseDN y Filtro buscan la entrada del usuario después del bind; sin
+	// BaseDN se lee directamente la entrada de PlantillaDN
+	BaseDN string
+	Filtro string
+	// BaseGrupos y FiltroGrupos buscan los grupos del usuario además de
+	// memberOf (OpenLDAP sin el overlay memberof)
+	BaseGrupos   string
+	FiltroGrupos string
+	// GruposRoles en orden de prioridad: gana el primer grupo que coincide.
+	// Si hay grupos configurados, quien no pertenece a ninguno no entra.
+	GruposRoles []GrupoRol
+	// AltaAutomatica crea el usuario la primera vez con el rol del grupo (o
+	// RolAlta si no hay grupos) y el plantel con clave PlantelAlta
+	AltaAutomatica bool
+	RolAlta        string
+	PlantelAlta    string
+}
+
+// LDAPDesdeEntorno lee LDAP_URL, LDAP_STARTTLS, LDAP_DN_USUARIO, LDAP_BASE_DN,
+// LDAP_FILTRO_USUARIO, LDAP_BASE_GRUPOS, LDAP_FILTRO_GRUPOS, LDAP_GRUPOS_ROLES,
+// LDAP_ALTA_AUTOMATICA, LDAP_ROL_POR_DEFECTO y LDAP_PLANTEL_POR_DEFECTO.
+// Devuelve nil si LDAP_URL no está definida.
+func LDAPDesdeEntorno() *ConfiguracionLDAP {
+	direccion := strings.TrimSpace(os.Getenv("LDAP_URL"))
+	if direccion == "" {
+		return nil
+	}
+	conf := &ConfiguracionLDAP{
+		URL:          direccion,
+		StartTLS:     booleanoEntorno("LDAP_STARTTLS"),
+		PlantillaDN:  os.Getenv("LDAP_DN_USUARIO"),
+		BaseDN:       os.Getenv("LDAP_BASE_DN"),
+		Filtro:       os.Getenv("LDAP_FILTRO_USUARIO"),
+		BaseGrupos:   os.Getenv("LDAP_BASE_GRUPOS"),
+		FiltroGrupos: os.Getenv("LDAP_FILTRO_GRUPOS"),
+		RolAlta:      os.Getenv("LDAP_ROL_POR_DEFECTO"),
+		PlantelAlta:  strings.TrimSpace(os.Getenv("LDAP_PLANTEL_POR_DEFECTO")),
+	}
+	if !strings.Contains(conf.PlantillaDN, "{usuario}") {
+		log.Println("⚠️  LDAP_DN_USUARIO debe incluir {usuario}; la autenticación con el directorio queda desactivada")
+		return nil
+	}
+	if conf.Filtro == "" {
+		conf.Filtro = filtroUsuarioLDAP
+	}
+	if conf.FiltroGrupos == "" {
+		conf.FiltroGrupos = filtroGruposLDAP
+	}
+	conf.AltaAutomatica = booleanoEntorno("LDAP_ALTA_AUTOMATICA")
+	if conf.RolAlta == "" {
+		conf.RolAlta = RolPorDefectoLDAP
+	} else if !auth.RolValido(conf.RolAlta) {
+		log.Printf("⚠️  Rol desconocido en LDAP_ROL_POR_DEFECTO: %q, usando %s", conf.RolAlta, RolPorDefectoLDAP)
+		conf.RolAlta = RolPorDefectoLDAP
+	}
+
+	// "Administrador:cn=admins,ou=grupos,dc=ues,dc=mx;Operador:operadores"
+	for _, par := range strings.Split(os.Getenv("LDAP_GRUPOS_ROLES"), ";") {
+		if strings.TrimSpace(par) == "" {
+			continue
+		}
+		rol, grupo, ok := strings.Cut(par, ":")
+		rol, grupo = strings.TrimSpace(rol), strings.TrimSpace(grupo)
+		if !ok || grupo == "" || !auth.RolValido(rol) {
+			log.Printf("⚠️  Se ignora %q en LDAP_GRUPOS_ROLES (se espera Rol:grupo)", par)
+			continue
+		}
+		conf.GruposRoles = append(conf.GruposRoles, GrupoRol{Grupo: grupo, Rol: rol})
+	}
+	return conf
+}
+
+func booleanoEntorno(nombre string) bool {
+	valor := os.Getenv(nombre)
+	if valor == "" {
+		return false
+	}
+	activo, err := strconv.ParseBool(valor)
+	if err != nil {
+		log.Printf("⚠️  %s inválido (%q), se deja desactivado", nombre, valor)
+	}
+	return activo
+}
+
+// LDAP autentica con un bind al directorio y sincroniza el nombre y el rol del
+// usuario en cada inicio de sesión
+type LDAP struct {
+	conf      ConfiguracionLDAP
+	usuarios  repository.UsuarioRepository
+	catalogos repository.CatalogoRepository
+}
+
+func NuevoLDAP(conf ConfiguracionLDAP, usuarios repository.UsuarioRepository, catalogos repository.CatalogoRepository) *LDAP {
+	return &LDAP{conf: conf, usuarios: usuarios, catalogos: catalogos}
+}
+
+func (l *LDAP) Nombre() string {
+	return "ldap"
+}
+
+// entradaLDAP son los datos del usuario en el directorio
+type entradaLDAP struct {
+	dn        string
+	nombre    string
+	apellidos string
+	completo  string
+	grupos    []string
+}
+
+func (l *LDAP) Autenticar(ctx context.Context, usuario, password string) (*Resultado, error) {
+	// Un bind sin contraseña es anónimo y el servidor lo acepta
+	if password == "" || !patronUsuarioLDAP.MatchString(usuario) {
+		return nil, ErrCredenciales
+	}
+
+	entrada, err := l.consultar(usuario, password)
+	if err != nil {
+		return nil, err
+	}
+
+	rol := ""
+	if len(l.conf.GruposRoles) > 0 {
+		rol = l.rolDeGrupos(entrada.grupos)
+		if rol == "" {
+			log.Printf("El usuario %s del directorio no pertenece a ningún grupo de LDAP_GRUPOS_ROLES", usuario)
+			return nil, ErrSinAcceso
+		}
+	}
+
+	u, err := l.usuarios.GetByUsuario(ctx, usuario)
+	if errors.Is(err, repository.ErrNotFound) {
+		if !l.conf.AltaAutomatica {
+			log.Printf("El usuario %s del directorio no existe en el sistema", usuario)
+			return nil, ErrSinAcceso
+		}
+		return l.alta(ctx, usuario, rol, entrada)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Una cuenta local con el mismo nombre no es la del directorio: aceptarla
+	// dejaría entrar a quien tenga ese nombre en el directorio
+	if u.Origen != models.OrigenLDAP {
+		log.Printf("⚠️  El usuario %s del directorio coincide con una cuenta %s del sistema; se rechaza", usuario, u.Origen)
+		return nil, ErrSinAcceso
+	}
+	return l.sincronizar(ctx, u, rol, entrada)
+}
+
+// consultar hace el bind como el usuario y lee su entrada y sus grupos
+func (l *LDAP) consultar(usuario, password string) (*entradaLDAP, error) {
+	conn, err := ldap.DialURL(l.conf.URL, ldap.DialWithDialer(&net.Dialer{Timeout: tiempoEsperaLDAP}))
+	if err != nil {
+		return nil, fmt.Errorf("conectar a %s: %w", l.conf.URL, err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(tiempoEsperaLDAP)
+
+	if l.conf.StartTLS {
+		servidor, err := url.Parse(l.conf.URL)
+		if err != nil {
+			return nil, err
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: servidor.Hostname()}); err != nil {
+			return nil, fmt.Errorf("StartTLS: %w", err)
+		}
+	}
+
+	dn := strings.ReplaceAll(l.conf.PlantillaDN, "{usuario}", usuario)
+	if err := conn.Bind(dn, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrCredenciales
+		}
+		return nil, fmt.Errorf("bind: %w", err)
+	}
+
+	atributos := []string{"givenName", "sn", "displayName", "cn", "memberOf"}
+	busqueda := ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)", atributos, nil)
+	if l.conf.BaseDN != "" {
+		filtro := strings.ReplaceAll(l.conf.Filtro, "{usuario}", ldap.EscapeFilter(usuario))
+		busqueda = ldap.NewSearchRequest(l.conf.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+			filtro, atributos, nil)
+	}
+	respuesta, err := conn.Search(busqueda)
+	if err != nil {
+		return nil, fmt.Errorf("buscar la entrada de %s: %w", usuario, err)
+	}
+	if len(respuesta.Entries) != 1 {
+		return nil, fmt.Errorf("se esperaba una entrada para %s y hay %d", usuario, len(respuesta.Entries))
+	}
+
+	e := respuesta.Entries[0]
+	entrada := &entradaLDAP{
+		dn:        e.DN,
+		nombre:    e.GetAttributeValue("givenName"),
+		apellidos: e.GetAttributeValue("sn"),
+		completo:  e.GetAttributeValue("displayName"),
+		grupos:    e.GetAttributeValues("memberOf"),
+	}
+	if entrada.completo == "" {
+		entrada.completo = e.GetAttributeValue("cn")
+	}
+
+	if l.conf.BaseGrupos != "" {
+		filtro := strings.NewReplacer("{dn}", ldap.EscapeFilter(e.DN), "{usuario}", ldap.EscapeFilter(usuario)).Replace(l.conf.FiltroGrupos)
+		grupos, err := conn.Search(ldap.NewSearchRequest(l.conf.BaseGrupos, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			filtro, []string{"cn"}, nil))
+		if err != nil {
+			return nil, fmt.Errorf("buscar los grupos de %s: %w", usuario, err)
+		}
+		for _, g := range grupos.Entries {
+			entrada.grupos = append(entrada.grupos, g.DN)
+		}
+	}
+	return entrada, nil
+}
+
+// rolDeGrupos devuelve el rol del primer grupo configurado al que pertenece
+func (l *LDAP) rolDeGrupos(grupos []string) string {
+	for _, gr := range l.conf.GruposRoles {
+		for _, dn := range grupos {
+			if strings.EqualFold(dn, gr.Grupo) || strings.EqualFold(cnDeDN(dn), gr.Grupo) {
+				return gr.Rol
+			}
+		}
+	}
+	return ""
+}
+
+func cnDeDN(dn string) string {
+	parseado, err := ldap.ParseDN(dn)
+	if err != nil || len(parseado.RDNs) == 0 {
+		return ""
+	}
+	for _, atributo := range parseado.RDNs[0].Attributes {
+		if strings.EqualFold(atributo.Type, "cn") {
+			return atributo.Value
+		}
+	}
+	return ""
+}
+
+// alta crea el usuario con una contraseña local aleatoria; sigue entrando con
+// la del directorio
+func (l *LDAP) alta(ctx context.Context, usuario, rol string, entrada *entradaLDAP) (*Resultado, error) {
+	if rol == "" {
+		rol = l.conf.RolAlta
+	}
+	u := &models.Usuario{Usuario: usuario, Rol: rol, Origen: models.OrigenLDAP}
+	u.Nombre, u.ApellidoPaterno, u.ApellidoMaterno = Nombres(entrada.nombre, entrada.apellidos, entrada.completo)
+	if u.Nombre == "" {
+		u.Nombre = usuario
+	}
+	if err := l.asignarPlantel(ctx, u); err != nil {
+		return nil, err
+	}
+
+	password, err := PasswordAleatoria(usuario)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	u.Password = string(hash)
+
+	id, err := l.usuarios.Create(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	creado, err := l.usuarios.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Usuario %s dado de alta desde el directorio", usuario)
+	return &Resultado{Usuario: creado, Creado: true}, nil
+}
+
+// sincronizar copia el nombre del directorio y, si hay grupos mapeados, el
+// rol. Solo se llama con cuentas de origen ldap.
+func (l *LDAP) sincronizar(ctx context.Context, u *models.Usuario, rol string, entrada *entradaLDAP) (*Resultado, error) {
+	antes := *u
+	nombre, paterno, materno := Nombres(entrada.nombre, entrada.apellidos, entrada.completo)
+	if nombre != "" {
+		u.Nombre = nombre
+		// Sin apellidos en el directorio se conservan los capturados
+		if paterno != "-" {
+			u.ApellidoPaterno, u.ApellidoMaterno = paterno, materno
+		}
+	}
+	if rol != "" && rol != u.Rol {
+		u.Rol = rol
+		if err := l.asignarPlantel(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+
+	if u.Nombre == antes.Nombre && u.ApellidoPaterno == antes.ApellidoPaterno &&
+		u.ApellidoMaterno == antes.ApellidoMaterno && u.Rol == antes.Rol {
+		return &Resultado{Usuario: u}, nil
+	}
+
+	// Update conserva la contraseña si Password va vacío
+	cambios := *u
+	cambios.Password = ""
+	if err := l.usuarios.Update(ctx, &cambios); err != nil {
+		return nil, err
+	}
+	actualizado, err := l.usuarios.GetByID(ctx, u.IDUsuario)
+	if err != nil {
+		return nil, err
+	}
+	if antes.Rol != actualizado.Rol {
+		log.Printf("🔄 Rol de %s sincronizado desde el directorio: %s → %s", u.Usuario, antes.Rol, actualizado.Rol)
+	}
+	return &Resultado{Usuario: actualizado, Anterior: &antes}, nil
+}
+
+// asignarPlantel pone el plantel de LDAP_PLANTEL_POR_DEFECTO cuando el rol lo
+// requiere y el usuario no tiene uno
+func (l *LDAP) asignarPlantel(ctx context.Context, u *models.Usuario) error {
+	if auth.HasPermission(u.Rol, auth.PermPlantelesTodos) || u.IDPlantel != nil {
+		return nil
+	}
+	if l.conf.PlantelAlta == "" {
+		log.Printf("El rol %s de %s requiere plantel y LDAP_PLANTEL_POR_DEFECTO no está definida", u.Rol, u.Usuario)
+		return ErrSinAcceso
+	}
+	plantel, err := l.catalogos.GetPlantelPorClave(ctx, l.conf.PlantelAlta)
+	if err != nil {
+		return fmt.Errorf("plantel %s de LDAP_PLANTEL_POR_DEFECTO: %w", l.conf.PlantelAlta, err)
+	}
+	u.IDPlantel = &plantel.IDPlantel
+	return nil
+}
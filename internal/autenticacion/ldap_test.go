@@ -0,0 +1,322 @@
+package autenticacion_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"ues-egresados/internal/autenticacion"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// entradaDirectorio es una persona del directorio de prueba
+type entradaDirectorio struct {
+	password  string
+	atributos map[string][]string
+}
+
+// directorioPrueba es un servidor LDAP mínimo en proceso: acepta binds
+// simples contra sus entradas y responde búsquedas de objeto base
+type directorioPrueba struct {
+	listener net.Listener
+	entradas map[string]entradaDirectorio // por DN en minúsculas
+}
+
+// Operaciones y resultados de LDAPv3 (RFC 4511) que usa la prueba
+const (
+	opBind        = 0
+	opBindResp    = 1
+	opUnbind      = 2
+	opSearch      = 3
+	opSearchEntry = 4
+	opSearchDone  = 5
+
+	resultadoExito                 = 0
+	resultadoObjetoInexistente     = 32
+	resultadoCredencialesInvalidas = 49
+)
+
+func nuevoDirectorio(t *testing.T) *directorioPrueba {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &directorioPrueba{listener: listener, entradas: map[string]entradaDirectorio{}}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go d.atender(conn)
+		}
+	}()
+	return d
+}
+
+func (d *directorioPrueba) URL() string {
+	return "ldap://" + d.listener.Addr().String()
+}
+
+func (d *directorioPrueba) agregar(usuario, password, nombre, apellidos string, grupos ...string) {
+	d.entradas[dnPrueba(usuario)] = entradaDirectorio{
+		password: password,
+		atributos: map[string][]string{
+			"givenName": {nombre},
+			"sn":        {apellidos},
+			"memberOf":  grupos,
+		},
+	}
+}
+
+func dnPrueba(usuario string) string {
+	return strings.ToLower("uid=" + usuario + ",ou=people,dc=ues,dc=mx")
+}
+
+func (d *directorioPrueba) atender(conn net.Conn) {
+	defer conn.Close()
+	for {
+		paquete, err := ber.ReadPacket(conn)
+		if err != nil || len(paquete.Children) < 2 {
+			return
+		}
+		id, _ := paquete.Children[0].Value.(int64)
+		op := paquete.Children[1]
+
+		switch op.Tag {
+		case opBind:
+			dn := strings.ToLower(op.Children[1].Data.String())
+			password := op.Children[2].Data.String()
+			codigo := int64(resultadoExito)
+			if e, ok := d.entradas[dn]; !ok || password == "" || e.password != password {
+				codigo = resultadoCredencialesInvalidas
+			}
+			conn.Write(mensaje(id, resultado(opBindResp, codigo)).Bytes())
+		case opSearch:
+			dn := strings.ToLower(op.Children[0].Data.String())
+			e, ok := d.entradas[dn]
+			if !ok {
+				conn.Write(mensaje(id, resultado(opSearchDone, resultadoObjetoInexistente)).Bytes())
+				continue
+			}
+			conn.Write(mensaje(id, entradaBER(dn, e.atributos)).Bytes())
+			conn.Write(mensaje(id, resultado(opSearchDone, resultadoExito)).Bytes())
+		case opUnbind:
+			return
+		}
+	}
+}
+
+func mensaje(id int64, op *ber.Packet) *ber.Packet {
+	m := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	m.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	m.AppendChild(op)
+	return m
+}
+
+func resultado(op ber.Tag, codigo int64) *ber.Packet {
+	r := ber.Encode(ber.ClassApplication, ber.TypeConstructed, op, nil, "")
+	r.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, codigo, ""))
+	r.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	r.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	return r
+}
+
+func entradaBER(dn string, atributos map[string][]string) *ber.Packet {
+	e := ber.Encode(ber.ClassApplication, ber.TypeConstructed, opSearchEntry, nil, "")
+	e.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+	lista := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	for nombre, valores := range atributos {
+		atributo := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		atributo.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, nombre, ""))
+		conjunto := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		for _, v := range valores {
+			conjunto.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, ""))
+		}
+		atributo.AppendChild(conjunto)
+		lista.AppendChild(atributo)
+	}
+	e.AppendChild(lista)
+	return e
+}
+
+// confLDAP apunta al directorio de prueba con el grupo "operadores" como
+// Operador y "coordinacion" como Coordinación
+func confLDAP(d *directorioPrueba) autenticacion.ConfiguracionLDAP {
+	return autenticacion.ConfiguracionLDAP{
+		URL:         d.URL(),
+		PlantillaDN: "uid={usuario},ou=people,dc=ues,dc=mx",
+		GruposRoles: []autenticacion.GrupoRol{
+			{Grupo: "coordinacion", Rol: auth.RolCoordinacion},
+			{Grupo: "operadores", Rol: auth.RolOperador},
+		},
+		RolAlta:     auth.RolConsulta,
+		PlantelAlta: "07",
+	}
+}
+
+func reposLDAP(t *testing.T) *repository.Repositories {
+	t.Helper()
+	repos := repository.NewMemory()
+	repos.Catalogos.(*repository.CatalogoMemory).AddPlantel(models.Plantel{IDPlantel: 3, Clave: "07", Nombre: "Culiacán"})
+	return repos
+}
+
+func crearUsuario(t *testing.T, repos *repository.Repositories, u models.Usuario, password string) *models.Usuario {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Password = string(hash)
+	id, err := repos.Usuarios.Create(context.Background(), &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creado, err := repos.Usuarios.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return creado
+}
+
+const grupoCoordinacion = "cn=coordinacion,ou=grupos,dc=ues,dc=mx"
+
+func TestLDAPAltaAutomaticaMarcaOrigen(t *testing.T) {
+	d := nuevoDirectorio(t)
+	d.agregar("ana.ruiz", "secreta-del-directorio", "Ana", "Ruiz Soto", grupoCoordinacion)
+	repos := reposLDAP(t)
+	conf := confLDAP(d)
+	conf.AltaAutomatica = true
+	ldap := autenticacion.NuevoLDAP(conf, repos.Usuarios, repos.Catalogos)
+
+	resultado, err := ldap.Autenticar(context.Background(), "ana.ruiz", "secreta-del-directorio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := resultado.Usuario
+	if !resultado.Creado || u.Origen != models.OrigenLDAP || u.Rol != auth.RolCoordinacion {
+		t.Errorf("alta = creado %v, origen %q, rol %q", resultado.Creado, u.Origen, u.Rol)
+	}
+	if u.Nombre != "Ana" || u.ApellidoPaterno != "Ruiz" || u.ApellidoMaterno != "Soto" {
+		t.Errorf("nombre = %q %q %q", u.Nombre, u.ApellidoPaterno, u.ApellidoMaterno)
+	}
+}
+
+func TestLDAPSincronizaCuentaDelDirectorio(t *testing.T) {
+	d := nuevoDirectorio(t)
+	d.agregar("ana.ruiz", "secreta-del-directorio", "Ana", "Ruiz Soto", grupoCoordinacion)
+	repos := reposLDAP(t)
+	plantel := 3
+	crearUsuario(t, repos, models.Usuario{
+		Usuario: "ana.ruiz", Nombre: "A", ApellidoPaterno: "-", Rol: auth.RolOperador,
+		IDPlantel: &plantel, Origen: models.OrigenLDAP,
+	}, "aleatoria-local-1")
+	ldap := autenticacion.NuevoLDAP(confLDAP(d), repos.Usuarios, repos.Catalogos)
+
+	resultado, err := ldap.Autenticar(context.Background(), "ana.ruiz", "secreta-del-directorio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resultado.Anterior == nil || resultado.Usuario.Rol != auth.RolCoordinacion || resultado.Usuario.Nombre != "Ana" {
+		t.Errorf("sincronización = %+v (antes %+v)", resultado.Usuario, resultado.Anterior)
+	}
+	if resultado.Usuario.Origen != models.OrigenLDAP {
+		t.Errorf("Origen = %q, la sincronización no debe cambiarlo", resultado.Usuario.Origen)
+	}
+}
+
+func TestLDAPRechazaColisionConCuentaLocal(t *testing.T) {
+	d := nuevoDirectorio(t)
+	// Alguien crea en el directorio una entrada con el nombre del administrador
+	d.agregar("admin", "del-directorio", "Intruso", "Apellido", grupoCoordinacion)
+	repos := reposLDAP(t)
+	local := crearUsuario(t, repos, models.Usuario{
+		Usuario: "admin", Nombre: "Administrador", ApellidoPaterno: "Sistema", Rol: auth.RolAdministrador,
+	}, "local-del-admin")
+	if local.Origen != models.OrigenLocal {
+		t.Fatalf("Origen por defecto = %q, se esperaba local", local.Origen)
+	}
+
+	conf := confLDAP(d)
+	conf.AltaAutomatica = true
+	ldap := autenticacion.NuevoLDAP(conf, repos.Usuarios, repos.Catalogos)
+	if _, err := ldap.Autenticar(context.Background(), "admin", "del-directorio"); !errors.Is(err, autenticacion.ErrSinAcceso) {
+		t.Fatalf("Autenticar con la cuenta del directorio = %v, se esperaba ErrSinAcceso", err)
+	}
+
+	// La cadena completa tampoco lo deja entrar, y la cuenta local no cambia
+	cadena := autenticacion.NuevaCadena(autenticacion.NuevoLocal(repos.Usuarios), ldap)
+	resultado, err := cadena.Autenticar(context.Background(), "admin", "del-directorio")
+	if !errors.Is(err, autenticacion.ErrSinAcceso) {
+		t.Errorf("Cadena = %v, se esperaba ErrSinAcceso", err)
+	}
+	if resultado == nil || resultado.Usuario.IDUsuario != local.IDUsuario {
+		t.Errorf("el intento fallido debe quedar asociado a la cuenta local: %+v", resultado)
+	}
+	despues, err := repos.Usuarios.GetByID(context.Background(), local.IDUsuario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if despues.Rol != auth.RolAdministrador || despues.Nombre != "Administrador" || despues.Version != local.Version {
+		t.Errorf("la cuenta local cambió: %+v", despues)
+	}
+
+	// El administrador sigue entrando con su contraseña local
+	if resultado, err := cadena.Autenticar(context.Background(), "admin", "local-del-admin"); err != nil || resultado.Origen != "local" {
+		t.Errorf("login local = %v, %+v", err, resultado)
+	}
+}
+
+func TestLDAPRechazaColisionConCuentaInstitucional(t *testing.T) {
+	d := nuevoDirectorio(t)
+	d.agregar("jperez", "del-directorio", "Juan", "Pérez", grupoCoordinacion)
+	repos := reposLDAP(t)
+	crearUsuario(t, repos, models.Usuario{
+		Usuario: "jperez", Nombre: "Juan", ApellidoPaterno: "Pérez", Rol: auth.RolCoordinacion, Origen: models.OrigenOIDC,
+	}, "aleatoria-local-2")
+	ldap := autenticacion.NuevoLDAP(confLDAP(d), repos.Usuarios, repos.Catalogos)
+
+	if _, err := ldap.Autenticar(context.Background(), "jperez", "del-directorio"); !errors.Is(err, autenticacion.ErrSinAcceso) {
+		t.Errorf("Autenticar = %v, se esperaba ErrSinAcceso", err)
+	}
+}
+
+func TestLDAPCredencialesIncorrectas(t *testing.T) {
+	d := nuevoDirectorio(t)
+	d.agregar("ana.ruiz", "secreta-del-directorio", "Ana", "Ruiz", grupoCoordinacion)
+	repos := reposLDAP(t)
+	conf := confLDAP(d)
+	conf.AltaAutomatica = true
+	ldap := autenticacion.NuevoLDAP(conf, repos.Usuarios, repos.Catalogos)
+
+	for _, password := range []string{"otra", ""} {
+		if _, err := ldap.Autenticar(context.Background(), "ana.ruiz", password); !errors.Is(err, autenticacion.ErrCredenciales) {
+			t.Errorf("Autenticar con %q = %v, se esperaba ErrCredenciales", password, err)
+		}
+	}
+	if _, err := repos.Usuarios.GetByUsuario(context.Background(), "ana.ruiz"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("no se debía crear el usuario: %v", err)
+	}
+}
+
+func TestLDAPSinGrupoNoEntra(t *testing.T) {
+	d := nuevoDirectorio(t)
+	d.agregar("ana.ruiz", "secreta-del-directorio", "Ana", "Ruiz")
+	repos := reposLDAP(t)
+	conf := confLDAP(d)
+	conf.AltaAutomatica = true
+	ldap := autenticacion.NuevoLDAP(conf, repos.Usuarios, repos.Catalogos)
+
+	if _, err := ldap.Autenticar(context.Background(), "ana.ruiz", "secreta-del-directorio"); !errors.Is(err, autenticacion.ErrSinAcceso) {
+		t.Errorf("Autenticar sin grupo = %v, se esperaba ErrSinAcceso", err)
+	}
+}
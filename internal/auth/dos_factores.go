@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// RolesDosFactoresPorDefecto son los roles que deben usar verificación en dos
+// pasos si DOS_FACTORES_ROLES no está definida
+const RolesDosFactoresPorDefecto = RolAdministrador
+
+// RolesDosFactoresDesdeEntorno lee DOS_FACTORES_ROLES, la lista separada por
+// comas de roles para los que la verificación en dos pasos es obligatoria.
+// Con el valor "ninguno" queda como opcional para todos.
+func RolesDosFactoresDesdeEntorno() map[string]bool {
+	valor, definida := os.LookupEnv("DOS_FACTORES_ROLES")
+	if !definida {
+		valor = RolesDosFactoresPorDefecto
+	}
+
+	roles := map[string]bool{}
+	for _, rol := range strings.Split(valor, ",") {
+		rol = strings.TrimSpace(rol)
+		if rol == "" || strings.EqualFold(rol, "ninguno") {
+			continue
+		}
+		if !RolValido(rol) {
+			log.Printf("⚠️  Rol desconocido en DOS_FACTORES_ROLES: %q", rol)
+			continue
+		}
+		roles[rol] = true
+	}
+	return roles
+}
@@ -0,0 +1,75 @@
+package auth
+
+// Permission identifica una acción protegida del sistema
+type Permission string
+
+const (
+	PermEgresadosRead   Permission = "egresados:read"
+	PermEgresadosWrite  Permission = "egresados:write"
+	PermEgresadosDelete Permission = "egresados:delete"
+	PermAdminsManage    Permission = "admins:manage"
+	PermReportsView     Permission = "reports:view"
+	PermAuditoriaView   Permission = "auditoria:view"
+	PermPapeleraPurge   Permission = "papelera:purge"
+	PermCatalogosManage Permission = "catalogos:manage"
+	// PermPlantelesTodos permite ver y editar egresados de cualquier plantel;
+	// sin él, el usuario queda limitado al plantel que tiene asignado
+	PermPlantelesTodos Permission = "planteles:todos"
+)
+
+// Roles disponibles para los usuarios del sistema
+const (
+	RolAdministrador = "Administrador"
+	RolOperador      = "Operador" // Capturista: registra y edita egresados
+	RolConsulta      = "Consulta"
+	RolCoordinacion  = "Coordinación" // Consulta de todos los planteles
+)
+
+var permisosPorRol = map[string][]Permission{
+	RolAdministrador: {
+		PermEgresadosRead,
+		PermEgresadosWrite,
+		PermEgresadosDelete,
+		PermAdminsManage,
+		PermReportsView,
+		PermAuditoriaView,
+		PermPapeleraPurge,
+		PermCatalogosManage,
+		PermPlantelesTodos,
+	},
+	RolOperador: {
+		PermEgresadosRead,
+		PermEgresadosWrite,
+		PermReportsView,
+	},
+	RolConsulta: {
+		PermEgresadosRead,
+		PermReportsView,
+	},
+	RolCoordinacion: {
+		PermEgresadosRead,
+		PermReportsView,
+		PermPlantelesTodos,
+	},
+}
+
+// RolValido indica si el rol existe en el catálogo de roles
+func RolValido(rol string) bool {
+	_, ok := permisosPorRol[rol]
+	return ok
+}
+
+// PermisosDeRol devuelve los permisos asignados a un rol
+func PermisosDeRol(rol string) []Permission {
+	return permisosPorRol[rol]
+}
+
+// HasPermission indica si el rol tiene asignado el permiso
+func HasPermission(rol string, permiso Permission) bool {
+	for _, p := range permisosPorRol[rol] {
+		if p == permiso {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+// Package cifrado protege en la aplicación los datos personales de los
+// egresados (LFPDPPP) con cifrado de sobre: cada fila tiene su propia llave de
+// datos AES-256-GCM, que se guarda envuelta con la llave maestra activa. Las
+// llaves maestras llevan un ID para poder rotarlas sin perder lo cifrado con
+// las anteriores. Los índices ciegos (HMAC-SHA256 con una llave aparte)
+// permiten buscar por coincidencia exacta sin descifrar.
+package cifrado
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TamanoLlave es el tamaño en bytes de las llaves (AES-256)
+const TamanoLlave = 32
+
+// Campos con índice ciego. El nombre forma parte del HMAC para que un mismo
+// valor tenga índices distintos en columnas distintas.
+const (
+	CampoCorreo    = "correo"
+	CampoTelefono  = "telefono"
+	CampoEstado    = "estado"
+	CampoMunicipio = "municipio"
+)
+
+// ErrDescifrar indica que el dato no corresponde a la llave o fue alterado
+var ErrDescifrar = errors.New("no se pudo descifrar el dato")
+
+var idLlaveValido = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// Cifrador guarda las llaves maestras (por ID) y la llave de los índices
+type Cifrador struct {
+	maestras map[string]cipher.AEAD
+	activa   string
+	indices  []byte
+}
+
+// Nuevo crea un cifrador. maestras relaciona cada ID con su llave de 32
+// bytes; activa es la que envuelve las llaves de datos nuevas.
+func Nuevo(maestras map[string][]byte, activa string, indices []byte) (*Cifrador, error) {
+	if len(maestras) == 0 {
+		return nil, errors.New("no hay llaves maestras")
+	}
+	c := &Cifrador{maestras: map[string]cipher.AEAD{}, activa: activa}
+	for id, llave := range maestras {
+		if !idLlaveValido.MatchString(id) {
+			return nil, fmt.Errorf("ID de llave inválido %q (letras, números, '-' o '_', hasta 32)", id)
+		}
+		aead, err := nuevoAEAD(llave)
+		if err != nil {
+			return nil, fmt.Errorf("llave %s: %w", id, err)
+		}
+		c.maestras[id] = aead
+	}
+	if _, ok := c.maestras[activa]; !ok {
+		return nil, fmt.Errorf("la llave activa %q no está entre las llaves maestras", activa)
+	}
+	if len(indices) != TamanoLlave {
+		return nil, fmt.Errorf("la llave de índices debe medir %d bytes", TamanoLlave)
+	}
+	for id, llave := range maestras {
+		if hmac.Equal(llave, indices) {
+			return nil, fmt.Errorf("la llave de índices no puede ser igual a la llave %s", id)
+		}
+	}
+	c.indices = append([]byte(nil), indices...)
+	return c, nil
+}
+
+// DesdeEntorno lee CIFRADO_LLAVES ("id:base64,id:base64"),
+// CIFRADO_LLAVE_ACTIVA (por defecto la primera de la lista) y
+// CIFRADO_LLAVE_INDICES (base64). Sin CIFRADO_LLAVES devuelve nil: los datos
+// se guardan sin cifrar, solo aceptable en desarrollo. Una configuración
+// incompleta o inválida es un error, para no escribir datos con la llave
+// equivocada.
+func DesdeEntorno() (*Cifrador, error) {
+	lista := strings.TrimSpace(os.Getenv("CIFRADO_LLAVES"))
+	if lista == "" {
+		log.Println("⚠️  CIFRADO_LLAVES no está definida: los datos personales de los egresados se guardan sin cifrar")
+		return nil, nil
+	}
+
+	maestras := map[string][]byte{}
+	activa := strings.TrimSpace(os.Getenv("CIFRADO_LLAVE_ACTIVA"))
+	for _, entrada := range strings.Split(lista, ",") {
+		id, valor, ok := strings.Cut(strings.TrimSpace(entrada), ":")
+		if !ok {
+			return nil, fmt.Errorf("CIFRADO_LLAVES: se esperaba id:llave, se recibió %q", entrada)
+		}
+		id = strings.TrimSpace(id)
+		if _, repetida := maestras[id]; repetida {
+			return nil, fmt.Errorf("CIFRADO_LLAVES: la llave %s está repetida", id)
+		}
+		llave, err := decodificarLlave(valor)
+		if err != nil {
+			return nil, fmt.Errorf("CIFRADO_LLAVES: llave %s: %w", id, err)
+		}
+		maestras[id] = llave
+		if activa == "" {
+			activa = id
+		}
+	}
+
+	valor := os.Getenv("CIFRADO_LLAVE_INDICES")
+	if strings.TrimSpace(valor) == "" {
+		return nil, errors.New("CIFRADO_LLAVE_INDICES es obligatoria junto con CIFRADO_LLAVES")
+	}
+	indices, err := decodificarLlave(valor)
+	if err != nil {
+		return nil, fmt.Errorf("CIFRADO_LLAVE_INDICES: %w", err)
+	}
+
+	return Nuevo(maestras, activa, indices)
+}
+
+// NuevaLlave genera una llave aleatoria en base64, lista para la configuración
+func NuevaLlave() (string, error) {
+	llave := make([]byte, TamanoLlave)
+	if _, err := rand.Read(llave); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(llave), nil
+}
+
+func decodificarLlave(valor string) ([]byte, error) {
+	llave, err := base64.StdEncoding.DecodeString(strings.TrimSpace(valor))
+	if err != nil {
+		return nil, errors.New("no es base64 válido")
+	}
+	if len(llave) != TamanoLlave {
+		return nil, fmt.Errorf("debe medir %d bytes y mide %d", TamanoLlave, len(llave))
+	}
+	return llave, nil
+}
+
+// LlaveActiva devuelve el ID de la llave maestra con la que se cifra
+func (c *Cifrador) LlaveActiva() string {
+	return c.activa
+}
+
+// NuevaLlaveDatos genera una llave de datos y la devuelve junto con su forma
+// envuelta ("id:base64"), que es la que se guarda. contexto liga la llave a
+// su registro (por ejemplo, la matrícula) para que no sirva en otro.
+func (c *Cifrador) NuevaLlaveDatos(contexto string) (*LlaveDatos, string, error) {
+	llave := make([]byte, TamanoLlave)
+	if _, err := rand.Read(llave); err != nil {
+		return nil, "", err
+	}
+	datos, err := nuevaLlaveDatos(llave)
+	if err != nil {
+		return nil, "", err
+	}
+	envuelta, err := sellar(c.maestras[c.activa], llave, []byte(c.activa+":"+contexto))
+	if err != nil {
+		return nil, "", err
+	}
+	return datos, c.activa + ":" + base64.StdEncoding.EncodeToString(envuelta), nil
+}
+
+// AbrirLlaveDatos desenvuelve una llave de datos con la llave maestra que
+// indica su ID
+func (c *Cifrador) AbrirLlaveDatos(envuelta, contexto string) (*LlaveDatos, error) {
+	id, valor, ok := strings.Cut(envuelta, ":")
+	if !ok {
+		return nil, errors.New("llave de datos con formato inválido")
+	}
+	maestra, ok := c.maestras[id]
+	if !ok {
+		return nil, fmt.Errorf("la llave maestra %q no está configurada en CIFRADO_LLAVES", id)
+	}
+	sellada, err := base64.StdEncoding.DecodeString(valor)
+	if err != nil {
+		return nil, errors.New("llave de datos con formato inválido")
+	}
+	llave, err := abrir(maestra, sellada, []byte(id+":"+contexto))
+	if err != nil {
+		return nil, err
+	}
+	return nuevaLlaveDatos(llave)
+}
+
+// IDLlave devuelve el ID de la llave maestra que envuelve una llave de datos
+func IDLlave(envuelta string) string {
+	id, _, _ := strings.Cut(envuelta, ":")
+	return id
+}
+
+// Indice calcula el índice ciego de un valor después de normalizarlo, de modo
+// que "Ana@Correo.mx" y "ana@correo.mx" coinciden. Devuelve nil si el valor
+// normalizado queda vacío.
+func (c *Cifrador) Indice(campo, valor string) []byte {
+	valor = Normalizar(campo, valor)
+	if valor == "" {
+		return nil
+	}
+	mac := hmac.New(sha256.New, c.indices)
+	mac.Write([]byte(campo))
+	mac.Write([]byte{0})
+	mac.Write([]byte(valor))
+	return mac.Sum(nil)
+}
+
+// Normalizar deja el valor en la forma que se usa para el índice ciego: solo
+// dígitos en el teléfono y, en lo demás, minúsculas sin acentos ni espacios
+// sobrantes (como validacion.Normalizar), para que "Michoacán" y "michoacan"
+// coincidan igual que con la intercalación de MySQL
+func Normalizar(campo, valor string) string {
+	if campo == CampoTelefono {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, valor)
+	}
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	sinAcentos, _, err := transform.String(t, valor)
+	if err != nil {
+		sinAcentos = valor
+	}
+	return strings.Join(strings.Fields(strings.ToLower(sinAcentos)), " ")
+}
+
+// LlaveDatos cifra los valores de un registro
+type LlaveDatos struct {
+	aead cipher.AEAD
+}
+
+func nuevaLlaveDatos(llave []byte) (*LlaveDatos, error) {
+	aead, err := nuevoAEAD(llave)
+	if err != nil {
+		return nil, err
+	}
+	return &LlaveDatos{aead: aead}, nil
+}
+
+// Cifrar devuelve nonce + texto cifrado. campo va como dato asociado, así un
+// valor no puede copiarse a otra columna del mismo registro.
+func (l *LlaveDatos) Cifrar(valor, campo string) ([]byte, error) {
+	return sellar(l.aead, []byte(valor), []byte(campo))
+}
+
+// Descifrar revierte Cifrar
+func (l *LlaveDatos) Descifrar(datos []byte, campo string) (string, error) {
+	valor, err := abrir(l.aead, datos, []byte(campo))
+	if err != nil {
+		return "", err
+	}
+	return string(valor), nil
+}
+
+func nuevoAEAD(llave []byte) (cipher.AEAD, error) {
+	if len(llave) != TamanoLlave {
+		return nil, fmt.Errorf("la llave debe medir %d bytes", TamanoLlave)
+	}
+	bloque, err := aes.NewCipher(llave)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(bloque)
+}
+
+func sellar(aead cipher.AEAD, texto, asociado []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(texto)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, texto, asociado), nil
+}
+
+func abrir(aead cipher.AEAD, datos, asociado []byte) ([]byte, error) {
+	if len(datos) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDescifrar
+	}
+	texto, err := aead.Open(nil, datos[:aead.NonceSize()], datos[aead.NonceSize():], asociado)
+	if err != nil {
+		return nil, ErrDescifrar
+	}
+	return texto, nil
+}
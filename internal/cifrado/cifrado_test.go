@@ -0,0 +1,73 @@
+package cifrado
+
+import (
+	"bytes"
+	"testing"
+)
+
+func cifradorPrueba(t *testing.T) *Cifrador {
+	t.Helper()
+	c, err := Nuevo(map[string][]byte{"k1": bytes.Repeat([]byte{1}, TamanoLlave)}, "k1", bytes.Repeat([]byte{2}, TamanoLlave))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestIndiceIgnoraAcentosYMayusculas(t *testing.T) {
+	c := cifradorPrueba(t)
+	casos := []struct {
+		campo, a, b string
+	}{
+		{CampoEstado, "Michoacán", "Michoacan"},
+		{CampoEstado, "Michoacán de Ocampo", "  MICHOACAN  de ocampo "},
+		{CampoMunicipio, "Culiacán", "culiacan"},
+		{CampoMunicipio, "Nuevo León", "Nuevo León"}, // acento combinado
+		{CampoCorreo, "José@Correo.MX", "jose@correo.mx"},
+		{CampoTelefono, "(667) 123-4567", "6671234567"},
+	}
+	for _, caso := range casos {
+		if !bytes.Equal(c.Indice(caso.campo, caso.a), c.Indice(caso.campo, caso.b)) {
+			t.Errorf("%s: %q y %q deberían tener el mismo índice", caso.campo, caso.a, caso.b)
+		}
+	}
+}
+
+func TestIndiceDistingueCamposYValores(t *testing.T) {
+	c := cifradorPrueba(t)
+	if bytes.Equal(c.Indice(CampoEstado, "Sinaloa"), c.Indice(CampoMunicipio, "Sinaloa")) {
+		t.Error("el mismo valor en campos distintos no debe tener el mismo índice")
+	}
+	if bytes.Equal(c.Indice(CampoEstado, "Sinaloa"), c.Indice(CampoEstado, "Sonora")) {
+		t.Error("valores distintos no deben tener el mismo índice")
+	}
+	if c.Indice(CampoTelefono, "sin dígitos") != nil {
+		t.Error("un valor que queda vacío no debe tener índice")
+	}
+}
+
+func TestCifrarYDescifrar(t *testing.T) {
+	c := cifradorPrueba(t)
+	llave, envuelta, err := c.NuevaLlaveDatos("13201234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	datos, err := llave.Cifrar("Av. Obregón 10", "calle")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	abierta, err := c.AbrirLlaveDatos(envuelta, "13201234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valor, err := abierta.Descifrar(datos, "calle"); err != nil || valor != "Av. Obregón 10" {
+		t.Fatalf("Descifrar = %q, %v", valor, err)
+	}
+	if _, err := abierta.Descifrar(datos, "numero"); err == nil {
+		t.Error("un valor no debe descifrarse como otra columna")
+	}
+	if _, err := c.AbrirLlaveDatos(envuelta, "13209999"); err == nil {
+		t.Error("la llave de datos no debe abrirse para otra matrícula")
+	}
+}
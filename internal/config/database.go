@@ -1,18 +1,18 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"fmt"
+	"log"
 	"os"
+	"strings"
 
-	_ "github.com/go-sql-driver/mysql"
-	"github.com/gorilla/sessions"
+	"github.com/go-sql-driver/mysql"
 )
 
-var (
-	DB    *sql.DB
-	Store *sessions.CookieStore
-)
+var DB *sql.DB
 
 // InitDB inicializa la conexión a la base de datos
 func InitDB() error {
@@ -23,11 +23,15 @@ func InitDB() error {
 	dbName := os.Getenv("DB_NAME")
 
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4&tls=skip-verify",
-	dbUser, dbPass, dbHost, dbPort, dbName,)
+	modoTLS, err := configurarTLS(dbHost)
+	if err != nil {
+		return err
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4&tls=%s",
+	dbUser, dbPass, dbHost, dbPort, dbName, modoTLS)
 
 
-	var err error
 	DB, err = sql.Open("mysql", dsn)
 	if err != nil {
 		return fmt.Errorf("error al abrir conexión: %w", err)
@@ -44,24 +48,50 @@ func InitDB() error {
 	return nil
 }
 
+// configurarTLS devuelve el valor del parámetro tls del DSN según DB_TLS:
+// "verificar" (por defecto) valida el certificado del servidor y su nombre
+// contra las CA del sistema o, si se indica, contra DB_TLS_CA (archivo PEM
+// de la CA del proveedor); "sin-verificar" cifra sin validar el certificado
+// y "desactivado" conecta sin TLS (solo para MySQL local).
+func configurarTLS(host string) (string, error) {
+	modo := strings.ToLower(strings.TrimSpace(os.Getenv("DB_TLS")))
+	switch modo {
+	case "", "verificar":
+	case "sin-verificar":
+		log.Println("⚠️  DB_TLS=sin-verificar: la conexión a la BD no valida el certificado del servidor")
+		return "skip-verify", nil
+	case "desactivado":
+		return "false", nil
+	default:
+		return "", fmt.Errorf("DB_TLS inválido (%q): usa verificar, sin-verificar o desactivado", modo)
+	}
+
+	archivoCA := os.Getenv("DB_TLS_CA")
+	if archivoCA == "" {
+		return "true", nil
+	}
+	pem, err := os.ReadFile(archivoCA)
+	if err != nil {
+		return "", fmt.Errorf("error al leer DB_TLS_CA: %w", err)
+	}
+	cas := x509.NewCertPool()
+	if !cas.AppendCertsFromPEM(pem) {
+		return "", fmt.Errorf("DB_TLS_CA no contiene certificados PEM")
+	}
+	err = mysql.RegisterTLSConfig("ues", &tls.Config{
+		RootCAs:    cas,
+		ServerName: host,
+		MinVersion: tls.VersionTLS12,
+	})
+	if err != nil {
+		return "", err
+	}
+	return "ues", nil
+}
+
 // CloseDB cierra la conexión a la base de datos
 func CloseDB() {
 	if DB != nil {
 		DB.Close()
 	}
 }
-
-// InitSessions inicializa el store de sesiones
-func InitSessions() {
-	secret := os.Getenv("SESSION_SECRET")
-	if secret == "" {
-		secret = "default-secret-key-change-in-production"
-	}
-	Store = sessions.NewCookieStore([]byte(secret))
-	Store.Options = &sessions.Options{
-		Path:     "/",
-		MaxAge:   3600 * 8, // 8 horas
-		HttpOnly: true,
-		Secure:   false, // Cambiar a true en producción con HTTPS
-	}
-}
\ No newline at end of file
@@ -1,24 +1,23 @@
 package config
 
 import (
-	"os"
-
-	"github.com/gorilla/sessions"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/sesiones"
+	"ues-egresados/internal/tokensapi"
 )
 
-var SessionStore *sessions.CookieStore
+var SessionStore *sesiones.Store
+
+// TokensAPI verifica los tokens personales de "Authorization: Bearer"
+var TokensAPI *tokensapi.Verificador
+
+// InitSession crea el store de sesiones guardadas en el servidor con los
+// límites de SESION_INACTIVIDAD_MINUTOS y SESION_DURACION_HORAS
+func InitSession(repo repository.SesionRepository) {
+	SessionStore = sesiones.NewStore(repo, sesiones.OpcionesDesdeEntorno())
+}
 
-func InitSession() {
-	secret := os.Getenv("SESSION_SECRET")
-	if secret == "" {
-		secret = "tu_clave_secreta_aqui_cambiar_en_produccion"
-	}
-	
-	SessionStore = sessions.NewCookieStore([]byte(secret))
-	SessionStore.Options = &sessions.Options{
-		Path:     "/",
-		MaxAge:   86400 * 7, // 7 días
-		HttpOnly: true,
-		SameSite: 1,
-	}
-}
\ No newline at end of file
+// InitTokensAPI prepara la verificación de los tokens personales de la API
+func InitTokensAPI(tokens repository.TokenAPIRepository, usuarios repository.UsuarioRepository) {
+	TokensAPI = tokensapi.NewVerificador(tokens, usuarios)
+}
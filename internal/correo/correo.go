@@ -0,0 +1,84 @@
+// Package correo envía los correos del sistema, como los enlaces para
+// restablecer la contraseña. El envío se hace a través de la interfaz
+// Enviador para poder cambiar de proveedor o usar uno falso en desarrollo.
+package correo
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Mensaje es un correo de texto plano
+type Mensaje struct {
+	Para   string
+	Asunto string
+	Texto  string
+}
+
+// Enviador entrega mensajes de correo
+type Enviador interface {
+	Enviar(ctx context.Context, m Mensaje) error
+}
+
+// Registro escribe los correos en el log en lugar de enviarlos. Se usa
+// cuando no hay un servidor SMTP configurado.
+type Registro struct{}
+
+func (Registro) Enviar(ctx context.Context, m Mensaje) error {
+	log.Printf("✉️  Correo sin enviar (SMTP_HOST no configurado) para %s: %s\n%s", m.Para, m.Asunto, m.Texto)
+	return nil
+}
+
+// PuertoPorDefecto es el puerto de envío con STARTTLS
+const PuertoPorDefecto = 587
+
+// DesdeEntorno arma el enviador a partir de SMTP_HOST, SMTP_PORT,
+// SMTP_USUARIO, SMTP_PASSWORD y SMTP_REMITENTE. Sin SMTP_HOST devuelve un
+// Registro.
+func DesdeEntorno() Enviador {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("⚠️  SMTP_HOST no configurado; los correos solo se escribirán en el log")
+		return Registro{}
+	}
+
+	puerto := PuertoPorDefecto
+	if valor := os.Getenv("SMTP_PORT"); valor != "" {
+		n, err := strconv.Atoi(valor)
+		if err != nil || n <= 0 {
+			log.Printf("⚠️  SMTP_PORT inválido (%q), usando %d", valor, PuertoPorDefecto)
+		} else {
+			puerto = n
+		}
+	}
+
+	remitente := os.Getenv("SMTP_REMITENTE")
+	if remitente == "" {
+		remitente = os.Getenv("SMTP_USUARIO")
+	}
+
+	return &SMTP{
+		Host:      host,
+		Puerto:    puerto,
+		Usuario:   os.Getenv("SMTP_USUARIO"),
+		Password:  os.Getenv("SMTP_PASSWORD"),
+		Remitente: remitente,
+	}
+}
+
+// URLBaseDesdeEntorno lee APP_URL, la dirección pública del sistema con la
+// que se arman los enlaces de los correos. No se toma del encabezado Host
+// de la petición para que no pueda falsificarse.
+func URLBaseDesdeEntorno() string {
+	if url := strings.TrimRight(os.Getenv("APP_URL"), "/"); url != "" {
+		return url
+	}
+	puerto := os.Getenv("SERVER_PORT")
+	if puerto == "" {
+		puerto = "8080"
+	}
+	return "http://localhost:" + puerto
+}
@@ -0,0 +1,146 @@
+package correo
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP envía los correos a un servidor SMTP. En el puerto 465 usa TLS
+// implícito; en los demás usa STARTTLS si el servidor lo ofrece.
+type SMTP struct {
+	Host      string
+	Puerto    int
+	Usuario   string // vacío si el servidor no pide autenticación
+	Password  string
+	Remitente string
+
+	raices *x509.CertPool // CA del servidor en las pruebas; nil usa las del sistema
+}
+
+// timeoutSMTP limita la conexión cuando el contexto no trae fecha límite
+const timeoutSMTP = 30 * time.Second
+
+func (s *SMTP) Enviar(ctx context.Context, m Mensaje) error {
+	de, err := mail.ParseAddress(s.Remitente)
+	if err != nil {
+		return fmt.Errorf("remitente inválido: %w", err)
+	}
+	para, err := mail.ParseAddress(m.Para)
+	if err != nil {
+		return fmt.Errorf("destinatario inválido: %w", err)
+	}
+	datos, err := armarMensaje(de, para, m)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeoutSMTP)
+		defer cancel()
+	}
+
+	direccion := net.JoinHostPort(s.Host, strconv.Itoa(s.Puerto))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", direccion)
+	if err != nil {
+		return err
+	}
+	if limite, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(limite)
+	}
+
+	tlsConfig := &tls.Config{ServerName: s.Host, RootCAs: s.raices}
+	if s.Puerto == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && s.Puerto != 465 {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if s.Usuario != "" {
+		// PlainAuth se niega a enviar la contraseña sin TLS, salvo a localhost
+		if err := c.Auth(smtp.PlainAuth("", s.Usuario, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(de.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(para.Address); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(datos); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// armarMensaje codifica los encabezados y el cuerpo en UTF-8
+func armarMensaje(de, para *mail.Address, m Mensaje) ([]byte, error) {
+	if strings.ContainsAny(m.Asunto, "\r\n") {
+		return nil, errors.New("el asunto no puede tener saltos de línea")
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	dominio := de.Address[strings.LastIndex(de.Address, "@")+1:]
+
+	var b bytes.Buffer
+	encabezados := [][2]string{
+		{"From", de.String()},
+		{"To", para.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.Asunto)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", "<" + hex.EncodeToString(id) + "@" + dominio + ">"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, e := range encabezados {
+		fmt.Fprintf(&b, "%s: %s\r\n", e[0], e[1])
+	}
+	b.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(m.Texto, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,316 @@
+package correo
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sesionSMTP es lo que recibió el servidor falso en una conexión
+type sesionSMTP struct {
+	tls         bool
+	authConTLS  bool
+	usuario     string
+	password    string
+	de          string
+	para        []string
+	datos       string
+	autenticado bool
+}
+
+// servidorSMTP es un servidor SMTP mínimo en proceso. Ofrece STARTTLS y
+// solo anuncia AUTH PLAIN después de cifrar la conexión.
+type servidorSMTP struct {
+	listener net.Listener
+	tls      *tls.Config
+	raices   *x509.CertPool
+	usuario  string
+	password string
+
+	mu       sync.Mutex
+	sesiones []*sesionSMTP
+}
+
+func nuevoServidorSMTP(t *testing.T) *servidorSMTP {
+	t.Helper()
+	certificado, raices := certificadoPrueba(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &servidorSMTP{
+		listener: listener,
+		tls:      &tls.Config{Certificates: []tls.Certificate{certificado}},
+		raices:   raices,
+		usuario:  "sistema@ues.mx",
+		password: "secreto-smtp",
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.atender(conn)
+		}
+	}()
+	return s
+}
+
+// cliente devuelve el enviador apuntando al servidor falso
+func (s *servidorSMTP) cliente() *SMTP {
+	puerto := s.listener.Addr().(*net.TCPAddr).Port
+	return &SMTP{
+		Host:      "127.0.0.1",
+		Puerto:    puerto,
+		Usuario:   s.usuario,
+		Password:  s.password,
+		Remitente: "Egresados UES <sistema@ues.mx>",
+		raices:    s.raices,
+	}
+}
+
+func (s *servidorSMTP) ultimaSesion(t *testing.T) *sesionSMTP {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.sesiones) == 0 {
+		t.Fatal("el servidor no recibió conexiones")
+	}
+	return s.sesiones[len(s.sesiones)-1]
+}
+
+func (s *servidorSMTP) atender(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	sesion := &sesionSMTP{}
+	s.mu.Lock()
+	s.sesiones = append(s.sesiones, sesion)
+	s.mu.Unlock()
+
+	lector := bufio.NewReader(conn)
+	responder := func(lineas ...string) {
+		io.WriteString(conn, strings.Join(lineas, "\r\n")+"\r\n")
+	}
+	responder("220 prueba ESMTP")
+
+	for {
+		linea, err := lector.ReadString('\n')
+		if err != nil {
+			return
+		}
+		linea = strings.TrimRight(linea, "\r\n")
+		comando := strings.ToUpper(linea)
+
+		switch {
+		case strings.HasPrefix(comando, "EHLO"):
+			if sesion.tls {
+				responder("250-prueba", "250 AUTH PLAIN")
+			} else {
+				responder("250-prueba", "250 STARTTLS")
+			}
+		case comando == "STARTTLS":
+			responder("220 listo para TLS")
+			cifrada := tls.Server(conn, s.tls)
+			if err := cifrada.Handshake(); err != nil {
+				return
+			}
+			conn = cifrada
+			lector = bufio.NewReader(conn)
+			sesion.tls = true
+		case strings.HasPrefix(comando, "AUTH PLAIN "):
+			sesion.authConTLS = sesion.tls
+			credenciales, _ := base64.StdEncoding.DecodeString(linea[len("AUTH PLAIN "):])
+			partes := strings.Split(string(credenciales), "\x00")
+			if len(partes) == 3 {
+				sesion.usuario, sesion.password = partes[1], partes[2]
+			}
+			if sesion.usuario == s.usuario && sesion.password == s.password {
+				sesion.autenticado = true
+				responder("235 autenticado")
+			} else {
+				responder("535 credenciales inválidas")
+			}
+		case strings.HasPrefix(comando, "MAIL FROM:"):
+			if !sesion.autenticado {
+				responder("530 autenticación requerida")
+				continue
+			}
+			sesion.de = strings.Trim(linea[len("MAIL FROM:"):], "<> ")
+			responder("250 ok")
+		case strings.HasPrefix(comando, "RCPT TO:"):
+			sesion.para = append(sesion.para, strings.Trim(linea[len("RCPT TO:"):], "<> "))
+			responder("250 ok")
+		case comando == "DATA":
+			responder("354 termina con un punto")
+			var datos strings.Builder
+			for {
+				l, err := lector.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				datos.WriteString(strings.TrimPrefix(l, "."))
+			}
+			sesion.datos = datos.String()
+			responder("250 en cola")
+		case comando == "QUIT":
+			responder("221 adiós")
+			return
+		default:
+			responder("502 no implementado")
+		}
+	}
+}
+
+// certificadoPrueba genera un certificado autofirmado para 127.0.0.1 y el
+// pool que lo reconoce
+func certificadoPrueba(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	llave, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plantilla := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "smtp de prueba"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, plantilla, plantilla, &llave.PublicKey, llave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificado, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raices := x509.NewCertPool()
+	raices.AddCert(certificado)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: llave}, raices
+}
+
+func TestEnviarConSTARTTLSYAuth(t *testing.T) {
+	servidor := nuevoServidorSMTP(t)
+	m := Mensaje{
+		Para:   "Ana Núñez <ana@ejemplo.mx>",
+		Asunto: "Restablecer contraseña",
+		Texto:  "Hola, Ana:\n\nAbre este enlace: https://egresados.ues.mx/restablecer?token=abc123=xyz\n\n" + strings.Repeat("línea larga ", 10),
+	}
+	if err := servidor.cliente().Enviar(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+
+	sesion := servidor.ultimaSesion(t)
+	if !sesion.tls || !sesion.authConTLS {
+		t.Fatalf("STARTTLS = %v, AUTH con TLS = %v; la contraseña no debe viajar sin cifrar", sesion.tls, sesion.authConTLS)
+	}
+	if sesion.usuario != "sistema@ues.mx" || sesion.password != "secreto-smtp" {
+		t.Errorf("AUTH PLAIN = %q / %q", sesion.usuario, sesion.password)
+	}
+	if sesion.de != "sistema@ues.mx" || len(sesion.para) != 1 || sesion.para[0] != "ana@ejemplo.mx" {
+		t.Errorf("sobre = MAIL FROM %q, RCPT TO %v", sesion.de, sesion.para)
+	}
+
+	mensaje, err := mail.ReadMessage(strings.NewReader(sesion.datos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	asunto, err := new(mime.WordDecoder).DecodeHeader(mensaje.Header.Get("Subject"))
+	if err != nil || asunto != m.Asunto {
+		t.Errorf("Subject = %q (%v)", asunto, err)
+	}
+	para, err := mensaje.Header.AddressList("To")
+	if err != nil || len(para) != 1 || para[0].Name != "Ana Núñez" || para[0].Address != "ana@ejemplo.mx" {
+		t.Errorf("To = %v (%v)", para, err)
+	}
+	if de, err := mail.ParseAddress(mensaje.Header.Get("From")); err != nil || de.Address != "sistema@ues.mx" {
+		t.Errorf("From = %v (%v)", de, err)
+	}
+	if !strings.HasSuffix(mensaje.Header.Get("Message-ID"), "@ues.mx>") {
+		t.Errorf("Message-ID = %q", mensaje.Header.Get("Message-ID"))
+	}
+	if mensaje.Header.Get("Content-Transfer-Encoding") != "quoted-printable" ||
+		!strings.Contains(mensaje.Header.Get("Content-Type"), "charset=utf-8") {
+		t.Errorf("encabezados MIME = %v", mensaje.Header)
+	}
+
+	cuerpo, err := io.ReadAll(quotedprintable.NewReader(mensaje.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DATA termina el mensaje con un salto de línea si no lo trae
+	if esperado := strings.ReplaceAll(m.Texto, "\n", "\r\n") + "\r\n"; string(cuerpo) != esperado {
+		t.Errorf("cuerpo = %q, se esperaba %q", cuerpo, esperado)
+	}
+}
+
+func TestEnviarRechazaCertificadoNoConfiable(t *testing.T) {
+	servidor := nuevoServidorSMTP(t)
+	cliente := servidor.cliente()
+	cliente.raices = nil
+
+	if err := cliente.Enviar(context.Background(), Mensaje{Para: "ana@ejemplo.mx", Asunto: "Prueba", Texto: "Hola"}); err == nil {
+		t.Fatal("se esperaba un error con un certificado que no es de una CA de confianza")
+	}
+	if sesion := servidor.ultimaSesion(t); sesion.usuario != "" || sesion.datos != "" {
+		t.Errorf("no se debían enviar credenciales ni el mensaje: %+v", sesion)
+	}
+}
+
+func TestEnviarCredencialesIncorrectas(t *testing.T) {
+	servidor := nuevoServidorSMTP(t)
+	cliente := servidor.cliente()
+	cliente.Password = "otra"
+
+	err := cliente.Enviar(context.Background(), Mensaje{Para: "ana@ejemplo.mx", Asunto: "Prueba", Texto: "Hola"})
+	if err == nil || !strings.Contains(err.Error(), "535") {
+		t.Fatalf("Enviar = %v, se esperaba el rechazo 535", err)
+	}
+	if sesion := servidor.ultimaSesion(t); sesion.datos != "" {
+		t.Error("el mensaje no se debía enviar")
+	}
+}
+
+func TestArmarMensajeRechazaSaltosEnElAsunto(t *testing.T) {
+	de := &mail.Address{Address: "sistema@ues.mx"}
+	para := &mail.Address{Address: "ana@ejemplo.mx"}
+	if _, err := armarMensaje(de, para, Mensaje{Asunto: "Hola\r\nBcc: otro@ejemplo.mx", Texto: "x"}); err == nil {
+		t.Error("se esperaba un error con un salto de línea en el asunto")
+	}
+}
+
+func TestDestinatarioInvalido(t *testing.T) {
+	cliente := &SMTP{Host: "127.0.0.1", Puerto: 1, Remitente: "sistema@ues.mx"}
+	for _, para := range []string{"", "no es un correo", "ana@ejemplo.mx\r\nRCPT TO:<otro@ejemplo.mx>"} {
+		if err := cliente.Enviar(context.Background(), Mensaje{Para: para, Asunto: "x", Texto: "x"}); err == nil {
+			t.Errorf("Enviar a %s: se esperaba un error", strconv.Quote(para))
+		}
+	}
+}
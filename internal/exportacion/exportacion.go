@@ -0,0 +1,195 @@
+package exportacion
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"ues-egresados/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Escritor recibe egresados uno por uno y los escribe en el formato elegido
+type Escritor interface {
+	Escribir(e *models.Egresado) error
+	// Cerrar termina el archivo; debe llamarse aunque no haya filas
+	Cerrar() error
+}
+
+// Formato describe un tipo de archivo de exportación
+type Formato struct {
+	Extension   string
+	ContentType string
+	nuevo       func(w io.Writer) (Escritor, error)
+}
+
+// Nuevo crea un escritor del formato sobre w y escribe los encabezados
+func (f Formato) Nuevo(w io.Writer) (Escritor, error) {
+	return f.nuevo(w)
+}
+
+// Formatos son los formatos disponibles, indexados por el parámetro "format"
+var Formatos = map[string]Formato{
+	"csv": {
+		Extension:   "csv",
+		ContentType: "text/csv; charset=utf-8",
+		nuevo:       nuevoCSV,
+	},
+	"xlsx": {
+		Extension:   "xlsx",
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		nuevo:       nuevoXLSX,
+	},
+}
+
+var encabezados = []string{
+	"Matrícula", "Nombre Completo", "Género", "Teléfono", "Correo",
+	"Código Postal", "Estado", "Municipio", "Asentamiento", "Calle", "Número",
+	"Plantel", "Carrera", "Generación", "Estatus", "Fecha de Registro",
+}
+
+func fila(e *models.Egresado) []string {
+	return []string{
+		e.Matricula,
+		e.NombreCompleto,
+		texto(e.Genero),
+		texto(e.Telefono),
+		texto(e.Correo),
+		texto(e.CodigoPostal),
+		texto(e.Estado),
+		texto(e.Municipio),
+		texto(e.Asentamiento),
+		texto(e.Calle),
+		texto(e.Numero),
+		e.NombrePlantel,
+		e.NombreCarrera,
+		e.PeriodoGeneracion,
+		e.DescripcionEstatus,
+		e.CreatedAt.Format("2006-01-02 15:04"),
+	}
+}
+
+func texto(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// filasPorVaciado controla cada cuántas filas se envía el CSV al cliente
+const filasPorVaciado = 500
+
+type escritorCSV struct {
+	w     *csv.Writer
+	filas int
+}
+
+func nuevoCSV(w io.Writer) (Escritor, error) {
+	// BOM para que Excel reconozca los acentos al abrir el CSV
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return nil, err
+	}
+	c := &escritorCSV{w: csv.NewWriter(w)}
+	if err := c.w.Write(encabezados); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *escritorCSV) Escribir(e *models.Egresado) error {
+	if err := c.w.Write(fila(e)); err != nil {
+		return err
+	}
+	c.filas++
+	if c.filas%filasPorVaciado == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *escritorCSV) Cerrar() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escritorXLSX usa el StreamWriter de excelize, que guarda las filas en un
+// archivo temporal en lugar de mantener toda la hoja en memoria
+type escritorXLSX struct {
+	destino io.Writer
+	archivo *excelize.File
+	hoja    *excelize.StreamWriter
+	fila    int
+}
+
+const hojaXLSX = "Egresados"
+
+// anchosXLSX corresponde a cada columna de encabezados
+var anchosXLSX = []float64{12, 35, 10, 14, 30, 10, 20, 20, 25, 25, 8, 35, 12, 12, 18}
+
+func nuevoXLSX(w io.Writer) (Escritor, error) {
+	archivo := excelize.NewFile()
+	if err := archivo.SetSheetName("Sheet1", hojaXLSX); err != nil {
+		archivo.Close()
+		return nil, err
+	}
+
+	estilo, err := archivo.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"8B233E"}},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	if err != nil {
+		archivo.Close()
+		return nil, err
+	}
+
+	hoja, err := archivo.NewStreamWriter(hojaXLSX)
+	if err != nil {
+		archivo.Close()
+		return nil, err
+	}
+
+	// Los anchos deben definirse antes de la primera fila
+	for i, ancho := range anchosXLSX {
+		if err := hoja.SetColWidth(i+1, i+1, ancho); err != nil {
+			archivo.Close()
+			return nil, err
+		}
+	}
+
+	celdas := make([]interface{}, len(encabezados))
+	for i, e := range encabezados {
+		celdas[i] = excelize.Cell{StyleID: estilo, Value: e}
+	}
+	if err := hoja.SetRow("A1", celdas, excelize.RowOpts{StyleID: estilo}); err != nil {
+		archivo.Close()
+		return nil, err
+	}
+
+	return &escritorXLSX{destino: w, archivo: archivo, hoja: hoja, fila: 1}, nil
+}
+
+func (x *escritorXLSX) Escribir(e *models.Egresado) error {
+	valores := fila(e)
+	celdas := make([]interface{}, len(valores))
+	for i, v := range valores {
+		celdas[i] = v
+	}
+
+	x.fila++
+	celda, err := excelize.CoordinatesToCellName(1, x.fila)
+	if err != nil {
+		return err
+	}
+	return x.hoja.SetRow(celda, celdas)
+}
+
+func (x *escritorXLSX) Cerrar() error {
+	defer x.archivo.Close()
+
+	if err := x.hoja.Flush(); err != nil {
+		return fmt.Errorf("error al cerrar la hoja: %w", err)
+	}
+	return x.archivo.Write(x.destino)
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
-	"ues-egresados/internal/config"
+	"ues-egresados/internal/auditoria"
 	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
 	"ues-egresados/internal/utils"
 
 	"github.com/gorilla/mux"
@@ -13,52 +15,29 @@ import (
 )
 
 // GetAdministradores obtiene todos los administradores
-func GetAdministradores(w http.ResponseWriter, r *http.Request) {
-	query := `
-		SELECT id_usuario, usuario, nombre, apellido_paterno, apellido_materno, rol, created_at
-		FROM usuarios
-		ORDER BY created_at DESC
-	`
-
-	rows, err := config.DB.Query(query)
+func (h *Handler) GetAdministradores(w http.ResponseWriter, r *http.Request) {
+	administradores, err := h.usuarios.List(r.Context())
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener administradores")
 		return
 	}
-	defer rows.Close()
-
-	var administradores []models.Usuario
-
-	for rows.Next() {
-		var admin models.Usuario
-		err := rows.Scan(
-			&admin.IDUsuario,
-			&admin.Usuario,
-			&admin.Nombre,
-			&admin.ApellidoPaterno,
-			&admin.ApellidoMaterno,
-			&admin.Rol,
-			&admin.CreatedAt,
-		)
-		if err != nil {
-			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al procesar administradores")
-			return
-		}
-		administradores = append(administradores, admin)
-	}
 
 	utils.SuccessResponse(w, "Administradores obtenidos correctamente", administradores)
 }
 
 // CreateAdministrador crea un nuevo administrador
-func CreateAdministrador(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateAdministrador(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Usuario         string `json:"usuario"`
-		Nombre          string `json:"nombre"`
-		ApellidoPaterno string `json:"apellido_paterno"`
-		ApellidoMaterno string `json:"apellido_materno"`
-		Password        string `json:"password"`
-		Rol             string `json:"rol"`
+		Usuario         string  `json:"usuario"`
+		Nombre          string  `json:"nombre"`
+		ApellidoPaterno string  `json:"apellido_paterno"`
+		ApellidoMaterno string  `json:"apellido_materno"`
+		Correo          *string `json:"correo"`
+		Password        string  `json:"password"`
+		Rol             string  `json:"rol"`
+		IDPlantel       *int    `json:"id_plantel"`
+		// Origen "ldap" da de alta por adelantado una cuenta del directorio
+		Origen string `json:"origen"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -66,22 +45,17 @@ func CreateAdministrador(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validar campos requeridos
-	if req.Usuario == "" || req.Nombre == "" || req.ApellidoPaterno == "" || req.Password == "" || req.Rol == "" {
-		utils.ErrorResponse(w, http.StatusBadRequest, "Faltan campos requeridos")
-		return
+	usuario := models.Usuario{
+		Usuario:         req.Usuario,
+		Nombre:          req.Nombre,
+		ApellidoPaterno: req.ApellidoPaterno,
+		ApellidoMaterno: req.ApellidoMaterno,
+		Correo:          req.Correo,
+		Rol:             req.Rol,
+		IDPlantel:       req.IDPlantel,
+		Origen:          req.Origen,
 	}
-
-	// Verificar si el usuario ya existe
-	var exists bool
-	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM usuarios WHERE usuario = ?)", req.Usuario).Scan(&exists)
-	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al verificar usuario")
-		return
-	}
-
-	if exists {
-		utils.ErrorResponse(w, http.StatusBadRequest, "El usuario ya existe")
+	if !h.validarUsuario(w, r, &usuario, req.Password, 0) {
 		return
 	}
 
@@ -91,33 +65,32 @@ func CreateAdministrador(w http.ResponseWriter, r *http.Request) {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al procesar contraseña")
 		return
 	}
+	usuario.Password = string(hashedPassword)
 
 	// Insertar administrador
-	result, err := config.DB.Exec(
-		"INSERT INTO usuarios (usuario, nombre, apellido_paterno, apellido_materno, password, rol) VALUES (?, ?, ?, ?, ?, ?)",
-		req.Usuario,
-		req.Nombre,
-		req.ApellidoPaterno,
-		req.ApellidoMaterno,
-		hashedPassword,
-		req.Rol,
-	)
-
+	lastID, err := h.usuarios.Create(r.Context(), &usuario)
 	if err != nil {
+		if errors.Is(err, repository.ErrDuplicado) {
+			utils.ValidationErrorResponse(w, map[string]string{"usuario": "El usuario ya existe"})
+			return
+		}
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear administrador")
 		return
 	}
 
-	lastID, _ := result.LastInsertId()
-	w.WriteHeader(http.StatusCreated)
-	utils.SuccessResponse(w, "Administrador creado correctamente", map[string]interface{}{
+	if creado, err := h.usuarios.GetByID(r.Context(), lastID); err == nil {
+		h.auditar(r, auditoria.EntidadUsuario, strconv.Itoa(lastID), auditoria.AccionCrear, nil, creado)
+	}
+
+	utils.CreatedResponse(w, "Administrador creado correctamente", map[string]interface{}{
 		"id_usuario": lastID,
-		"usuario":    req.Usuario,
+		"usuario":    usuario.Usuario,
 	})
 }
 
-// UpdateAdministrador actualiza un administrador
-func UpdateAdministrador(w http.ResponseWriter, r *http.Request) {
+// UpdateAdministrador actualiza un administrador. Igual que en egresados,
+// requiere If-Match con la versión que muestra el listado.
+func (h *Handler) UpdateAdministrador(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idUsuario, err := strconv.Atoi(vars["id"])
 	if err != nil {
@@ -126,12 +99,14 @@ func UpdateAdministrador(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Usuario         string `json:"usuario"`
-		Nombre          string `json:"nombre"`
-		ApellidoPaterno string `json:"apellido_paterno"`
-		ApellidoMaterno string `json:"apellido_materno"`
-		Password        string `json:"password"`
-		Rol             string `json:"rol"`
+		Usuario         string  `json:"usuario"`
+		Nombre          string  `json:"nombre"`
+		ApellidoPaterno string  `json:"apellido_paterno"`
+		ApellidoMaterno string  `json:"apellido_materno"`
+		Correo          *string `json:"correo"`
+		Password        string  `json:"password"`
+		Rol             string  `json:"rol"`
+		IDPlantel       *int    `json:"id_plantel"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -139,37 +114,32 @@ func UpdateAdministrador(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validar campos requeridos
-	if req.Usuario == "" || req.Nombre == "" || req.ApellidoPaterno == "" || req.Rol == "" {
-		utils.ErrorResponse(w, http.StatusBadRequest, "Faltan campos requeridos")
-		return
-	}
-
 	// Verificar que el usuario existe
-	var exists bool
-	err = config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM usuarios WHERE id_usuario = ?)", idUsuario).Scan(&exists)
-	if err != nil || !exists {
+	antes, err := h.usuarios.GetByID(r.Context(), idUsuario)
+	if err != nil {
 		utils.ErrorResponse(w, http.StatusNotFound, "Administrador no encontrado")
 		return
 	}
 
-	// Verificar si el nuevo usuario ya existe (y no es el mismo)
-	var otherExists bool
-	err = config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM usuarios WHERE usuario = ? AND id_usuario != ?)", req.Usuario, idUsuario).Scan(&otherExists)
-	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al verificar usuario")
+	if !verificarVersion(w, r, antes.Version, antes) {
 		return
 	}
 
-	if otherExists {
-		utils.ErrorResponse(w, http.StatusBadRequest, "El usuario ya existe")
+	usuario := models.Usuario{
+		IDUsuario:       idUsuario,
+		Usuario:         req.Usuario,
+		Nombre:          req.Nombre,
+		ApellidoPaterno: req.ApellidoPaterno,
+		ApellidoMaterno: req.ApellidoMaterno,
+		Correo:          req.Correo,
+		Rol:             req.Rol,
+		IDPlantel:       req.IDPlantel,
+		Version:         antes.Version,
+	}
+	if !h.validarUsuario(w, r, &usuario, req.Password, idUsuario) {
 		return
 	}
 
-	// Preparar actualización
-	var query string
-	var args []interface{}
-
 	if req.Password != "" {
 		// Si hay contraseña, encriptarla y actualizar
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -177,25 +147,43 @@ func UpdateAdministrador(w http.ResponseWriter, r *http.Request) {
 			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al procesar contraseña")
 			return
 		}
-		query = "UPDATE usuarios SET usuario = ?, nombre = ?, apellido_paterno = ?, apellido_materno = ?, password = ?, rol = ? WHERE id_usuario = ?"
-		args = []interface{}{req.Usuario, req.Nombre, req.ApellidoPaterno, req.ApellidoMaterno, hashedPassword, req.Rol, idUsuario}
-	} else {
-		// Sin contraseña, solo actualizar datos
-		query = "UPDATE usuarios SET usuario = ?, nombre = ?, apellido_paterno = ?, apellido_materno = ?, rol = ? WHERE id_usuario = ?"
-		args = []interface{}{req.Usuario, req.Nombre, req.ApellidoPaterno, req.ApellidoMaterno, req.Rol, idUsuario}
+		usuario.Password = string(hashedPassword)
 	}
 
-	_, err = config.DB.Exec(query, args...)
-	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al actualizar administrador")
+	if err := h.usuarios.Update(r.Context(), &usuario); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			utils.ErrorResponse(w, http.StatusNotFound, "Administrador no encontrado")
+		case errors.Is(err, repository.ErrDuplicado):
+			utils.ValidationErrorResponse(w, map[string]string{"usuario": "El usuario ya existe"})
+		case errors.Is(err, repository.ErrConflictoVersion):
+			if actual, err := h.usuarios.GetByID(r.Context(), idUsuario); err == nil {
+				responderConflicto(w, http.StatusConflict, actual.Version, actual)
+				return
+			}
+			utils.ErrorResponse(w, http.StatusConflict, "El registro fue modificado por otro usuario")
+		default:
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al actualizar administrador")
+		}
 		return
 	}
 
-	utils.SuccessResponse(w, "Administrador actualizado correctamente", nil)
+	despues := h.auditarUsuario(r, idUsuario, antes, req.Password != "")
+
+	// Las sesiones abiertas conservan el rol y el plantel anteriores; al
+	// cambiarlos, o la contraseña, el usuario debe volver a iniciar sesión
+	if pierdeAcceso(antes, &usuario) || req.Password != "" {
+		h.cerrarSesionesUsuario(r, idUsuario)
+	}
+	if despues != nil {
+		w.Header().Set("ETag", etag(despues.Version))
+	}
+
+	utils.SuccessResponse(w, "Administrador actualizado correctamente", despues)
 }
 
 // DeleteAdministrador elimina un administrador
-func DeleteAdministrador(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteAdministrador(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idUsuario, err := strconv.Atoi(vars["id"])
 	if err != nil {
@@ -203,20 +191,69 @@ func DeleteAdministrador(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verificar que el usuario existe
-	var exists bool
-	err = config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM usuarios WHERE id_usuario = ?)", idUsuario).Scan(&exists)
-	if err != nil || !exists {
-		utils.ErrorResponse(w, http.StatusNotFound, "Administrador no encontrado")
+	if idUsuario == idUsuarioSesion(r) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "No puedes eliminar tu propio usuario")
 		return
 	}
 
-	// Eliminar administrador
-	_, err = config.DB.Exec("DELETE FROM usuarios WHERE id_usuario = ?", idUsuario)
+	antes, err := h.usuarios.GetByID(r.Context(), idUsuario)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Administrador no encontrado")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener administrador")
+		return
+	}
+
+	// Eliminar administrador
+	if err := h.usuarios.Delete(r.Context(), idUsuario, idUsuarioSesion(r)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Administrador no encontrado")
+			return
+		}
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al eliminar administrador")
 		return
 	}
 
-	utils.SuccessResponse(w, "Administrador eliminado correctamente", nil)
+	h.auditar(r, auditoria.EntidadUsuario, strconv.Itoa(idUsuario), auditoria.AccionEliminar, antes, nil)
+	h.cerrarSesionesUsuario(r, idUsuario)
+
+	utils.SuccessResponse(w, "Administrador enviado a la papelera", nil)
+}
+
+// validarUsuario responde 422 con los errores por campo; devuelve false
+// cuando ya se respondió
+func (h *Handler) validarUsuario(w http.ResponseWriter, r *http.Request, u *models.Usuario, password string, excluirID int) bool {
+	errores, err := h.validador.Usuario(r.Context(), u, password, excluirID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al verificar usuario")
+		return false
+	}
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return false
+	}
+	return true
+}
+
+// auditarUsuario registra los cambios de un usuario. La contraseña nunca se
+// guarda; solo se indica que fue reemplazada. Devuelve el usuario ya guardado.
+func (h *Handler) auditarUsuario(r *http.Request, idUsuario int, antes *models.Usuario, cambioPassword bool) *models.Usuario {
+	despues, err := h.usuarios.GetByID(r.Context(), idUsuario)
+	if err != nil {
+		return nil
+	}
+
+	cambios, err := auditoria.Diff(antes, despues)
+	if err != nil {
+		return despues
+	}
+	if cambioPassword {
+		cambios["password"] = auditoria.Cambio{Antes: "********", Despues: "********"}
+	}
+	if len(cambios) > 0 {
+		h.registrarAuditoria(r, auditoria.EntidadUsuario, strconv.Itoa(idUsuario), auditoria.AccionActualizar, cambios)
+	}
+	return despues
 }
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// GetAuditoria consulta la bitácora con filtros por entidad, actor y fechas
+func (h *Handler) GetAuditoria(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filtro := models.FiltroAuditoria{
+		Entidad:   strings.TrimSpace(q.Get("entidad")),
+		EntidadID: strings.TrimSpace(q.Get("entidad_id")),
+		Actor:     strings.TrimSpace(q.Get("actor")),
+	}
+
+	if filtro.Entidad != "" && !auditoria.EntidadValida(filtro.Entidad) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Entidad inválida")
+		return
+	}
+
+	var err error
+	if filtro.Desde, err = parseFecha(q.Get("desde"), false); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Fecha \"desde\" inválida, usa AAAA-MM-DD")
+		return
+	}
+	if filtro.Hasta, err = parseFecha(q.Get("hasta"), true); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Fecha \"hasta\" inválida, usa AAAA-MM-DD")
+		return
+	}
+
+	h.listarAuditoria(w, r, filtro)
+}
+
+// GetHistorialEgresado devuelve los cambios registrados de un egresado
+func (h *Handler) GetHistorialEgresado(w http.ResponseWriter, r *http.Request) {
+	matricula := mux.Vars(r)["matricula"]
+
+	// Un usuario limitado a su plantel solo ve el historial de sus egresados
+	if _, restringido := alcancePlantel(r); restringido && h.obtenerEgresado(w, r, matricula) == nil {
+		return
+	}
+
+	h.listarAuditoria(w, r, models.FiltroAuditoria{
+		Entidad:   auditoria.EntidadEgresado,
+		EntidadID: matricula,
+	})
+}
+
+func (h *Handler) listarAuditoria(w http.ResponseWriter, r *http.Request, filtro models.FiltroAuditoria) {
+	page, perPage, err := parsePaginacion(r.URL.Query())
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filtro.Page = page
+	filtro.PerPage = perPage
+
+	registros, total, err := h.auditoria.List(r.Context(), filtro)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener la bitácora")
+		return
+	}
+
+	utils.PaginatedResponse(w, "Bitácora obtenida correctamente", registros,
+		utils.NewPagination(total, page, perPage))
+}
+
+// parseFecha interpreta AAAA-MM-DD; con finDeDia devuelve el inicio del día
+// siguiente para usarlo como límite exclusivo
+func parseFecha(valor string, finDeDia bool) (*time.Time, error) {
+	if valor == "" {
+		return nil, nil
+	}
+	fecha, err := time.ParseInLocation("2006-01-02", valor, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if finDeDia {
+		fecha = fecha.AddDate(0, 0, 1)
+	}
+	return &fecha, nil
+}
+
+// auditar registra una operación en la bitácora. Antes y después pueden ser
+// nil; solo se guardan los campos que cambiaron. Un fallo al registrar no
+// revierte la operación, pero queda en el log del servidor.
+func (h *Handler) auditar(r *http.Request, entidad, entidadID, accion string, antes, despues interface{}) {
+	cambios, err := auditoria.Diff(antes, despues)
+	if err != nil {
+		log.Printf("Error al calcular cambios de %s %s: %v", entidad, entidadID, err)
+		return
+	}
+	if accion == auditoria.AccionActualizar && len(cambios) == 0 {
+		return
+	}
+	h.registrarAuditoria(r, entidad, entidadID, accion, cambios)
+}
+
+// registrarAuditoria guarda un evento con detalles libres (importaciones, exportaciones)
+func (h *Handler) registrarAuditoria(r *http.Request, entidad, entidadID, accion string, detalles interface{}) {
+	session, _ := config.SessionStore.Get(r, "session-name")
+	autor := &models.Usuario{}
+	autor.IDUsuario, _ = session.Values["user_id"].(int)
+	autor.Usuario, _ = session.Values["username"].(string)
+	h.registrarAuditoriaDe(r, autor, entidad, entidadID, accion, detalles)
+}
+
+// registrarAuditoriaDe guarda un evento con un autor distinto al de la sesión
+func (h *Handler) registrarAuditoriaDe(r *http.Request, autor *models.Usuario, entidad, entidadID, accion string, detalles interface{}) {
+	registro := models.Auditoria{
+		IP:        utils.ClientIP(r),
+		Entidad:   entidad,
+		EntidadID: entidadID,
+		Accion:    accion,
+		Usuario:   autor.Usuario,
+	}
+	if autor.IDUsuario != 0 {
+		id := autor.IDUsuario
+		registro.IDUsuario = &id
+	}
+
+	if detalles != nil {
+		datos, err := json.Marshal(detalles)
+		if err != nil {
+			log.Printf("Error al serializar auditoría de %s %s: %v", entidad, entidadID, err)
+			return
+		}
+		registro.Cambios = datos
+	}
+
+	// La bitácora no depende de que el cliente siga conectado
+	if err := h.auditoria.Registrar(context.WithoutCancel(r.Context()), &registro); err != nil {
+		log.Printf("Error al registrar auditoría de %s %s: %v", entidad, entidadID, err)
+	}
+}
@@ -1,90 +1,216 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"html/template"
 	"log"
 	"net/http"
+	"strconv"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/autenticacion"
+	"ues-egresados/internal/auth"
 	"ues-egresados/internal/config"
+	"ues-egresados/internal/intentos"
+	"ues-egresados/internal/middleware"
 	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
 	"ues-egresados/internal/utils"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
-func LoginPage(w http.ResponseWriter, r *http.Request) {
+const credencialesIncorrectas = "Usuario o contraseña incorrectos"
+
+func (h *Handler) LoginPage(w http.ResponseWriter, r *http.Request) {
+	h.renderLogin(w, r, http.StatusOK, "")
+}
+
+// renderLogin dibuja el login con el botón de la cuenta institucional si está
+// configurada. Si falta confirmar el código de verificación (por ejemplo, al
+// regresar del proveedor OIDC) muestra directamente ese paso.
+func (h *Handler) renderLogin(w http.ResponseWriter, r *http.Request, status int, mensajeError string) {
+	// El formulario de login también envía el token CSRF; sin sesión viaja en
+	// una cookie firmada y no se guarda nada hasta iniciar sesión
+	token, err := middleware.TokenCSRF(w, r)
+	if err != nil {
+		http.Error(w, "Error al crear sesión", http.StatusInternalServerError)
+		return
+	}
+
 	tmpl, err := template.ParseFiles("web/templates/login.html")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	tmpl.Execute(w, nil)
+
+	session, _ := config.SessionStore.Get(r, "session-name")
+	idPendiente, _ := session.Values[pendiente2FA].(int)
+	desde, _ := session.Values[pendiente2FADesde].(int64)
+
+	datos := map[string]interface{}{
+		"CSRFToken":    token,
+		"Nonce":        middleware.NonceCSP(r),
+		"Error":        mensajeError,
+		"Pendiente2FA": idPendiente != 0 && time.Since(time.Unix(desde, 0)) <= vigenciaPendiente2FA,
+	}
+	if h.sso != nil {
+		datos["NombreSSO"] = h.sso.Configuracion().Nombre
+	}
+	w.WriteHeader(status)
+	tmpl.Execute(w, datos)
 }
 
-func Login(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var loginReq models.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
 		return
 	}
 
-	// Buscar usuario en la base de datos
-	var usuario models.Usuario
-	query := `SELECT id_usuario, usuario, nombre, apellido_paterno, apellido_materno, password, rol, created_at 
-	          FROM usuarios WHERE usuario = ?`
-
-	err := config.DB.QueryRow(query, loginReq.Usuario).Scan(
-		&usuario.IDUsuario,
-		&usuario.Usuario,
-		&usuario.Nombre,
-		&usuario.ApellidoPaterno,
-		&usuario.ApellidoMaterno,
-		&usuario.Password,
-		&usuario.Rol,
-		&usuario.CreatedAt,
-	)
+	intento := &models.IntentoLogin{
+		Usuario:   intentos.NormalizarUsuario(loginReq.Usuario),
+		IP:        utils.ClientIP(r),
+		UserAgent: utils.UserAgent(r),
+	}
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			utils.ErrorResponse(w, http.StatusUnauthorized, "Usuario o contraseña incorrectos")
+	// Rechazar sin verificar la contraseña mientras el usuario o la IP estén bloqueados
+	if h.bloqueado(w, r, intento) {
+		return
+	}
+
+	// Contraseña local y, si está configurado, el directorio LDAP
+	resultado, err := h.autenticador.Autenticar(r.Context(), loginReq.Usuario, loginReq.Password)
+	if resultado != nil {
+		intento.IDUsuario = &resultado.Usuario.IDUsuario
+	}
+	switch {
+	case errors.Is(err, autenticacion.ErrCredenciales):
+		h.rechazarLogin(w, r, intento, credencialesIncorrectas)
+		return
+	case errors.Is(err, autenticacion.ErrSinAcceso):
+		h.rechazarLogin(w, r, intento, "Tu cuenta del directorio no tiene acceso al sistema")
+		return
+	case err != nil:
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+	usuario := resultado.Usuario
+	h.auditarSincronizacion(r, resultado)
+
+	dosFactores, err := h.dosFactores.Get(r.Context(), usuario.IDUsuario)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Println("Error al consultar verificación en dos pasos:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+
+	// Con la verificación en dos pasos activa la sesión no se autentica hasta
+	// que se confirme el código en /login/2fa. El éxito tampoco se registra
+	// aún, para que los códigos incorrectos sigan sumando al bloqueo.
+	if dosFactores != nil && dosFactores.Activo {
+		if err := marcarPendiente2FA(w, r, usuario.IDUsuario); err != nil {
+			log.Println("❌ Error al guardar sesión:", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear sesión")
 			return
 		}
-		log.Println("Error al buscar usuario:", err)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		utils.SuccessResponse(w, "Ingresa el código de verificación", map[string]bool{"requiere_2fa": true})
 		return
 	}
 
-	// Verificar contraseña
-	if err := bcrypt.CompareHashAndPassword([]byte(usuario.Password), []byte(loginReq.Password)); err != nil {
-		utils.ErrorResponse(w, http.StatusUnauthorized, "Usuario o contraseña incorrectos")
+	h.iniciarSesion(w, r, usuario, intento, dosFactores)
+}
+
+// auditarSincronizacion registra el alta o los cambios de nombre y rol que
+// trajo el directorio. El autor es el propio usuario, aunque la sesión aún
+// espere el código de verificación.
+func (h *Handler) auditarSincronizacion(r *http.Request, resultado *autenticacion.Resultado) {
+	if !resultado.Creado && resultado.Anterior == nil {
+		return
+	}
+	accion := auditoria.AccionActualizar
+	var antes interface{}
+	if resultado.Creado {
+		accion = auditoria.AccionCrear
+	} else {
+		antes = resultado.Anterior
+	}
+
+	cambios, err := auditoria.Diff(antes, resultado.Usuario)
+	if err != nil || len(cambios) == 0 {
 		return
 	}
+	h.registrarAuditoriaDe(r, resultado.Usuario, auditoria.EntidadUsuario, strconv.Itoa(resultado.Usuario.IDUsuario), accion, cambios)
+}
 
-	// Crear sesión usando el store centralizado
+// marcarPendiente2FA guarda en la sesión al usuario que ya se identificó y
+// solo falta que confirme el código en /login/2fa
+func marcarPendiente2FA(w http.ResponseWriter, r *http.Request, idUsuario int) error {
 	session, _ := config.SessionStore.Get(r, "session-name")
+	session.Values[pendiente2FA] = idUsuario
+	session.Values[pendiente2FADesde] = time.Now().Unix()
+	return session.Save(r, w)
+}
+
+// iniciarSesion autentica la sesión y responde con los datos del usuario
+func (h *Handler) iniciarSesion(w http.ResponseWriter, r *http.Request, usuario *models.Usuario, intento *models.IntentoLogin, dosFactores *models.DosFactores) {
+	configurar, err := h.autenticarSesion(w, r, usuario, intento, dosFactores)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear sesión")
+		return
+	}
+
+	utils.SuccessResponse(w, "Login exitoso", map[string]interface{}{
+		"usuario":         usuario.Usuario,
+		"nombre_completo": usuario.NombreCompleto(),
+		"rol":             usuario.Rol,
+		"id_plantel":      usuario.IDPlantel,
+		"configurar_2fa":  configurar,
+	})
+}
+
+// autenticarSesion registra el inicio de sesión exitoso y autentica la sesión.
+// Si el rol exige verificación en dos pasos y el usuario no la tiene activa,
+// la sesión queda limitada a configurarla (ver middleware.ExigirDosFactores)
+// y devuelve true.
+func (h *Handler) autenticarSesion(w http.ResponseWriter, r *http.Request, usuario *models.Usuario, intento *models.IntentoLogin, dosFactores *models.DosFactores) (bool, error) {
+	if err := h.limitador.RegistrarExito(r.Context(), intento); err != nil {
+		log.Println("Error al registrar intento de login:", err)
+	}
+
+	// Crear sesión usando el store centralizado, con un token nuevo para que
+	// no pueda reutilizarse uno obtenido antes del login
+	session, _ := config.SessionStore.Get(r, "session-name")
+	if err := config.SessionStore.Regenerar(r.Context(), session); err != nil {
+		log.Println("❌ Error al regenerar sesión:", err)
+		return false, err
+	}
 	session.Values["authenticated"] = true
 	session.Values["user_id"] = usuario.IDUsuario
 	session.Values["username"] = usuario.Usuario
 	session.Values["nombre_completo"] = usuario.NombreCompleto()
 	session.Values["rol"] = usuario.Rol
+	// 0 cuando no tiene plantel asignado
+	idPlantel := 0
+	if usuario.IDPlantel != nil {
+		idPlantel = *usuario.IDPlantel
+	}
+	session.Values["id_plantel"] = idPlantel
+
+	configurar := h.rolesDosFactores[usuario.Rol] && (dosFactores == nil || !dosFactores.Activo)
+	if configurar {
+		session.Values[middleware.Configurar2FA] = true
+	}
 
 	if err := session.Save(r, w); err != nil {
 		log.Println("❌ Error al guardar sesión:", err)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear sesión")
-		return
+		return false, err
 	}
 
 	log.Printf("✅ Login exitoso para: %s (ID: %d)", usuario.Usuario, usuario.IDUsuario)
-
-	utils.SuccessResponse(w, "Login exitoso", map[string]interface{}{
-		"usuario":         usuario.Usuario,
-		"nombre_completo": usuario.NombreCompleto(),
-		"rol":             usuario.Rol,
-	})
+	return configurar, nil
 }
 
-func Logout(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	session, _ := config.SessionStore.Get(r, "session-name")
 	session.Values["authenticated"] = false
 	session.Options.MaxAge = -1
@@ -92,66 +218,52 @@ func Logout(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
-func DashboardPage(w http.ResponseWriter, r *http.Request) {
-	session, _ := config.SessionStore.Get(r, "session-name")
-
-	data := map[string]interface{}{
-		"Title":          "Dashboard",
-		"Username":       session.Values["username"],
-		"NombreCompleto": session.Values["nombre_completo"],
-	}
-
-	tmpl, err := template.ParseFiles(
-		"web/templates/base.html",
-		"web/templates/dashboard.html",
-		"web/templates/components/header.html",
-		"web/templates/components/footer.html",
-	)
-
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+func (h *Handler) DashboardPage(w http.ResponseWriter, r *http.Request) {
+	renderPagina(w, r, "Dashboard", "web/templates/dashboard.html")
+}
 
-	tmpl.ExecuteTemplate(w, "base", data)
+func (h *Handler) EgresadosPage(w http.ResponseWriter, r *http.Request) {
+	renderPagina(w, r, "Gestión de Egresados", "web/templates/egresados.html")
 }
 
-func EgresadosPage(w http.ResponseWriter, r *http.Request) {
-	session, _ := config.SessionStore.Get(r, "session-name")
+func (h *Handler) AdministradoresPage(w http.ResponseWriter, r *http.Request) {
+	renderPagina(w, r, "Gestión de Administradores", "web/templates/administradores.html")
+}
 
-	data := map[string]interface{}{
-		"Title":          "Gestión de Egresados",
-		"Username":       session.Values["username"],
-		"NombreCompleto": session.Values["nombre_completo"],
-	}
+func (h *Handler) CatalogosPage(w http.ResponseWriter, r *http.Request) {
+	renderPagina(w, r, "Catálogos", "web/templates/catalogos.html")
+}
 
-	tmpl, err := template.ParseFiles(
-		"web/templates/base.html",
-		"web/templates/egresados.html",
-		"web/templates/components/header.html",
-		"web/templates/components/footer.html",
-	)
+func (h *Handler) SeguridadPage(w http.ResponseWriter, r *http.Request) {
+	renderPagina(w, r, "Seguridad", "web/templates/seguridad.html")
+}
 
+// renderPagina dibuja una página protegida dentro de base.html con los datos
+// de la sesión que usa el encabezado
+func renderPagina(w http.ResponseWriter, r *http.Request, titulo, plantilla string) {
+	token, err := middleware.TokenCSRF(w, r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Error al crear sesión", http.StatusInternalServerError)
 		return
 	}
 
-	tmpl.ExecuteTemplate(w, "base", data)
-}
-
-func AdministradoresPage(w http.ResponseWriter, r *http.Request) {
 	session, _ := config.SessionStore.Get(r, "session-name")
+	rol, _ := session.Values["rol"].(string)
 
 	data := map[string]interface{}{
-		"Title":          "Gestión de Administradores",
-		"Username":       session.Values["username"],
-		"NombreCompleto": session.Values["nombre_completo"],
+		"Title":                   titulo,
+		"Username":                session.Values["username"],
+		"NombreCompleto":          session.Values["nombre_completo"],
+		"PuedeAdministrar":        auth.HasPermission(rol, auth.PermAdminsManage),
+		"PuedeGestionarCatalogos": auth.HasPermission(rol, auth.PermCatalogosManage),
+		"VerTodosLosPlanteles":    auth.HasPermission(rol, auth.PermPlantelesTodos),
+		"CSRFToken":               token,
+		"Nonce":                   middleware.NonceCSP(r),
 	}
 
 	tmpl, err := template.ParseFiles(
 		"web/templates/base.html",
-		"web/templates/administradores.html",
+		plantilla,
 		"web/templates/components/header.html",
 		"web/templates/components/footer.html",
 	)
@@ -165,12 +277,12 @@ func AdministradoresPage(w http.ResponseWriter, r *http.Request) {
 }
 
 // Error404Handler maneja las páginas no encontradas
-func Error404Handler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) Error404Handler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotFound)
 	tmpl, err := template.ParseFiles("web/templates/error404.html")
 	if err != nil {
 		http.Error(w, "404 - Página no encontrada", http.StatusNotFound)
 		return
 	}
-	tmpl.Execute(w, nil)
+	tmpl.Execute(w, map[string]interface{}{"Nonce": middleware.NonceCSP(r)})
 }
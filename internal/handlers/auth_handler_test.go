@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/middleware"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/totp"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var metaCSRF = regexp.MustCompile(`<meta name="csrf-token" content="([^"]*)">`)
+
+// paginaLogin pide el login con las cookies de anterior (si hay) y devuelve
+// la respuesta y el token CSRF publicado en la página
+func paginaLogin(t *testing.T, h *Handler, anterior *httptest.ResponseRecorder) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if anterior != nil {
+		r = conCookies(r, anterior)
+	}
+	w := httptest.NewRecorder()
+	h.LoginPage(w, r)
+	coincidencia := metaCSRF.FindStringSubmatch(w.Body.String())
+	if w.Code != http.StatusOK || coincidencia == nil || coincidencia[1] == "" {
+		t.Fatalf("LoginPage = %d sin token CSRF", w.Code)
+	}
+	return w, coincidencia[1]
+}
+
+// enviarConCSRF pasa la petición por middleware.CSRF con el token y las
+// cookies de las respuestas anteriores
+func enviarConCSRF(handler http.HandlerFunc, ruta, cuerpo, token string, anteriores ...*httptest.ResponseRecorder) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, ruta, strings.NewReader(cuerpo))
+	r.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		r.Header.Set(middleware.CSRFHeader, token)
+	}
+	for _, anterior := range anteriores {
+		r = conCookies(r, anterior)
+	}
+	w := httptest.NewRecorder()
+	middleware.CSRF(handler).ServeHTTP(w, r)
+	return w
+}
+
+func cookie(w *httptest.ResponseRecorder, nombre string) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == nombre {
+			return c
+		}
+	}
+	return nil
+}
+
+func usuarioPrueba(t *testing.T, repos *repository.Repositories) *models.Usuario {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("contraseña123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &models.Usuario{Usuario: "ana", Nombre: "Ana", Rol: auth.RolConsulta, Password: string(hash)}
+	id, err := repos.Usuarios.Create(context.Background(), u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.IDUsuario = id
+	return u
+}
+
+const credencialesPrueba = `{"usuario":"ana","password":"contraseña123"}`
+
+func TestLoginPageNoCreaSesion(t *testing.T) {
+	h, _ := handlerPrueba(t)
+
+	primera, token := paginaLogin(t, h, nil)
+	if c := cookie(primera, "session-name"); c != nil {
+		t.Fatalf("el login anónimo no debe crear una sesión: %v", c)
+	}
+	if cookie(primera, "csrf_anonimo") == nil {
+		t.Fatal("falta la cookie con el token CSRF anónimo")
+	}
+
+	// Al volver se reutiliza el mismo token sin enviar cookies nuevas
+	segunda, otra := paginaLogin(t, h, primera)
+	if otra != token || len(segunda.Result().Cookies()) != 0 {
+		t.Errorf("token = %q (antes %q), cookies = %v", otra, token, segunda.Result().Cookies())
+	}
+}
+
+func TestLoginConTokenCSRFAnonimo(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	usuarioPrueba(t, repos)
+	pagina, token := paginaLogin(t, h, nil)
+
+	if w := enviarConCSRF(h.Login, "/login", credencialesPrueba, "", pagina); w.Code != http.StatusForbidden {
+		t.Errorf("login sin token = %d, se esperaba 403", w.Code)
+	}
+	if w := enviarConCSRF(h.Login, "/login", credencialesPrueba, token); w.Code != http.StatusForbidden {
+		t.Errorf("login sin la cookie = %d, se esperaba 403", w.Code)
+	}
+
+	// Una cookie con otro token y sin la firma del servidor no sirve
+	alterada := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(credencialesPrueba))
+	alterada.Header.Set(middleware.CSRFHeader, "inventado")
+	alterada.AddCookie(&http.Cookie{Name: "csrf_anonimo", Value: "inventado.firma"})
+	w := httptest.NewRecorder()
+	middleware.CSRF(http.HandlerFunc(h.Login)).ServeHTTP(w, alterada)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("login con cookie alterada = %d, se esperaba 403", w.Code)
+	}
+
+	w = enviarConCSRF(h.Login, "/login", credencialesPrueba, token, pagina)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Login = %d: %s", w.Code, w.Body.String())
+	}
+	if cookie(w, "session-name") == nil {
+		t.Error("el login debe crear la sesión")
+	}
+}
+
+func TestLoginDosFactoresConTokenCSRFAnonimo(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	usuario := usuarioPrueba(t, repos)
+	secreto, err := totp.GenerarSecreto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := repos.DosFactores.GuardarPendiente(ctx, usuario.IDUsuario, secreto); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.DosFactores.Activar(ctx, usuario.IDUsuario, totp.Paso(time.Now())-2, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	pagina, token := paginaLogin(t, h, nil)
+	login := enviarConCSRF(h.Login, "/login", credencialesPrueba, token, pagina)
+	if login.Code != http.StatusOK || !strings.Contains(login.Body.String(), `"requiere_2fa":true`) {
+		t.Fatalf("Login = %d: %s", login.Code, login.Body.String())
+	}
+	if cookie(login, "session-name") == nil {
+		t.Fatal("el paso pendiente del código debe guardarse en una sesión")
+	}
+
+	codigo, err := totp.Codigo(secreto, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// La página del login sigue abierta con el token anónimo
+	w := enviarConCSRF(h.LoginDosFactores, "/login/2fa", `{"codigo":"`+codigo+`"}`, token, pagina, login)
+	if w.Code != http.StatusOK {
+		t.Fatalf("LoginDosFactores = %d: %s", w.Code, w.Body.String())
+	}
+}
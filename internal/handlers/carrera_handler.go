@@ -1,30 +1,113 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
-	"ues-egresados/internal/config"
+	"strconv"
+	"ues-egresados/internal/auditoria"
 	"ues-egresados/internal/models"
 	"ues-egresados/internal/utils"
+	"ues-egresados/internal/validacion"
 )
 
+var mensajesCarrera = mensajesCatalogo{
+	campo:        "nombre",
+	duplicado:    "Ya existe una carrera con ese nombre",
+	noEncontrado: "Carrera no encontrada",
+	enUso:        "La carrera tiene %d egresados asignados (incluida la papelera); reasígnalos a otra carrera antes de eliminarla",
+}
+
 // GetCarreras obtiene todas las carreras
-func GetCarreras(w http.ResponseWriter, r *http.Request) {
-	rows, err := config.DB.Query("SELECT id_carrera, nombre FROM carreras ORDER BY nombre")
+func (h *Handler) GetCarreras(w http.ResponseWriter, r *http.Request) {
+	carreras, err := h.catalogos.ListCarreras(r.Context())
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener carreras")
 		return
 	}
-	defer rows.Close()
 
-	var carreras []models.Carrera
-	for rows.Next() {
-		var c models.Carrera
-		if err := rows.Scan(&c.IDCarrera, &c.Nombre); err != nil {
-			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al procesar datos")
-			return
-		}
-		carreras = append(carreras, c)
+	utils.SuccessResponse(w, "Carreras obtenidas correctamente", carreras)
+}
+
+// CreateCarrera agrega una carrera al catálogo
+func (h *Handler) CreateCarrera(w http.ResponseWriter, r *http.Request) {
+	var c models.Carrera
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	if errores := validacion.Carrera(&c); len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
 	}
 
-	utils.SuccessResponse(w, "Carreras obtenidas correctamente", carreras)
-}
\ No newline at end of file
+	if err := h.catalogos.CreateCarrera(r.Context(), &c); err != nil {
+		responderErrorCatalogo(w, err, mensajesCarrera, "Error al crear carrera")
+		return
+	}
+
+	h.auditar(r, auditoria.EntidadCarrera, strconv.Itoa(c.IDCarrera), auditoria.AccionCrear, nil, c)
+
+	utils.CreatedResponse(w, "Carrera creada correctamente", c)
+}
+
+// UpdateCarrera modifica una carrera del catálogo
+func (h *Handler) UpdateCarrera(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCatalogo(w, r)
+	if !ok {
+		return
+	}
+
+	var c models.Carrera
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	c.IDCarrera = id
+	if errores := validacion.Carrera(&c); len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	antes, err := h.catalogos.GetCarrera(r.Context(), id)
+	if err != nil {
+		responderErrorCatalogo(w, err, mensajesCarrera, "Error al obtener carrera")
+		return
+	}
+
+	if err := h.catalogos.UpdateCarrera(r.Context(), &c); err != nil {
+		responderErrorCatalogo(w, err, mensajesCarrera, "Error al actualizar carrera")
+		return
+	}
+
+	h.auditar(r, auditoria.EntidadCarrera, strconv.Itoa(id), auditoria.AccionActualizar, antes, c)
+
+	utils.SuccessResponse(w, "Carrera actualizada correctamente", c)
+}
+
+// DeleteCarrera elimina una carrera sin egresados asignados; con
+// ?reasignar=ID primero los mueve a otra carrera
+func (h *Handler) DeleteCarrera(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCatalogo(w, r)
+	if !ok {
+		return
+	}
+	reasignarA, ok := reasignarCatalogo(w, r, id)
+	if !ok {
+		return
+	}
+
+	antes, err := h.catalogos.GetCarrera(r.Context(), id)
+	if err != nil {
+		responderErrorCatalogo(w, err, mensajesCarrera, "Error al obtener carrera")
+		return
+	}
+
+	if err := h.catalogos.DeleteCarrera(r.Context(), id, reasignarA); err != nil {
+		responderErrorCatalogo(w, err, mensajesCarrera, "Error al eliminar carrera")
+		return
+	}
+
+	h.auditarBajaCatalogo(r, auditoria.EntidadCarrera, id, antes, reasignarA)
+
+	utils.SuccessResponse(w, "Carrera eliminada correctamente", nil)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// mensajesCatalogo personaliza las respuestas de error de cada catálogo
+type mensajesCatalogo struct {
+	campo        string // campo con la restricción de unicidad
+	duplicado    string
+	noEncontrado string
+	enUso        string // recibe el número de egresados asignados
+	// enUsoUsuarios recibe egresados y usuarios; solo aplica a planteles
+	enUsoUsuarios string
+}
+
+// responderErrorCatalogo traduce los errores del repositorio de catálogos
+func responderErrorCatalogo(w http.ResponseWriter, err error, m mensajesCatalogo, generico string) {
+	var enUso *repository.EnUsoError
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, m.noEncontrado)
+	case errors.Is(err, repository.ErrDuplicado):
+		utils.ValidationErrorResponse(w, map[string]string{m.campo: m.duplicado})
+	case errors.Is(err, repository.ErrReferenciaInvalida):
+		utils.ValidationErrorResponse(w, map[string]string{"reasignar": "El registro al que se reasigna no existe"})
+	case errors.As(err, &enUso) && enUso.Usuarios > 0:
+		utils.JSONResponse(w, http.StatusConflict, utils.Response{
+			Success: false,
+			Error:   fmt.Sprintf(m.enUsoUsuarios, enUso.Egresados, enUso.Usuarios),
+			Data:    map[string]int{"egresados": enUso.Egresados, "usuarios": enUso.Usuarios},
+		})
+	case errors.As(err, &enUso):
+		utils.JSONResponse(w, http.StatusConflict, utils.Response{
+			Success: false,
+			Error:   fmt.Sprintf(m.enUso, enUso.Egresados),
+			Data:    map[string]int{"egresados": enUso.Egresados},
+		})
+	case errors.Is(err, repository.ErrEnUso):
+		utils.ErrorResponse(w, http.StatusConflict, "El registro tiene egresados asignados")
+	default:
+		utils.ErrorResponse(w, http.StatusInternalServerError, generico)
+	}
+}
+
+// idCatalogo lee el ID de la ruta; responde 400 si no es numérico
+func idCatalogo(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id <= 0 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "ID inválido")
+		return 0, false
+	}
+	return id, true
+}
+
+// reasignarCatalogo lee ?reasignar=ID, el registro que recibirá a los
+// egresados del que se elimina (0 si no se indicó)
+func reasignarCatalogo(w http.ResponseWriter, r *http.Request, id int) (int, bool) {
+	valor := r.URL.Query().Get("reasignar")
+	if valor == "" {
+		return 0, true
+	}
+
+	destino, err := strconv.Atoi(valor)
+	if err != nil || destino <= 0 {
+		utils.ValidationErrorResponse(w, map[string]string{"reasignar": "ID inválido"})
+		return 0, false
+	}
+	if destino == id {
+		utils.ValidationErrorResponse(w, map[string]string{"reasignar": "Elige un registro distinto al que se elimina"})
+		return 0, false
+	}
+	return destino, true
+}
+
+// auditarBajaCatalogo registra la eliminación y, si la hubo, la reasignación
+// de los egresados
+func (h *Handler) auditarBajaCatalogo(r *http.Request, entidad string, id int, antes interface{}, reasignarA int) {
+	cambios, err := auditoria.Diff(antes, nil)
+	if err != nil {
+		return
+	}
+	if reasignarA != 0 {
+		cambios["egresados_reasignados_a"] = auditoria.Cambio{Despues: reasignarA}
+	}
+	h.registrarAuditoria(r, entidad, strconv.Itoa(id), auditoria.AccionEliminar, cambios)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"ues-egresados/internal/utils"
+)
+
+// etag representa la versión de un registro como ETag fuerte
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// coincideIfMatch revisa el encabezado If-Match contra la versión actual.
+// presente es falso si el cliente no lo envió. Se aceptan listas de ETags,
+// el comodín "*" y ETags débiles, porque algunos proxies los debilitan al
+// comprimir la respuesta.
+func coincideIfMatch(r *http.Request, version int) (presente, coincide bool) {
+	valores := r.Header.Values("If-Match")
+	if len(valores) == 0 {
+		return false, false
+	}
+
+	esperado := etag(version)
+	for _, valor := range valores {
+		for _, tag := range strings.Split(valor, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == esperado {
+				return true, true
+			}
+		}
+	}
+	return true, false
+}
+
+// verificarVersion exige If-Match y responde 428 si falta o 412 con el
+// registro actual si el cliente editó una versión anterior. Devuelve false
+// cuando ya se respondió.
+func verificarVersion(w http.ResponseWriter, r *http.Request, version int, actual interface{}) bool {
+	presente, coincide := coincideIfMatch(r, version)
+	if !presente {
+		utils.ErrorResponse(w, http.StatusPreconditionRequired, "Falta el encabezado If-Match con la versión del registro")
+		return false
+	}
+	if !coincide {
+		responderConflicto(w, http.StatusPreconditionFailed, version, actual)
+		return false
+	}
+	return true
+}
+
+// responderConflicto devuelve la versión vigente del registro para que el
+// cliente pueda recargarla antes de volver a guardar
+func responderConflicto(w http.ResponseWriter, status, version int, actual interface{}) {
+	w.Header().Set("ETag", etag(version))
+	utils.JSONResponse(w, status, utils.Response{
+		Success: false,
+		Error:   "El registro fue modificado por otro usuario; recarga los datos e inténtalo de nuevo",
+		Data:    actual,
+	})
+}
@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/intentos"
+	"ues-egresados/internal/middleware"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/totp"
+	"ues-egresados/internal/utils"
+
+	"github.com/gorilla/mux"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// emisorTOTP es el nombre con el que aparece la cuenta en la aplicación
+	// autenticadora
+	emisorTOTP = "SIDEUESSJR"
+
+	// Valores de la sesión entre la contraseña y el código de verificación
+	pendiente2FA      = "pendiente_2fa"
+	pendiente2FADesde = "pendiente_2fa_desde"
+	// vigenciaPendiente2FA es el tiempo para ingresar el código tras la contraseña
+	vigenciaPendiente2FA = 5 * time.Minute
+
+	codigoIncorrecto = "Código de verificación incorrecto"
+)
+
+// LoginDosFactores completa el inicio de sesión de un usuario con
+// verificación en dos pasos. Acepta un código de la aplicación o uno de
+// recuperación; los incorrectos cuentan para el bloqueo igual que una
+// contraseña incorrecta.
+func (h *Handler) LoginDosFactores(w http.ResponseWriter, r *http.Request) {
+	var req models.CodigoDosFactoresRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	session, _ := config.SessionStore.Get(r, "session-name")
+	idUsuario, _ := session.Values[pendiente2FA].(int)
+	desde, _ := session.Values[pendiente2FADesde].(int64)
+	if idUsuario == 0 || time.Since(time.Unix(desde, 0)) > vigenciaPendiente2FA {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "La verificación expiró, vuelve a ingresar tu contraseña")
+		return
+	}
+
+	usuario, err := h.usuarios.GetByID(r.Context(), idUsuario)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "La verificación expiró, vuelve a ingresar tu contraseña")
+			return
+		}
+		log.Println("Error al buscar usuario:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+
+	intento := &models.IntentoLogin{
+		Usuario:   intentos.NormalizarUsuario(usuario.Usuario),
+		IDUsuario: &usuario.IDUsuario,
+		IP:        utils.ClientIP(r),
+		UserAgent: utils.UserAgent(r),
+	}
+
+	dosFactores, ok := h.dosFactoresActivos(w, r, usuario.IDUsuario)
+	if !ok {
+		return
+	}
+	if !h.comprobarCodigo(w, r, intento, dosFactores, req.Codigo) {
+		return
+	}
+
+	h.iniciarSesion(w, r, usuario, intento, dosFactores)
+}
+
+// GetDosFactores indica si el usuario tiene activa la verificación en dos
+// pasos, si su rol la exige y cuántos códigos de recuperación le quedan
+func (h *Handler) GetDosFactores(w http.ResponseWriter, r *http.Request) {
+	idUsuario := idUsuarioSesion(r)
+	estado := map[string]interface{}{
+		"activo":            false,
+		"pendiente":         false,
+		"obligatorio":       h.rolesDosFactores[rolSesion(r)],
+		"activado_en":       nil,
+		"codigos_restantes": 0,
+	}
+
+	dosFactores, err := h.dosFactores.Get(r.Context(), idUsuario)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al consultar la verificación en dos pasos")
+		return
+	}
+	if dosFactores != nil {
+		estado["activo"] = dosFactores.Activo
+		estado["pendiente"] = !dosFactores.Activo
+		estado["activado_en"] = dosFactores.ActivadoEn
+	}
+	if dosFactores != nil && dosFactores.Activo {
+		restantes, err := h.dosFactores.CodigosRestantes(r.Context(), idUsuario)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al consultar la verificación en dos pasos")
+			return
+		}
+		estado["codigos_restantes"] = restantes
+	}
+
+	utils.SuccessResponse(w, "Estado obtenido correctamente", estado)
+}
+
+// IniciarDosFactores genera un secreto nuevo pendiente de confirmar y
+// devuelve el enlace otpauth:// para la aplicación autenticadora
+func (h *Handler) IniciarDosFactores(w http.ResponseWriter, r *http.Request) {
+	idUsuario := idUsuarioSesion(r)
+	actual, err := h.dosFactores.Get(r.Context(), idUsuario)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al consultar la verificación en dos pasos")
+		return
+	}
+	if actual != nil && actual.Activo {
+		utils.ErrorResponse(w, http.StatusConflict, "La verificación en dos pasos ya está activa")
+		return
+	}
+
+	secreto, err := totp.GenerarSecreto()
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al generar el secreto")
+		return
+	}
+	if err := h.dosFactores.GuardarPendiente(r.Context(), idUsuario, secreto); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al guardar el secreto")
+		return
+	}
+
+	utils.SuccessResponse(w, "Escanea el código QR con tu aplicación autenticadora", map[string]string{
+		"secreto":     secreto,
+		"otpauth_uri": totp.URI(emisorTOTP, usuarioSesion(r), secreto),
+	})
+}
+
+// GetQRDosFactores devuelve como PNG el código QR del secreto pendiente
+func (h *Handler) GetQRDosFactores(w http.ResponseWriter, r *http.Request) {
+	dosFactores, err := h.dosFactores.Get(r.Context(), idUsuarioSesion(r))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "No hay una configuración pendiente")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al consultar la verificación en dos pasos")
+		return
+	}
+	// El secreto no vuelve a mostrarse una vez activo
+	if dosFactores.Activo {
+		utils.ErrorResponse(w, http.StatusNotFound, "No hay una configuración pendiente")
+		return
+	}
+
+	png, err := qrcode.Encode(totp.URI(emisorTOTP, usuarioSesion(r), dosFactores.Secreto), qrcode.Medium, 256)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al generar el código QR")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(png)
+}
+
+// ActivarDosFactores confirma el secreto pendiente con un código de la
+// aplicación y entrega los códigos de recuperación, que solo se muestran
+// esta vez
+func (h *Handler) ActivarDosFactores(w http.ResponseWriter, r *http.Request) {
+	var req models.CodigoDosFactoresRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	idUsuario := idUsuarioSesion(r)
+	dosFactores, err := h.dosFactores.Get(r.Context(), idUsuario)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al consultar la verificación en dos pasos")
+		return
+	}
+	if dosFactores == nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "No hay una configuración pendiente")
+		return
+	}
+	if dosFactores.Activo {
+		utils.ErrorResponse(w, http.StatusConflict, "La verificación en dos pasos ya está activa")
+		return
+	}
+
+	paso, ok := totp.Verificar(dosFactores.Secreto, normalizarCodigo(req.Codigo), time.Now())
+	if !ok {
+		utils.ErrorResponse(w, http.StatusUnauthorized, codigoIncorrecto)
+		return
+	}
+
+	codigos, hashes, err := nuevosCodigosRecuperacion()
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al generar los códigos de recuperación")
+		return
+	}
+	if err := h.dosFactores.Activar(r.Context(), idUsuario, paso, hashes); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusConflict, "La configuración cambió, vuelve a intentarlo")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al activar la verificación en dos pasos")
+		return
+	}
+
+	session, _ := config.SessionStore.Get(r, "session-name")
+	if _, ok := session.Values[middleware.Configurar2FA]; ok {
+		delete(session.Values, middleware.Configurar2FA)
+		if err := session.Save(r, w); err != nil {
+			log.Println("❌ Error al guardar sesión:", err)
+		}
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadDosFactores, strconv.Itoa(idUsuario), auditoria.AccionCrear, nil)
+
+	utils.SuccessResponse(w, "Verificación en dos pasos activada", map[string][]string{
+		"codigos_recuperacion": codigos,
+	})
+}
+
+// DesactivarDosFactores elimina la verificación en dos pasos del usuario tras
+// confirmar un código. No se permite si su rol la exige.
+func (h *Handler) DesactivarDosFactores(w http.ResponseWriter, r *http.Request) {
+	var req models.CodigoDosFactoresRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	if h.rolesDosFactores[rolSesion(r)] {
+		utils.ErrorResponse(w, http.StatusForbidden, "La verificación en dos pasos es obligatoria para tu rol")
+		return
+	}
+
+	idUsuario := idUsuarioSesion(r)
+	dosFactores, ok := h.dosFactoresActivos(w, r, idUsuario)
+	if !ok {
+		return
+	}
+	if !h.comprobarCodigo(w, r, intentoDeSesion(r), dosFactores, req.Codigo) {
+		return
+	}
+
+	if err := h.dosFactores.Delete(r.Context(), idUsuario); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al desactivar la verificación en dos pasos")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadDosFactores, strconv.Itoa(idUsuario), auditoria.AccionEliminar, nil)
+
+	utils.SuccessResponse(w, "Verificación en dos pasos desactivada", nil)
+}
+
+// RegenerarCodigosRecuperacion reemplaza los códigos de recuperación del
+// usuario; los anteriores dejan de servir
+func (h *Handler) RegenerarCodigosRecuperacion(w http.ResponseWriter, r *http.Request) {
+	var req models.CodigoDosFactoresRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	idUsuario := idUsuarioSesion(r)
+	dosFactores, ok := h.dosFactoresActivos(w, r, idUsuario)
+	if !ok {
+		return
+	}
+	if !h.comprobarCodigo(w, r, intentoDeSesion(r), dosFactores, req.Codigo) {
+		return
+	}
+
+	codigos, hashes, err := nuevosCodigosRecuperacion()
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al generar los códigos de recuperación")
+		return
+	}
+	if err := h.dosFactores.ReemplazarCodigos(r.Context(), idUsuario, hashes); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al guardar los códigos de recuperación")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadDosFactores, strconv.Itoa(idUsuario), auditoria.AccionActualizar,
+		map[string]int{"codigos_recuperacion": len(codigos)})
+
+	utils.SuccessResponse(w, "Códigos de recuperación generados", map[string][]string{
+		"codigos_recuperacion": codigos,
+	})
+}
+
+// RestablecerDosFactores elimina la verificación en dos pasos de otro
+// usuario, por ejemplo si perdió el teléfono y sus códigos de recuperación,
+// y cierra sus sesiones. Si su rol la exige deberá configurarla de nuevo al
+// iniciar sesión.
+func (h *Handler) RestablecerDosFactores(w http.ResponseWriter, r *http.Request) {
+	idUsuario, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "ID de usuario inválido")
+		return
+	}
+
+	if _, err := h.usuarios.GetByID(r.Context(), idUsuario); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Administrador no encontrado")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener administrador")
+		return
+	}
+
+	if err := h.dosFactores.Delete(r.Context(), idUsuario); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "El usuario no tiene verificación en dos pasos")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al restablecer la verificación en dos pasos")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadDosFactores, strconv.Itoa(idUsuario), auditoria.AccionEliminar, nil)
+
+	if _, err := h.cerrarSesionesUsuario(r, idUsuario); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Verificación restablecida, pero no se pudieron cerrar sus sesiones")
+		return
+	}
+
+	utils.SuccessResponse(w, "Verificación en dos pasos restablecida", nil)
+}
+
+// dosFactoresActivos devuelve la configuración activa del usuario o responde
+// 409 si no la tiene
+func (h *Handler) dosFactoresActivos(w http.ResponseWriter, r *http.Request, idUsuario int) (*models.DosFactores, bool) {
+	dosFactores, err := h.dosFactores.Get(r.Context(), idUsuario)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al consultar la verificación en dos pasos")
+		return nil, false
+	}
+	if dosFactores == nil || !dosFactores.Activo {
+		utils.ErrorResponse(w, http.StatusConflict, "La verificación en dos pasos no está activa")
+		return nil, false
+	}
+	return dosFactores, true
+}
+
+// comprobarCodigo acepta un código de la aplicación (6 dígitos, que no se haya
+// usado antes) o uno de recuperación, que queda consumido. Responde y
+// devuelve false si el usuario está bloqueado o el código es incorrecto.
+func (h *Handler) comprobarCodigo(w http.ResponseWriter, r *http.Request, intento *models.IntentoLogin, dosFactores *models.DosFactores, codigo string) bool {
+	if h.bloqueado(w, r, intento) {
+		return false
+	}
+
+	codigo = normalizarCodigo(codigo)
+	var valido bool
+	if paso, ok := totp.Verificar(dosFactores.Secreto, codigo, time.Now()); ok {
+		registrado, err := h.dosFactores.RegistrarPaso(r.Context(), dosFactores.IDUsuario, paso)
+		if err != nil {
+			log.Println("Error al registrar código de verificación:", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+			return false
+		}
+		valido = registrado
+	} else if len(codigo) != totp.Digitos {
+		err := h.dosFactores.UsarCodigo(r.Context(), dosFactores.IDUsuario, totp.HashRecuperacion(codigo))
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			log.Println("Error al usar código de recuperación:", err)
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+			return false
+		}
+		valido = err == nil
+		if valido {
+			log.Printf("🔑 Código de recuperación usado por el usuario %d", dosFactores.IDUsuario)
+		}
+	}
+
+	if !valido {
+		h.rechazarLogin(w, r, intento, codigoIncorrecto)
+		return false
+	}
+	return true
+}
+
+// intentoDeSesion arma el intento con el que se cuentan los códigos
+// incorrectos del usuario autenticado
+func intentoDeSesion(r *http.Request) *models.IntentoLogin {
+	idUsuario := idUsuarioSesion(r)
+	return &models.IntentoLogin{
+		Usuario:   intentos.NormalizarUsuario(usuarioSesion(r)),
+		IDUsuario: &idUsuario,
+		IP:        utils.ClientIP(r),
+		UserAgent: utils.UserAgent(r),
+	}
+}
+
+// normalizarCodigo quita los espacios que algunas aplicaciones muestran a
+// mitad del código
+func normalizarCodigo(codigo string) string {
+	return strings.ReplaceAll(strings.TrimSpace(codigo), " ", "")
+}
+
+// nuevosCodigosRecuperacion genera los códigos que se muestran al usuario y
+// los hashes que se guardan
+func nuevosCodigosRecuperacion() ([]string, []string, error) {
+	codigos, err := totp.GenerarCodigosRecuperacion(totp.CodigosRecuperacion)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codigos))
+	for i, codigo := range codigos {
+		hashes[i] = totp.HashRecuperacion(codigo)
+	}
+	return codigos, hashes, nil
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+)
+
+// parseFiltroEgresados lee los parámetros de búsqueda y orden. La paginación
+// la agrega el listado; la exportación y el reporte recorren todas las filas.
+func parseFiltroEgresados(r *http.Request) (models.FiltroEgresados, error) {
+	q := r.URL.Query()
+
+	f := models.FiltroEgresados{
+		Generacion: q.Get("generacion"),
+		Carrera:    q.Get("carrera"),
+		Estatus:    q.Get("estatus"),
+		Plantel:    plantelDeFiltro(r),
+		Genero:     strings.TrimSpace(q.Get("genero")),
+		Estado:     strings.TrimSpace(q.Get("estado")),
+		Municipio:  strings.TrimSpace(q.Get("municipio")),
+		Q:          strings.TrimSpace(q.Get("q")),
+		Sort:       q.Get("sort"),
+		Order:      strings.ToLower(q.Get("order")),
+	}
+
+	if f.Sort == "" {
+		f.Sort = "created_at"
+	}
+	if !repository.CampoOrdenValido(f.Sort) {
+		return f, fmt.Errorf("Campo de orden inválido: %s", f.Sort)
+	}
+
+	if f.Order == "" {
+		f.Order = "desc"
+	}
+	if f.Order != "asc" && f.Order != "desc" {
+		return f, fmt.Errorf("Orden inválido: %s", f.Order)
+	}
+
+	return f, nil
+}
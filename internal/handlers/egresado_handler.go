@@ -1,177 +1,78 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
-	"ues-egresados/internal/config"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/mergepatch"
 	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
 	"ues-egresados/internal/utils"
+	"ues-egresados/internal/validacion"
 
 	"github.com/gorilla/mux"
 )
 
-// GetEgresados obtiene todos los egresados con sus relaciones
-func GetEgresados(w http.ResponseWriter, r *http.Request) {
-	query := `
-		SELECT 
-			e.matricula,
-			e.nombre_completo,
-			e.genero,
-			e.telefono,
-			e.correo,
-			e.codigo_postal,
-			e.estado,
-			e.municipio,
-			e.asentamiento,
-			e.calle,
-			e.numero,
-			e.id_carrera,
-			e.id_generacion,
-			e.id_estatus,
-			e.created_at,
-			c.nombre AS nombre_carrera,
-			g.periodo AS periodo_generacion,
-			es.descripcion AS descripcion_estatus
-		FROM egresados e
-		LEFT JOIN carreras c ON e.id_carrera = c.id_carrera
-		LEFT JOIN generaciones g ON e.id_generacion = g.id_generacion
-		LEFT JOIN estatus es ON e.id_estatus = es.id_estatus
-		ORDER BY e.created_at DESC
-	`
-
-	rows, err := config.DB.Query(query)
-	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener egresados")
-		return
-	}
-	defer rows.Close()
-
-	var egresados []models.Egresado
-	for rows.Next() {
-		var e models.Egresado
-		err := rows.Scan(
-			&e.Matricula,
-			&e.NombreCompleto,
-			&e.Genero,
-			&e.Telefono,
-			&e.Correo,
-			&e.CodigoPostal,
-			&e.Estado,
-			&e.Municipio,
-			&e.Asentamiento,
-			&e.Calle,
-			&e.Numero,
-			&e.IDCarrera,
-			&e.IDGeneracion,
-			&e.IDEstatus,
-			&e.CreatedAt,
-			&e.NombreCarrera,
-			&e.PeriodoGeneracion,
-			&e.DescripcionEstatus,
-		)
-		if err != nil {
-			continue
-		}
-		egresados = append(egresados, e)
-	}
-
-	utils.SuccessResponse(w, "Egresados obtenidos correctamente", egresados)
+// GetEgresados obtiene los egresados con sus relaciones (paginado, ordenado y filtrado)
+func (h *Handler) GetEgresados(w http.ResponseWriter, r *http.Request) {
+	h.listarEgresados(w, r)
 }
 
 // GetEgresado obtiene un egresado por matrícula
-func GetEgresado(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetEgresado(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	matricula := vars["matricula"]
 
-	query := `
-		SELECT 
-			e.matricula, e.nombre_completo, e.genero, e.telefono, e.correo,
-			e.codigo_postal, e.estado, e.municipio, e.asentamiento, e.calle, e.numero,
-			e.id_carrera, e.id_generacion, e.id_estatus, e.created_at
-		FROM egresados e
-		WHERE e.matricula = ?
-	`
-
-	var e models.Egresado
-	err := config.DB.QueryRow(query, matricula).Scan(
-		&e.Matricula,
-		&e.NombreCompleto,
-		&e.Genero,
-		&e.Telefono,
-		&e.Correo,
-		&e.CodigoPostal,
-		&e.Estado,
-		&e.Municipio,
-		&e.Asentamiento,
-		&e.Calle,
-		&e.Numero,
-		&e.IDCarrera,
-		&e.IDGeneracion,
-		&e.IDEstatus,
-		&e.CreatedAt,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			utils.ErrorResponse(w, http.StatusNotFound, "Egresado no encontrado")
-			return
-		}
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener egresado")
+	e := h.obtenerEgresado(w, r, matricula)
+	if e == nil {
 		return
 	}
 
+	w.Header().Set("ETag", etag(e.Version))
 	utils.SuccessResponse(w, "Egresado obtenido correctamente", e)
 }
 
 // CreateEgresado crea un nuevo egresado
-func CreateEgresado(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateEgresado(w http.ResponseWriter, r *http.Request) {
 	var egresado models.Egresado
 	if err := json.NewDecoder(r.Body).Decode(&egresado); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
 		return
 	}
 
-	if egresado.Matricula == "" || egresado.NombreCompleto == "" {
-		utils.ErrorResponse(w, http.StatusBadRequest, "Matrícula y nombre son obligatorios")
+	if !h.validarEgresado(w, r, &egresado, nil) {
 		return
 	}
 
-	query := `
-		INSERT INTO egresados 
-		(matricula, nombre_completo, genero, telefono, correo, 
-		 codigo_postal, estado, municipio, asentamiento, calle, numero,
-		 id_carrera, id_generacion, id_estatus)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
-
-	_, err := config.DB.Exec(query,
-		egresado.Matricula,
-		egresado.NombreCompleto,
-		egresado.Genero,
-		egresado.Telefono,
-		egresado.Correo,
-		egresado.CodigoPostal,
-		egresado.Estado,
-		egresado.Municipio,
-		egresado.Asentamiento,
-		egresado.Calle,
-		egresado.Numero,
-		egresado.IDCarrera,
-		egresado.IDGeneracion,
-		egresado.IDEstatus,
-	)
-
-	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear egresado")
+	if err := h.egresados.Create(r.Context(), &egresado); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicado) && h.enPapelera(r, egresado.Matricula):
+			utils.ValidationErrorResponse(w, map[string]string{
+				"matricula": "La matrícula está en la papelera; restáurala en lugar de crearla de nuevo",
+			})
+		case errors.Is(err, repository.ErrDuplicado):
+			utils.ValidationErrorResponse(w, map[string]string{"matricula": "La matrícula ya existe"})
+		case errors.Is(err, repository.ErrReferenciaInvalida):
+			utils.ErrorResponse(w, http.StatusBadRequest, "Carrera, generación o estatus inválido")
+		default:
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear egresado")
+		}
 		return
 	}
 
-	utils.SuccessResponse(w, "Egresado creado correctamente", egresado)
+	guardado := h.egresadoGuardado(r, egresado.Matricula, &egresado)
+	h.auditar(r, auditoria.EntidadEgresado, egresado.Matricula, auditoria.AccionCrear, nil, guardado)
+
+	w.Header().Set("ETag", etag(guardado.Version))
+	utils.SuccessResponse(w, "Egresado creado correctamente", guardado)
 }
 
-// UpdateEgresado actualiza un egresado existente
-func UpdateEgresado(w http.ResponseWriter, r *http.Request) {
+// UpdateEgresado actualiza un egresado existente. Requiere If-Match con el
+// ETag obtenido de GetEgresado para no sobrescribir cambios de otro usuario.
+func (h *Handler) UpdateEgresado(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	matricula := vars["matricula"]
 
@@ -181,103 +82,226 @@ func UpdateEgresado(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `
-		UPDATE egresados 
-		SET nombre_completo = ?, genero = ?, telefono = ?, correo = ?,
-		    codigo_postal = ?, estado = ?, municipio = ?, asentamiento = ?,
-		    calle = ?, numero = ?,
-		    id_carrera = ?, id_generacion = ?, id_estatus = ?
-		WHERE matricula = ?
-	`
-
-	result, err := config.DB.Exec(query,
-		egresado.NombreCompleto,
-		egresado.Genero,
-		egresado.Telefono,
-		egresado.Correo,
-		egresado.CodigoPostal,
-		egresado.Estado,
-		egresado.Municipio,
-		egresado.Asentamiento,
-		egresado.Calle,
-		egresado.Numero,
-		egresado.IDCarrera,
-		egresado.IDGeneracion,
-		egresado.IDEstatus,
-		matricula,
-	)
+	antes := h.obtenerEgresado(w, r, matricula)
+	if antes == nil {
+		return
+	}
+
+	if !verificarVersion(w, r, antes.Version, antes) {
+		return
+	}
+	egresado.Matricula = matricula
+	egresado.Version = antes.Version
+
+	h.guardarEgresado(w, r, matricula, antes, &egresado)
+}
+
+// camposEditablesEgresado son los campos que acepta PATCH; el resto
+// (matrícula, fechas, versión y nombres de catálogos) es de solo lectura
+var camposEditablesEgresado = map[string]bool{
+	"nombre_completo": true,
+	"genero":          true,
+	"telefono":        true,
+	"correo":          true,
+	"codigo_postal":   true,
+	"estado":          true,
+	"municipio":       true,
+	"asentamiento":    true,
+	"calle":           true,
+	"numero":          true,
+	"id_carrera":      true,
+	"id_generacion":   true,
+	"id_estatus":      true,
+}
+
+// camposObligatoriosEgresado no pueden borrarse con null en un PATCH
+var camposObligatoriosEgresado = []string{"nombre_completo", "id_carrera", "id_generacion", "id_estatus"}
+
+// PatchEgresado actualiza solo los campos presentes en un JSON Merge Patch
+// (RFC 7396); un campo con null se borra. If-Match es opcional: si se envía
+// se valida igual que en PUT.
+func (h *Handler) PatchEgresado(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	matricula := vars["matricula"]
+
+	tipo, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if tipo != mergepatch.ContentType && tipo != "application/json" {
+		utils.ErrorResponse(w, http.StatusUnsupportedMediaType, "Se esperaba "+mergepatch.ContentType)
+		return
+	}
+
+	parche, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	campos, err := mergepatch.Campos(parche)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	antes := h.obtenerEgresado(w, r, matricula)
+	if antes == nil {
+		return
+	}
+
+	errores := validacion.Errores{}
+	for campo, valor := range campos {
+		// Repetir la matrícula actual es válido; cambiarla no
+		if campo == "matricula" {
+			var m string
+			if json.Unmarshal(valor, &m) == nil && m == matricula {
+				delete(campos, campo)
+				continue
+			}
+		}
+		if !camposEditablesEgresado[campo] {
+			errores.Agregar(campo, "Este campo no se puede modificar")
+		}
+	}
+	for _, campo := range camposObligatoriosEgresado {
+		if valor, ok := campos[campo]; ok && string(valor) == "null" {
+			errores.Agregar(campo, "Este campo es obligatorio")
+		}
+	}
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	if presente, coincide := coincideIfMatch(r, antes.Version); presente && !coincide {
+		responderConflicto(w, http.StatusPreconditionFailed, antes.Version, antes)
+		return
+	}
+
+	if len(campos) == 0 {
+		w.Header().Set("ETag", etag(antes.Version))
+		utils.SuccessResponse(w, "Sin cambios", antes)
+		return
+	}
 
+	original, err := json.Marshal(antes)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al actualizar egresado")
 		return
 	}
+	parche, _ = json.Marshal(campos)
+	combinado, err := mergepatch.Aplicar(original, parche)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos: "+err.Error())
+		return
+	}
+
+	var egresado models.Egresado
+	if err := json.Unmarshal(combinado, &egresado); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	egresado.Version = antes.Version
+
+	h.guardarEgresado(w, r, matricula, antes, &egresado)
+}
+
+// guardarEgresado valida y aplica la actualización de PUT o PATCH y
+// responde con el registro guardado y su nuevo ETag
+func (h *Handler) guardarEgresado(w http.ResponseWriter, r *http.Request, matricula string, antes, egresado *models.Egresado) {
+	if !h.validarEgresado(w, r, egresado, antes) {
+		return
+	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		utils.ErrorResponse(w, http.StatusNotFound, "Egresado no encontrado")
+	if err := h.egresados.Update(r.Context(), matricula, egresado); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			utils.ErrorResponse(w, http.StatusNotFound, "Egresado no encontrado")
+		case errors.Is(err, repository.ErrConflictoVersion):
+			// Otro usuario guardó entre la lectura y la actualización
+			if actual, err := h.egresados.Get(r.Context(), matricula); err == nil {
+				responderConflicto(w, http.StatusConflict, actual.Version, actual)
+				return
+			}
+			utils.ErrorResponse(w, http.StatusConflict, "El registro fue modificado por otro usuario")
+		case errors.Is(err, repository.ErrReferenciaInvalida):
+			utils.ErrorResponse(w, http.StatusBadRequest, "Carrera, generación o estatus inválido")
+		default:
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al actualizar egresado")
+		}
 		return
 	}
 
-	utils.SuccessResponse(w, "Egresado actualizado correctamente", egresado)
+	guardado := h.egresadoGuardado(r, matricula, egresado)
+	h.auditar(r, auditoria.EntidadEgresado, matricula, auditoria.AccionActualizar, antes, guardado)
+
+	w.Header().Set("ETag", etag(guardado.Version))
+	utils.SuccessResponse(w, "Egresado actualizado correctamente", guardado)
 }
 
 // DeleteEgresado elimina un egresado
-func DeleteEgresado(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) DeleteEgresado(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	matricula := vars["matricula"]
 
-	query := "DELETE FROM egresados WHERE matricula = ?"
-	result, err := config.DB.Exec(query, matricula)
+	antes := h.obtenerEgresado(w, r, matricula)
+	if antes == nil {
+		return
+	}
 
-	if err != nil {
+	if err := h.egresados.Delete(r.Context(), matricula, idUsuarioSesion(r)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Egresado no encontrado")
+			return
+		}
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al eliminar egresado")
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		utils.ErrorResponse(w, http.StatusNotFound, "Egresado no encontrado")
-		return
+	h.auditar(r, auditoria.EntidadEgresado, matricula, auditoria.AccionEliminar, antes, nil)
+
+	utils.SuccessResponse(w, "Egresado enviado a la papelera", nil)
+}
+
+// validarEgresado responde 422 con los errores por campo; devuelve false
+// cuando ya se respondió. antes es el registro que se edita (nil al crear).
+func (h *Handler) validarEgresado(w http.ResponseWriter, r *http.Request, e, antes *models.Egresado) bool {
+	var errores validacion.Errores
+	var err error
+	if antes == nil {
+		errores, err = h.validador.Egresado(r.Context(), e)
+	} else {
+		errores, err = h.validador.EgresadoEditado(r.Context(), e, antes)
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al validar egresado")
+		return false
+	}
+	validarAlcanceEgresado(r, e, errores)
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return false
 	}
+	return true
+}
 
-	utils.SuccessResponse(w, "Egresado eliminado correctamente", nil)
+// egresadoGuardado relee el egresado para auditar el estado final con sus
+// relaciones; si la lectura falla se usa lo que envió el cliente
+func (h *Handler) egresadoGuardado(r *http.Request, matricula string, respaldo *models.Egresado) *models.Egresado {
+	e, err := h.egresados.Get(r.Context(), matricula)
+	if err != nil {
+		return respaldo
+	}
+	return e
 }
 
 // GetGeneracionesStats obtiene las generaciones con el conteo de egresados
-func GetGeneracionesStats(w http.ResponseWriter, r *http.Request) {
-	query := `
-		SELECT 
-			g.id_generacion,
-			g.periodo,
-			COUNT(e.matricula) as total_egresados
-		FROM generaciones g
-		LEFT JOIN egresados e ON g.id_generacion = e.id_generacion
-		GROUP BY g.id_generacion, g.periodo
-		ORDER BY g.periodo DESC
-	`
-
-	rows, err := config.DB.Query(query)
+func (h *Handler) GetGeneracionesStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.egresados.StatsPorGeneracion(r.Context(), plantelDeFiltro(r))
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener estadísticas de generaciones")
 		return
 	}
-	defer rows.Close()
-
-	type GeneracionStats struct {
-		IDGeneracion   int    `json:"id_generacion"`
-		Periodo        string `json:"periodo"`
-		TotalEgresados int    `json:"total_egresados"`
-	}
 
-	var stats []GeneracionStats
 	totalGeneral := 0
-
-	for rows.Next() {
-		var s GeneracionStats
-		if err := rows.Scan(&s.IDGeneracion, &s.Periodo, &s.TotalEgresados); err != nil {
-			continue
-		}
-		stats = append(stats, s)
+	for _, s := range stats {
 		totalGeneral += s.TotalEgresados
 	}
 
@@ -291,68 +315,44 @@ func GetGeneracionesStats(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetCarrerasStatsByGeneracion obtiene las carreras con el conteo de egresados por generación
-func GetCarrerasStatsByGeneracion(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetCarrerasStatsByGeneracion(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	generacionID := vars["id_generacion"]
 
-	var query string
-	var rows *sql.Rows
-	var err error
-
-	if generacionID == "all" || generacionID == "" {
-		// Obtener todas las carreras sin filtrar por generación
-		query = `
-			SELECT 
-				c.id_carrera,
-				c.nombre,
-				COUNT(e.matricula) as total_egresados
-			FROM carreras c
-			LEFT JOIN egresados e ON c.id_carrera = e.id_carrera
-			GROUP BY c.id_carrera, c.nombre
-			ORDER BY c.nombre
-		`
-		rows, err = config.DB.Query(query)
-	} else {
-		// Filtrar por generación específica
-		query = `
-			SELECT 
-				c.id_carrera,
-				c.nombre,
-				COUNT(e.matricula) as total_egresados
-			FROM carreras c
-			LEFT JOIN egresados e ON c.id_carrera = e.id_carrera AND e.id_generacion = ?
-			GROUP BY c.id_carrera, c.nombre
-			ORDER BY c.nombre
-		`
-		rows, err = config.DB.Query(query, generacionID)
-	}
-
+	stats, err := h.egresados.StatsPorCarrera(r.Context(), generacionID, plantelDeFiltro(r))
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener estadísticas de carreras")
 		return
 	}
-	defer rows.Close()
 
-	type CarreraStats struct {
-		IDCarrera      int    `json:"id_carrera"`
-		Nombre         string `json:"nombre"`
-		TotalEgresados int    `json:"total_egresados"`
+	totalGeneral := 0
+	for _, s := range stats {
+		totalGeneral += s.TotalEgresados
 	}
 
-	var stats []CarreraStats
-	totalGeneral := 0
+	response := map[string]interface{}{
+		"carreras":      stats,
+		"total_general": totalGeneral,
+	}
 
-	for rows.Next() {
-		var s CarreraStats
-		if err := rows.Scan(&s.IDCarrera, &s.Nombre, &s.TotalEgresados); err != nil {
-			continue
-		}
-		stats = append(stats, s)
+	utils.SuccessResponse(w, "Estadísticas obtenidas correctamente", response)
+}
+
+// GetEstatusStats obtiene los estatus con el conteo de egresados
+func (h *Handler) GetEstatusStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.egresados.StatsPorEstatus(r.Context(), plantelDeFiltro(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener estadísticas de estatus")
+		return
+	}
+
+	totalGeneral := 0
+	for _, s := range stats {
 		totalGeneral += s.TotalEgresados
 	}
 
 	response := map[string]interface{}{
-		"carreras":      stats,
+		"estatus":       stats,
 		"total_general": totalGeneral,
 	}
 
@@ -360,88 +360,29 @@ func GetCarrerasStatsByGeneracion(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetEgresadosFiltrados obtiene egresados filtrados por generación y/o carrera
-func GetEgresadosFiltrados(w http.ResponseWriter, r *http.Request) {
-	generacionID := r.URL.Query().Get("generacion")
-	carreraID := r.URL.Query().Get("carrera")
-
-	query := `
-		SELECT 
-			e.matricula,
-			e.nombre_completo,
-			e.genero,
-			e.telefono,
-			e.correo,
-			e.codigo_postal,
-			e.estado,
-			e.municipio,
-			e.asentamiento,
-			e.calle,
-			e.numero,
-			e.id_carrera,
-			e.id_generacion,
-			e.id_estatus,
-			e.created_at,
-			c.nombre AS nombre_carrera,
-			g.periodo AS periodo_generacion,
-			es.descripcion AS descripcion_estatus
-		FROM egresados e
-		LEFT JOIN carreras c ON e.id_carrera = c.id_carrera
-		LEFT JOIN generaciones g ON e.id_generacion = g.id_generacion
-		LEFT JOIN estatus es ON e.id_estatus = es.id_estatus
-		WHERE 1=1
-	`
-
-	var args []interface{}
-
-	// Agregar filtro de generación si no es "all"
-	if generacionID != "" && generacionID != "all" {
-		query += " AND e.id_generacion = ?"
-		args = append(args, generacionID)
-	}
-
-	// Agregar filtro de carrera si no es "all"
-	if carreraID != "" && carreraID != "all" {
-		query += " AND e.id_carrera = ?"
-		args = append(args, carreraID)
-	}
-
-	query += " ORDER BY e.created_at DESC"
-
-	rows, err := config.DB.Query(query, args...)
+func (h *Handler) GetEgresadosFiltrados(w http.ResponseWriter, r *http.Request) {
+	h.listarEgresados(w, r)
+}
+
+// listarEgresados aplica búsqueda, filtros, orden y paginación al listado de egresados
+func (h *Handler) listarEgresados(w http.ResponseWriter, r *http.Request) {
+	filtro, err := parseFiltroEgresados(r)
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener egresados")
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	defer rows.Close()
-
-	var egresados []models.Egresado
-	for rows.Next() {
-		var e models.Egresado
-		err := rows.Scan(
-			&e.Matricula,
-			&e.NombreCompleto,
-			&e.Genero,
-			&e.Telefono,
-			&e.Correo,
-			&e.CodigoPostal,
-			&e.Estado,
-			&e.Municipio,
-			&e.Asentamiento,
-			&e.Calle,
-			&e.Numero,
-			&e.IDCarrera,
-			&e.IDGeneracion,
-			&e.IDEstatus,
-			&e.CreatedAt,
-			&e.NombreCarrera,
-			&e.PeriodoGeneracion,
-			&e.DescripcionEstatus,
-		)
-		if err != nil {
-			continue
-		}
-		egresados = append(egresados, e)
+	filtro.Page, filtro.PerPage, err = parsePaginacion(r.URL.Query())
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	egresados, total, err := h.egresados.List(r.Context(), filtro)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener egresados")
+		return
 	}
 
-	utils.SuccessResponse(w, "Egresados obtenidos correctamente", egresados)
+	utils.PaginatedResponse(w, "Egresados obtenidos correctamente", egresados,
+		utils.NewPagination(total, filtro.Page, filtro.PerPage))
 }
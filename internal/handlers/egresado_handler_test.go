@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/middleware"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// clientePrueba es un navegador con la sesión iniciada y su token CSRF
+type clientePrueba struct {
+	router  http.Handler
+	cookies []*http.Cookie
+	token   string
+}
+
+// routerEgresados arma las rutas de egresados y de su papelera igual que
+// cmd/server
+func routerEgresados(h *Handler) http.Handler {
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(middleware.AuthRequired)
+	api.Use(middleware.ExigirDosFactores)
+	api.Use(middleware.CSRF)
+
+	api.Handle("/egresados", middleware.WithPermission(auth.PermEgresadosRead, h.GetEgresados)).Methods("GET")
+	api.Handle("/egresados", middleware.WithPermission(auth.PermEgresadosWrite, h.CreateEgresado)).Methods("POST")
+	api.Handle("/egresados/import", middleware.WithPermission(auth.PermEgresadosWrite, h.ImportarEgresados)).Methods("POST")
+	api.Handle("/egresados/export", middleware.WithPermission(auth.PermEgresadosRead, h.ExportarEgresados)).Methods("GET")
+	api.Handle("/egresados/siguiente-matricula", middleware.WithPermission(auth.PermEgresadosWrite, h.SiguienteMatricula)).Methods("GET")
+	api.Handle("/egresados/stats/estatus", middleware.WithPermission(auth.PermEgresadosRead, h.GetEstatusStats)).Methods("GET")
+	api.Handle("/egresados/{matricula}", middleware.WithPermission(auth.PermEgresadosRead, h.GetEgresado)).Methods("GET")
+	api.Handle("/egresados/{matricula}", middleware.WithPermission(auth.PermEgresadosWrite, h.UpdateEgresado)).Methods("PUT")
+	api.Handle("/egresados/{matricula}", middleware.WithPermission(auth.PermEgresadosWrite, h.PatchEgresado)).Methods("PATCH")
+	api.Handle("/egresados/{matricula}", middleware.WithPermission(auth.PermEgresadosDelete, h.DeleteEgresado)).Methods("DELETE")
+	api.Handle("/papelera/egresados/{matricula}/restaurar", middleware.WithPermission(auth.PermEgresadosDelete, h.RestaurarEgresado)).Methods("POST")
+	api.Handle("/papelera/egresados/{matricula}", middleware.WithPermission(auth.PermPapeleraPurge, h.PurgarEgresado)).Methods("DELETE")
+	return r
+}
+
+// catalogosPrueba registra dos planteles (13 y 07) y una carrera,
+// generación y estatus con ID 1
+func catalogosPrueba(repos *repository.Repositories) {
+	catalogos := repos.Catalogos.(*repository.CatalogoMemory)
+	catalogos.AddPlantel(models.Plantel{IDPlantel: 1, Clave: "13", Nombre: "Culiacán"})
+	catalogos.AddPlantel(models.Plantel{IDPlantel: 2, Clave: "07", Nombre: "Mazatlán"})
+	catalogos.AddCarrera(models.Carrera{IDCarrera: 1, Nombre: "Ingeniería de Software"})
+	catalogos.AddGeneracion(models.Generacion{IDGeneracion: 1, Periodo: "2022-2026"})
+	catalogos.AddEstatus(models.Estatus{IDEstatus: 1, Descripcion: "Egresado"})
+}
+
+// iniciarSesionComo da de alta un usuario con el rol y plantel indicados,
+// inicia sesión y toma el token CSRF de una página autenticada
+func iniciarSesionComo(t *testing.T, h *Handler, repos *repository.Repositories, usuario, rol string, idPlantel int) *clientePrueba {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("contraseña123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &models.Usuario{Usuario: usuario, Nombre: usuario, Rol: rol, Password: string(hash)}
+	if idPlantel != 0 {
+		u.IDPlantel = &idPlantel
+	}
+	if _, err := repos.Usuarios.Create(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+
+	pagina, token := paginaLogin(t, h, nil)
+	login := enviarConCSRF(h.Login, "/login", `{"usuario":"`+usuario+`","password":"contraseña123"}`, token, pagina)
+	if login.Code != http.StatusOK {
+		t.Fatalf("Login de %s = %d: %s", usuario, login.Code, login.Body.String())
+	}
+
+	seguridad := httptest.NewRecorder()
+	h.SeguridadPage(seguridad, conCookies(httptest.NewRequest(http.MethodGet, "/seguridad", nil), login))
+	coincidencia := metaCSRF.FindStringSubmatch(seguridad.Body.String())
+	if coincidencia == nil {
+		t.Fatalf("la página autenticada no publicó el token CSRF: %d", seguridad.Code)
+	}
+	return &clientePrueba{router: routerEgresados(h), cookies: login.Result().Cookies(), token: coincidencia[1]}
+}
+
+// pedir envía la petición con la cookie de sesión, el token CSRF y los
+// encabezados indicados ("Nombre: valor")
+func (c *clientePrueba) pedir(metodo, ruta, cuerpo string, encabezados ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(metodo, ruta, strings.NewReader(cuerpo))
+	r.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		r.Header.Set(middleware.CSRFHeader, c.token)
+	}
+	for _, e := range encabezados {
+		nombre, valor, _ := strings.Cut(e, ": ")
+		r.Header.Set(nombre, valor)
+	}
+	for _, cookie := range c.cookies {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	c.router.ServeHTTP(w, r)
+	return w
+}
+
+// egresadoDe decodifica el egresado de una respuesta exitosa
+func egresadoDe(t *testing.T, w *httptest.ResponseRecorder) models.Egresado {
+	t.Helper()
+	var respuesta struct {
+		Data models.Egresado `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &respuesta); err != nil {
+		t.Fatalf("respuesta inválida: %v: %s", err, w.Body.String())
+	}
+	return respuesta.Data
+}
+
+const egresadoPrueba = `{"matricula":"13220030","nombre_completo":"Ana López","id_carrera":1,"id_generacion":1,"id_estatus":1}`
+
+func TestEgresadoAltaEdicionYBaja(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	h.rolesDosFactores = nil
+	catalogosPrueba(repos)
+	operador := iniciarSesionComo(t, h, repos, "operador", auth.RolOperador, 1)
+
+	alta := operador.pedir(http.MethodPost, "/api/egresados", egresadoPrueba)
+	if alta.Code != http.StatusOK || alta.Header().Get("ETag") != `"1"` {
+		t.Fatalf("alta = %d, ETag %q: %s", alta.Code, alta.Header().Get("ETag"), alta.Body.String())
+	}
+	if e := egresadoDe(t, alta); e.IDPlantel != 1 || e.NombreCompleto != "Ana López" {
+		t.Errorf("egresado creado = %+v", e)
+	}
+	if repetida := operador.pedir(http.MethodPost, "/api/egresados", egresadoPrueba); repetida.Code != http.StatusUnprocessableEntity {
+		t.Errorf("alta repetida = %d, se esperaba 422", repetida.Code)
+	}
+
+	consulta := operador.pedir(http.MethodGet, "/api/egresados/13220030", "")
+	if consulta.Code != http.StatusOK || consulta.Header().Get("ETag") != `"1"` {
+		t.Fatalf("consulta = %d, ETag %q", consulta.Code, consulta.Header().Get("ETag"))
+	}
+
+	cambio := strings.Replace(egresadoPrueba, "Ana López", "Ana López Ruiz", 1)
+	if w := operador.pedir(http.MethodPut, "/api/egresados/13220030", cambio); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("PUT sin If-Match = %d, se esperaba 428", w.Code)
+	}
+	edicion := operador.pedir(http.MethodPut, "/api/egresados/13220030", cambio, `If-Match: "1"`)
+	if edicion.Code != http.StatusOK || edicion.Header().Get("ETag") != `"2"` {
+		t.Fatalf("PUT = %d, ETag %q: %s", edicion.Code, edicion.Header().Get("ETag"), edicion.Body.String())
+	}
+
+	// Quien editó la versión 1 no sobrescribe la 2 y recibe la vigente
+	vieja := operador.pedir(http.MethodPut, "/api/egresados/13220030", egresadoPrueba, `If-Match: "1"`)
+	if vieja.Code != http.StatusPreconditionFailed || vieja.Header().Get("ETag") != `"2"` {
+		t.Fatalf("PUT con versión vieja = %d, ETag %q", vieja.Code, vieja.Header().Get("ETag"))
+	}
+	if e := egresadoDe(t, vieja); e.NombreCompleto != "Ana López Ruiz" {
+		t.Errorf("el 412 debe traer el registro vigente: %+v", e)
+	}
+
+	parche := `{"telefono":"6671234567"}`
+	if w := operador.pedir(http.MethodPatch, "/api/egresados/13220030", parche, `If-Match: "1"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PATCH con versión vieja = %d, se esperaba 412", w.Code)
+	}
+	if w := operador.pedir(http.MethodPatch, "/api/egresados/13220030", parche, `If-Match: W/"2"`); w.Code != http.StatusOK || w.Header().Get("ETag") != `"3"` {
+		t.Fatalf("PATCH = %d, ETag %q: %s", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+
+	// El operador no puede eliminar; el administrador sí
+	if w := operador.pedir(http.MethodDelete, "/api/egresados/13220030", ""); w.Code != http.StatusForbidden {
+		t.Errorf("DELETE del operador = %d, se esperaba 403", w.Code)
+	}
+	admin := iniciarSesionComo(t, h, repos, "admin", auth.RolAdministrador, 0)
+	if w := admin.pedir(http.MethodDelete, "/api/egresados/13220030", ""); w.Code != http.StatusOK {
+		t.Fatalf("DELETE = %d: %s", w.Code, w.Body.String())
+	}
+	if w := admin.pedir(http.MethodGet, "/api/egresados/13220030", ""); w.Code != http.StatusNotFound {
+		t.Errorf("consulta tras eliminar = %d, se esperaba 404", w.Code)
+	}
+}
+
+func TestEgresadoPermisosDenegados(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	catalogosPrueba(repos)
+	consulta := iniciarSesionComo(t, h, repos, "consulta", auth.RolConsulta, 1)
+
+	if w := consulta.pedir(http.MethodPost, "/api/egresados", egresadoPrueba); w.Code != http.StatusForbidden {
+		t.Errorf("alta con rol Consulta = %d, se esperaba 403", w.Code)
+	}
+	if _, err := repos.Egresados.Get(context.Background(), "13220030"); err != repository.ErrNotFound {
+		t.Errorf("Get = %v, no se debía crear el egresado", err)
+	}
+
+	anonimo := &clientePrueba{router: routerEgresados(h)}
+	if w := anonimo.pedir(http.MethodGet, "/api/egresados/13220030", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("consulta sin sesión = %d, se esperaba 401", w.Code)
+	}
+
+	operador := iniciarSesionComo(t, h, repos, "operador", auth.RolOperador, 1)
+	operador.token = ""
+	if w := operador.pedir(http.MethodPost, "/api/egresados", egresadoPrueba); w.Code != http.StatusForbidden {
+		t.Errorf("alta sin token CSRF = %d, se esperaba 403", w.Code)
+	}
+}
+
+func TestEgresadoFueraDelPlantel(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	catalogosPrueba(repos)
+	deCuliacan := iniciarSesionComo(t, h, repos, "culiacan", auth.RolOperador, 1)
+	deMazatlan := iniciarSesionComo(t, h, repos, "mazatlan", auth.RolOperador, 2)
+
+	if w := deCuliacan.pedir(http.MethodPost, "/api/egresados", egresadoPrueba); w.Code != http.StatusOK {
+		t.Fatalf("alta = %d: %s", w.Code, w.Body.String())
+	}
+
+	// Para otro plantel el egresado no existe, ni para leerlo ni para editarlo
+	if w := deMazatlan.pedir(http.MethodGet, "/api/egresados/13220030", ""); w.Code != http.StatusNotFound {
+		t.Errorf("consulta de otro plantel = %d, se esperaba 404", w.Code)
+	}
+	if w := deMazatlan.pedir(http.MethodPatch, "/api/egresados/13220030", `{"nombre_completo":"Otro"}`); w.Code != http.StatusNotFound {
+		t.Errorf("PATCH de otro plantel = %d, se esperaba 404", w.Code)
+	}
+	if w := deMazatlan.pedir(http.MethodPost, "/api/egresados", strings.Replace(egresadoPrueba, "13220030", "13220031", 1)); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("alta en otro plantel = %d, se esperaba 422", w.Code)
+	}
+}
+
+func TestEgresadoConMatriculaAnteriorSeEdita(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	catalogosPrueba(repos)
+	repos.Catalogos.(*repository.CatalogoMemory).AddGeneracion(models.Generacion{IDGeneracion: 2, Periodo: "2019-2023"})
+	operador := iniciarSesionComo(t, h, repos, "operador", auth.RolOperador, 1)
+
+	// Registros anteriores a la validación: sin el formato de 8 dígitos y con
+	// un año que no corresponde a la generación
+	for _, m := range []string{"E-2015-044", "13190001"} {
+		e := &models.Egresado{Matricula: m, IDPlantel: 1, NombreCompleto: "Egresado " + m, IDCarrera: 1, IDGeneracion: 1, IDEstatus: 1}
+		if err := repos.Egresados.Create(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	put := `{"matricula":"E-2015-044","nombre_completo":"Luis Pérez","id_carrera":1,"id_generacion":1,"id_estatus":1}`
+	w := operador.pedir(http.MethodPut, "/api/egresados/E-2015-044", put, `If-Match: "1"`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT de matrícula anterior = %d: %s", w.Code, w.Body.String())
+	}
+	if e := egresadoDe(t, w); e.IDPlantel != 1 || e.NombreCompleto != "Luis Pérez" {
+		t.Errorf("egresado editado = %+v", e)
+	}
+	if w := operador.pedir(http.MethodPatch, "/api/egresados/13190001", `{"telefono":"6671234567"}`); w.Code != http.StatusOK {
+		t.Errorf("PATCH de matrícula de otra generación = %d: %s", w.Code, w.Body.String())
+	}
+
+	// Cambiar la generación sí vuelve a exigir formato y correspondencia
+	if w := operador.pedir(http.MethodPatch, "/api/egresados/E-2015-044", `{"id_generacion":2}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("PATCH de generación con matrícula anterior = %d, se esperaba 422", w.Code)
+	}
+	if w := operador.pedir(http.MethodPatch, "/api/egresados/13190001", `{"id_generacion":2}`); w.Code != http.StatusOK {
+		t.Errorf("PATCH a la generación que corresponde = %d: %s", w.Code, w.Body.String())
+	}
+	if w := operador.pedir(http.MethodPost, "/api/egresados", strings.Replace(egresadoPrueba, "13220030", "E-2020-001", 1)); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("alta con matrícula sin formato = %d, se esperaba 422", w.Code)
+	}
+}
+
+func TestPatchEgresado(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	catalogosPrueba(repos)
+	operador := iniciarSesionComo(t, h, repos, "operador", auth.RolOperador, 1)
+
+	alta := strings.Replace(egresadoPrueba, `"id_estatus":1`, `"id_estatus":1,"telefono":"6671234567","correo":"ana@example.com"`, 1)
+	if w := operador.pedir(http.MethodPost, "/api/egresados", alta); w.Code != http.StatusOK {
+		t.Fatalf("alta = %d: %s", w.Code, w.Body.String())
+	}
+
+	// null en un campo obligatorio y campos fuera de la lista se rechazan
+	// con el campo señalado, sin guardar nada
+	rechazos := []struct {
+		parche, campo string
+	}{
+		{`{"nombre_completo":null}`, "nombre_completo"},
+		{`{"id_carrera":null}`, "id_carrera"},
+		{`{"id_plantel":2}`, "id_plantel"},
+		{`{"version":7}`, "version"},
+		{`{"matricula":"13220031"}`, "matricula"},
+		{`{"telefono":"6670000000","created_at":"2020-01-01T00:00:00Z"}`, "created_at"},
+	}
+	for _, c := range rechazos {
+		w := operador.pedir(http.MethodPatch, "/api/egresados/13220030", c.parche)
+		var respuesta struct {
+			Errores map[string]string `json:"errores"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &respuesta)
+		if w.Code != http.StatusUnprocessableEntity || respuesta.Errores[c.campo] == "" {
+			t.Errorf("PATCH %s = %d: %s, se esperaba 422 en %s", c.parche, w.Code, w.Body.String(), c.campo)
+		}
+	}
+	if e, _ := repos.Egresados.Get(context.Background(), "13220030"); e.Version != 1 || *e.Telefono != "6671234567" {
+		t.Fatalf("un PATCH rechazado no debe guardar: %+v", e)
+	}
+
+	// Los campos ausentes conservan su valor y null borra un campo opcional
+	w := operador.pedir(http.MethodPatch, "/api/egresados/13220030", `{"telefono":"6670000000","matricula":"13220030"}`, "Content-Type: application/merge-patch+json")
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d: %s", w.Code, w.Body.String())
+	}
+	if e := egresadoDe(t, w); *e.Telefono != "6670000000" || e.Correo == nil || *e.Correo != "ana@example.com" || e.NombreCompleto != "Ana López" || e.IDCarrera != 1 {
+		t.Errorf("egresado tras PATCH = %+v", e)
+	}
+	w = operador.pedir(http.MethodPatch, "/api/egresados/13220030", `{"correo":null}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH con null = %d: %s", w.Code, w.Body.String())
+	}
+	if e := egresadoDe(t, w); e.Correo != nil || e.Telefono == nil || *e.Telefono != "6670000000" {
+		t.Errorf("egresado tras borrar el correo = %+v", e)
+	}
+}
+
+func TestSiguienteMatricula(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		existentes []string
+		codigo     int
+		quiere     string
+	}{
+		{"primera de la generación", nil, http.StatusOK, "13220001"},
+		{"después de la mayor", []string{"13220007", "13220030", "13210099"}, http.StatusOK, "13220031"},
+		{"solo existe la 0000 heredada", []string{"13220000"}, http.StatusOK, "13220001"},
+		{"ignora matrículas sin formato", []string{"1322-0050", "13220002"}, http.StatusOK, "13220003"},
+		{"consecutivos agotados", []string{"13229999"}, http.StatusConflict, ""},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			h, repos := handlerPrueba(t)
+			h.plantel = "13"
+			catalogosPrueba(repos)
+			for _, m := range c.existentes {
+				e := &models.Egresado{Matricula: m, IDPlantel: 1, NombreCompleto: m, IDCarrera: 1, IDGeneracion: 1, IDEstatus: 1}
+				if err := repos.Egresados.Create(context.Background(), e); err != nil {
+					t.Fatal(err)
+				}
+			}
+			operador := iniciarSesionComo(t, h, repos, "operador", auth.RolOperador, 1)
+
+			w := operador.pedir(http.MethodGet, "/api/egresados/siguiente-matricula?generacion=1", "")
+			if w.Code != c.codigo {
+				t.Fatalf("código = %d, quiere %d: %s", w.Code, c.codigo, w.Body.String())
+			}
+			if c.quiere == "" {
+				return
+			}
+			var respuesta struct {
+				Data struct {
+					Matricula string `json:"matricula"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &respuesta); err != nil {
+				t.Fatal(err)
+			}
+			if respuesta.Data.Matricula != c.quiere {
+				t.Errorf("matrícula = %q, quiere %q", respuesta.Data.Matricula, c.quiere)
+			}
+		})
+	}
+}
+
+// egresadosMasivos registra n egresados del plantel 13 directamente en el repositorio
+func egresadosMasivos(t *testing.T, repos *repository.Repositories, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		e := &models.Egresado{
+			Matricula: fmt.Sprintf("1322%04d", i), IDPlantel: 1, NombreCompleto: fmt.Sprintf("Egresado %d", i),
+			IDCarrera: 1, IDGeneracion: 1, IDEstatus: 1,
+		}
+		if err := repos.Egresados.Create(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestListadoEgresadosSiemprePaginado(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	catalogosPrueba(repos)
+	egresadosMasivos(t, repos, 260)
+	consulta := iniciarSesionComo(t, h, repos, "consulta", auth.RolCoordinacion, 0)
+
+	for _, caso := range []struct {
+		consulta      string
+		filas, pagina int
+	}{
+		{"", 50, 50},
+		{"?page=2", 50, 50},
+		{"?per_page=1000", 200, 200},
+		{"?page=2&per_page=200", 60, 200},
+	} {
+		w := consulta.pedir(http.MethodGet, "/api/egresados"+caso.consulta, "")
+		var respuesta struct {
+			Data []models.Egresado `json:"data"`
+			Meta struct {
+				Total   int `json:"total"`
+				PerPage int `json:"per_page"`
+			} `json:"meta"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &respuesta); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("listado%s = %d: %v", caso.consulta, w.Code, err)
+		}
+		if len(respuesta.Data) != caso.filas || respuesta.Meta.PerPage != caso.pagina || respuesta.Meta.Total != 260 {
+			t.Errorf("listado%s: %d filas, per_page %d, total %d", caso.consulta, len(respuesta.Data), respuesta.Meta.PerPage, respuesta.Meta.Total)
+		}
+	}
+
+	// La exportación no se pagina, aunque traiga los parámetros del listado
+	w := consulta.pedir(http.MethodGet, "/api/egresados/export?format=csv&page=2&per_page=10", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("exportación = %d: %s", w.Code, w.Body.String())
+	}
+	filas, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(w.Body.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filas) != 261 {
+		t.Errorf("exportación con %d filas, se esperaban 260 más el encabezado", len(filas))
+	}
+
+	// Los conteos del dashboard tampoco dependen de la paginación
+	stats := consulta.pedir(http.MethodGet, "/api/egresados/stats/estatus", "")
+	if !strings.Contains(stats.Body.String(), `"total_general":260`) {
+		t.Errorf("stats/estatus = %d: %s", stats.Code, stats.Body.String())
+	}
+}
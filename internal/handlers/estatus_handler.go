@@ -1,30 +1,113 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
-	"ues-egresados/internal/config"
+	"strconv"
+	"ues-egresados/internal/auditoria"
 	"ues-egresados/internal/models"
 	"ues-egresados/internal/utils"
+	"ues-egresados/internal/validacion"
 )
 
+var mensajesEstatus = mensajesCatalogo{
+	campo:        "descripcion",
+	duplicado:    "Ya existe un estatus con esa descripción",
+	noEncontrado: "Estatus no encontrado",
+	enUso:        "El estatus tiene %d egresados asignados (incluida la papelera); reasígnalos a otro estatus antes de eliminarlo",
+}
+
 // GetEstatus obtiene todos los estatus
-func GetEstatus(w http.ResponseWriter, r *http.Request) {
-	rows, err := config.DB.Query("SELECT id_estatus, descripcion FROM estatus ORDER BY descripcion")
+func (h *Handler) GetEstatus(w http.ResponseWriter, r *http.Request) {
+	estatusList, err := h.catalogos.ListEstatus(r.Context())
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener estatus")
 		return
 	}
-	defer rows.Close()
 
-	var estatusList []models.Estatus
-	for rows.Next() {
-		var e models.Estatus
-		if err := rows.Scan(&e.IDEstatus, &e.Descripcion); err != nil {
-			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al procesar datos")
-			return
-		}
-		estatusList = append(estatusList, e)
+	utils.SuccessResponse(w, "Estatus obtenidos correctamente", estatusList)
+}
+
+// CreateEstatus agrega un estatus al catálogo
+func (h *Handler) CreateEstatus(w http.ResponseWriter, r *http.Request) {
+	var e models.Estatus
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	if errores := validacion.Estatus(&e); len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
 	}
 
-	utils.SuccessResponse(w, "Estatus obtenidos correctamente", estatusList)
-}
\ No newline at end of file
+	if err := h.catalogos.CreateEstatus(r.Context(), &e); err != nil {
+		responderErrorCatalogo(w, err, mensajesEstatus, "Error al crear estatus")
+		return
+	}
+
+	h.auditar(r, auditoria.EntidadEstatus, strconv.Itoa(e.IDEstatus), auditoria.AccionCrear, nil, e)
+
+	utils.CreatedResponse(w, "Estatus creado correctamente", e)
+}
+
+// UpdateEstatus modifica un estatus del catálogo
+func (h *Handler) UpdateEstatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCatalogo(w, r)
+	if !ok {
+		return
+	}
+
+	var e models.Estatus
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	e.IDEstatus = id
+	if errores := validacion.Estatus(&e); len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	antes, err := h.catalogos.GetEstatus(r.Context(), id)
+	if err != nil {
+		responderErrorCatalogo(w, err, mensajesEstatus, "Error al obtener estatus")
+		return
+	}
+
+	if err := h.catalogos.UpdateEstatus(r.Context(), &e); err != nil {
+		responderErrorCatalogo(w, err, mensajesEstatus, "Error al actualizar estatus")
+		return
+	}
+
+	h.auditar(r, auditoria.EntidadEstatus, strconv.Itoa(id), auditoria.AccionActualizar, antes, e)
+
+	utils.SuccessResponse(w, "Estatus actualizado correctamente", e)
+}
+
+// DeleteEstatus elimina un estatus sin egresados asignados; con
+// ?reasignar=ID primero los mueve a otro estatus
+func (h *Handler) DeleteEstatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCatalogo(w, r)
+	if !ok {
+		return
+	}
+	reasignarA, ok := reasignarCatalogo(w, r, id)
+	if !ok {
+		return
+	}
+
+	antes, err := h.catalogos.GetEstatus(r.Context(), id)
+	if err != nil {
+		responderErrorCatalogo(w, err, mensajesEstatus, "Error al obtener estatus")
+		return
+	}
+
+	if err := h.catalogos.DeleteEstatus(r.Context(), id, reasignarA); err != nil {
+		responderErrorCatalogo(w, err, mensajesEstatus, "Error al eliminar estatus")
+		return
+	}
+
+	h.auditarBajaCatalogo(r, auditoria.EntidadEstatus, id, antes, reasignarA)
+
+	utils.SuccessResponse(w, "Estatus eliminado correctamente", nil)
+}
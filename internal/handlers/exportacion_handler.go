@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/exportacion"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/utils"
+)
+
+// ExportarEgresados descarga los egresados filtrados en CSV o XLSX.
+// Acepta los mismos filtros que GetEgresadosFiltrados y escribe las filas
+// conforme se leen de la base de datos.
+func (h *Handler) ExportarEgresados(w http.ResponseWriter, r *http.Request) {
+	nombreFormato := r.URL.Query().Get("format")
+	if nombreFormato == "" {
+		nombreFormato = "csv"
+	}
+	formato, ok := exportacion.Formatos[nombreFormato]
+	if !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Formato inválido: usa csv o xlsx")
+		return
+	}
+
+	filtro, err := parseFiltroEgresados(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	nombreArchivo := fmt.Sprintf("egresados_%s.%s", time.Now().Format("20060102_150405"), formato.Extension)
+	w.Header().Set("Content-Type", formato.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, nombreArchivo))
+	w.Header().Set("Cache-Control", "no-store")
+
+	escritor, err := formato.Nuevo(w)
+	if err != nil {
+		log.Printf("Error al iniciar exportación: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al generar la exportación")
+		return
+	}
+
+	filas := 0
+	err = h.egresados.Recorrer(r.Context(), filtro, func(e *models.Egresado) error {
+		filas++
+		return escritor.Escribir(e)
+	})
+	if err == nil {
+		err = escritor.Cerrar()
+	}
+
+	session, _ := config.SessionStore.Get(r, "session-name")
+	if err != nil {
+		// Los encabezados ya se enviaron; solo queda registrar el fallo
+		log.Printf("Error en exportación %s de %v tras %d filas: %v", nombreFormato, session.Values["username"], filas, err)
+		return
+	}
+
+	filtros := auditoria.OcultarFiltros(r.URL.Query())
+	log.Printf("📤 Exportación %s por %v: %d egresados %v", nombreFormato, session.Values["username"], filas, filtros)
+	h.registrarAuditoria(r, auditoria.EntidadEgresado, "", auditoria.AccionExportar, map[string]interface{}{
+		"formato": nombreFormato,
+		"filtros": filtros,
+		"filas":   filas,
+	})
+}
@@ -1,30 +1,113 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
-	"ues-egresados/internal/config"
+	"strconv"
+	"ues-egresados/internal/auditoria"
 	"ues-egresados/internal/models"
 	"ues-egresados/internal/utils"
+	"ues-egresados/internal/validacion"
 )
 
+var mensajesGeneracion = mensajesCatalogo{
+	campo:        "periodo",
+	duplicado:    "Ya existe una generación con ese periodo",
+	noEncontrado: "Generación no encontrada",
+	enUso:        "La generación tiene %d egresados asignados (incluida la papelera); reasígnalos a otra generación antes de eliminarla",
+}
+
 // GetGeneraciones obtiene todas las generaciones
-func GetGeneraciones(w http.ResponseWriter, r *http.Request) {
-	rows, err := config.DB.Query("SELECT id_generacion, periodo FROM generaciones ORDER BY periodo DESC")
+func (h *Handler) GetGeneraciones(w http.ResponseWriter, r *http.Request) {
+	generaciones, err := h.catalogos.ListGeneraciones(r.Context())
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener generaciones")
 		return
 	}
-	defer rows.Close()
 
-	var generaciones []models.Generacion
-	for rows.Next() {
-		var g models.Generacion
-		if err := rows.Scan(&g.IDGeneracion, &g.Periodo); err != nil {
-			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al procesar datos")
-			return
-		}
-		generaciones = append(generaciones, g)
+	utils.SuccessResponse(w, "Generaciones obtenidas correctamente", generaciones)
+}
+
+// CreateGeneracion agrega una generación al catálogo
+func (h *Handler) CreateGeneracion(w http.ResponseWriter, r *http.Request) {
+	var g models.Generacion
+	if err := json.NewDecoder(r.Body).Decode(&g); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	if errores := validacion.Generacion(&g); len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
 	}
 
-	utils.SuccessResponse(w, "Generaciones obtenidas correctamente", generaciones)
-}
\ No newline at end of file
+	if err := h.catalogos.CreateGeneracion(r.Context(), &g); err != nil {
+		responderErrorCatalogo(w, err, mensajesGeneracion, "Error al crear generación")
+		return
+	}
+
+	h.auditar(r, auditoria.EntidadGeneracion, strconv.Itoa(g.IDGeneracion), auditoria.AccionCrear, nil, g)
+
+	utils.CreatedResponse(w, "Generación creada correctamente", g)
+}
+
+// UpdateGeneracion modifica una generación del catálogo
+func (h *Handler) UpdateGeneracion(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCatalogo(w, r)
+	if !ok {
+		return
+	}
+
+	var g models.Generacion
+	if err := json.NewDecoder(r.Body).Decode(&g); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	g.IDGeneracion = id
+	if errores := validacion.Generacion(&g); len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	antes, err := h.catalogos.GetGeneracion(r.Context(), id)
+	if err != nil {
+		responderErrorCatalogo(w, err, mensajesGeneracion, "Error al obtener generación")
+		return
+	}
+
+	if err := h.catalogos.UpdateGeneracion(r.Context(), &g); err != nil {
+		responderErrorCatalogo(w, err, mensajesGeneracion, "Error al actualizar generación")
+		return
+	}
+
+	h.auditar(r, auditoria.EntidadGeneracion, strconv.Itoa(id), auditoria.AccionActualizar, antes, g)
+
+	utils.SuccessResponse(w, "Generación actualizada correctamente", g)
+}
+
+// DeleteGeneracion elimina una generación sin egresados asignados; con
+// ?reasignar=ID primero los mueve a otra generación
+func (h *Handler) DeleteGeneracion(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCatalogo(w, r)
+	if !ok {
+		return
+	}
+	reasignarA, ok := reasignarCatalogo(w, r, id)
+	if !ok {
+		return
+	}
+
+	antes, err := h.catalogos.GetGeneracion(r.Context(), id)
+	if err != nil {
+		responderErrorCatalogo(w, err, mensajesGeneracion, "Error al obtener generación")
+		return
+	}
+
+	if err := h.catalogos.DeleteGeneracion(r.Context(), id, reasignarA); err != nil {
+		responderErrorCatalogo(w, err, mensajesGeneracion, "Error al eliminar generación")
+		return
+	}
+
+	h.auditarBajaCatalogo(r, auditoria.EntidadGeneracion, id, antes, reasignarA)
+
+	utils.SuccessResponse(w, "Generación eliminada correctamente", nil)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"ues-egresados/internal/autenticacion"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/correo"
+	"ues-egresados/internal/importacion"
+	"ues-egresados/internal/intentos"
+	"ues-egresados/internal/matricula"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/sso"
+	"ues-egresados/internal/validacion"
+)
+
+// Handler agrupa los controladores HTTP y los repositorios que utilizan
+type Handler struct {
+	egresados       repository.EgresadoRepository
+	usuarios        repository.UsuarioRepository
+	catalogos       repository.CatalogoRepository
+	codigosPostales repository.CodigoPostalRepository
+	auditoria       repository.AuditoriaRepository
+	intentosLogin   repository.IntentoLoginRepository
+	dosFactores     repository.DosFactoresRepository
+	tokensPassword  repository.TokenPasswordRepository
+	tokensAPI       repository.TokenAPIRepository
+	autenticador    *autenticacion.Cadena
+	correo          correo.Enviador
+	importador      *importacion.Importador
+	limitador       *intentos.Limitador
+	validador       *validacion.Validador
+	plantel         string         // clave propuesta al generar matrículas
+	urlBase         string         // dirección pública para los enlaces de los correos
+	sso             *sso.Proveedor // nil si no hay proveedor OIDC configurado
+	// rolesDosFactores son los roles que deben usar verificación en dos pasos
+	rolesDosFactores map[string]bool
+}
+
+// NewHandler crea los controladores a partir de los repositorios
+func NewHandler(repos *repository.Repositories) *Handler {
+	urlBase := correo.URLBaseDesdeEntorno()
+	h := &Handler{
+		egresados:        repos.Egresados,
+		usuarios:         repos.Usuarios,
+		catalogos:        repos.Catalogos,
+		codigosPostales:  repos.CodigosPostales,
+		auditoria:        repos.Auditoria,
+		intentosLogin:    repos.IntentosLogin,
+		dosFactores:      repos.DosFactores,
+		tokensPassword:   repos.TokensPassword,
+		tokensAPI:        repos.TokensAPI,
+		autenticador:     autenticacion.DesdeEntorno(repos),
+		correo:           correo.DesdeEntorno(),
+		importador:       importacion.NewImportador(repos),
+		limitador:        intentos.NuevoLimitador(repos.IntentosLogin, intentos.OpcionesDesdeEntorno()),
+		validador:        validacion.NewValidador(repos),
+		plantel:          matricula.PlantelDesdeEntorno(),
+		urlBase:          urlBase,
+		rolesDosFactores: auth.RolesDosFactoresDesdeEntorno(),
+	}
+	if conf := sso.DesdeEntorno(urlBase); conf != nil {
+		h.sso = sso.NuevoProveedor(*conf)
+	}
+	return h
+}
+
+// idUsuarioSesion devuelve el ID del usuario autenticado o 0 si no hay sesión
+func idUsuarioSesion(r *http.Request) int {
+	session, _ := config.SessionStore.Get(r, "session-name")
+	id, _ := session.Values["user_id"].(int)
+	return id
+}
+
+// rolSesion devuelve el rol del usuario autenticado
+func rolSesion(r *http.Request) string {
+	session, _ := config.SessionStore.Get(r, "session-name")
+	rol, _ := session.Values["rol"].(string)
+	return rol
+}
+
+// usuarioSesion devuelve el nombre de usuario autenticado
+func usuarioSesion(r *http.Request) string {
+	session, _ := config.SessionStore.Get(r, "session-name")
+	usuario, _ := session.Values["username"].(string)
+	return usuario
+}
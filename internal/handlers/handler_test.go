@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/repository"
+)
+
+// Las plantillas se cargan con rutas relativas a la raíz del proyecto
+func TestMain(m *testing.M) {
+	if err := os.Chdir("../.."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// handlerPrueba arma el Handler sobre los repositorios en memoria, con el
+// store de sesiones y los tokens de la API apuntando a ellos
+func handlerPrueba(t *testing.T) (*Handler, *repository.Repositories) {
+	t.Helper()
+	repos := repository.NewMemory()
+	config.InitSession(repos.Sesiones)
+	config.InitTokensAPI(repos.TokensAPI, repos.Usuarios)
+	return NewHandler(repos), repos
+}
+
+// conCookies copia a la petición las cookies que dejó una respuesta anterior
+func conCookies(r *http.Request, anterior *httptest.ResponseRecorder) *http.Request {
+	for _, c := range anterior.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	return r
+}
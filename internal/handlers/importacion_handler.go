@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/importacion"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+)
+
+// maxTamanoImportacion limita el archivo subido a 10 MB
+const maxTamanoImportacion = 10 << 20
+
+// ImportarEgresados valida un archivo CSV/XLSX y, si se confirma, lo aplica.
+// Por defecto se ejecuta en modo dry_run y solo devuelve el reporte por fila.
+func (h *Handler) ImportarEgresados(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxTamanoImportacion)
+	if err := r.ParseMultipartForm(maxTamanoImportacion); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "El archivo es demasiado grande o la solicitud es inválida")
+		return
+	}
+
+	dryRun := true
+	if valor := r.FormValue("dry_run"); valor != "" {
+		v, err := strconv.ParseBool(valor)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "dry_run debe ser true o false")
+			return
+		}
+		dryRun = v
+	}
+
+	archivo, cabecera, err := r.FormFile("archivo")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Debes adjuntar un archivo en el campo \"archivo\"")
+		return
+	}
+	defer archivo.Close()
+
+	tabla, err := importacion.Leer(cabecera.Filename, archivo)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Un usuario limitado a su plantel solo importa matrículas de ese plantel
+	soloPlantel, restringido := alcancePlantel(r)
+	if restringido && soloPlantel == 0 {
+		utils.ErrorResponse(w, http.StatusForbidden, "No tienes un plantel asignado")
+		return
+	}
+
+	reporte, err := h.importador.Analizar(r.Context(), tabla, soloPlantel)
+	if err != nil {
+		log.Printf("Error al analizar importación: %v", err)
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if dryRun {
+		utils.SuccessResponse(w, "Archivo validado (sin aplicar cambios)", reporte)
+		return
+	}
+
+	if err := h.importador.Aplicar(r.Context(), reporte); err != nil {
+		if errors.Is(err, importacion.ErrFilasInvalidas) {
+			utils.JSONResponse(w, http.StatusUnprocessableEntity, utils.Response{
+				Success: false,
+				Error:   "El archivo contiene filas con errores; no se aplicó ningún cambio",
+				Data:    reporte,
+			})
+			return
+		}
+		if errors.Is(err, repository.ErrConflictoVersion) {
+			utils.ErrorResponse(w, http.StatusConflict, "Algunos egresados cambiaron después de validar el archivo; vuelve a validarlo")
+			return
+		}
+		log.Printf("Error al aplicar importación: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al importar egresados")
+		return
+	}
+
+	matriculas := make([]string, 0, len(reporte.Filas))
+	for _, fila := range reporte.Filas {
+		if fila.Accion != importacion.AccionConflicto {
+			matriculas = append(matriculas, fila.Matricula)
+		}
+	}
+	h.registrarAuditoria(r, auditoria.EntidadEgresado, "", auditoria.AccionImportar, map[string]interface{}{
+		"archivo":      cabecera.Filename,
+		"creados":      reporte.Crear,
+		"actualizados": reporte.Actualizar,
+		"conflictos":   reporte.Conflictos,
+		"matriculas":   matriculas,
+	})
+	h.auditarActualizacionesImportadas(r, reporte.Anteriores())
+
+	utils.SuccessResponse(w, "Importación aplicada correctamente", reporte)
+}
+
+// auditarActualizacionesImportadas deja en el historial de cada egresado que
+// actualizó la importación los mismos cambios que registraría un PUT
+func (h *Handler) auditarActualizacionesImportadas(r *http.Request, anteriores []models.Egresado) {
+	if len(anteriores) == 0 {
+		return
+	}
+	matriculas := make([]string, len(anteriores))
+	for i, e := range anteriores {
+		matriculas[i] = e.Matricula
+	}
+	despues, err := h.egresados.Activos(r.Context(), matriculas)
+	if err != nil {
+		log.Printf("Error al auditar la importación: %v", err)
+		return
+	}
+
+	for i := range anteriores {
+		antes := &anteriores[i]
+		if guardado, ok := despues[antes.Matricula]; ok {
+			h.auditar(r, auditoria.EntidadEgresado, antes.Matricula, auditoria.AccionActualizar, antes, guardado)
+		}
+	}
+}
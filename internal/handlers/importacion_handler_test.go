@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/importacion"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+)
+
+// importar envía el archivo CSV al endpoint de importación
+func (c *clientePrueba) importar(t *testing.T, csv string, dryRun bool) *importacion.Reporte {
+	t.Helper()
+	var cuerpo bytes.Buffer
+	formulario := multipart.NewWriter(&cuerpo)
+	if !dryRun {
+		formulario.WriteField("dry_run", "false")
+	}
+	archivo, err := formulario.CreateFormFile("archivo", "egresados.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivo.Write([]byte(csv))
+	formulario.Close()
+
+	w := c.pedir(http.MethodPost, "/api/egresados/import", cuerpo.String(), "Content-Type: "+formulario.FormDataContentType())
+	if w.Code != http.StatusOK {
+		t.Fatalf("importación = %d: %s", w.Code, w.Body.String())
+	}
+	var respuesta struct {
+		Data importacion.Reporte `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &respuesta); err != nil {
+		t.Fatal(err)
+	}
+	return &respuesta.Data
+}
+
+func TestImportacionActualizaSinBorrarNiRestaurar(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	catalogosPrueba(repos)
+	ctx := context.Background()
+
+	telefono, correo := "6671112233", "ana@ues.mx"
+	for _, e := range []*models.Egresado{
+		{Matricula: "13220030", IDPlantel: 1, NombreCompleto: "Ana López", Telefono: &telefono, Correo: &correo, IDCarrera: 1, IDGeneracion: 1, IDEstatus: 1},
+		{Matricula: "13220031", IDPlantel: 1, NombreCompleto: "Beto Ruiz", IDCarrera: 1, IDGeneracion: 1, IDEstatus: 1},
+	} {
+		if err := repos.Egresados.Create(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repos.Egresados.Delete(ctx, "13220031", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	operador := iniciarSesionComo(t, h, repos, "operador", auth.RolOperador, 1)
+	csv := "matricula,nombre,carrera,generacion,estatus,telefono,correo\n" +
+		"13220030,Ana López Ruiz,Ingeniería de Software,2022-2026,Egresado,,ana.lopez@ues.mx\n" +
+		"13220031,Roberto Ruiz,Ingeniería de Software,2022-2026,Egresado,,\n" +
+		"13220032,Carla Soto,Ingeniería de Software,2022-2026,Egresado,,\n"
+
+	analisis := operador.importar(t, csv, true)
+	if analisis.Crear != 1 || analisis.Actualizar != 1 || analisis.Conflictos != 1 || analisis.ConErrores != 0 {
+		t.Fatalf("análisis = %+v", analisis)
+	}
+	if fila := analisis.Filas[1]; fila.Accion != importacion.AccionConflicto || len(fila.Errores) == 0 {
+		t.Errorf("fila en la papelera = %+v, se esperaba conflicto", fila)
+	}
+
+	reporte := operador.importar(t, csv, false)
+	if !reporte.Aplicado || reporte.Crear != 1 || reporte.Actualizar != 1 {
+		t.Fatalf("reporte = %+v", reporte)
+	}
+
+	// La celda vacía de teléfono conserva el dato guardado
+	ana, err := repos.Egresados.Get(ctx, "13220030")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ana.NombreCompleto != "Ana López Ruiz" || ana.Telefono == nil || *ana.Telefono != telefono ||
+		ana.Correo == nil || *ana.Correo != "ana.lopez@ues.mx" || ana.Version != 2 {
+		t.Errorf("egresado actualizado = %+v", ana)
+	}
+
+	// La matrícula en la papelera sigue ahí, sin cambios
+	if _, err := repos.Egresados.Get(ctx, "13220031"); err != repository.ErrNotFound {
+		t.Errorf("Get de la matrícula en la papelera = %v, se esperaba ErrNotFound", err)
+	}
+	eliminados, _ := repos.Egresados.ListEliminados(ctx)
+	if len(eliminados) != 1 || eliminados[0].NombreCompleto != "Beto Ruiz" {
+		t.Errorf("papelera = %+v", eliminados)
+	}
+
+	// El egresado actualizado tiene su entrada con los cambios campo por campo
+	historial, _, err := repos.Auditoria.List(ctx, models.FiltroAuditoria{Entidad: auditoria.EntidadEgresado, EntidadID: "13220030"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(historial) != 1 || historial[0].Accion != auditoria.AccionActualizar {
+		t.Fatalf("historial = %+v", historial)
+	}
+	var cambios map[string]auditoria.Cambio
+	if err := json.Unmarshal(historial[0].Cambios, &cambios); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cambios["nombre_completo"]; !ok {
+		t.Errorf("cambios = %v, falta nombre_completo", cambios)
+	}
+	if _, ok := cambios["telefono"]; ok {
+		t.Errorf("cambios = %v, el teléfono no cambió", cambios)
+	}
+}
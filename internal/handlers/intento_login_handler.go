@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/intentos"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// bloqueado responde 429 y registra el intento si el usuario o la IP están
+// bloqueados; así no se verifican credenciales durante el bloqueo
+func (h *Handler) bloqueado(w http.ResponseWriter, r *http.Request, intento *models.IntentoLogin) bool {
+	bloqueo, err := h.limitador.Bloqueo(r.Context(), intento.Usuario, intento.IP)
+	if err != nil {
+		log.Println("Error al consultar bloqueos de login:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return true
+	}
+	if bloqueo == nil {
+		return false
+	}
+	if err := h.limitador.RegistrarRechazo(r.Context(), intento); err != nil {
+		log.Println("Error al registrar intento de login:", err)
+	}
+	responderBloqueo(w, bloqueo)
+	return true
+}
+
+// rechazarLogin registra un intento con credenciales incorrectas y responde
+// 401 con el mensaje, o 429 si con este fallo el usuario o la IP quedaron
+// bloqueados
+func (h *Handler) rechazarLogin(w http.ResponseWriter, r *http.Request, intento *models.IntentoLogin, mensaje string) {
+	bloqueo, err := h.limitador.RegistrarFallo(r.Context(), intento)
+	if err != nil {
+		log.Println("Error al registrar intento de login:", err)
+	}
+	if bloqueo != nil {
+		responderBloqueo(w, bloqueo)
+		return
+	}
+	utils.ErrorResponse(w, http.StatusUnauthorized, mensaje)
+}
+
+// responderBloqueo responde 429 con Retry-After. No indica si el bloqueo es
+// del usuario o de la IP.
+func responderBloqueo(w http.ResponseWriter, bloqueo *models.BloqueoLogin) {
+	restante := time.Until(bloqueo.BloqueadoHasta)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(restante.Seconds()))))
+	utils.ErrorResponse(w, http.StatusTooManyRequests, fmt.Sprintf(
+		"Demasiados intentos fallidos. Intenta de nuevo en %d minutos", int(math.Ceil(restante.Minutes()))))
+}
+
+// GetIntentosLogin consulta los intentos de inicio de sesión por usuario, IP,
+// resultado y fechas
+func (h *Handler) GetIntentosLogin(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filtro := models.FiltroIntentosLogin{
+		Usuario: intentos.NormalizarUsuario(q.Get("usuario")),
+		IP:      strings.TrimSpace(q.Get("ip")),
+	}
+
+	if exitoso := q.Get("exitoso"); exitoso != "" {
+		valor, err := strconv.ParseBool(exitoso)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Valor de \"exitoso\" inválido, usa true o false")
+			return
+		}
+		filtro.Exitoso = &valor
+	}
+
+	var err error
+	if filtro.Desde, err = parseFecha(q.Get("desde"), false); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Fecha \"desde\" inválida, usa AAAA-MM-DD")
+		return
+	}
+	if filtro.Hasta, err = parseFecha(q.Get("hasta"), true); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Fecha \"hasta\" inválida, usa AAAA-MM-DD")
+		return
+	}
+
+	page, perPage, err := parsePaginacion(q)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filtro.Page = page
+	filtro.PerPage = perPage
+
+	registros, total, err := h.intentosLogin.List(r.Context(), filtro)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener los intentos de inicio de sesión")
+		return
+	}
+
+	utils.PaginatedResponse(w, "Intentos obtenidos correctamente", registros,
+		utils.NewPagination(total, page, perPage))
+}
+
+// GetBloqueosLogin lista los bloqueos de inicio de sesión vigentes
+func (h *Handler) GetBloqueosLogin(w http.ResponseWriter, r *http.Request) {
+	bloqueos, err := h.intentosLogin.ListBloqueos(r.Context(), time.Now())
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener bloqueos")
+		return
+	}
+
+	utils.SuccessResponse(w, "Bloqueos obtenidos correctamente", bloqueos)
+}
+
+// DesbloquearLogin levanta el bloqueo de un usuario o de una IP
+func (h *Handler) DesbloquearLogin(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tipo, valor := vars["tipo"], vars["valor"]
+	if tipo != models.BloqueoPorUsuario && tipo != models.BloqueoPorIP {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Tipo de bloqueo inválido, usa usuario o ip")
+		return
+	}
+	if tipo == models.BloqueoPorUsuario {
+		valor = intentos.NormalizarUsuario(valor)
+	}
+
+	if err := h.limitador.Desbloquear(r.Context(), tipo, valor); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "No hay un bloqueo vigente")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al desbloquear")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadBloqueoLogin, tipo+":"+valor, auditoria.AccionEliminar, nil)
+
+	utils.SuccessResponse(w, "Bloqueo eliminado correctamente", nil)
+}
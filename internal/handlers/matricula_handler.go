@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"ues-egresados/internal/matricula"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+)
+
+// SiguienteMatricula propone la siguiente matrícula libre para la generación
+// y el plantel indicados (?generacion=ID&plantel=PP). Sin plantel se usa el
+// configurado en PLANTEL_CLAVE; un usuario limitado a su plantel siempre
+// recibe matrículas de ese plantel. La matrícula no se reserva: si otro
+// usuario la registra antes, el alta responde que ya existe.
+func (h *Handler) SiguienteMatricula(w http.ResponseWriter, r *http.Request) {
+	idGeneracion, err := strconv.Atoi(r.URL.Query().Get("generacion"))
+	if err != nil || idGeneracion <= 0 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Indica la generación")
+		return
+	}
+	plantel := r.URL.Query().Get("plantel")
+	if plantel == "" {
+		plantel = h.plantel
+	}
+	if idPlantel, restringido := alcancePlantel(r); restringido {
+		propio, err := h.catalogos.GetPlantel(r.Context(), idPlantel)
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusForbidden, "No tienes un plantel asignado")
+			return
+		}
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener plantel")
+			return
+		}
+		plantel = propio.Clave
+	}
+
+	generacion, err := h.catalogos.GetGeneracion(r.Context(), idGeneracion)
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Generación no encontrada")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener generación")
+		return
+	}
+
+	anio, err := matricula.AnioInicial(generacion.Periodo)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, "El periodo de la generación no indica su año de inicio")
+		return
+	}
+	primera, err := matricula.Nueva(plantel, anio, 1)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "La clave del plantel debe tener 2 dígitos")
+		return
+	}
+	if _, err := h.catalogos.GetPlantelPorClave(r.Context(), plantel); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "El plantel "+plantel+" no está registrado")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener plantel")
+		return
+	}
+
+	ultima, err := h.egresados.UltimaMatricula(r.Context(), primera.Prefijo())
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al consultar matrículas")
+		return
+	}
+
+	// Un consecutivo 0000 heredado no es una matrícula válida, pero como es
+	// el mayor del prefijo tampoco hay otras ocupadas: se propone la 0001
+	siguiente := primera
+	if ultima != "" && ultima != primera.Prefijo()+"0000" {
+		actual, err := matricula.Parse(ultima)
+		if err == nil {
+			siguiente, err = actual.Siguiente()
+		}
+		if errors.Is(err, matricula.ErrAgotado) {
+			utils.ErrorResponse(w, http.StatusConflict, "Ya no hay consecutivos disponibles para este plantel y generación")
+			return
+		}
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al calcular la matrícula")
+			return
+		}
+	}
+
+	utils.SuccessResponse(w, "Matrícula disponible", map[string]interface{}{
+		"matricula":   siguiente.String(),
+		"plantel":     siguiente.Plantel,
+		"anio":        siguiente.Anio,
+		"consecutivo": siguiente.Consecutivo,
+	})
+}
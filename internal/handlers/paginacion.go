@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 200
+)
+
+// parsePaginacion lee "page" y "per_page". Los listados siempre se paginan:
+// sin per_page se usan defaultPerPage filas y nunca más de maxPerPage. Para
+// obtener todo están la exportación y el reporte.
+func parsePaginacion(q url.Values) (page, perPage int, err error) {
+	page = 1
+	perPage = defaultPerPage
+
+	if p := q.Get("page"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("Página inválida")
+		}
+		page = n
+	}
+
+	if pp := q.Get("per_page"); pp != "" {
+		n, err := strconv.Atoi(pp)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("Tamaño de página inválido")
+		}
+		if n > maxPerPage {
+			n = maxPerPage
+		}
+		perPage = n
+	}
+
+	return page, perPage, nil
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/middleware"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// GetPapelera lista los registros eliminados. Los usuarios solo se incluyen
+// para quien puede administrarlos.
+func (h *Handler) GetPapelera(w http.ResponseWriter, r *http.Request) {
+	egresados, err := h.egresados.ListEliminados(r.Context())
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener la papelera")
+		return
+	}
+
+	if _, restringido := alcancePlantel(r); restringido {
+		propios := egresados[:0]
+		for i := range egresados {
+			if enAlcance(r, &egresados[i]) {
+				propios = append(propios, egresados[i])
+			}
+		}
+		egresados = propios
+	}
+
+	respuesta := map[string]interface{}{
+		"egresados": egresados,
+	}
+
+	if middleware.TienePermiso(r, auth.PermAdminsManage) {
+		usuarios, err := h.usuarios.ListEliminados(r.Context())
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener la papelera")
+			return
+		}
+		respuesta["usuarios"] = usuarios
+	}
+
+	utils.SuccessResponse(w, "Papelera obtenida correctamente", respuesta)
+}
+
+// RestaurarEgresado saca un egresado de la papelera
+func (h *Handler) RestaurarEgresado(w http.ResponseWriter, r *http.Request) {
+	matricula := mux.Vars(r)["matricula"]
+	if h.egresadoEnPapelera(w, r, matricula) == nil {
+		return
+	}
+
+	if err := h.egresados.Restaurar(r.Context(), matricula); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "El egresado no está en la papelera")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al restaurar egresado")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadEgresado, matricula, auditoria.AccionRestaurar, nil)
+	utils.SuccessResponse(w, "Egresado restaurado correctamente", nil)
+}
+
+// PurgarEgresado elimina definitivamente un egresado de la papelera
+func (h *Handler) PurgarEgresado(w http.ResponseWriter, r *http.Request) {
+	matricula := mux.Vars(r)["matricula"]
+	if h.egresadoEnPapelera(w, r, matricula) == nil {
+		return
+	}
+
+	if err := h.egresados.Purgar(r.Context(), matricula); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "El egresado no está en la papelera")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al eliminar egresado")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadEgresado, matricula, auditoria.AccionPurgar, nil)
+	utils.SuccessResponse(w, "Egresado eliminado definitivamente", nil)
+}
+
+// RestaurarUsuario saca un usuario de la papelera
+func (h *Handler) RestaurarUsuario(w http.ResponseWriter, r *http.Request) {
+	idUsuario, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "ID de usuario inválido")
+		return
+	}
+
+	if err := h.usuarios.Restaurar(r.Context(), idUsuario); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "El usuario no está en la papelera")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al restaurar usuario")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadUsuario, strconv.Itoa(idUsuario), auditoria.AccionRestaurar, nil)
+	utils.SuccessResponse(w, "Usuario restaurado correctamente", nil)
+}
+
+// PurgarUsuario elimina definitivamente un usuario de la papelera
+func (h *Handler) PurgarUsuario(w http.ResponseWriter, r *http.Request) {
+	idUsuario, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "ID de usuario inválido")
+		return
+	}
+
+	if err := h.usuarios.Purgar(r.Context(), idUsuario); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "El usuario no está en la papelera")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al eliminar usuario")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadUsuario, strconv.Itoa(idUsuario), auditoria.AccionPurgar, nil)
+	utils.SuccessResponse(w, "Usuario eliminado definitivamente", nil)
+}
+
+// egresadoEnPapelera lee el egresado eliminado de la ruta y responde 404 si
+// no está en la papelera o pertenece a un plantel fuera del alcance del
+// usuario; devuelve nil cuando ya se respondió
+func (h *Handler) egresadoEnPapelera(w http.ResponseWriter, r *http.Request, matricula string) *models.Egresado {
+	e, err := h.egresados.GetEliminado(r.Context(), matricula)
+	if err == nil && !enAlcance(r, e) {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "El egresado no está en la papelera")
+			return nil
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener egresado")
+		return nil
+	}
+	return e
+}
+
+// enPapelera indica si la matrícula existe pero está eliminada
+func (h *Handler) enPapelera(r *http.Request, matricula string) bool {
+	if _, err := h.egresados.Get(r.Context(), matricula); !errors.Is(err, repository.ErrNotFound) {
+		return false
+	}
+	existentes, err := h.egresados.Existentes(r.Context(), []string{matricula})
+	return err == nil && existentes[matricula]
+}
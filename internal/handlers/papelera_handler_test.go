@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/tokensapi"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Un token sin planteles:todos queda limitado al plantel de su usuario,
+// también en la papelera
+func TestPapeleraFueraDelPlantel(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	catalogosPrueba(repos)
+	ctx := context.Background()
+
+	for _, e := range []models.Egresado{
+		{Matricula: "13220030", IDPlantel: 1, NombreCompleto: "Ana López", IDCarrera: 1, IDGeneracion: 1, IDEstatus: 1},
+		{Matricula: "07220031", IDPlantel: 2, NombreCompleto: "Luis Pérez", IDCarrera: 1, IDGeneracion: 1, IDEstatus: 1},
+	} {
+		e := e
+		if err := repos.Egresados.Create(ctx, &e); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Egresados.Delete(ctx, e.Matricula, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("contraseña123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idPlantel := 2
+	idUsuario, err := repos.Usuarios.Create(ctx, &models.Usuario{Usuario: "admin", Nombre: "admin", Rol: auth.RolAdministrador, Password: string(hash), IDPlantel: &idPlantel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, hashToken, prefijo, err := tokensapi.Nuevo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alcances := []string{string(auth.PermEgresadosRead), string(auth.PermEgresadosDelete), string(auth.PermPapeleraPurge)}
+	if _, err := repos.TokensAPI.Create(ctx, &models.TokenAPI{IDUsuario: idUsuario, Hash: hashToken, Nombre: "sincronización", Prefijo: prefijo, Alcances: alcances}); err != nil {
+		t.Fatal(err)
+	}
+	cliente := &clientePrueba{router: routerEgresados(h)}
+	bearer := "Authorization: Bearer " + token
+
+	// El egresado de otro plantel no existe para el token
+	if w := cliente.pedir(http.MethodPost, "/api/papelera/egresados/13220030/restaurar", "", bearer); w.Code != http.StatusNotFound {
+		t.Errorf("restaurar de otro plantel = %d, se esperaba 404: %s", w.Code, w.Body.String())
+	}
+	if w := cliente.pedir(http.MethodDelete, "/api/papelera/egresados/13220030", "", bearer); w.Code != http.StatusNotFound {
+		t.Errorf("purgar de otro plantel = %d, se esperaba 404: %s", w.Code, w.Body.String())
+	}
+	if _, err := repos.Egresados.GetEliminado(ctx, "13220030"); err != nil {
+		t.Errorf("el egresado de otro plantel debe seguir en la papelera: %v", err)
+	}
+
+	// El de su plantel sí se restaura y se purga
+	if w := cliente.pedir(http.MethodPost, "/api/papelera/egresados/07220031/restaurar", "", bearer); w.Code != http.StatusOK {
+		t.Fatalf("restaurar = %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := repos.Egresados.Get(ctx, "07220031"); err != nil {
+		t.Fatalf("Get tras restaurar = %v", err)
+	}
+	if err := repos.Egresados.Delete(ctx, "07220031", idUsuario); err != nil {
+		t.Fatal(err)
+	}
+	if w := cliente.pedir(http.MethodDelete, "/api/papelera/egresados/07220031", "", bearer); w.Code != http.StatusOK {
+		t.Fatalf("purgar = %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := repos.Egresados.GetEliminado(ctx, "07220031"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetEliminado tras purgar = %v, se esperaba ErrNotFound", err)
+	}
+}
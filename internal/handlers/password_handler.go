@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/correo"
+	"ues-egresados/internal/intentos"
+	"ues-egresados/internal/middleware"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+	"ues-egresados/internal/validacion"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// vigenciaTokenPassword es el tiempo para usar el enlace enviado por correo
+	vigenciaTokenPassword = 30 * time.Minute
+
+	enlaceInvalido = "El enlace no es válido o ya venció; solicita uno nuevo"
+)
+
+// CambiarPassword cambia la contraseña del usuario autenticado tras verificar
+// la actual y cierra sus demás sesiones
+func (h *Handler) CambiarPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.CambioPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	idUsuario := idUsuarioSesion(r)
+	usuario, err := h.usuarios.GetByID(r.Context(), idUsuario)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener el usuario")
+		return
+	}
+
+	// Una contraseña actual incorrecta cuenta para el bloqueo igual que en el login
+	intento := intentoDeSesion(r)
+	if h.bloqueado(w, r, intento) {
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(usuario.Password), []byte(req.PasswordActual)); err != nil {
+		h.rechazarLogin(w, r, intento, "La contraseña actual es incorrecta")
+		return
+	}
+
+	if req.PasswordNueva == req.PasswordActual {
+		utils.ValidationErrorResponse(w, map[string]string{"password_nueva": "La nueva contraseña debe ser distinta de la actual"})
+		return
+	}
+	if motivo := validacion.Password(req.PasswordNueva, usuario.Usuario); motivo != "" {
+		utils.ValidationErrorResponse(w, map[string]string{"password_nueva": motivo})
+		return
+	}
+
+	if !h.guardarPassword(w, r, usuario.IDUsuario, req.PasswordNueva) {
+		return
+	}
+	n, _ := h.cerrarSesionesUsuario(r, idUsuario)
+
+	utils.SuccessResponse(w, "Contraseña actualizada correctamente", map[string]int64{"sesiones_cerradas": n})
+}
+
+// SolicitarRestablecimiento envía por correo un enlace para restablecer la
+// contraseña. Responde lo mismo exista o no el correo, para no revelar qué
+// correos están registrados.
+func (h *Handler) SolicitarRestablecimiento(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Correo string `json:"correo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	direccion := strings.ToLower(strings.TrimSpace(req.Correo))
+	if direccion == "" || !utils.ValidateEmail(direccion) {
+		utils.ValidationErrorResponse(w, map[string]string{"correo": "Correo inválido"})
+		return
+	}
+
+	const respuesta = "Si el correo está registrado, recibirás un enlace para restablecer tu contraseña"
+
+	// Se limita por correo y por IP antes de buscar al usuario, así la
+	// respuesta tampoco revela si el correo existe
+	espera, err := h.limitador.Restablecimiento(r.Context(), &models.IntentoLogin{
+		Usuario:   intentos.NormalizarUsuario(direccion),
+		IP:        utils.ClientIP(r),
+		UserAgent: utils.UserAgent(r),
+	})
+	if err != nil {
+		log.Println("Error al registrar solicitud de restablecimiento:", err)
+	}
+	if espera > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(espera.Seconds())))
+		utils.ErrorResponse(w, http.StatusTooManyRequests, fmt.Sprintf(
+			"Demasiadas solicitudes. Intenta de nuevo en %d minutos", int(espera.Minutes())))
+		return
+	}
+
+	usuario, err := h.usuarios.GetByCorreo(r.Context(), direccion)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Println("Error al buscar usuario por correo:", err)
+		}
+		utils.SuccessResponse(w, respuesta, nil)
+		return
+	}
+
+	token, err := nuevoTokenPassword()
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+	// Solo el último enlace solicitado sirve
+	if err := h.tokensPassword.DeletePorUsuario(r.Context(), usuario.IDUsuario); err != nil {
+		log.Println("Error al invalidar enlaces anteriores:", err)
+	}
+	err = h.tokensPassword.Create(r.Context(), &models.TokenPassword{
+		ID:        hashTokenPassword(token),
+		IDUsuario: usuario.IDUsuario,
+		IP:        utils.ClientIP(r),
+		ExpiraEn:  time.Now().Add(vigenciaTokenPassword),
+	})
+	if err != nil {
+		log.Println("Error al guardar enlace de restablecimiento:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+
+	mensaje := correo.Mensaje{
+		Para:   direccion,
+		Asunto: "Restablecer tu contraseña del Sistema de Egresados",
+		Texto: fmt.Sprintf(`Hola, %s:
+
+Recibimos una solicitud para restablecer la contraseña del usuario %s.
+Para elegir una nueva, abre este enlace en los próximos %d minutos:
+
+%s/password/restablecer?token=%s
+
+El enlace sirve una sola vez. Si no lo solicitaste, ignora este correo;
+tu contraseña no cambiará.
+`, usuario.Nombre, usuario.Usuario, int(vigenciaTokenPassword.Minutes()), h.urlBase, url.QueryEscape(token)),
+	}
+
+	// El envío no retrasa la respuesta, así el tiempo tampoco revela si el correo existe
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := h.correo.Enviar(ctx, mensaje); err != nil {
+			log.Printf("❌ Error al enviar correo de restablecimiento al usuario %d: %v", usuario.IDUsuario, err)
+		}
+	}()
+
+	log.Printf("🔑 Enlace de restablecimiento de contraseña enviado al usuario %d", usuario.IDUsuario)
+	utils.SuccessResponse(w, respuesta, nil)
+}
+
+// RestablecerPassword cambia la contraseña con el token del enlace enviado
+// por correo y cierra todas las sesiones del usuario
+func (h *Handler) RestablecerPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.RestablecerPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	id := hashTokenPassword(req.Token)
+	token, err := h.tokensPassword.Get(r.Context(), id, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusBadRequest, enlaceInvalido)
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+
+	usuario, err := h.usuarios.GetByID(r.Context(), token.IDUsuario)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusBadRequest, enlaceInvalido)
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+
+	// La política se revisa antes de consumir el token para poder corregir
+	// la contraseña con el mismo enlace
+	if motivo := validacion.Password(req.Password, usuario.Usuario); motivo != "" {
+		utils.ValidationErrorResponse(w, map[string]string{"password": motivo})
+		return
+	}
+
+	if err := h.tokensPassword.Usar(r.Context(), id, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusBadRequest, enlaceInvalido)
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+
+	if !h.guardarPassword(w, r, usuario.IDUsuario, req.Password) {
+		return
+	}
+	h.cerrarSesionesUsuario(r, usuario.IDUsuario)
+
+	log.Printf("🔑 Contraseña restablecida por correo para el usuario %d", usuario.IDUsuario)
+	utils.SuccessResponse(w, "Contraseña restablecida; ya puedes iniciar sesión", nil)
+}
+
+// guardarPassword encripta y guarda la contraseña, invalida los enlaces de
+// restablecimiento pendientes y lo registra en la bitácora
+func (h *Handler) guardarPassword(w http.ResponseWriter, r *http.Request, idUsuario int, password string) bool {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al procesar contraseña")
+		return false
+	}
+	if err := h.usuarios.CambiarPassword(r.Context(), idUsuario, string(hash)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Usuario no encontrado")
+			return false
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al guardar la contraseña")
+		return false
+	}
+
+	if err := h.tokensPassword.DeletePorUsuario(r.Context(), idUsuario); err != nil {
+		log.Println("Error al invalidar enlaces de restablecimiento:", err)
+	}
+	h.registrarAuditoria(r, auditoria.EntidadUsuario, strconv.Itoa(idUsuario), auditoria.AccionActualizar,
+		map[string]auditoria.Cambio{"password": {Antes: "********", Despues: "********"}})
+	return true
+}
+
+// OlvidoPasswordPage muestra el formulario para pedir el enlace por correo
+func (h *Handler) OlvidoPasswordPage(w http.ResponseWriter, r *http.Request) {
+	renderPaginaPassword(w, r, "")
+}
+
+// RestablecerPasswordPage muestra el formulario para elegir la nueva
+// contraseña con el token del enlace
+func (h *Handler) RestablecerPasswordPage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Redirect(w, r, "/password/olvido", http.StatusSeeOther)
+		return
+	}
+	renderPaginaPassword(w, r, token)
+}
+
+// renderPaginaPassword dibuja password.html, que fuera de una sesión
+// autenticada sirve para pedir el enlace o, con token, para usarlo
+func renderPaginaPassword(w http.ResponseWriter, r *http.Request, token string) {
+	csrf, err := middleware.TokenCSRF(w, r)
+	if err != nil {
+		http.Error(w, "Error al crear sesión", http.StatusInternalServerError)
+		return
+	}
+
+	tmpl, err := template.ParseFiles("web/templates/password.html")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tmpl.Execute(w, map[string]interface{}{
+		"CSRFToken":      csrf,
+		"Nonce":          middleware.NonceCSP(r),
+		"Token":          token,
+		"PasswordMinimo": validacion.PasswordMinimo,
+	})
+}
+
+func nuevoTokenPassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashTokenPassword es el ID con el que se guarda el token
+func hashTokenPassword(token string) string {
+	suma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(suma[:])
+}
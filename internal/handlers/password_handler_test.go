@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/correo"
+	"ues-egresados/internal/intentos"
+	"ues-egresados/internal/models"
+)
+
+// correoNulo descarta los mensajes
+type correoNulo struct{}
+
+func (correoNulo) Enviar(context.Context, correo.Mensaje) error { return nil }
+
+func solicitarRestablecimiento(h *Handler, direccion, ip string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/password/olvido", strings.NewReader(`{"correo":"`+direccion+`"}`))
+	r.RemoteAddr = ip + ":40000"
+	w := httptest.NewRecorder()
+	h.SolicitarRestablecimiento(w, r)
+	return w
+}
+
+func TestSolicitarRestablecimientoLimitaPorCorreoYPorIP(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	h.correo = correoNulo{}
+	h.limitador = intentos.NuevoLimitador(repos.IntentosLogin, intentos.Opciones{
+		MaxFallidosUsuario:         5,
+		MaxFallidosIP:              20,
+		Ventana:                    time.Minute,
+		Bloqueo:                    time.Minute,
+		MaxRestablecimientosCorreo: 2,
+		MaxRestablecimientosIP:     4,
+		VentanaRestablecimiento:    time.Hour,
+	})
+	direccion := "ana@ues.mx"
+	if _, err := repos.Usuarios.Create(context.Background(), &models.Usuario{
+		Usuario: "ana", Nombre: "Ana", Correo: &direccion, Rol: auth.RolConsulta, Password: "x",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := solicitarRestablecimiento(h, direccion, "203.0.113.1"); w.Code != http.StatusOK {
+			t.Fatalf("solicitud %d = %d: %s", i+1, w.Code, w.Body.String())
+		}
+	}
+	// El mismo correo desde otra IP
+	w := solicitarRestablecimiento(h, "ANA@ues.mx", "198.51.100.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3600" {
+		t.Fatalf("tercera solicitud del correo = %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// Correos que no existen desde la misma IP: el límite no depende de que existan
+	for _, otro := range []string{"beto@ues.mx", "carla@ues.mx"} {
+		if w := solicitarRestablecimiento(h, otro, "203.0.113.1"); w.Code != http.StatusOK {
+			t.Fatalf("solicitud para %s = %d", otro, w.Code)
+		}
+	}
+	if w := solicitarRestablecimiento(h, "diana@ues.mx", "203.0.113.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("quinta solicitud desde la IP = %d, se esperaba 429", w.Code)
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/middleware"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+	"ues-egresados/internal/validacion"
+)
+
+var mensajesPlantel = mensajesCatalogo{
+	campo:         "clave",
+	duplicado:     "Ya existe un plantel con esa clave o ese nombre",
+	noEncontrado:  "Plantel no encontrado",
+	enUso:         "El plantel tiene %d egresados asignados (incluida la papelera); no se puede eliminar",
+	enUsoUsuarios: "El plantel tiene %d egresados y %d usuarios asignados (incluida la papelera); no se puede eliminar",
+}
+
+// mensajesClavePlantel se usa al editar: la clave no cambia si hay egresados
+var mensajesClavePlantel = mensajesCatalogo{
+	campo:        mensajesPlantel.campo,
+	duplicado:    mensajesPlantel.duplicado,
+	noEncontrado: mensajesPlantel.noEncontrado,
+	enUso:        "El plantel tiene %d egresados; su clave no puede cambiar porque forma parte de sus matrículas",
+}
+
+// alcancePlantel indica a qué plantel está limitado el usuario de la sesión.
+// Los roles con planteles:todos no tienen restricción, salvo que usen un token
+// personal sin ese alcance; un usuario restringido sin plantel asignado
+// devuelve 0 y no ve ningún egresado.
+func alcancePlantel(r *http.Request) (idPlantel int, restringido bool) {
+	if middleware.TienePermiso(r, auth.PermPlantelesTodos) {
+		return 0, false
+	}
+	session, _ := config.SessionStore.Get(r, "session-name")
+	idPlantel, _ = session.Values["id_plantel"].(int)
+	return idPlantel, true
+}
+
+// enAlcance indica si el usuario de la sesión puede ver el egresado
+func enAlcance(r *http.Request, e *models.Egresado) bool {
+	idPlantel, restringido := alcancePlantel(r)
+	return !restringido || e.IDPlantel == idPlantel
+}
+
+// obtenerEgresado lee el egresado de la ruta y responde 404 si no existe o
+// pertenece a un plantel fuera del alcance del usuario; devuelve nil cuando
+// ya se respondió
+func (h *Handler) obtenerEgresado(w http.ResponseWriter, r *http.Request, matricula string) *models.Egresado {
+	e, err := h.egresados.Get(r.Context(), matricula)
+	if err == nil && !enAlcance(r, e) {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Egresado no encontrado")
+			return nil
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener egresado")
+		return nil
+	}
+	return e
+}
+
+// plantelDeFiltro devuelve el plantel pedido en ?plantel= o, para un usuario
+// restringido, siempre el suyo
+func plantelDeFiltro(r *http.Request) string {
+	if idPlantel, restringido := alcancePlantel(r); restringido {
+		return strconv.Itoa(idPlantel)
+	}
+	return r.URL.Query().Get("plantel")
+}
+
+// GetPlanteles obtiene todos los planteles
+func (h *Handler) GetPlanteles(w http.ResponseWriter, r *http.Request) {
+	planteles, err := h.catalogos.ListPlanteles(r.Context())
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener planteles")
+		return
+	}
+
+	utils.SuccessResponse(w, "Planteles obtenidos correctamente", planteles)
+}
+
+// CreatePlantel agrega un plantel al catálogo
+func (h *Handler) CreatePlantel(w http.ResponseWriter, r *http.Request) {
+	var p models.Plantel
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	if errores := validacion.Plantel(&p); len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	if err := h.catalogos.CreatePlantel(r.Context(), &p); err != nil {
+		responderErrorCatalogo(w, err, mensajesPlantel, "Error al crear plantel")
+		return
+	}
+
+	h.auditar(r, auditoria.EntidadPlantel, strconv.Itoa(p.IDPlantel), auditoria.AccionCrear, nil, p)
+
+	utils.CreatedResponse(w, "Plantel creado correctamente", p)
+}
+
+// UpdatePlantel modifica un plantel; la clave solo puede cambiar si aún no
+// tiene egresados
+func (h *Handler) UpdatePlantel(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCatalogo(w, r)
+	if !ok {
+		return
+	}
+
+	var p models.Plantel
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+	p.IDPlantel = id
+	if errores := validacion.Plantel(&p); len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	antes, err := h.catalogos.GetPlantel(r.Context(), id)
+	if err != nil {
+		responderErrorCatalogo(w, err, mensajesPlantel, "Error al obtener plantel")
+		return
+	}
+
+	if err := h.catalogos.UpdatePlantel(r.Context(), &p); err != nil {
+		responderErrorCatalogo(w, err, mensajesClavePlantel, "Error al actualizar plantel")
+		return
+	}
+
+	h.auditar(r, auditoria.EntidadPlantel, strconv.Itoa(id), auditoria.AccionActualizar, antes, p)
+
+	utils.SuccessResponse(w, "Plantel actualizado correctamente", p)
+}
+
+// DeletePlantel elimina un plantel sin egresados ni usuarios asignados. No
+// admite reasignación porque la clave forma parte de la matrícula.
+func (h *Handler) DeletePlantel(w http.ResponseWriter, r *http.Request) {
+	id, ok := idCatalogo(w, r)
+	if !ok {
+		return
+	}
+
+	antes, err := h.catalogos.GetPlantel(r.Context(), id)
+	if err != nil {
+		responderErrorCatalogo(w, err, mensajesPlantel, "Error al obtener plantel")
+		return
+	}
+
+	if err := h.catalogos.DeletePlantel(r.Context(), id); err != nil {
+		responderErrorCatalogo(w, err, mensajesPlantel, "Error al eliminar plantel")
+		return
+	}
+
+	h.auditarBajaCatalogo(r, auditoria.EntidadPlantel, id, antes, 0)
+
+	utils.SuccessResponse(w, "Plantel eliminado correctamente", nil)
+}
+
+// validarAlcanceEgresado marca la matrícula de otro plantel cuando el
+// usuario está limitado al suyo
+func validarAlcanceEgresado(r *http.Request, e *models.Egresado, errores validacion.Errores) {
+	if _, invalido := errores["matricula"]; invalido || enAlcance(r, e) {
+		return
+	}
+	errores.Agregar("matricula", "Solo puedes registrar egresados de tu plantel")
+}
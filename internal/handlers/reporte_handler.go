@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/reportes"
+	"ues-egresados/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// GetExpedientePDF genera el expediente individual de un egresado en PDF
+func (h *Handler) GetExpedientePDF(w http.ResponseWriter, r *http.Request) {
+	matricula := mux.Vars(r)["matricula"]
+
+	e := h.obtenerEgresado(w, r, matricula)
+	if e == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := reportes.Expediente(&buf, e, infoReporte(r)); err != nil {
+		log.Printf("Error al generar expediente %s: %v", matricula, err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al generar el expediente")
+		return
+	}
+
+	enviarPDF(w, fmt.Sprintf("expediente_%s.pdf", e.Matricula), &buf)
+}
+
+// GetReportePDF genera la tabla de egresados en PDF con los filtros del listado
+func (h *Handler) GetReportePDF(w http.ResponseWriter, r *http.Request) {
+	filtro, err := parseFiltroEgresados(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	generacion, carrera, err := h.nombresFiltro(r, filtro)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener catálogos")
+		return
+	}
+
+	// El PDF se arma completo antes de enviarlo para poder responder con error
+	var buf bytes.Buffer
+	err = reportes.Tabla(&buf, generacion, carrera, infoReporte(r), func(agregar func(*models.Egresado) error) error {
+		return h.egresados.Recorrer(r.Context(), filtro, agregar)
+	})
+	if err != nil {
+		log.Printf("Error al generar reporte PDF: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al generar el reporte")
+		return
+	}
+
+	enviarPDF(w, fmt.Sprintf("tabla_egresados_%s.pdf", time.Now().Format("2006-01-02")), &buf)
+}
+
+// nombresFiltro traduce los IDs de generación y carrera del filtro a texto
+func (h *Handler) nombresFiltro(r *http.Request, filtro models.FiltroEgresados) (string, string, error) {
+	generacion, carrera := "Todas", "Todas"
+
+	if id, err := strconv.Atoi(filtro.Generacion); err == nil {
+		generaciones, err := h.catalogos.ListGeneraciones(r.Context())
+		if err != nil {
+			return "", "", err
+		}
+		for _, g := range generaciones {
+			if g.IDGeneracion == id {
+				generacion = g.Periodo
+			}
+		}
+	}
+
+	if id, err := strconv.Atoi(filtro.Carrera); err == nil {
+		carreras, err := h.catalogos.ListCarreras(r.Context())
+		if err != nil {
+			return "", "", err
+		}
+		for _, c := range carreras {
+			if c.IDCarrera == id {
+				carrera = c.Nombre
+			}
+		}
+	}
+
+	return generacion, carrera, nil
+}
+
+// infoReporte toma de la sesión el nombre de quien genera el documento
+func infoReporte(r *http.Request) reportes.Info {
+	session, _ := config.SessionStore.Get(r, "session-name")
+	nombre, _ := session.Values["nombre_completo"].(string)
+	if nombre == "" {
+		nombre, _ = session.Values["username"].(string)
+	}
+	return reportes.Info{GeneradoPor: nombre, Fecha: time.Now()}
+}
+
+func enviarPDF(w http.ResponseWriter, nombreArchivo string, buf *bytes.Buffer) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, nombreArchivo))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Header().Set("Cache-Control", "no-store")
+	buf.WriteTo(w)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// GetMisSesiones lista las sesiones abiertas del usuario y marca la actual
+func (h *Handler) GetMisSesiones(w http.ResponseWriter, r *http.Request) {
+	sesiones, err := config.SessionStore.Listar(r.Context(), idUsuarioSesion(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener sesiones")
+		return
+	}
+
+	actual := config.SessionStore.IDActual(r, "session-name")
+	for i := range sesiones {
+		sesiones[i].Actual = sesiones[i].ID == actual
+	}
+
+	utils.SuccessResponse(w, "Sesiones obtenidas correctamente", sesiones)
+}
+
+// RevocarSesion cierra una de las sesiones del usuario
+func (h *Handler) RevocarSesion(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := config.SessionStore.Revocar(r.Context(), idUsuarioSesion(r), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Sesión no encontrada")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al cerrar la sesión")
+		return
+	}
+
+	utils.SuccessResponse(w, "Sesión cerrada correctamente", nil)
+}
+
+// CerrarOtrasSesiones cierra todas las sesiones del usuario salvo la actual
+func (h *Handler) CerrarOtrasSesiones(w http.ResponseWriter, r *http.Request) {
+	actual := config.SessionStore.IDActual(r, "session-name")
+	n, err := config.SessionStore.CerrarSesionesDeUsuario(r.Context(), idUsuarioSesion(r), actual)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al cerrar las sesiones")
+		return
+	}
+
+	utils.SuccessResponse(w, "Sesiones cerradas correctamente", map[string]int64{"cerradas": n})
+}
+
+// CerrarSesionesAdministrador revoca todas las sesiones de otro usuario
+func (h *Handler) CerrarSesionesAdministrador(w http.ResponseWriter, r *http.Request) {
+	idUsuario, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "ID de usuario inválido")
+		return
+	}
+
+	if _, err := h.usuarios.GetByID(r.Context(), idUsuario); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Administrador no encontrado")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener administrador")
+		return
+	}
+
+	n, err := h.cerrarSesionesUsuario(r, idUsuario)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al cerrar las sesiones")
+		return
+	}
+
+	utils.SuccessResponse(w, "Sesiones cerradas correctamente", map[string]int64{"cerradas": n})
+}
+
+// cerrarSesionesUsuario revoca las sesiones de un usuario (conservando la de
+// quien hace la petición) y lo registra en la bitácora
+func (h *Handler) cerrarSesionesUsuario(r *http.Request, idUsuario int) (int64, error) {
+	n, err := config.SessionStore.CerrarSesionesDeUsuario(r.Context(), idUsuario, config.SessionStore.IDActual(r, "session-name"))
+	if err != nil {
+		log.Printf("Error al cerrar las sesiones del usuario %d: %v", idUsuario, err)
+		return 0, err
+	}
+	if n > 0 {
+		h.registrarAuditoria(r, auditoria.EntidadUsuario, strconv.Itoa(idUsuario), auditoria.AccionCerrarSesiones,
+			map[string]int64{"sesiones_cerradas": n})
+	}
+	return n, nil
+}
+
+// pierdeAcceso indica si el cambio de un usuario invalida sus sesiones
+// abiertas, que guardan el rol y el plantel con los que inició sesión
+func pierdeAcceso(antes, despues *models.Usuario) bool {
+	return antes.Rol != despues.Rol || !mismoPlantel(antes.IDPlantel, despues.IDPlantel)
+}
+
+func mismoPlantel(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
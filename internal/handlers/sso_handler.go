@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/autenticacion"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/intentos"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/sso"
+	"ues-egresados/internal/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Valores de sesión entre la ida al proveedor y el regreso
+const (
+	ssoEstado      = "sso_estado"
+	ssoNonce       = "sso_nonce"
+	ssoVerificador = "sso_verificador"
+	ssoDesde       = "sso_desde"
+
+	// Tiempo para completar el inicio de sesión en el proveedor
+	vigenciaSSO = 10 * time.Minute
+)
+
+const errorSSO = "No se pudo iniciar sesión con la cuenta institucional"
+
+var caracterNoValidoUsuario = regexp.MustCompile(`[^a-zA-Z0-9._\-]+`)
+
+// LoginSSO envía al usuario al proveedor OpenID Connect. El state, el nonce y
+// el verificador PKCE quedan en la sesión anónima para validar el regreso.
+func (h *Handler) LoginSSO(w http.ResponseWriter, r *http.Request) {
+	if h.sso == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	solicitud, err := sso.NuevaSolicitud()
+	if err != nil {
+		log.Println("Error al generar la solicitud OIDC:", err)
+		h.renderLogin(w, r, http.StatusInternalServerError, errorSSO)
+		return
+	}
+	destino, err := h.sso.URLAutorizacion(r.Context(), solicitud)
+	if err != nil {
+		log.Println("Error al contactar al proveedor OIDC:", err)
+		h.renderLogin(w, r, http.StatusBadGateway, "El proveedor de la cuenta institucional no está disponible")
+		return
+	}
+
+	session, _ := config.SessionStore.Get(r, "session-name")
+	session.Values[ssoEstado] = solicitud.Estado
+	session.Values[ssoNonce] = solicitud.Nonce
+	session.Values[ssoVerificador] = solicitud.VerificadorPKCE
+	session.Values[ssoDesde] = time.Now().Unix()
+	if err := session.Save(r, w); err != nil {
+		log.Println("❌ Error al guardar sesión:", err)
+		http.Error(w, "Error al crear sesión", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, destino, http.StatusFound)
+}
+
+// CallbackSSO recibe el código del proveedor, valida el ID token y relaciona
+// la identidad con un usuario por correo o, si no hay coincidencia, por
+// preferred_username. Con OIDC_ALTA_AUTOMATICA el usuario se crea la primera
+// vez. La verificación en dos pasos local se sigue pidiendo si está activa.
+func (h *Handler) CallbackSSO(w http.ResponseWriter, r *http.Request) {
+	if h.sso == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// La solicitud solo sirve una vez
+	session, _ := config.SessionStore.Get(r, "session-name")
+	estado, _ := session.Values[ssoEstado].(string)
+	solicitud := &sso.Solicitud{Estado: estado}
+	solicitud.Nonce, _ = session.Values[ssoNonce].(string)
+	solicitud.VerificadorPKCE, _ = session.Values[ssoVerificador].(string)
+	desde, _ := session.Values[ssoDesde].(int64)
+	for _, clave := range []string{ssoEstado, ssoNonce, ssoVerificador, ssoDesde} {
+		delete(session.Values, clave)
+	}
+	if err := session.Save(r, w); err != nil {
+		log.Println("❌ Error al guardar sesión:", err)
+	}
+
+	consulta := r.URL.Query()
+	if estado == "" || consulta.Get("state") != estado || time.Since(time.Unix(desde, 0)) > vigenciaSSO {
+		h.renderLogin(w, r, http.StatusBadRequest, "La solicitud de inicio de sesión expiró; inténtalo de nuevo")
+		return
+	}
+	if motivo := consulta.Get("error"); motivo != "" {
+		log.Printf("El proveedor OIDC rechazó el inicio de sesión: %s %s", motivo, consulta.Get("error_description"))
+		h.renderLogin(w, r, http.StatusUnauthorized, errorSSO)
+		return
+	}
+
+	identidad, err := h.sso.Canjear(r.Context(), solicitud, consulta.Get("code"))
+	if err != nil {
+		log.Println("Error al validar la respuesta del proveedor OIDC:", err)
+		h.renderLogin(w, r, http.StatusUnauthorized, errorSSO)
+		return
+	}
+
+	usuario, err := h.usuarioSSO(r, identidad)
+	nuevo := false
+	if errors.Is(err, repository.ErrNotFound) && h.sso.Configuracion().AltaAutomatica {
+		usuario, err = h.altaUsuarioSSO(r, identidad)
+		nuevo = err == nil
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Printf("Inicio de sesión OIDC sin usuario registrado (sub %s, correo %q)", identidad.Sujeto, identidad.Correo)
+		h.renderLogin(w, r, http.StatusForbidden, "Tu cuenta institucional no tiene acceso al sistema")
+		return
+	}
+	if err != nil {
+		log.Println("Error al relacionar la cuenta institucional:", err)
+		h.renderLogin(w, r, http.StatusInternalServerError, errorSSO)
+		return
+	}
+
+	intento := &models.IntentoLogin{
+		IDUsuario: &usuario.IDUsuario,
+		Usuario:   intentos.NormalizarUsuario(usuario.Usuario),
+		IP:        utils.ClientIP(r),
+		UserAgent: utils.UserAgent(r),
+	}
+
+	// Un usuario bloqueado por intentos fallidos tampoco entra por aquí
+	bloqueo, err := h.limitador.Bloqueo(r.Context(), intento.Usuario, intento.IP)
+	if err != nil {
+		log.Println("Error al consultar bloqueos de login:", err)
+		h.renderLogin(w, r, http.StatusInternalServerError, errorSSO)
+		return
+	}
+	if bloqueo != nil {
+		if err := h.limitador.RegistrarRechazo(r.Context(), intento); err != nil {
+			log.Println("Error al registrar intento de login:", err)
+		}
+		h.renderLogin(w, r, http.StatusTooManyRequests, "Acceso bloqueado temporalmente por intentos fallidos")
+		return
+	}
+
+	dosFactores, err := h.dosFactores.Get(r.Context(), usuario.IDUsuario)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Println("Error al consultar verificación en dos pasos:", err)
+		h.renderLogin(w, r, http.StatusInternalServerError, errorSSO)
+		return
+	}
+	if dosFactores != nil && dosFactores.Activo {
+		// El login muestra directamente el paso del código
+		if err := marcarPendiente2FA(w, r, usuario.IDUsuario); err != nil {
+			log.Println("❌ Error al guardar sesión:", err)
+			h.renderLogin(w, r, http.StatusInternalServerError, errorSSO)
+			return
+		}
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	configurar, err := h.autenticarSesion(w, r, usuario, intento, dosFactores)
+	if err != nil {
+		h.renderLogin(w, r, http.StatusInternalServerError, errorSSO)
+		return
+	}
+	if nuevo {
+		h.auditar(r, auditoria.EntidadUsuario, strconv.Itoa(usuario.IDUsuario), auditoria.AccionCrear, nil, usuario)
+	}
+
+	destino := "/dashboard"
+	if configurar {
+		destino = "/seguridad"
+	}
+	http.Redirect(w, r, destino, http.StatusFound)
+}
+
+// usuarioSSO busca al usuario por el correo verificado y después por
+// preferred_username
+func (h *Handler) usuarioSSO(r *http.Request, identidad *sso.Identidad) (*models.Usuario, error) {
+	if identidad.Correo != "" {
+		usuario, err := h.usuarios.GetByCorreo(r.Context(), identidad.Correo)
+		if !errors.Is(err, repository.ErrNotFound) {
+			return usuario, err
+		}
+	}
+	if identidad.Usuario != "" {
+		return h.usuarios.GetByUsuario(r.Context(), identidad.Usuario)
+	}
+	return nil, repository.ErrNotFound
+}
+
+// altaUsuarioSSO crea el usuario de una identidad que aún no existe, con el rol
+// y plantel configurados. La contraseña es aleatoria: la persona entra con su
+// cuenta institucional o puede definir una con "¿Olvidaste tu contraseña?".
+func (h *Handler) altaUsuarioSSO(r *http.Request, identidad *sso.Identidad) (*models.Usuario, error) {
+	conf := h.sso.Configuracion()
+
+	nombreUsuario, err := h.nombreUsuarioSSO(r, identidad)
+	if err != nil {
+		return nil, err
+	}
+	usuario := &models.Usuario{
+		Usuario: nombreUsuario,
+		Rol:     conf.RolAlta,
+		Origen:  models.OrigenOIDC,
+	}
+	usuario.Nombre, usuario.ApellidoPaterno, usuario.ApellidoMaterno = autenticacion.Nombres(identidad.Nombre, identidad.Apellidos, identidad.NombreCompleto)
+	if usuario.Nombre == "" {
+		usuario.Nombre = usuario.Usuario
+	}
+	if identidad.Correo != "" {
+		correo := identidad.Correo
+		usuario.Correo = &correo
+	}
+	if conf.PlantelAlta != "" && !auth.HasPermission(conf.RolAlta, auth.PermPlantelesTodos) {
+		plantel, err := h.catalogos.GetPlantelPorClave(r.Context(), conf.PlantelAlta)
+		if err != nil {
+			return nil, err
+		}
+		usuario.IDPlantel = &plantel.IDPlantel
+	}
+
+	password, err := autenticacion.PasswordAleatoria(usuario.Usuario)
+	if err != nil {
+		return nil, err
+	}
+	errores, err := h.validador.Usuario(r.Context(), usuario, password, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(errores) > 0 {
+		log.Printf("La cuenta institucional %q no cumple con los datos de usuario: %v", identidad.Sujeto, errores)
+		return nil, repository.ErrNotFound
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	usuario.Password = string(hash)
+
+	id, err := h.usuarios.Create(r.Context(), usuario)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Usuario %s dado de alta desde la cuenta institucional", usuario.Usuario)
+	return h.usuarios.GetByID(r.Context(), id)
+}
+
+// nombreUsuarioSSO propone preferred_username o la parte local del correo y,
+// si ya está ocupado, le agrega un número
+func (h *Handler) nombreUsuarioSSO(r *http.Request, identidad *sso.Identidad) (string, error) {
+	base := identidad.Usuario
+	if base == "" || caracterNoValidoUsuario.MatchString(base) {
+		base, _, _ = strings.Cut(identidad.Correo, "@")
+		base = caracterNoValidoUsuario.ReplaceAllString(base, "")
+	}
+	if len(base) > 45 {
+		base = base[:45]
+	}
+	if len(base) < 3 {
+		base = "usuario" + base
+	}
+
+	for i := 1; i <= 20; i++ {
+		candidato := base
+		if i > 1 {
+			candidato = base + strconv.Itoa(i)
+		}
+		existe, err := h.usuarios.ExisteUsuario(r.Context(), candidato, 0)
+		if err != nil {
+			return "", err
+		}
+		if !existe {
+			return candidato, nil
+		}
+	}
+	return "", errors.New("no hay un nombre de usuario disponible para " + base)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/sso"
+	"ues-egresados/internal/sso/ssoprueba"
+)
+
+// ssoPrueba conecta el handler con un proveedor OIDC en proceso que aprueba
+// sin formulario a jperez
+func ssoPrueba(t *testing.T, h *Handler, conf sso.Configuracion) *ssoprueba.Proveedor {
+	t.Helper()
+	idp, err := ssoprueba.Nuevo("http://localhost", "ues-egresados")
+	if err != nil {
+		t.Fatal(err)
+	}
+	servidor := httptest.NewServer(idp)
+	t.Cleanup(servidor.Close)
+	idp.Emisor = servidor.URL
+	idp.Auto = true
+	idp.Usuario = "jperez"
+	idp.Correo = "jperez@ues.mx"
+
+	conf.Emisor = servidor.URL
+	conf.ClientID = "ues-egresados"
+	conf.URLRetorno = "http://app.local/login/sso/callback"
+	conf.Scopes = []string{"openid", "email", "profile"}
+	h.sso = sso.NuevoProveedor(conf)
+	return idp
+}
+
+// iniciarSSO recorre LoginSSO y la autorización del proveedor; devuelve la
+// respuesta de LoginSSO (con la cookie de sesión) y la dirección de regreso
+func iniciarSSO(t *testing.T, h *Handler) (*httptest.ResponseRecorder, *url.URL) {
+	t.Helper()
+	ida := httptest.NewRecorder()
+	h.LoginSSO(ida, httptest.NewRequest(http.MethodGet, "/login/sso", nil))
+	if ida.Code != http.StatusFound {
+		t.Fatalf("LoginSSO = %d: %s", ida.Code, ida.Body.String())
+	}
+
+	navegador := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := navegador.Get(ida.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	regreso, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || regreso.Query().Get("code") == "" {
+		t.Fatalf("el proveedor no devolvió código: %q", resp.Header.Get("Location"))
+	}
+	return ida, regreso
+}
+
+func callbackSSO(h *Handler, ida *httptest.ResponseRecorder, regreso *url.URL) *httptest.ResponseRecorder {
+	regresoRec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/login/sso/callback?"+regreso.RawQuery, nil)
+	h.CallbackSSO(regresoRec, conCookies(r, ida))
+	return regresoRec
+}
+
+func TestCallbackSSORelacionaUsuarioExistente(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	ssoPrueba(t, h, sso.Configuracion{})
+	correo := "jperez@ues.mx"
+	if _, err := repos.Usuarios.Create(context.Background(), &models.Usuario{
+		Usuario: "juan.perez", Nombre: "Juan", Correo: &correo, Rol: auth.RolCoordinacion, Password: "x",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ida, regreso := iniciarSSO(t, h)
+	resp := callbackSSO(h, ida, regreso)
+	if resp.Code != http.StatusFound || resp.Header().Get("Location") != "/dashboard" {
+		t.Fatalf("CallbackSSO = %d %q: %s", resp.Code, resp.Header().Get("Location"), resp.Body.String())
+	}
+
+	// La misma solicitud no sirve dos veces
+	if repetido := callbackSSO(h, ida, regreso); repetido.Code != http.StatusBadRequest {
+		t.Errorf("segundo CallbackSSO = %d, se esperaba 400", repetido.Code)
+	}
+}
+
+func TestCallbackSSORechazaStateDistinto(t *testing.T) {
+	h, _ := handlerPrueba(t)
+	ssoPrueba(t, h, sso.Configuracion{})
+
+	ida, regreso := iniciarSSO(t, h)
+	consulta := regreso.Query()
+	consulta.Set("state", "alterado")
+	regreso.RawQuery = consulta.Encode()
+
+	if resp := callbackSSO(h, ida, regreso); resp.Code != http.StatusBadRequest {
+		t.Errorf("CallbackSSO con otro state = %d, se esperaba 400", resp.Code)
+	}
+}
+
+func TestCallbackSSORechazaSinSesion(t *testing.T) {
+	h, _ := handlerPrueba(t)
+	ssoPrueba(t, h, sso.Configuracion{})
+
+	_, regreso := iniciarSSO(t, h)
+	// Sin la cookie de la sesión en la que empezó el inicio de sesión
+	if resp := callbackSSO(h, httptest.NewRecorder(), regreso); resp.Code != http.StatusBadRequest {
+		t.Errorf("CallbackSSO sin sesión = %d, se esperaba 400", resp.Code)
+	}
+}
+
+func TestCallbackSSOSinAltaAutomatica(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	ssoPrueba(t, h, sso.Configuracion{})
+
+	ida, regreso := iniciarSSO(t, h)
+	if resp := callbackSSO(h, ida, regreso); resp.Code != http.StatusForbidden {
+		t.Fatalf("CallbackSSO de usuario desconocido = %d, se esperaba 403", resp.Code)
+	}
+	if _, err := repos.Usuarios.GetByUsuario(context.Background(), "jperez"); err != repository.ErrNotFound {
+		t.Errorf("GetByUsuario = %v, no se debía crear el usuario", err)
+	}
+}
+
+func TestCallbackSSOConAltaAutomatica(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	repos.Catalogos.(*repository.CatalogoMemory).AddPlantel(models.Plantel{IDPlantel: 3, Clave: "07", Nombre: "Culiacán"})
+	ssoPrueba(t, h, sso.Configuracion{AltaAutomatica: true, RolAlta: auth.RolConsulta, PlantelAlta: "07"})
+
+	ida, regreso := iniciarSSO(t, h)
+	resp := callbackSSO(h, ida, regreso)
+	if resp.Code != http.StatusFound {
+		t.Fatalf("CallbackSSO = %d: %s", resp.Code, resp.Body.String())
+	}
+
+	usuario, err := repos.Usuarios.GetByUsuario(context.Background(), "jperez")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usuario.Rol != auth.RolConsulta || usuario.IDPlantel == nil || *usuario.IDPlantel != 3 {
+		t.Errorf("usuario creado con rol %q y plantel %v", usuario.Rol, usuario.IDPlantel)
+	}
+	if usuario.Origen != models.OrigenOIDC {
+		t.Errorf("Origen = %q, se esperaba oidc", usuario.Origen)
+	}
+	if usuario.Correo == nil || *usuario.Correo != "jperez@ues.mx" {
+		t.Errorf("Correo = %v", usuario.Correo)
+	}
+}
+
+func TestCallbackSSOCorreoNoVerificadoNoRelaciona(t *testing.T) {
+	h, repos := handlerPrueba(t)
+	idp := ssoPrueba(t, h, sso.Configuracion{})
+	idp.Usuario = "otro"
+	idp.Claims = map[string]interface{}{"email_verified": false}
+	correo := "jperez@ues.mx"
+	if _, err := repos.Usuarios.Create(context.Background(), &models.Usuario{
+		Usuario: "juan.perez", Nombre: "Juan", Correo: &correo, Rol: auth.RolCoordinacion, Password: "x",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// El correo sin verificar no basta para entrar como juan.perez
+	ida, regreso := iniciarSSO(t, h)
+	if resp := callbackSSO(h, ida, regreso); resp.Code != http.StatusForbidden {
+		t.Errorf("CallbackSSO con correo no verificado = %d, se esperaba 403", resp.Code)
+	}
+}
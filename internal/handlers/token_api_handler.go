@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/middleware"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/tokensapi"
+	"ues-egresados/internal/utils"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxTokensAPI limita los tokens personales de cada usuario
+	maxTokensAPI = 20
+	// maxDiasVigenciaToken es la vigencia más larga que se puede pedir
+	maxDiasVigenciaToken = 365
+)
+
+// GetTokensAPI lista los tokens personales del usuario, sin el token mismo
+func (h *Handler) GetTokensAPI(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.tokensAPI.ListPorUsuario(r.Context(), idUsuarioSesion(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al obtener los tokens")
+		return
+	}
+
+	utils.SuccessResponse(w, "Tokens obtenidos correctamente", tokens)
+}
+
+// GetAlcancesTokenAPI devuelve los permisos del rol del usuario, que son los
+// alcances que puede dar a un token
+func (h *Handler) GetAlcancesTokenAPI(w http.ResponseWriter, r *http.Request) {
+	utils.SuccessResponse(w, "Alcances obtenidos correctamente", auth.PermisosDeRol(rolSesion(r)))
+}
+
+// CrearTokenAPI genera un token personal. El token solo se devuelve en esta
+// respuesta; después solo se guarda su hash.
+func (h *Handler) CrearTokenAPI(w http.ResponseWriter, r *http.Request) {
+	// Un token no debe servir para saltarse la verificación en dos pasos obligatoria
+	session, _ := config.SessionStore.Get(r, "session-name")
+	if pendiente, _ := session.Values[middleware.Configurar2FA].(bool); pendiente {
+		utils.ErrorResponse(w, http.StatusForbidden, "Debes activar la verificación en dos pasos para continuar")
+		return
+	}
+
+	var req models.CrearTokenAPIRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Datos inválidos")
+		return
+	}
+
+	idUsuario := idUsuarioSesion(r)
+	req.Nombre = strings.TrimSpace(req.Nombre)
+	alcances, errores := validarTokenAPI(&req, rolSesion(r))
+	if len(errores) > 0 {
+		utils.ValidationErrorResponse(w, errores)
+		return
+	}
+
+	existentes, err := h.tokensAPI.ListPorUsuario(r.Context(), idUsuario)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear el token")
+		return
+	}
+	if len(existentes) >= maxTokensAPI {
+		utils.ErrorResponse(w, http.StatusConflict, "Alcanzaste el máximo de "+strconv.Itoa(maxTokensAPI)+" tokens; revoca alguno para crear otro")
+		return
+	}
+
+	token, hash, prefijo, err := tokensapi.Nuevo()
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear el token")
+		return
+	}
+	nuevo := &models.TokenAPI{
+		IDUsuario: idUsuario,
+		Hash:      hash,
+		Nombre:    req.Nombre,
+		Prefijo:   prefijo,
+		Alcances:  alcances,
+	}
+	if req.DiasVigencia > 0 {
+		expira := time.Now().AddDate(0, 0, req.DiasVigencia)
+		nuevo.ExpiraEn = &expira
+	}
+
+	id, err := h.tokensAPI.Create(r.Context(), nuevo)
+	if err != nil {
+		log.Println("Error al guardar token personal:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al crear el token")
+		return
+	}
+	nuevo.IDToken = id
+	nuevo.CreatedAt = time.Now()
+
+	h.auditar(r, auditoria.EntidadTokenAPI, strconv.Itoa(id), auditoria.AccionCrear, nil, nuevo)
+	log.Printf("🔑 Token personal %d creado por el usuario %d", id, idUsuario)
+
+	utils.CreatedResponse(w, "Token creado; cópialo ahora, no se volverá a mostrar", map[string]interface{}{
+		"token":     token,
+		"token_api": nuevo,
+	})
+}
+
+// validarTokenAPI revisa el nombre, la vigencia y que cada alcance sea un
+// permiso del rol; devuelve los alcances sin repetir
+func validarTokenAPI(req *models.CrearTokenAPIRequest, rol string) ([]string, map[string]string) {
+	errores := map[string]string{}
+
+	if req.Nombre == "" {
+		errores["nombre"] = "El nombre es requerido"
+	} else if utf8.RuneCountInString(req.Nombre) > 100 {
+		errores["nombre"] = "El nombre no debe exceder 100 caracteres"
+	}
+
+	if req.DiasVigencia < 0 || req.DiasVigencia > maxDiasVigenciaToken {
+		errores["dias_vigencia"] = "La vigencia debe ser de 1 a " + strconv.Itoa(maxDiasVigenciaToken) + " días, o 0 para que no venza"
+	}
+
+	alcances := []string{}
+	vistos := map[string]bool{}
+	for _, alcance := range req.Alcances {
+		alcance = strings.TrimSpace(alcance)
+		if vistos[alcance] {
+			continue
+		}
+		if !auth.HasPermission(rol, auth.Permission(alcance)) {
+			errores["alcances"] = "Tu rol no tiene el permiso " + strconv.Quote(alcance)
+			break
+		}
+		vistos[alcance] = true
+		alcances = append(alcances, alcance)
+	}
+	if len(alcances) == 0 && errores["alcances"] == "" {
+		errores["alcances"] = "Elige al menos un alcance"
+	}
+
+	return alcances, errores
+}
+
+// RevocarTokenAPI elimina uno de los tokens personales del usuario
+func (h *Handler) RevocarTokenAPI(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	if err := h.tokensAPI.Delete(r.Context(), idUsuarioSesion(r), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.ErrorResponse(w, http.StatusNotFound, "Token no encontrado")
+			return
+		}
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error al revocar el token")
+		return
+	}
+
+	h.registrarAuditoria(r, auditoria.EntidadTokenAPI, strconv.Itoa(id), auditoria.AccionEliminar, nil)
+	utils.SuccessResponse(w, "Token revocado correctamente", nil)
+}
@@ -0,0 +1,402 @@
+package importacion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"ues-egresados/internal/matricula"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+	"ues-egresados/internal/validacion"
+)
+
+// Acciones posibles para cada fila del reporte
+const (
+	AccionCrear      = "crear"
+	AccionActualizar = "actualizar"
+	AccionError      = "error"
+	// AccionConflicto marca las matrículas en la papelera, que no se modifican
+	AccionConflicto = "conflicto"
+)
+
+// columnas mapea los encabezados aceptados (normalizados) al campo del egresado
+var columnas = map[string]string{
+	"matricula":          "matricula",
+	"nombre":             "nombre_completo",
+	"nombre completo":    "nombre_completo",
+	"nombre_completo":    "nombre_completo",
+	"genero":             "genero",
+	"sexo":               "genero",
+	"telefono":           "telefono",
+	"celular":            "telefono",
+	"correo":             "correo",
+	"email":              "correo",
+	"correo electronico": "correo",
+	"codigo postal":      "codigo_postal",
+	"codigo_postal":      "codigo_postal",
+	"cp":                 "codigo_postal",
+	"estado":             "estado",
+	"municipio":          "municipio",
+	"asentamiento":       "asentamiento",
+	"colonia":            "asentamiento",
+	"calle":              "calle",
+	"numero":             "numero",
+	"carrera":            "carrera",
+	"generacion":         "generacion",
+	"estatus":            "estatus",
+}
+
+var columnasObligatorias = []string{"matricula", "nombre_completo", "carrera", "generacion", "estatus"}
+
+// ResultadoFila describe lo que ocurrirá (o no) con una fila del archivo
+type ResultadoFila struct {
+	Fila      int      `json:"fila"`
+	Matricula string   `json:"matricula"`
+	Accion    string   `json:"accion"`
+	Errores   []string `json:"errores,omitempty"`
+}
+
+// Reporte resume la validación de un archivo de importación
+type Reporte struct {
+	DryRun     bool            `json:"dry_run"`
+	Total      int             `json:"total"`
+	Crear      int             `json:"crear"`
+	Actualizar int             `json:"actualizar"`
+	ConErrores int             `json:"con_errores"`
+	Conflictos int             `json:"conflictos"`
+	Aplicado   bool            `json:"aplicado"`
+	Filas      []ResultadoFila `json:"filas"`
+	validos    []models.Egresado
+	anteriores []models.Egresado
+}
+
+// Anteriores devuelve cómo estaban, antes de importar, los egresados que el
+// archivo actualiza
+func (r *Reporte) Anteriores() []models.Egresado {
+	return r.anteriores
+}
+
+// Importador valida y aplica archivos de egresados usando los repositorios
+type Importador struct {
+	egresados       repository.EgresadoRepository
+	catalogos       repository.CatalogoRepository
+	codigosPostales repository.CodigoPostalRepository
+}
+
+func NewImportador(repos *repository.Repositories) *Importador {
+	return &Importador{
+		egresados:       repos.Egresados,
+		catalogos:       repos.Catalogos,
+		codigosPostales: repos.CodigosPostales,
+	}
+}
+
+// Analizar valida cada fila de la tabla sin escribir en la base de datos.
+// Con soloPlantel distinto de 0 se rechazan las matrículas de otros planteles.
+func (imp *Importador) Analizar(ctx context.Context, tabla *Tabla, soloPlantel int) (*Reporte, error) {
+	indices, err := mapearColumnas(tabla.Encabezados)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := imp.nuevoResolvedor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolver.soloPlantel = soloPlantel
+
+	reporte := &Reporte{DryRun: true, Total: len(tabla.Filas), Filas: []ResultadoFila{}}
+	vistas := map[string]int{}
+	var candidatos []models.Egresado
+	var posiciones []int
+
+	for _, fila := range tabla.Filas {
+		valor := func(campo string) string {
+			i, ok := indices[campo]
+			if !ok || i >= len(fila.Celdas) {
+				return ""
+			}
+			return utils.SanitizeString(fila.Celdas[i])
+		}
+
+		e, errores := resolver.egresado(ctx, valor)
+		resultado := ResultadoFila{Fila: fila.Numero, Matricula: e.Matricula, Errores: errores}
+
+		if previa, ok := vistas[e.Matricula]; ok && e.Matricula != "" {
+			resultado.Errores = append(resultado.Errores, fmt.Sprintf("Matrícula repetida en la fila %d", previa))
+		} else {
+			vistas[e.Matricula] = fila.Numero
+		}
+
+		if len(resultado.Errores) > 0 {
+			resultado.Accion = AccionError
+			reporte.ConErrores++
+		} else {
+			candidatos = append(candidatos, e)
+			posiciones = append(posiciones, len(reporte.Filas))
+		}
+		reporte.Filas = append(reporte.Filas, resultado)
+	}
+
+	// Las matrículas existentes se actualizan con las celdas que traen valor;
+	// las que están en la papelera no se tocan
+	matriculas := make([]string, len(candidatos))
+	for i, e := range candidatos {
+		matriculas[i] = e.Matricula
+	}
+	existentes, err := imp.egresados.Existentes(ctx, matriculas)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar matrículas: %w", err)
+	}
+	activos, err := imp.egresados.Activos(ctx, matriculas)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener egresados existentes: %w", err)
+	}
+
+	for i, e := range candidatos {
+		fila := &reporte.Filas[posiciones[i]]
+		antes, activo := activos[e.Matricula]
+		switch {
+		case activo:
+			combinado, errores := resolver.combinar(ctx, antes, e)
+			if len(errores) > 0 {
+				fila.Accion = AccionError
+				fila.Errores = errores
+				reporte.ConErrores++
+				continue
+			}
+			fila.Accion = AccionActualizar
+			reporte.Actualizar++
+			reporte.validos = append(reporte.validos, combinado)
+			reporte.anteriores = append(reporte.anteriores, *antes)
+		case existentes[e.Matricula]:
+			fila.Accion = AccionConflicto
+			fila.Errores = []string{"La matrícula está en la papelera; restáurala para actualizarla"}
+			reporte.Conflictos++
+		default:
+			fila.Accion = AccionCrear
+			reporte.Crear++
+			reporte.validos = append(reporte.validos, e)
+		}
+	}
+
+	return reporte, nil
+}
+
+// ErrFilasInvalidas indica que el archivo tiene errores y no se puede aplicar
+var ErrFilasInvalidas = errors.New("el archivo contiene filas con errores")
+
+// Aplicar escribe todas las filas válidas del reporte en una sola transacción.
+// Si alguna fila tiene errores no se escribe nada; las filas en conflicto se
+// omiten. Si un egresado cambió después del análisis devuelve
+// repository.ErrConflictoVersion y tampoco se escribe nada.
+func (imp *Importador) Aplicar(ctx context.Context, reporte *Reporte) error {
+	if reporte.ConErrores > 0 {
+		return ErrFilasInvalidas
+	}
+
+	creados, actualizados, err := imp.egresados.Importar(ctx, reporte.validos)
+	if err != nil {
+		return err
+	}
+
+	reporte.DryRun = false
+	reporte.Aplicado = true
+	reporte.Crear = creados
+	reporte.Actualizar = actualizados
+	return nil
+}
+
+func mapearColumnas(encabezados []string) (map[string]int, error) {
+	indices := map[string]int{}
+	for i, encabezado := range encabezados {
+		if campo, ok := columnas[validacion.Normalizar(encabezado)]; ok {
+			if _, repetida := indices[campo]; !repetida {
+				indices[campo] = i
+			}
+		}
+	}
+
+	var faltantes []string
+	for _, campo := range columnasObligatorias {
+		if _, ok := indices[campo]; !ok {
+			faltantes = append(faltantes, campo)
+		}
+	}
+	if len(faltantes) > 0 {
+		return nil, fmt.Errorf("faltan columnas obligatorias: %s", strings.Join(faltantes, ", "))
+	}
+	return indices, nil
+}
+
+// resolvedor traduce nombres de catálogo a IDs y cachea los códigos postales
+type resolvedor struct {
+	planteles       map[string]int // clave de la matrícula → ID
+	soloPlantel     int
+	carreras        map[string]int
+	generaciones    map[string]int
+	estatus         map[string]int
+	codigosPostales repository.CodigoPostalRepository
+	cps             map[string]*models.ResultadoCodigoPostal
+}
+
+func (imp *Importador) nuevoResolvedor(ctx context.Context) (*resolvedor, error) {
+	planteles, err := imp.catalogos.ListPlanteles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener planteles: %w", err)
+	}
+	carreras, err := imp.catalogos.ListCarreras(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener carreras: %w", err)
+	}
+	generaciones, err := imp.catalogos.ListGeneraciones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener generaciones: %w", err)
+	}
+	estatus, err := imp.catalogos.ListEstatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener estatus: %w", err)
+	}
+
+	res := &resolvedor{
+		planteles:       map[string]int{},
+		carreras:        map[string]int{},
+		generaciones:    map[string]int{},
+		estatus:         map[string]int{},
+		codigosPostales: imp.codigosPostales,
+		cps:             map[string]*models.ResultadoCodigoPostal{},
+	}
+	for _, p := range planteles {
+		res.planteles[p.Clave] = p.IDPlantel
+	}
+	for _, c := range carreras {
+		res.carreras[validacion.Normalizar(c.Nombre)] = c.IDCarrera
+	}
+	for _, g := range generaciones {
+		res.generaciones[validacion.Normalizar(g.Periodo)] = g.IDGeneracion
+	}
+	for _, e := range estatus {
+		res.estatus[validacion.Normalizar(e.Descripcion)] = e.IDEstatus
+	}
+	return res, nil
+}
+
+// egresado construye y valida un egresado a partir de los valores de una fila
+func (res *resolvedor) egresado(ctx context.Context, valor func(string) string) (models.Egresado, []string) {
+	var errores []string
+	e := models.Egresado{
+		Matricula:      valor("matricula"),
+		NombreCompleto: valor("nombre_completo"),
+		Genero:         opcional(valor("genero")),
+		Telefono:       opcional(valor("telefono")),
+		Correo:         opcional(valor("correo")),
+		CodigoPostal:   opcional(valor("codigo_postal")),
+		Estado:         opcional(valor("estado")),
+		Municipio:      opcional(valor("municipio")),
+		Asentamiento:   opcional(valor("asentamiento")),
+		Calle:          opcional(valor("calle")),
+		Numero:         opcional(valor("numero")),
+	}
+
+	formato := validacion.Egresado(&e)
+	errores = append(errores, formato.Mensajes()...)
+
+	if m, err := matricula.Parse(e.Matricula); err == nil {
+		var ok bool
+		if e.IDPlantel, ok = res.planteles[m.Plantel]; !ok {
+			errores = append(errores, fmt.Sprintf("Plantel no registrado: %s", m.Plantel))
+		} else if res.soloPlantel != 0 && e.IDPlantel != res.soloPlantel {
+			errores = append(errores, "La matrícula pertenece a otro plantel")
+		}
+	}
+
+	var ok bool
+	if e.IDCarrera, ok = res.carreras[validacion.Normalizar(valor("carrera"))]; !ok {
+		errores = append(errores, fmt.Sprintf("Carrera no encontrada: %q", valor("carrera")))
+	}
+	if e.IDGeneracion, ok = res.generaciones[validacion.Normalizar(valor("generacion"))]; !ok {
+		errores = append(errores, fmt.Sprintf("Generación no encontrada: %q", valor("generacion")))
+	} else if _, invalida := formato["matricula"]; !invalida {
+		generacion := validacion.Errores{}
+		validacion.MatriculaDeGeneracion(&e, valor("generacion"), generacion)
+		errores = append(errores, generacion.Mensajes()...)
+	}
+	if e.IDEstatus, ok = res.estatus[validacion.Normalizar(valor("estatus"))]; !ok {
+		errores = append(errores, fmt.Sprintf("Estatus no encontrado: %q", valor("estatus")))
+	}
+
+	if _, invalido := formato["codigo_postal"]; e.CodigoPostal != nil && !invalido {
+		errores = append(errores, res.validarCodigoPostal(ctx, &e)...)
+	}
+
+	return e, errores
+}
+
+// combinar aplica las celdas de la fila sobre el egresado guardado. Las
+// celdas opcionales vacías conservan el dato actual en lugar de borrarlo.
+func (res *resolvedor) combinar(ctx context.Context, antes *models.Egresado, fila models.Egresado) (models.Egresado, []string) {
+	e := *antes
+	e.NombreCompleto = fila.NombreCompleto
+	e.IDCarrera = fila.IDCarrera
+	e.IDGeneracion = fila.IDGeneracion
+	e.IDEstatus = fila.IDEstatus
+	e.IDPlantel = fila.IDPlantel
+
+	for _, campo := range []struct {
+		destino **string
+		valor   *string
+	}{
+		{&e.Genero, fila.Genero},
+		{&e.Telefono, fila.Telefono},
+		{&e.Correo, fila.Correo},
+		{&e.CodigoPostal, fila.CodigoPostal},
+		{&e.Estado, fila.Estado},
+		{&e.Municipio, fila.Municipio},
+		{&e.Asentamiento, fila.Asentamiento},
+		{&e.Calle, fila.Calle},
+		{&e.Numero, fila.Numero},
+	} {
+		if campo.valor != nil {
+			*campo.destino = campo.valor
+		}
+	}
+
+	// Un estado o municipio sin código postal en la fila se compara con el guardado
+	if fila.CodigoPostal == nil && e.CodigoPostal != nil && (fila.Estado != nil || fila.Municipio != nil) {
+		if errores := res.validarCodigoPostal(ctx, &e); len(errores) > 0 {
+			return e, errores
+		}
+	}
+	return e, nil
+}
+
+// validarCodigoPostal verifica el CP contra el catálogo y completa estado y municipio
+func (res *resolvedor) validarCodigoPostal(ctx context.Context, e *models.Egresado) []string {
+	cp := *e.CodigoPostal
+	info, consultado := res.cps[cp]
+	if !consultado {
+		var err error
+		info, err = res.codigosPostales.BuscarPorCodigo(ctx, cp)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return []string{"Error al consultar el código postal"}
+		}
+		res.cps[cp] = info
+	}
+
+	if info == nil {
+		return []string{fmt.Sprintf("Código postal no encontrado: %s", cp)}
+	}
+
+	errores := validacion.Errores{}
+	validacion.CompararUbicacion(e, info, errores)
+	return errores.Mensajes()
+}
+
+func opcional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,125 @@
+package importacion
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// Tabla es el contenido de una hoja de cálculo: encabezados y filas de datos
+type Tabla struct {
+	Encabezados []string
+	Filas       []Fila
+}
+
+// Fila conserva el número de renglón original para el reporte
+type Fila struct {
+	Numero int
+	Celdas []string
+}
+
+// Leer interpreta un archivo CSV o XLSX según su extensión
+func Leer(nombre string, r io.Reader) (*Tabla, error) {
+	switch strings.ToLower(filepath.Ext(nombre)) {
+	case ".csv":
+		return leerCSV(r)
+	case ".xlsx":
+		return leerXLSX(r)
+	default:
+		return nil, fmt.Errorf("formato no soportado: use CSV o XLSX")
+	}
+}
+
+func leerCSV(r io.Reader) (*Tabla, error) {
+	contenido, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer archivo: %w", err)
+	}
+
+	// Quitar BOM de UTF-8 que agrega Excel
+	contenido = bytes.TrimPrefix(contenido, []byte("\xef\xbb\xbf"))
+
+	// Los CSV exportados desde Excel en Windows suelen venir en Windows-1252
+	if !utf8.Valid(contenido) {
+		contenido, _, err = transform.Bytes(charmap.Windows1252.NewDecoder(), contenido)
+		if err != nil {
+			return nil, fmt.Errorf("error al convertir codificación: %w", err)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(contenido))
+	reader.Comma = detectarSeparador(contenido)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	registros, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error al leer CSV: %w", err)
+	}
+	return nuevaTabla(registros)
+}
+
+func leerXLSX(r io.Reader) (*Tabla, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir XLSX: %w", err)
+	}
+	defer f.Close()
+
+	hojas := f.GetSheetList()
+	if len(hojas) == 0 {
+		return nil, fmt.Errorf("el archivo no tiene hojas")
+	}
+
+	// Se importa sólo la primera hoja
+	registros, err := f.GetRows(hojas[0])
+	if err != nil {
+		return nil, fmt.Errorf("error al leer hoja %s: %w", hojas[0], err)
+	}
+	return nuevaTabla(registros)
+}
+
+func nuevaTabla(registros [][]string) (*Tabla, error) {
+	if len(registros) == 0 {
+		return nil, fmt.Errorf("el archivo está vacío")
+	}
+
+	tabla := &Tabla{Encabezados: registros[0]}
+	for i, celdas := range registros[1:] {
+		if filaVacia(celdas) {
+			continue
+		}
+		// El renglón 1 es el encabezado
+		tabla.Filas = append(tabla.Filas, Fila{Numero: i + 2, Celdas: celdas})
+	}
+	return tabla, nil
+}
+
+// detectarSeparador elige entre "," y ";" (Excel en español usa punto y coma)
+func detectarSeparador(contenido []byte) rune {
+	primeraLinea := contenido
+	if i := bytes.IndexByte(contenido, '\n'); i >= 0 {
+		primeraLinea = contenido[:i]
+	}
+	if bytes.Count(primeraLinea, []byte(";")) > bytes.Count(primeraLinea, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+func filaVacia(fila []string) bool {
+	for _, celda := range fila {
+		if strings.TrimSpace(celda) != "" {
+			return false
+		}
+	}
+	return true
+}
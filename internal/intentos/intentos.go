@@ -0,0 +1,263 @@
+package intentos
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+)
+
+// Valores por defecto de la protección contra fuerza bruta
+const (
+	MaxFallidosUsuarioPorDefecto = 5
+	MaxFallidosIPPorDefecto      = 20
+	VentanaPorDefecto            = 15 * time.Minute
+	BloqueoPorDefecto            = 15 * time.Minute
+
+	MaxRestablecimientosCorreoPorDefecto = 3
+	MaxRestablecimientosIPPorDefecto     = 10
+	VentanaRestablecimientoPorDefecto    = time.Hour
+)
+
+// Opciones define cuántos fallos se toleran y por cuánto tiempo se bloquea
+type Opciones struct {
+	MaxFallidosUsuario int
+	MaxFallidosIP      int
+	Ventana            time.Duration
+	Bloqueo            time.Duration
+
+	// Solicitudes de restablecimiento de contraseña permitidas por correo y
+	// por IP dentro de VentanaRestablecimiento
+	MaxRestablecimientosCorreo int
+	MaxRestablecimientosIP     int
+	VentanaRestablecimiento    time.Duration
+}
+
+// OpcionesDesdeEntorno lee LOGIN_MAX_FALLIDOS_USUARIO, LOGIN_MAX_FALLIDOS_IP,
+// LOGIN_VENTANA_MINUTOS, LOGIN_BLOQUEO_MINUTOS, RESTABLECER_MAX_CORREO,
+// RESTABLECER_MAX_IP y RESTABLECER_VENTANA_MINUTOS
+func OpcionesDesdeEntorno() Opciones {
+	return Opciones{
+		MaxFallidosUsuario: enteroDesdeEntorno("LOGIN_MAX_FALLIDOS_USUARIO", MaxFallidosUsuarioPorDefecto),
+		MaxFallidosIP:      enteroDesdeEntorno("LOGIN_MAX_FALLIDOS_IP", MaxFallidosIPPorDefecto),
+		Ventana:            time.Duration(enteroDesdeEntorno("LOGIN_VENTANA_MINUTOS", int(VentanaPorDefecto.Minutes()))) * time.Minute,
+		Bloqueo:            time.Duration(enteroDesdeEntorno("LOGIN_BLOQUEO_MINUTOS", int(BloqueoPorDefecto.Minutes()))) * time.Minute,
+
+		MaxRestablecimientosCorreo: enteroDesdeEntorno("RESTABLECER_MAX_CORREO", MaxRestablecimientosCorreoPorDefecto),
+		MaxRestablecimientosIP:     enteroDesdeEntorno("RESTABLECER_MAX_IP", MaxRestablecimientosIPPorDefecto),
+		VentanaRestablecimiento:    time.Duration(enteroDesdeEntorno("RESTABLECER_VENTANA_MINUTOS", int(VentanaRestablecimientoPorDefecto.Minutes()))) * time.Minute,
+	}
+}
+
+func enteroDesdeEntorno(variable string, porDefecto int) int {
+	valor := os.Getenv(variable)
+	if valor == "" {
+		return porDefecto
+	}
+	n, err := strconv.Atoi(valor)
+	if err != nil || n <= 0 {
+		log.Printf("⚠️  %s inválido (%q), usando %d", variable, valor, porDefecto)
+		return porDefecto
+	}
+	return n
+}
+
+// NormalizarUsuario da la forma con la que se cuentan los intentos de un
+// usuario; el nombre de usuario no distingue mayúsculas en MySQL
+func NormalizarUsuario(usuario string) string {
+	usuario = strings.ToLower(strings.TrimSpace(usuario))
+	if runas := []rune(usuario); len(runas) > 50 {
+		usuario = string(runas[:50])
+	}
+	return usuario
+}
+
+// Limitador cuenta los inicios de sesión fallidos en una ventana deslizante
+// por usuario y por IP, y bloquea temporalmente al superar el máximo
+type Limitador struct {
+	repo     repository.IntentoLoginRepository
+	opciones Opciones
+
+	// noGuardados son los intentos que no se pudieron registrar, por
+	// "motivo:tipo:valor"; se suman al conteo mientras están en la ventana
+	mu          sync.Mutex
+	noGuardados map[string][]time.Time
+}
+
+func NuevoLimitador(repo repository.IntentoLoginRepository, opciones Opciones) *Limitador {
+	return &Limitador{repo: repo, opciones: opciones, noGuardados: map[string][]time.Time{}}
+}
+
+// Bloqueo devuelve el bloqueo vigente del usuario o de la IP (el que termine
+// después), o nil si puede intentar iniciar sesión
+func (l *Limitador) Bloqueo(ctx context.Context, usuario, ip string) (*models.BloqueoLogin, error) {
+	ahora := time.Now()
+	var vigente *models.BloqueoLogin
+	for _, b := range []struct{ tipo, valor string }{
+		{models.BloqueoPorUsuario, usuario},
+		{models.BloqueoPorIP, ip},
+	} {
+		bloqueo, err := l.repo.GetBloqueo(ctx, b.tipo, b.valor, ahora)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if vigente == nil || bloqueo.BloqueadoHasta.After(vigente.BloqueadoHasta) {
+			vigente = bloqueo
+		}
+	}
+	return vigente, nil
+}
+
+// RegistrarRechazo guarda un intento que se rechazó por un bloqueo vigente;
+// no cuenta como fallo para no prolongar el bloqueo
+func (l *Limitador) RegistrarRechazo(ctx context.Context, intento *models.IntentoLogin) error {
+	intento.Exitoso = false
+	intento.Motivo = models.MotivoBloqueado
+	return l.repo.Registrar(ctx, intento)
+}
+
+// RegistrarFallo guarda un intento con credenciales incorrectas y bloquea al
+// usuario o a la IP que alcancen el máximo de fallos dentro de la ventana.
+// Devuelve el bloqueo creado, si lo hubo. Si el intento no se pudo guardar,
+// el fallo cuenta de todos modos y el error se devuelve junto con el bloqueo.
+func (l *Limitador) RegistrarFallo(ctx context.Context, intento *models.IntentoLogin) (*models.BloqueoLogin, error) {
+	intento.Exitoso = false
+	intento.Motivo = models.MotivoCredenciales
+	ahora := time.Now()
+	// Un error al guardar no debe servir para seguir probando contraseñas
+	errRegistro := l.repo.Registrar(ctx, intento)
+	if errRegistro != nil {
+		l.recordarNoGuardado(intento, ahora)
+	}
+
+	var bloqueo *models.BloqueoLogin
+	for _, b := range []struct {
+		tipo, valor string
+		max         int
+	}{
+		{models.BloqueoPorUsuario, intento.Usuario, l.opciones.MaxFallidosUsuario},
+		{models.BloqueoPorIP, intento.IP, l.opciones.MaxFallidosIP},
+	} {
+		fallidos, err := l.repo.ContarFallidos(ctx, b.tipo, b.valor, models.MotivoCredenciales, ahora.Add(-l.opciones.Ventana))
+		if err != nil {
+			return nil, err
+		}
+		fallidos += l.contarNoGuardados(models.MotivoCredenciales, b.tipo, b.valor, ahora.Add(-l.opciones.Ventana))
+		if fallidos < b.max {
+			continue
+		}
+
+		nuevo := &models.BloqueoLogin{
+			Tipo:           b.tipo,
+			Valor:          b.valor,
+			Fallidos:       fallidos,
+			BloqueadoHasta: ahora.Add(l.opciones.Bloqueo),
+			CreatedAt:      ahora,
+		}
+		if err := l.repo.Bloquear(ctx, nuevo); err != nil {
+			return nil, err
+		}
+		log.Printf("🔒 Inicio de sesión bloqueado para %s %q hasta %s (%d fallos)",
+			b.tipo, b.valor, nuevo.BloqueadoHasta.Format(time.RFC3339), fallidos)
+		bloqueo = nuevo
+	}
+	return bloqueo, errRegistro
+}
+
+func (l *Limitador) recordarNoGuardado(intento *models.IntentoLogin, ahora time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, clave := range []string{
+		intento.Motivo + ":" + models.BloqueoPorUsuario + ":" + intento.Usuario,
+		intento.Motivo + ":" + models.BloqueoPorIP + ":" + intento.IP,
+	} {
+		l.noGuardados[clave] = append(l.noGuardados[clave], ahora)
+	}
+}
+
+// contarNoGuardados devuelve los intentos sin registrar desde el inicio de la
+// ventana y olvida los anteriores
+func (l *Limitador) contarNoGuardados(motivo, tipo, valor string, desde time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clave := motivo + ":" + tipo + ":" + valor
+	vigentes := l.noGuardados[clave][:0]
+	for _, t := range l.noGuardados[clave] {
+		if t.After(desde) {
+			vigentes = append(vigentes, t)
+		}
+	}
+	if len(vigentes) == 0 {
+		delete(l.noGuardados, clave)
+		return 0
+	}
+	l.noGuardados[clave] = vigentes
+	return len(vigentes)
+}
+
+// Restablecimiento registra una solicitud de restablecimiento de contraseña
+// para el correo en intento.Usuario. Si el correo o la IP ya hicieron el
+// máximo de solicitudes en la ventana, la registra como rechazada y devuelve
+// cuánto esperar; las rechazadas no cuentan, así la espera no se prolonga.
+func (l *Limitador) Restablecimiento(ctx context.Context, intento *models.IntentoLogin) (time.Duration, error) {
+	ahora := time.Now()
+	desde := ahora.Add(-l.opciones.VentanaRestablecimiento)
+	for _, b := range []struct {
+		tipo, valor string
+		max         int
+	}{
+		{models.BloqueoPorUsuario, intento.Usuario, l.opciones.MaxRestablecimientosCorreo},
+		{models.BloqueoPorIP, intento.IP, l.opciones.MaxRestablecimientosIP},
+	} {
+		solicitudes, err := l.repo.ContarFallidos(ctx, b.tipo, b.valor, models.MotivoRestablecimiento, desde)
+		if err != nil {
+			return 0, err
+		}
+		solicitudes += l.contarNoGuardados(models.MotivoRestablecimiento, b.tipo, b.valor, desde)
+		if solicitudes >= b.max {
+			if err := l.RegistrarRechazo(ctx, intento); err != nil {
+				log.Println("Error al registrar solicitud de restablecimiento:", err)
+			}
+			return l.opciones.VentanaRestablecimiento, nil
+		}
+	}
+
+	intento.Exitoso = false
+	intento.Motivo = models.MotivoRestablecimiento
+	// Igual que con los fallos, un error al guardar no debe liberar el límite
+	if err := l.repo.Registrar(ctx, intento); err != nil {
+		l.recordarNoGuardado(intento, ahora)
+		return 0, err
+	}
+	return 0, nil
+}
+
+// RegistrarExito guarda un inicio de sesión correcto y reinicia el conteo de
+// fallos del usuario. El de la IP se conserva: una IP que prueba muchas
+// cuentas no se libera por acertar en una.
+func (l *Limitador) RegistrarExito(ctx context.Context, intento *models.IntentoLogin) error {
+	intento.Exitoso = true
+	intento.Motivo = ""
+	if err := l.repo.Registrar(ctx, intento); err != nil {
+		return err
+	}
+	return l.repo.Descartar(ctx, models.BloqueoPorUsuario, intento.Usuario)
+}
+
+// Desbloquear levanta un bloqueo vigente y descarta sus fallos para que el
+// siguiente error no vuelva a bloquear de inmediato
+func (l *Limitador) Desbloquear(ctx context.Context, tipo, valor string) error {
+	if err := l.repo.Desbloquear(ctx, tipo, valor, time.Now()); err != nil {
+		return err
+	}
+	return l.repo.Descartar(ctx, tipo, valor)
+}
@@ -0,0 +1,137 @@
+package intentos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+)
+
+// repoSinEscritura no puede guardar intentos, como cuando falla el INSERT
+type repoSinEscritura struct {
+	repository.IntentoLoginRepository
+}
+
+var errInsert = errors.New("Data too long for column 'ip'")
+
+func (r repoSinEscritura) Registrar(ctx context.Context, i *models.IntentoLogin) error {
+	return errInsert
+}
+
+func opcionesPrueba() Opciones {
+	return Opciones{MaxFallidosUsuario: 3, MaxFallidosIP: 10, Ventana: time.Minute, Bloqueo: time.Minute}
+}
+
+func TestRegistrarFalloBloqueaAlMaximo(t *testing.T) {
+	l := NuevoLimitador(repository.NewIntentoLoginMemory(), opcionesPrueba())
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		bloqueo, err := l.RegistrarFallo(ctx, &models.IntentoLogin{Usuario: "ana", IP: "203.0.113.1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (bloqueo != nil) != (i == 3) {
+			t.Fatalf("fallo %d: bloqueo = %+v", i, bloqueo)
+		}
+	}
+	if b, err := l.Bloqueo(ctx, "ana", "198.51.100.1"); err != nil || b == nil || b.Tipo != models.BloqueoPorUsuario {
+		t.Errorf("Bloqueo = %+v, %v", b, err)
+	}
+}
+
+func TestRegistrarFalloCuentaAunqueNoSeGuarde(t *testing.T) {
+	l := NuevoLimitador(repoSinEscritura{repository.NewIntentoLoginMemory()}, opcionesPrueba())
+	ctx := context.Background()
+
+	var bloqueo *models.BloqueoLogin
+	for i := 1; i <= 3; i++ {
+		var err error
+		bloqueo, err = l.RegistrarFallo(ctx, &models.IntentoLogin{Usuario: "ana", IP: "203.0.113.1"})
+		if !errors.Is(err, errInsert) {
+			t.Fatalf("fallo %d: err = %v, se esperaba el error del INSERT", i, err)
+		}
+		if i < 3 && bloqueo != nil {
+			t.Fatalf("fallo %d: bloqueo antes de tiempo", i)
+		}
+	}
+	if bloqueo == nil || bloqueo.Tipo != models.BloqueoPorUsuario || bloqueo.Fallidos != 3 {
+		t.Fatalf("bloqueo = %+v, se esperaba el del usuario con 3 fallos", bloqueo)
+	}
+	if b, err := l.Bloqueo(ctx, "ana", "198.51.100.1"); err != nil || b == nil {
+		t.Errorf("Bloqueo = %+v, %v", b, err)
+	}
+}
+
+func TestFallosNoGuardadosVencenConLaVentana(t *testing.T) {
+	l := NuevoLimitador(repoSinEscritura{repository.NewIntentoLoginMemory()}, opcionesPrueba())
+	antes := time.Now().Add(-2 * time.Minute)
+	l.recordarNoGuardado(&models.IntentoLogin{Usuario: "ana", IP: "203.0.113.1", Motivo: models.MotivoCredenciales}, antes)
+	l.recordarNoGuardado(&models.IntentoLogin{Usuario: "ana", IP: "203.0.113.1", Motivo: models.MotivoCredenciales}, time.Now())
+
+	if n := l.contarNoGuardados(models.MotivoCredenciales, models.BloqueoPorUsuario, "ana", time.Now().Add(-time.Minute)); n != 1 {
+		t.Errorf("contarNoGuardados = %d, se esperaba 1", n)
+	}
+	if n := l.contarNoGuardados(models.MotivoCredenciales, models.BloqueoPorIP, "203.0.113.1", time.Now().Add(time.Second)); n != 0 {
+		t.Errorf("contarNoGuardados = %d, se esperaba 0", n)
+	}
+	if _, ok := l.noGuardados[models.MotivoCredenciales+":"+models.BloqueoPorIP+":203.0.113.1"]; ok {
+		t.Error("los fallos vencidos deben olvidarse")
+	}
+}
+
+func TestRestablecimientoLimitaPorCorreoYPorIP(t *testing.T) {
+	opciones := opcionesPrueba()
+	opciones.MaxRestablecimientosCorreo = 2
+	opciones.MaxRestablecimientosIP = 3
+	opciones.VentanaRestablecimiento = time.Hour
+	l := NuevoLimitador(repository.NewIntentoLoginMemory(), opciones)
+	ctx := context.Background()
+
+	solicitar := func(correo, ip string) time.Duration {
+		t.Helper()
+		espera, err := l.Restablecimiento(ctx, &models.IntentoLogin{Usuario: correo, IP: ip})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return espera
+	}
+
+	for i := 0; i < 2; i++ {
+		if espera := solicitar("ana@ues.mx", "203.0.113.1"); espera != 0 {
+			t.Fatalf("solicitud %d: espera = %v", i+1, espera)
+		}
+	}
+	if espera := solicitar("ana@ues.mx", "198.51.100.1"); espera != time.Hour {
+		t.Errorf("tercera solicitud del mismo correo: espera = %v, se esperaba 1h", espera)
+	}
+	if espera := solicitar("beto@ues.mx", "203.0.113.1"); espera != 0 {
+		t.Errorf("otro correo desde la misma IP: espera = %v", espera)
+	}
+	if espera := solicitar("carla@ues.mx", "203.0.113.1"); espera != time.Hour {
+		t.Errorf("cuarta solicitud desde la IP: espera = %v, se esperaba 1h", espera)
+	}
+
+	// Las solicitudes no cuentan como fallos de inicio de sesión
+	if b, err := l.Bloqueo(ctx, "ana@ues.mx", "203.0.113.1"); err != nil || b != nil {
+		t.Errorf("Bloqueo = %+v, %v", b, err)
+	}
+}
+
+func TestRestablecimientoCuentaAunqueNoSeGuarde(t *testing.T) {
+	opciones := opcionesPrueba()
+	opciones.MaxRestablecimientosCorreo = 1
+	opciones.MaxRestablecimientosIP = 10
+	opciones.VentanaRestablecimiento = time.Hour
+	l := NuevoLimitador(repoSinEscritura{repository.NewIntentoLoginMemory()}, opciones)
+	ctx := context.Background()
+
+	if _, err := l.Restablecimiento(ctx, &models.IntentoLogin{Usuario: "ana@ues.mx", IP: "203.0.113.1"}); !errors.Is(err, errInsert) {
+		t.Fatalf("err = %v, se esperaba el error del INSERT", err)
+	}
+	if espera, _ := l.Restablecimiento(ctx, &models.IntentoLogin{Usuario: "ana@ues.mx", IP: "203.0.113.1"}); espera == 0 {
+		t.Error("la solicitud no guardada debe contar para el límite")
+	}
+}
@@ -0,0 +1,132 @@
+// Package matricula interpreta la estructura de las matrículas de la UES:
+// PP AA NNNN, es decir, la clave del plantel (2 dígitos), los dos últimos
+// dígitos del año en que inicia la generación y un consecutivo (4 dígitos).
+// Por ejemplo, 13220030 es el alumno 30 de la generación 2022 del plantel 13.
+package matricula
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// Longitud es el número de dígitos de una matrícula
+	Longitud = 8
+	// MaxConsecutivo es el último consecutivo disponible por plantel y generación
+	MaxConsecutivo = 9999
+	// PlantelPorDefecto es la clave que se usa si no se configura PLANTEL_CLAVE
+	PlantelPorDefecto = "13"
+)
+
+var (
+	// ErrFormato indica que la matrícula no tiene la estructura PP AA NNNN
+	ErrFormato = errors.New("la matrícula debe tener 8 dígitos: plantel (2), año de la generación (2) y consecutivo (4)")
+	// ErrPlantel indica una clave de plantel que no es de 2 dígitos
+	ErrPlantel = errors.New("la clave del plantel debe tener 2 dígitos")
+	// ErrPeriodo indica que no se pudo leer el año inicial de la generación
+	ErrPeriodo = errors.New("el periodo de la generación debe iniciar con un año de 4 dígitos")
+	// ErrAgotado indica que ya se usaron todos los consecutivos
+	ErrAgotado = errors.New("ya no hay consecutivos disponibles para el plantel y la generación")
+)
+
+var (
+	patronMatricula = regexp.MustCompile(`^(\d{2})(\d{2})(\d{4})$`)
+	patronPlantel   = regexp.MustCompile(`^\d{2}$`)
+	patronPeriodo   = regexp.MustCompile(`^(\d{4})`)
+)
+
+// Matricula es una matrícula separada en sus partes
+type Matricula struct {
+	Plantel     string `json:"plantel"`
+	Anio        int    `json:"anio"` // dos últimos dígitos del año inicial de la generación
+	Consecutivo int    `json:"consecutivo"`
+}
+
+// Parse separa una matrícula en plantel, año y consecutivo. El consecutivo
+// 0000 no es válido.
+func Parse(s string) (Matricula, error) {
+	partes := patronMatricula.FindStringSubmatch(s)
+	if partes == nil {
+		return Matricula{}, ErrFormato
+	}
+
+	anio, _ := strconv.Atoi(partes[2])
+	consecutivo, _ := strconv.Atoi(partes[3])
+	if consecutivo == 0 {
+		return Matricula{}, ErrFormato
+	}
+	return Matricula{Plantel: partes[1], Anio: anio, Consecutivo: consecutivo}, nil
+}
+
+// Nueva arma la matrícula del plantel para la generación que inicia en
+// anioInicial
+func Nueva(plantel string, anioInicial, consecutivo int) (Matricula, error) {
+	if !patronPlantel.MatchString(plantel) {
+		return Matricula{}, ErrPlantel
+	}
+	if consecutivo < 1 {
+		return Matricula{}, ErrFormato
+	}
+	if consecutivo > MaxConsecutivo {
+		return Matricula{}, ErrAgotado
+	}
+	return Matricula{Plantel: plantel, Anio: anioInicial % 100, Consecutivo: consecutivo}, nil
+}
+
+func (m Matricula) String() string {
+	return fmt.Sprintf("%s%02d%04d", m.Plantel, m.Anio, m.Consecutivo)
+}
+
+// Prefijo son los primeros 4 dígitos, compartidos por todas las matrículas
+// del mismo plantel y generación
+func (m Matricula) Prefijo() string {
+	return m.String()[:4]
+}
+
+// Siguiente devuelve la matrícula con el consecutivo posterior
+func (m Matricula) Siguiente() (Matricula, error) {
+	if m.Consecutivo >= MaxConsecutivo {
+		return Matricula{}, ErrAgotado
+	}
+	m.Consecutivo++
+	return m, nil
+}
+
+// AnioInicial lee el año en que inicia una generación a partir de su
+// periodo (por ejemplo 2022 para "2022-2026")
+func AnioInicial(periodo string) (int, error) {
+	partes := patronPeriodo.FindStringSubmatch(periodo)
+	if partes == nil {
+		return 0, ErrPeriodo
+	}
+	anio, _ := strconv.Atoi(partes[1])
+	return anio, nil
+}
+
+// CorrespondeA indica si el año de la matrícula es el de la generación
+func (m Matricula) CorrespondeA(anioInicial int) bool {
+	return m.Anio == anioInicial%100
+}
+
+// ClaveValida indica si la clave de plantel tiene 2 dígitos
+func ClaveValida(clave string) bool {
+	return patronPlantel.MatchString(clave)
+}
+
+// PlantelDesdeEntorno lee PLANTEL_CLAVE, la clave de plantel que se propone
+// al generar matrículas
+func PlantelDesdeEntorno() string {
+	valor := os.Getenv("PLANTEL_CLAVE")
+	if valor == "" {
+		return PlantelPorDefecto
+	}
+	if !patronPlantel.MatchString(valor) {
+		log.Printf("⚠️  PLANTEL_CLAVE inválido (%q), usando %s", valor, PlantelPorDefecto)
+		return PlantelPorDefecto
+	}
+	return valor
+}
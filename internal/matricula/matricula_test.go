@@ -0,0 +1,129 @@
+package matricula
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	casos := []struct {
+		entrada string
+		quiere  Matricula
+		error   error
+	}{
+		{"13220030", Matricula{Plantel: "13", Anio: 22, Consecutivo: 30}, nil},
+		{"07009999", Matricula{Plantel: "07", Anio: 0, Consecutivo: 9999}, nil},
+		{"13990001", Matricula{Plantel: "13", Anio: 99, Consecutivo: 1}, nil},
+		{"13220000", Matricula{}, ErrFormato},
+		{"1322003", Matricula{}, ErrFormato},
+		{"132200301", Matricula{}, ErrFormato},
+		{"13-22-0030", Matricula{}, ErrFormato},
+		{"A3220030", Matricula{}, ErrFormato},
+		{"", Matricula{}, ErrFormato},
+	}
+
+	for _, c := range casos {
+		got, err := Parse(c.entrada)
+		if !errors.Is(err, c.error) || got != c.quiere {
+			t.Errorf("Parse(%q) = %+v, %v; quiere %+v, %v", c.entrada, got, err, c.quiere, c.error)
+		}
+	}
+}
+
+func TestNueva(t *testing.T) {
+	casos := []struct {
+		plantel     string
+		anio        int
+		consecutivo int
+		quiere      string
+		error       error
+	}{
+		{"13", 2022, 1, "13220001", nil},
+		{"13", 2022, 9999, "13229999", nil},
+		{"07", 1999, 42, "07990042", nil},
+		{"07", 2000, 42, "07000042", nil},
+		{"07", 2105, 42, "07050042", nil},
+		{"13", 2022, 0, "", ErrFormato},
+		{"13", 2022, 10000, "", ErrAgotado},
+		{"1", 2022, 1, "", ErrPlantel},
+		{"AB", 2022, 1, "", ErrPlantel},
+	}
+
+	for _, c := range casos {
+		m, err := Nueva(c.plantel, c.anio, c.consecutivo)
+		if !errors.Is(err, c.error) {
+			t.Errorf("Nueva(%q, %d, %d) error = %v, quiere %v", c.plantel, c.anio, c.consecutivo, err, c.error)
+			continue
+		}
+		if err == nil && m.String() != c.quiere {
+			t.Errorf("Nueva(%q, %d, %d) = %s, quiere %s", c.plantel, c.anio, c.consecutivo, m, c.quiere)
+		}
+	}
+}
+
+func TestSiguiente(t *testing.T) {
+	casos := []struct {
+		actual string
+		quiere string
+		error  error
+	}{
+		{"13220001", "13220002", nil},
+		{"13220999", "13221000", nil},
+		{"13229998", "13229999", nil},
+		{"13229999", "", ErrAgotado},
+		{"13999999", "", ErrAgotado},
+	}
+
+	for _, c := range casos {
+		m, err := Parse(c.actual)
+		if err != nil {
+			t.Fatal(err)
+		}
+		siguiente, err := m.Siguiente()
+		if !errors.Is(err, c.error) {
+			t.Errorf("Siguiente(%s) error = %v, quiere %v", c.actual, err, c.error)
+			continue
+		}
+		if err == nil && (siguiente.String() != c.quiere || siguiente.Prefijo() != m.Prefijo()) {
+			t.Errorf("Siguiente(%s) = %s, quiere %s", c.actual, siguiente, c.quiere)
+		}
+	}
+}
+
+func TestAnioInicialYCorrespondencia(t *testing.T) {
+	casos := []struct {
+		periodo   string
+		anio      int
+		error     error
+		matricula string
+		coincide  bool
+	}{
+		{"2022-2026", 2022, nil, "13220030", true},
+		{"2022-2026", 2022, nil, "13210030", false},
+		{"1999-2003", 1999, nil, "13990001", true},
+		{"2000-2004", 2000, nil, "13000001", true},
+		{"2000-2004", 2000, nil, "13990001", false},
+		{"2100-2104", 2100, nil, "13000001", true},
+		{"2023", 2023, nil, "13230001", true},
+		{"22-26", 0, ErrPeriodo, "", false},
+		{"Generación 2022", 0, ErrPeriodo, "", false},
+	}
+
+	for _, c := range casos {
+		anio, err := AnioInicial(c.periodo)
+		if !errors.Is(err, c.error) || anio != c.anio {
+			t.Errorf("AnioInicial(%q) = %d, %v; quiere %d, %v", c.periodo, anio, err, c.anio, c.error)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		m, err := Parse(c.matricula)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := m.CorrespondeA(anio); got != c.coincide {
+			t.Errorf("%s.CorrespondeA(%d) = %v, quiere %v", c.matricula, anio, got, c.coincide)
+		}
+	}
+}
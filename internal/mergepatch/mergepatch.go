@@ -0,0 +1,61 @@
+// Package mergepatch implementa JSON Merge Patch (RFC 7396)
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ContentType es el tipo de contenido registrado para JSON Merge Patch
+const ContentType = "application/merge-patch+json"
+
+// ErrNoEsObjeto indica que el parche no es un objeto JSON. El RFC permite
+// reemplazar el documento completo con otro valor, pero en la API un
+// parche siempre describe campos.
+var ErrNoEsObjeto = errors.New("el parche debe ser un objeto JSON")
+
+// Aplicar combina el parche con el documento original: los campos con null
+// se eliminan, los objetos se combinan recursivamente y cualquier otro
+// valor reemplaza al original.
+func Aplicar(original, parche []byte) ([]byte, error) {
+	var p map[string]interface{}
+	if err := json.Unmarshal(parche, &p); err != nil || p == nil {
+		return nil, ErrNoEsObjeto
+	}
+
+	var o interface{}
+	if err := json.Unmarshal(original, &o); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(combinar(o, p))
+}
+
+// Campos devuelve los nombres de primer nivel presentes en el parche
+func Campos(parche []byte) (map[string]json.RawMessage, error) {
+	var campos map[string]json.RawMessage
+	if err := json.Unmarshal(parche, &campos); err != nil || campos == nil {
+		return nil, ErrNoEsObjeto
+	}
+	return campos, nil
+}
+
+func combinar(destino interface{}, parche interface{}) interface{} {
+	p, ok := parche.(map[string]interface{})
+	if !ok {
+		return parche
+	}
+
+	d, ok := destino.(map[string]interface{})
+	if !ok {
+		d = map[string]interface{}{}
+	}
+	for campo, valor := range p {
+		if valor == nil {
+			delete(d, campo)
+			continue
+		}
+		d[campo] = combinar(d[campo], valor)
+	}
+	return d
+}
@@ -0,0 +1,70 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Ejemplos del apéndice A del RFC 7396
+func TestAplicarEjemplosRFC(t *testing.T) {
+	casos := []struct {
+		original, parche, esperado string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range casos {
+		resultado, err := Aplicar([]byte(c.original), []byte(c.parche))
+		if err != nil {
+			t.Errorf("%s + %s: %v", c.original, c.parche, err)
+			continue
+		}
+		if !mismoJSON(t, resultado, []byte(c.esperado)) {
+			t.Errorf("%s + %s = %s, se esperaba %s", c.original, c.parche, resultado, c.esperado)
+		}
+	}
+}
+
+// Los ejemplos del RFC cuyo parche no es un objeto reemplazan el documento
+// completo; la API los rechaza.
+func TestAplicarRechazaParchesQueNoSonObjetos(t *testing.T) {
+	casos := []struct {
+		original, parche string
+	}{
+		{`["a","b"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`},
+		{`{"a":"foo"}`, `null`},
+		{`{"a":"foo"}`, `"bar"`},
+	}
+	for _, c := range casos {
+		if _, err := Aplicar([]byte(c.original), []byte(c.parche)); !errors.Is(err, ErrNoEsObjeto) {
+			t.Errorf("%s + %s: err = %v, se esperaba ErrNoEsObjeto", c.original, c.parche, err)
+		}
+		if _, err := Campos([]byte(c.parche)); !errors.Is(err, ErrNoEsObjeto) {
+			t.Errorf("Campos(%s): err = %v, se esperaba ErrNoEsObjeto", c.parche, err)
+		}
+	}
+}
+
+func mismoJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("JSON inválido %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("JSON inválido %s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -4,10 +4,20 @@ import (
 	"log"
 	"net/http"
 	"ues-egresados/internal/config"
+	"ues-egresados/internal/tokensapi"
+	"ues-egresados/internal/utils"
 )
 
+// AuthRequired exige una sesión iniciada o un token personal en
+// "Authorization: Bearer". Sin ellas, las rutas de /api/ responden 401 en
+// JSON y las páginas redirigen al login.
 func AuthRequired(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := tokensapi.DeEncabezado(r); token != "" {
+			autenticarToken(w, r, next, token)
+			return
+		}
+
 		session, _ := config.SessionStore.Get(r, "session-name")
 
 		auth, ok := session.Values["authenticated"].(bool)
@@ -16,6 +26,11 @@ func AuthRequired(next http.Handler) http.Handler {
 		log.Printf("🔍 Auth check - Path: %s, Authenticated: %v, UserID: %d", r.URL.Path, auth, userID)
 
 		if !ok || !auth || !hasUserID || userID == 0 {
+			if isAPIRequest(r) {
+				log.Printf("❌ Acceso denegado - Sin sesión")
+				utils.ErrorResponse(w, http.StatusUnauthorized, "Inicia sesión para continuar")
+				return
+			}
 			log.Printf("❌ Acceso denegado - Redirigiendo a login")
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/utils"
+)
+
+const (
+	// CSRFHeader es el encabezado con el que fetchAPI envía el token
+	CSRFHeader = "X-CSRF-Token"
+	// CSRFCampo es el campo de los formularios HTML que envían el token
+	CSRFCampo = "csrf_token"
+
+	// cookieCSRFAnonima lleva el token de los formularios de quien aún no
+	// tiene sesión (login y restablecimiento de contraseña)
+	cookieCSRFAnonima = "csrf_anonimo"
+)
+
+// llaveCSRF firma el token de cookieCSRFAnonima
+var llaveCSRF = llaveAleatoria()
+
+// ConfigurarCSRF define la llave con la que se firman los tokens CSRF de los
+// visitantes sin sesión
+func ConfigurarCSRF(llave []byte) {
+	llaveCSRF = llave
+}
+
+// LlaveCSRFDesdeEntorno lee CSRF_LLAVE (32 bytes en base64). Sin ella se usa
+// una llave aleatoria: los formularios abiertos dejan de servir al reiniciar
+// y no se comparten entre instancias.
+func LlaveCSRFDesdeEntorno() []byte {
+	valor := strings.TrimSpace(os.Getenv("CSRF_LLAVE"))
+	if valor == "" {
+		log.Println("⚠️  CSRF_LLAVE no está definida; se usa una llave aleatoria por proceso")
+		return llaveAleatoria()
+	}
+	llave, err := base64.StdEncoding.DecodeString(valor)
+	if err != nil || len(llave) != 32 {
+		log.Println("⚠️  CSRF_LLAVE inválida (se esperan 32 bytes en base64); se usa una llave aleatoria por proceso")
+		return llaveAleatoria()
+	}
+	return llave
+}
+
+func llaveAleatoria() []byte {
+	llave := make([]byte, 32)
+	if _, err := rand.Read(llave); err != nil {
+		panic(err)
+	}
+	return llave
+}
+
+// TokenCSRF devuelve el token CSRF de la sesión y lo crea si aún no existe.
+// Las páginas lo publican en <meta name="csrf-token">. Sin sesión el token va
+// firmado en una cookie, así visitar el login no guarda nada en el servidor.
+func TokenCSRF(w http.ResponseWriter, r *http.Request) (string, error) {
+	session, _ := config.SessionStore.Get(r, "session-name")
+	if token, ok := session.Values["csrf_token"].(string); ok && token != "" {
+		return token, nil
+	}
+	if session.IsNew {
+		return tokenAnonimo(w, r)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	session.Values["csrf_token"] = token
+	if err := session.Save(r, w); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// tokenAnonimo devuelve el token de cookieCSRFAnonima, o crea uno y envía la
+// cookie si no hay o su firma no es válida
+func tokenAnonimo(w http.ResponseWriter, r *http.Request) (string, error) {
+	if token := leerTokenAnonimo(r); token != "" {
+		return token, nil
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieCSRFAnonima,
+		Value:    token + "." + firmarTokenCSRF(token),
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token, nil
+}
+
+// leerTokenAnonimo devuelve el token de cookieCSRFAnonima si su firma es
+// válida, o ""
+func leerTokenAnonimo(r *http.Request) string {
+	cookie, err := r.Cookie(cookieCSRFAnonima)
+	if err != nil {
+		return ""
+	}
+	token, firma, ok := strings.Cut(cookie.Value, ".")
+	if !ok || token == "" || !hmac.Equal([]byte(firma), []byte(firmarTokenCSRF(token))) {
+		return ""
+	}
+	return token
+}
+
+func firmarTokenCSRF(token string) string {
+	mac := hmac.New(sha256.New, llaveCSRF)
+	mac.Write([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CSRF rechaza con 403 las peticiones que modifican datos (todo salvo GET,
+// HEAD y OPTIONS) si no traen el token de la sesión en X-CSRF-Token o, en
+// formularios, en el campo csrf_token. Si la sesión no tiene token (quien aún
+// no inicia sesión) se compara con el de la cookie firmada. No aplica a las
+// peticiones con token personal.
+func CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Un token personal viaja en un encabezado que el navegador no envía
+		// por su cuenta, así que no hay riesgo de CSRF
+		if EsTokenAPI(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		session, _ := config.SessionStore.Get(r, "session-name")
+		esperado, _ := session.Values["csrf_token"].(string)
+		if esperado == "" {
+			esperado = leerTokenAnonimo(r)
+		}
+
+		formulario := strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded")
+		enviado := r.Header.Get(CSRFHeader)
+		if enviado == "" && formulario {
+			enviado = r.PostFormValue(CSRFCampo)
+		}
+
+		if esperado == "" || subtle.ConstantTimeCompare([]byte(enviado), []byte(esperado)) != 1 {
+			log.Printf("⛔ Token CSRF inválido - %s %s", r.Method, r.URL.Path)
+			if formulario && !isAPIRequest(r) {
+				http.Error(w, "403 - Token CSRF inválido; recarga la página", http.StatusForbidden)
+				return
+			}
+			utils.ErrorResponse(w, http.StatusForbidden, "Token CSRF inválido; recarga la página")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/utils"
+)
+
+// Configurar2FA marca en la sesión que el rol del usuario exige verificación
+// en dos pasos y aún no la ha activado
+const Configurar2FA = "configurar_2fa"
+
+// ExigirDosFactores limita las sesiones marcadas con Configurar2FA a la página
+// de seguridad y a las rutas /api/me/ hasta que el usuario active la
+// verificación en dos pasos
+func ExigirDosFactores(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, _ := config.SessionStore.Get(r, "session-name")
+		pendiente, _ := session.Values[Configurar2FA].(bool)
+
+		if !pendiente || r.URL.Path == "/seguridad" || strings.HasPrefix(r.URL.Path, "/api/me/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log.Printf("⛔ Verificación en dos pasos pendiente - Path: %s", r.URL.Path)
+		if isAPIRequest(r) {
+			utils.ErrorResponse(w, http.StatusForbidden, "Debes activar la verificación en dos pasos para continuar")
+			return
+		}
+		http.Redirect(w, r, "/seguridad", http.StatusSeeOther)
+	})
+}
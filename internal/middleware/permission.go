@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/utils"
+)
+
+// RequirePermission restringe el acceso a los usuarios cuyo rol tiene el
+// permiso indicado; con un token personal, el permiso debe estar además entre
+// sus alcances
+func RequirePermission(permiso auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !TienePermiso(r, permiso) {
+				session, _ := config.SessionStore.Get(r, "session-name")
+				rol, _ := session.Values["rol"].(string)
+				log.Printf("⛔ Permiso %s denegado - Path: %s, Rol: %q", permiso, r.URL.Path, rol)
+				if isAPIRequest(r) {
+					utils.ErrorResponse(w, http.StatusForbidden, "No tienes permiso para realizar esta acción")
+					return
+				}
+				http.Error(w, "403 - No tienes permiso para acceder a esta página", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithPermission envuelve un handler individual con RequirePermission
+func WithPermission(permiso auth.Permission, h http.HandlerFunc) http.Handler {
+	return RequirePermission(permiso)(h)
+}
+
+func isAPIRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/")
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"ues-egresados/internal/utils"
+)
+
+type contextKey string
+
+const claveNonce contextKey = "nonce_csp"
+
+// Modos de CSP_MODO
+const (
+	CSPAplicar     = "aplicar"
+	CSPReporte     = "reporte" // Content-Security-Policy-Report-Only
+	CSPDesactivado = "desactivado"
+)
+
+// Las vistas cargan Tailwind y Chart.js de CDN y las fuentes de Google. El CDN
+// de Tailwind inserta estilos en línea, por eso style-src permite
+// 'unsafe-inline'. Los <script> necesitan el nonce de la petición y las vistas
+// no usan atributos de evento (onclick), así que no hay script-src-attr.
+const politicaCSP = "default-src 'self'; " +
+	"script-src 'self' 'nonce-%s' https://cdn.tailwindcss.com https://cdn.jsdelivr.net; " +
+	"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; " +
+	"font-src 'self' https://fonts.gstatic.com; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"form-action 'self'; " +
+	"frame-ancestors 'none'"
+
+// OpcionesSeguridad son los encabezados que agrega EncabezadosSeguridad
+type OpcionesSeguridad struct {
+	CSP        string // CSPAplicar, CSPReporte o CSPDesactivado
+	CSPReporte string // report-uri opcional
+	// HSTSDias es el max-age de Strict-Transport-Security; 0 no lo envía.
+	// Solo se envía en peticiones por HTTPS.
+	HSTSDias          int
+	FrameOptions      string
+	ReferrerPolicy    string
+	PermissionsPolicy string
+}
+
+// OpcionesSeguridadDesdeEntorno lee CSP_MODO, CSP_REPORTE_URI, HSTS_DIAS,
+// FRAME_OPTIONS, REFERRER_POLICY y PERMISSIONS_POLICY. Una variable definida
+// como "ninguno" omite ese encabezado.
+func OpcionesSeguridadDesdeEntorno() OpcionesSeguridad {
+	op := OpcionesSeguridad{
+		CSP:               strings.ToLower(strings.TrimSpace(os.Getenv("CSP_MODO"))),
+		CSPReporte:        strings.TrimSpace(os.Getenv("CSP_REPORTE_URI")),
+		HSTSDias:          365,
+		FrameOptions:      encabezadoEntorno("FRAME_OPTIONS", "DENY"),
+		ReferrerPolicy:    encabezadoEntorno("REFERRER_POLICY", "strict-origin-when-cross-origin"),
+		PermissionsPolicy: encabezadoEntorno("PERMISSIONS_POLICY", "camera=(), microphone=(), geolocation=(), payment=(), usb=()"),
+	}
+	switch op.CSP {
+	case "":
+		op.CSP = CSPAplicar
+	case CSPAplicar, CSPReporte, CSPDesactivado:
+	default:
+		log.Printf("⚠️  CSP_MODO inválido (%q), usando %s", op.CSP, CSPAplicar)
+		op.CSP = CSPAplicar
+	}
+	if valor := os.Getenv("HSTS_DIAS"); valor != "" {
+		dias, err := strconv.Atoi(valor)
+		if err != nil || dias < 0 {
+			log.Printf("⚠️  HSTS_DIAS inválido (%q), usando %d", valor, op.HSTSDias)
+		} else {
+			op.HSTSDias = dias
+		}
+	}
+	return op
+}
+
+func encabezadoEntorno(variable, porDefecto string) string {
+	valor := strings.TrimSpace(os.Getenv(variable))
+	switch {
+	case valor == "":
+		return porDefecto
+	case strings.EqualFold(valor, "ninguno"):
+		return ""
+	}
+	return valor
+}
+
+// EncabezadosSeguridad agrega los encabezados de seguridad a todas las
+// respuestas y genera el nonce de CSP de la petición (ver NonceCSP). Detrás
+// del proxy de Fly.io la petición llega por HTTP; X-Forwarded-Proto indica si
+// el cliente usó HTTPS, y en ese caso también envía HSTS y marca las cookies
+// como Secure.
+func EncabezadosSeguridad(op OpcionesSeguridad) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+			if op.FrameOptions != "" {
+				h.Set("X-Frame-Options", op.FrameOptions)
+			}
+			if op.ReferrerPolicy != "" {
+				h.Set("Referrer-Policy", op.ReferrerPolicy)
+			}
+			if op.PermissionsPolicy != "" {
+				h.Set("Permissions-Policy", op.PermissionsPolicy)
+			}
+
+			if op.CSP != CSPDesactivado {
+				nonce, err := nuevoNonce()
+				if err != nil {
+					log.Println("Error al generar el nonce de CSP:", err)
+					http.Error(w, "Error en el servidor", http.StatusInternalServerError)
+					return
+				}
+				politica := fmt.Sprintf(politicaCSP, nonce)
+				if op.CSPReporte != "" {
+					politica += "; report-uri " + op.CSPReporte
+				}
+				encabezado := "Content-Security-Policy"
+				if op.CSP == CSPReporte {
+					encabezado = "Content-Security-Policy-Report-Only"
+				}
+				h.Set(encabezado, politica)
+				r = r.WithContext(context.WithValue(r.Context(), claveNonce, nonce))
+			}
+
+			if utils.EsHTTPS(r) {
+				if op.HSTSDias > 0 {
+					h.Set("Strict-Transport-Security", fmt.Sprintf("max-age=%d", op.HSTSDias*24*60*60))
+				}
+				w = &escritorCookiesSeguras{ResponseWriter: w}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NonceCSP devuelve el nonce que deben llevar los <script> de la página
+func NonceCSP(r *http.Request) string {
+	nonce, _ := r.Context().Value(claveNonce).(string)
+	return nonce
+}
+
+func nuevoNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	// Sin '+' ni '/', que html/template escaparía dentro del atributo
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// escritorCookiesSeguras agrega el atributo Secure a las cookies de la
+// respuesta justo antes de enviar los encabezados
+type escritorCookiesSeguras struct {
+	http.ResponseWriter
+	enviado bool
+}
+
+func (e *escritorCookiesSeguras) WriteHeader(status int) {
+	e.asegurarCookies()
+	e.ResponseWriter.WriteHeader(status)
+}
+
+func (e *escritorCookiesSeguras) Write(b []byte) (int, error) {
+	e.asegurarCookies()
+	return e.ResponseWriter.Write(b)
+}
+
+// Unwrap permite a http.ResponseController llegar al ResponseWriter original
+func (e *escritorCookiesSeguras) Unwrap() http.ResponseWriter {
+	return e.ResponseWriter
+}
+
+func (e *escritorCookiesSeguras) asegurarCookies() {
+	if e.enviado {
+		return
+	}
+	e.enviado = true
+
+	cookies := e.Header()["Set-Cookie"]
+	for i, cookie := range cookies {
+		if !cookieSegura(cookie) {
+			cookies[i] = cookie + "; Secure"
+		}
+	}
+}
+
+func cookieSegura(cookie string) bool {
+	for _, atributo := range strings.Split(cookie, ";") {
+		if strings.EqualFold(strings.TrimSpace(atributo), "Secure") {
+			return true
+		}
+	}
+	return false
+}
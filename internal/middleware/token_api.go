@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/config"
+	"ues-egresados/internal/sesiones"
+	"ues-egresados/internal/tokensapi"
+	"ues-egresados/internal/utils"
+)
+
+// AlcancesToken guarda en la sesión de un token personal los permisos que el
+// token puede usar
+const AlcancesToken = "alcances_token"
+
+// autenticarToken atiende una petición con "Authorization: Bearer". Arma en
+// memoria la sesión del usuario del token, con su rol y plantel vigentes, para
+// que los handlers la lean igual que una sesión del navegador. Los tokens solo
+// sirven en /api/ y no dan acceso a /api/me/, donde se administra la cuenta.
+func autenticarToken(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	if !isAPIRequest(r) {
+		rechazarToken(w, "Los tokens personales solo sirven para la API")
+		return
+	}
+
+	t, usuario, err := config.TokensAPI.Verificar(r.Context(), token, utils.ClientIP(r))
+	if err != nil {
+		if errors.Is(err, tokensapi.ErrTokenInvalido) {
+			log.Printf("❌ Token personal inválido - Path: %s", r.URL.Path)
+			rechazarToken(w, "Token inválido o vencido")
+			return
+		}
+		log.Printf("Error al verificar token personal: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Error en el servidor")
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/me/") {
+		utils.ErrorResponse(w, http.StatusForbidden, "Esta acción requiere iniciar sesión en el navegador")
+		return
+	}
+
+	// Se descartan los valores de una cookie que viniera en la misma petición
+	session, _ := config.SessionStore.Get(r, "session-name")
+	idPlantel := 0
+	if usuario.IDPlantel != nil {
+		idPlantel = *usuario.IDPlantel
+	}
+	session.ID = ""
+	session.Values = map[interface{}]interface{}{
+		sesiones.TokenAPI: t.IDToken,
+		AlcancesToken:     t.Alcances,
+		"authenticated":   true,
+		"user_id":         usuario.IDUsuario,
+		"username":        usuario.Usuario,
+		"nombre_completo": usuario.NombreCompleto(),
+		"rol":             usuario.Rol,
+		"id_plantel":      idPlantel,
+	}
+
+	log.Printf("🔑 Acceso con token personal %d del usuario %d - Path: %s", t.IDToken, usuario.IDUsuario, r.URL.Path)
+	next.ServeHTTP(w, r)
+}
+
+func rechazarToken(w http.ResponseWriter, mensaje string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	utils.ErrorResponse(w, http.StatusUnauthorized, mensaje)
+}
+
+// EsTokenAPI indica si la petición se autenticó con un token personal
+func EsTokenAPI(r *http.Request) bool {
+	session, _ := config.SessionStore.Get(r, "session-name")
+	_, ok := session.Values[sesiones.TokenAPI]
+	return ok
+}
+
+// TienePermiso indica si el usuario de la petición tiene el permiso en su
+// rol y, si usa un token personal, además entre los alcances del token
+func TienePermiso(r *http.Request, permiso auth.Permission) bool {
+	session, _ := config.SessionStore.Get(r, "session-name")
+	rol, _ := session.Values["rol"].(string)
+	if !auth.HasPermission(rol, permiso) {
+		return false
+	}
+
+	alcances, esToken := session.Values[AlcancesToken].([]string)
+	if !esToken {
+		return true
+	}
+	for _, alcance := range alcances {
+		if alcance == string(permiso) {
+			return true
+		}
+	}
+	return false
+}
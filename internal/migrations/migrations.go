@@ -0,0 +1,414 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+//go:embed sql/*.sql
+var archivos embed.FS
+
+// Dir es la ruta (relativa a la raíz del proyecto) donde viven los archivos SQL
+const Dir = "internal/migrations/sql"
+
+const lockName = "ues_egresados_migraciones"
+
+var nombreArchivo = regexp.MustCompile(`^(\d{4})_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration es un cambio de esquema numerado con su reversión
+type Migration struct {
+	Version int
+	Nombre  string
+	Up      string
+	Down    string
+}
+
+// Estado describe si una migración ya fue aplicada
+type Estado struct {
+	Version    int
+	Nombre     string
+	Aplicada   bool
+	AplicadaEn *time.Time
+}
+
+// Migrator aplica las migraciones embebidas sobre una base de datos MySQL
+type Migrator struct {
+	db          *sql.DB
+	migraciones []Migration
+}
+
+// New carga las migraciones embebidas en el binario
+func New(db *sql.DB) (*Migrator, error) {
+	migraciones, err := cargar(archivos, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migraciones: migraciones}, nil
+}
+
+func cargar(fsys fs.FS, dir string) ([]Migration, error) {
+	entradas, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer migraciones: %w", err)
+	}
+
+	porVersion := map[int]*Migration{}
+	for _, entrada := range entradas {
+		partes := nombreArchivo.FindStringSubmatch(entrada.Name())
+		if partes == nil {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", entrada.Name())
+		}
+
+		version, _ := strconv.Atoi(partes[1])
+		contenido, err := fs.ReadFile(fsys, path.Join(dir, entrada.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error al leer %s: %w", entrada.Name(), err)
+		}
+
+		m, ok := porVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Nombre: partes[2]}
+			porVersion[version] = m
+		} else if m.Nombre != partes[2] {
+			return nil, fmt.Errorf("versión %04d duplicada: %s y %s", version, m.Nombre, partes[2])
+		}
+
+		if partes[3] == "up" {
+			m.Up = string(contenido)
+		} else {
+			m.Down = string(contenido)
+		}
+	}
+
+	var migraciones []Migration
+	for _, m := range porVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("la migración %04d_%s no tiene archivo up", m.Version, m.Nombre)
+		}
+		migraciones = append(migraciones, *m)
+	}
+	sort.Slice(migraciones, func(i, j int) bool {
+		return migraciones[i].Version < migraciones[j].Version
+	})
+
+	return migraciones, nil
+}
+
+// Up aplica todas las migraciones pendientes y devuelve cuántas se aplicaron
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	aplicadas := 0
+	err := m.conLock(ctx, func(conn *sql.Conn) error {
+		hechas, err := versionesAplicadas(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(hechas) == 0 {
+			if err := verificarEsquemaVacio(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		for _, mig := range m.migraciones {
+			if _, ok := hechas[mig.Version]; ok {
+				continue
+			}
+
+			log.Printf("⬆️  Aplicando migración %04d_%s", mig.Version, mig.Nombre)
+			if err := ejecutar(ctx, conn, mig.Up); err != nil {
+				return fmt.Errorf("migración %04d_%s: %w", mig.Version, mig.Nombre, err)
+			}
+			if _, err := conn.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, nombre) VALUES (?, ?)",
+				mig.Version, mig.Nombre,
+			); err != nil {
+				return fmt.Errorf("error al registrar migración %04d: %w", mig.Version, err)
+			}
+			aplicadas++
+		}
+		return nil
+	})
+	return aplicadas, err
+}
+
+// Down revierte las últimas "pasos" migraciones aplicadas
+func (m *Migrator) Down(ctx context.Context, pasos int) (int, error) {
+	revertidas := 0
+	err := m.conLock(ctx, func(conn *sql.Conn) error {
+		hechas, err := versionesAplicadas(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migraciones) - 1; i >= 0 && revertidas < pasos; i-- {
+			mig := m.migraciones[i]
+			if _, ok := hechas[mig.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(mig.Down) == "" {
+				return fmt.Errorf("la migración %04d_%s no tiene archivo down", mig.Version, mig.Nombre)
+			}
+
+			log.Printf("⬇️  Revirtiendo migración %04d_%s", mig.Version, mig.Nombre)
+			if err := ejecutar(ctx, conn, mig.Down); err != nil {
+				return fmt.Errorf("reversión %04d_%s: %w", mig.Version, mig.Nombre, err)
+			}
+			if _, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version); err != nil {
+				return fmt.Errorf("error al desregistrar migración %04d: %w", mig.Version, err)
+			}
+			revertidas++
+		}
+		return nil
+	})
+	return revertidas, err
+}
+
+// Baseline registra como aplicadas, sin ejecutarlas, todas las migraciones hasta
+// version inclusive. Sirve para adoptar una base de datos cuyo esquema se creó
+// antes de existir las migraciones; devuelve cuántas se registraron.
+func (m *Migrator) Baseline(ctx context.Context, version int) (int, error) {
+	conocida := false
+	for _, mig := range m.migraciones {
+		if mig.Version == version {
+			conocida = true
+			break
+		}
+	}
+	if !conocida {
+		return 0, fmt.Errorf("no existe la migración %04d", version)
+	}
+
+	registradas := 0
+	err := m.conLock(ctx, func(conn *sql.Conn) error {
+		hechas, err := versionesAplicadas(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migraciones {
+			if mig.Version > version {
+				break
+			}
+			if _, ok := hechas[mig.Version]; ok {
+				continue
+			}
+
+			log.Printf("📌 Marcando migración %04d_%s como aplicada", mig.Version, mig.Nombre)
+			if _, err := conn.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, nombre) VALUES (?, ?)",
+				mig.Version, mig.Nombre,
+			); err != nil {
+				return fmt.Errorf("error al registrar migración %04d: %w", mig.Version, err)
+			}
+			registradas++
+		}
+		return nil
+	})
+	return registradas, err
+}
+
+// Status lista todas las migraciones conocidas y si ya fueron aplicadas
+func (m *Migrator) Status(ctx context.Context) ([]Estado, error) {
+	var estados []Estado
+	err := m.conLock(ctx, func(conn *sql.Conn) error {
+		hechas, err := versionesAplicadas(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migraciones {
+			e := Estado{Version: mig.Version, Nombre: mig.Nombre}
+			if aplicadaEn, ok := hechas[mig.Version]; ok {
+				e.Aplicada = true
+				e.AplicadaEn = &aplicadaEn
+			}
+			estados = append(estados, e)
+		}
+		return nil
+	})
+	return estados, err
+}
+
+// conLock ejecuta fn en una conexión dedicada que retiene un candado de MySQL,
+// de modo que varias instancias arrancando a la vez no migren en paralelo.
+func (m *Migrator) conLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error al obtener conexión: %w", err)
+	}
+	defer conn.Close()
+
+	var obtenido sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 30)", lockName).Scan(&obtenido); err != nil {
+		return fmt.Errorf("error al obtener candado de migraciones: %w", err)
+	}
+	if !obtenido.Valid || obtenido.Int64 != 1 {
+		return fmt.Errorf("otra instancia está aplicando migraciones")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT NOT NULL,
+			nombre VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (version)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`); err != nil {
+		return fmt.Errorf("error al crear tabla schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func versionesAplicadas(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	hechas := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var aplicadaEn time.Time
+		if err := rows.Scan(&version, &aplicadaEn); err != nil {
+			return nil, err
+		}
+		hechas[version] = aplicadaEn
+	}
+	return hechas, rows.Err()
+}
+
+// verificarEsquemaVacio evita aplicar la migración inicial sobre una base de
+// datos que ya tiene las tablas pero aún no registra versiones.
+func verificarEsquemaVacio(ctx context.Context, conn *sql.Conn) error {
+	var tablas int
+	if err := conn.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = 'egresados'
+	`).Scan(&tablas); err != nil {
+		return fmt.Errorf("error al inspeccionar el esquema: %w", err)
+	}
+	if tablas > 0 {
+		return fmt.Errorf("la base de datos ya tiene tablas pero schema_migrations está vacía; " +
+			"ejecute \"migrate baseline <versión>\" con la última migración que refleja el esquema actual")
+	}
+	return nil
+}
+
+// ejecutar corre cada sentencia del script por separado. MySQL confirma el DDL
+// de forma implícita, por lo que una migración fallida puede quedar a medias.
+func ejecutar(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, sentencia := range dividirSentencias(script) {
+		if _, err := conn.ExecContext(ctx, sentencia); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dividirSentencias separa un script en sentencias por los ";" que quedan
+// fuera de cadenas, identificadores entre comillas y comentarios. Los
+// comentarios (--, # y /* */) se descartan.
+func dividirSentencias(script string) []string {
+	var sentencias []string
+	var actual strings.Builder
+
+	terminar := func() {
+		if sentencia := strings.TrimSpace(actual.String()); sentencia != "" {
+			sentencias = append(sentencias, sentencia)
+		}
+		actual.Reset()
+	}
+
+	texto := []rune(script)
+	for i := 0; i < len(texto); i++ {
+		c := texto[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// Copiar la cadena completa; \x escapa dentro de '' y "", y la
+			// comilla doble ('') se lee como cerrar y volver a abrir
+			actual.WriteRune(c)
+			for i++; i < len(texto); i++ {
+				actual.WriteRune(texto[i])
+				if texto[i] == '\\' && c != '`' && i+1 < len(texto) {
+					i++
+					actual.WriteRune(texto[i])
+					continue
+				}
+				if texto[i] == c {
+					break
+				}
+			}
+
+		case c == '#' || (c == '-' && i+2 < len(texto) && texto[i+1] == '-' && unicode.IsSpace(texto[i+2])):
+			for i < len(texto) && texto[i] != '\n' {
+				i++
+			}
+			actual.WriteRune('\n')
+
+		case c == '/' && i+1 < len(texto) && texto[i+1] == '*':
+			i += 2
+			for i+1 < len(texto) && !(texto[i] == '*' && texto[i+1] == '/') {
+				i++
+			}
+			i++ // queda sobre "/"
+			actual.WriteRune(' ')
+
+		case c == ';':
+			terminar()
+
+		default:
+			actual.WriteRune(c)
+		}
+	}
+	terminar()
+
+	return sentencias
+}
+
+// Create genera el par de archivos up/down de una nueva migración en dir
+func Create(dir, nombre string) (string, string, error) {
+	nombre = strings.ToLower(strings.TrimSpace(nombre))
+	nombre = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(nombre, "_")
+	nombre = strings.Trim(nombre, "_")
+	if nombre == "" {
+		return "", "", fmt.Errorf("el nombre de la migración es obligatorio")
+	}
+
+	existentes, err := cargar(os.DirFS(dir), ".")
+	if err != nil {
+		return "", "", err
+	}
+
+	siguiente := 1
+	if len(existentes) > 0 {
+		siguiente = existentes[len(existentes)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", siguiente, nombre)
+	up := filepath.Join(dir, base+".up.sql")
+	down := filepath.Join(dir, base+".down.sql")
+
+	if err := os.WriteFile(up, []byte("-- "+base+"\n\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(down, []byte("-- Revierte "+base+"\n\n"), 0o644); err != nil {
+		return "", "", err
+	}
+
+	return up, down, nil
+}
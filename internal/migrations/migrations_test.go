@@ -0,0 +1,371 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDividirSentencias(t *testing.T) {
+	casos := []struct {
+		nombre string
+		script string
+		quiere []string
+	}{
+		{
+			nombre: "una sentencia en varias líneas",
+			script: "CREATE TABLE t (\n    id INT,\n    nombre VARCHAR(10)\n);\n",
+			quiere: []string{"CREATE TABLE t (\n    id INT,\n    nombre VARCHAR(10)\n)"},
+		},
+		{
+			nombre: "varias sentencias en una línea",
+			script: "DELETE FROM a; DELETE FROM b;",
+			quiere: []string{"DELETE FROM a", "DELETE FROM b"},
+		},
+		{
+			nombre: "última sentencia sin punto y coma",
+			script: "SELECT 1;\nSELECT 2",
+			quiere: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			nombre: "punto y coma dentro de cadenas",
+			script: "INSERT INTO t VALUES ('a;b', \"c;d\");\nSELECT 1;",
+			quiere: []string{"INSERT INTO t VALUES ('a;b', \"c;d\")", "SELECT 1"},
+		},
+		{
+			nombre: "comillas escapadas y duplicadas",
+			script: "INSERT INTO t VALUES ('it''s;', 'a\\';b');\nSELECT 2;",
+			quiere: []string{"INSERT INTO t VALUES ('it''s;', 'a\\';b')", "SELECT 2"},
+		},
+		{
+			nombre: "identificador entre acentos graves",
+			script: "SELECT `a;b` FROM t;",
+			quiere: []string{"SELECT `a;b` FROM t"},
+		},
+		{
+			nombre: "comentarios de línea con punto y coma",
+			script: "-- crea; la tabla\nCREATE TABLE t (id INT); # fin; de verdad\n-- otra;\n",
+			quiere: []string{"CREATE TABLE t (id INT)"},
+		},
+		{
+			nombre: "comentario de bloque con punto y coma",
+			script: "/* a; b */ SELECT 1 /* c;\n d */;\nSELECT 2;",
+			quiere: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			nombre: "guiones que no son comentario",
+			script: "SELECT 5--1;",
+			quiere: []string{"SELECT 5--1"},
+		},
+		{
+			nombre: "solo comentarios",
+			script: "-- Revierte 0001\n\n/* nada */\n",
+			quiere: nil,
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			if got := dividirSentencias(c.script); !reflect.DeepEqual(got, c.quiere) {
+				t.Errorf("dividirSentencias(%q)\n got %q\nwant %q", c.script, got, c.quiere)
+			}
+		})
+	}
+}
+
+func TestCargarEmparejaUpYDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_indices.up.sql":   {Data: []byte("CREATE INDEX i ON t (id);")},
+		"sql/0001_tablas.up.sql":    {Data: []byte("CREATE TABLE t (id INT);")},
+		"sql/0001_tablas.down.sql":  {Data: []byte("DROP TABLE t;")},
+		"sql/0002_indices.down.sql": {Data: []byte("DROP INDEX i ON t;")},
+	}
+
+	migraciones, err := cargar(fsys, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	quiere := []Migration{
+		{Version: 1, Nombre: "tablas", Up: "CREATE TABLE t (id INT);", Down: "DROP TABLE t;"},
+		{Version: 2, Nombre: "indices", Up: "CREATE INDEX i ON t (id);", Down: "DROP INDEX i ON t;"},
+	}
+	if !reflect.DeepEqual(migraciones, quiere) {
+		t.Errorf("cargar = %+v, quiere %+v", migraciones, quiere)
+	}
+}
+
+func TestCargarRechazaArchivosInconsistentes(t *testing.T) {
+	casos := []struct {
+		nombre   string
+		archivos fstest.MapFS
+		error    string
+	}{
+		{
+			nombre:   "down sin up",
+			archivos: fstest.MapFS{"sql/0001_tablas.down.sql": {Data: []byte("DROP TABLE t;")}},
+			error:    "no tiene archivo up",
+		},
+		{
+			nombre: "versión con dos nombres",
+			archivos: fstest.MapFS{
+				"sql/0001_tablas.up.sql":  {Data: []byte("SELECT 1;")},
+				"sql/0001_otras.down.sql": {Data: []byte("SELECT 1;")},
+			},
+			error: "duplicada",
+		},
+		{
+			nombre:   "nombre inválido",
+			archivos: fstest.MapFS{"sql/1_tablas.sql": {Data: []byte("SELECT 1;")}},
+			error:    "nombre de migración inválido",
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			_, err := cargar(c.archivos, "sql")
+			if err == nil || !strings.Contains(err.Error(), c.error) {
+				t.Errorf("error = %v, quiere %q", err, c.error)
+			}
+		})
+	}
+}
+
+func TestMigracionesEmbebidasTienenReversion(t *testing.T) {
+	m, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, mig := range m.migraciones {
+		if mig.Version != i+1 {
+			t.Errorf("versión %04d_%s fuera de secuencia (esperaba %04d)", mig.Version, mig.Nombre, i+1)
+		}
+		if strings.TrimSpace(mig.Down) == "" {
+			t.Errorf("%04d_%s no tiene archivo down", mig.Version, mig.Nombre)
+		}
+		if len(dividirSentencias(mig.Up)) == 0 {
+			t.Errorf("%04d_%s no tiene sentencias", mig.Version, mig.Nombre)
+		}
+	}
+}
+
+func TestUpSinCandadoNoEjecutaNada(t *testing.T) {
+	bd := &bdPrueba{candadoOcupado: true}
+	m := migradorPrueba(bd)
+
+	if _, err := m.Up(context.Background()); err == nil || !strings.Contains(err.Error(), "otra instancia") {
+		t.Fatalf("error = %v, quiere candado ocupado", err)
+	}
+	if len(bd.ejecutadas) > 0 {
+		t.Errorf("se ejecutaron sentencias sin el candado: %q", bd.ejecutadas)
+	}
+}
+
+func TestUpAplicaPendientesEnOrdenYLiberaCandado(t *testing.T) {
+	bd := &bdPrueba{aplicadas: map[int]bool{1: true}}
+	m := migradorPrueba(bd)
+
+	n, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("aplicadas = %d, quiere 2", n)
+	}
+	quiere := []string{"CREATE TABLE b (id INT)", "INSERT INTO b VALUES (1)", "CREATE TABLE c (id INT)"}
+	if !reflect.DeepEqual(bd.ejecutadas, quiere) {
+		t.Errorf("ejecutadas = %q, quiere %q", bd.ejecutadas, quiere)
+	}
+	if !bd.aplicadas[2] || !bd.aplicadas[3] {
+		t.Errorf("no se registraron las versiones: %v", bd.aplicadas)
+	}
+	if !bd.candadoLiberado {
+		t.Error("no se liberó el candado")
+	}
+}
+
+func TestUpDetieneEnLaMigracionFallida(t *testing.T) {
+	bd := &bdPrueba{fallar: "CREATE TABLE c"}
+	m := migradorPrueba(bd)
+
+	n, err := m.Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "0003_c") {
+		t.Fatalf("error = %v, quiere fallo en 0003_c", err)
+	}
+	if n != 2 || bd.aplicadas[3] {
+		t.Errorf("aplicadas = %d (%v), quiere 2 sin registrar la 3", n, bd.aplicadas)
+	}
+	if !bd.candadoLiberado {
+		t.Error("no se liberó el candado")
+	}
+}
+
+func TestUpRechazaEsquemaSinRegistrar(t *testing.T) {
+	bd := &bdPrueba{tablasExistentes: true}
+	m := migradorPrueba(bd)
+
+	if _, err := m.Up(context.Background()); err == nil || !strings.Contains(err.Error(), "baseline") {
+		t.Fatalf("error = %v, quiere sugerencia de baseline", err)
+	}
+	if len(bd.ejecutadas) > 0 {
+		t.Errorf("se ejecutaron sentencias sobre el esquema existente: %q", bd.ejecutadas)
+	}
+}
+
+func TestBaselineRegistraSinEjecutar(t *testing.T) {
+	bd := &bdPrueba{tablasExistentes: true}
+	m := migradorPrueba(bd)
+
+	n, err := m.Baseline(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || !bd.aplicadas[1] || !bd.aplicadas[2] || bd.aplicadas[3] {
+		t.Errorf("registradas = %d (%v), quiere 1 y 2", n, bd.aplicadas)
+	}
+	if len(bd.ejecutadas) > 0 {
+		t.Errorf("baseline ejecutó sentencias: %q", bd.ejecutadas)
+	}
+
+	// Después del baseline, up aplica solo lo posterior
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if quiere := []string{"CREATE TABLE c (id INT)"}; !reflect.DeepEqual(bd.ejecutadas, quiere) {
+		t.Errorf("ejecutadas = %q, quiere %q", bd.ejecutadas, quiere)
+	}
+
+	if _, err := m.Baseline(context.Background(), 9); err == nil {
+		t.Error("se aceptó una versión inexistente")
+	}
+}
+
+func TestDownRevierteLasUltimas(t *testing.T) {
+	bd := &bdPrueba{aplicadas: map[int]bool{1: true, 2: true, 3: true}}
+	m := migradorPrueba(bd)
+
+	n, err := m.Down(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("revertidas = %d, quiere 2", n)
+	}
+	if quiere := []string{"DROP TABLE c", "DROP TABLE b"}; !reflect.DeepEqual(bd.ejecutadas, quiere) {
+		t.Errorf("ejecutadas = %q, quiere %q", bd.ejecutadas, quiere)
+	}
+	if !bd.aplicadas[1] || bd.aplicadas[2] || bd.aplicadas[3] {
+		t.Errorf("aplicadas = %v, quiere solo la 1", bd.aplicadas)
+	}
+}
+
+func migradorPrueba(bd *bdPrueba) *Migrator {
+	if bd.aplicadas == nil {
+		bd.aplicadas = map[int]bool{}
+	}
+	return &Migrator{
+		db: sql.OpenDB(bd),
+		migraciones: []Migration{
+			{Version: 1, Nombre: "a", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+			{Version: 2, Nombre: "b", Up: "-- dos sentencias\nCREATE TABLE b (id INT);\nINSERT INTO b VALUES (1);", Down: "DROP TABLE b;"},
+			{Version: 3, Nombre: "c", Up: "CREATE TABLE c (id INT);", Down: "DROP TABLE c;"},
+		},
+	}
+}
+
+// bdPrueba simula lo que el Migrator usa de MySQL: el candado, la tabla
+// schema_migrations y information_schema. Registra el resto de sentencias.
+type bdPrueba struct {
+	mu               sync.Mutex
+	candadoOcupado   bool
+	candadoLiberado  bool
+	tablasExistentes bool
+	fallar           string
+	aplicadas        map[int]bool
+	ejecutadas       []string
+}
+
+func (b *bdPrueba) Connect(context.Context) (driver.Conn, error) { return conexionPrueba{b}, nil }
+func (b *bdPrueba) Driver() driver.Driver                        { return nil }
+
+type conexionPrueba struct{ bd *bdPrueba }
+
+func (c conexionPrueba) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare no soportado")
+}
+func (c conexionPrueba) Close() error              { return nil }
+func (c conexionPrueba) Begin() (driver.Tx, error) { return nil, errors.New("sin transacciones") }
+
+func (c conexionPrueba) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	b := c.bd
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "RELEASE_LOCK"):
+		b.candadoLiberado = true
+	case strings.Contains(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		b.aplicadas[int(args[0].Value.(int64))] = true
+	case strings.HasPrefix(query, "DELETE FROM schema_migrations"):
+		delete(b.aplicadas, int(args[0].Value.(int64)))
+	default:
+		if b.fallar != "" && strings.Contains(query, b.fallar) {
+			return nil, errors.New("error de sintaxis")
+		}
+		b.ejecutadas = append(b.ejecutadas, query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c conexionPrueba) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	b := c.bd
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case strings.Contains(query, "GET_LOCK"):
+		obtenido := int64(1)
+		if b.candadoOcupado {
+			obtenido = 0
+		}
+		return &filasPrueba{columnas: []string{"obtenido"}, filas: [][]driver.Value{{obtenido}}}, nil
+	case strings.Contains(query, "information_schema"):
+		tablas := int64(0)
+		if b.tablasExistentes {
+			tablas = 1
+		}
+		return &filasPrueba{columnas: []string{"tablas"}, filas: [][]driver.Value{{tablas}}}, nil
+	case strings.Contains(query, "FROM schema_migrations"):
+		filas := &filasPrueba{columnas: []string{"version", "applied_at"}}
+		for version := range b.aplicadas {
+			filas.filas = append(filas.filas, []driver.Value{int64(version), time.Now()})
+		}
+		return filas, nil
+	}
+	return nil, errors.New("consulta no soportada: " + query)
+}
+
+type filasPrueba struct {
+	columnas []string
+	filas    [][]driver.Value
+}
+
+func (f *filasPrueba) Columns() []string { return f.columnas }
+func (f *filasPrueba) Close() error      { return nil }
+
+func (f *filasPrueba) Next(dest []driver.Value) error {
+	if len(f.filas) == 0 {
+		return io.EOF
+	}
+	copy(dest, f.filas[0])
+	f.filas = f.filas[1:]
+	return nil
+}
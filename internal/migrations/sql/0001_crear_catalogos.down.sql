@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS estatus;
+DROP TABLE IF EXISTS generaciones;
+DROP TABLE IF EXISTS carreras;
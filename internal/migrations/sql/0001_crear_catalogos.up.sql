@@ -0,0 +1,22 @@
+-- Catálogos de carreras, generaciones y estatus
+
+CREATE TABLE carreras (
+    id_carrera INT NOT NULL AUTO_INCREMENT,
+    nombre VARCHAR(150) NOT NULL,
+    PRIMARY KEY (id_carrera),
+    UNIQUE KEY uk_carreras_nombre (nombre)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE generaciones (
+    id_generacion INT NOT NULL AUTO_INCREMENT,
+    periodo VARCHAR(20) NOT NULL,
+    PRIMARY KEY (id_generacion),
+    UNIQUE KEY uk_generaciones_periodo (periodo)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE estatus (
+    id_estatus INT NOT NULL AUTO_INCREMENT,
+    descripcion VARCHAR(50) NOT NULL,
+    PRIMARY KEY (id_estatus),
+    UNIQUE KEY uk_estatus_descripcion (descripcion)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
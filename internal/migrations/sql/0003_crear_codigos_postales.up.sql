@@ -0,0 +1,13 @@
+-- Catálogo de códigos postales de SEPOMEX (se llena con cmd/import_cp)
+
+CREATE TABLE codigos_postales (
+    id INT NOT NULL AUTO_INCREMENT,
+    d_codigo VARCHAR(5) NOT NULL,
+    d_asenta VARCHAR(200) NOT NULL,
+    d_mnpio VARCHAR(150) NOT NULL,
+    d_estado VARCHAR(100) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_codigo (d_codigo),
+    KEY idx_estado_municipio (d_estado, d_mnpio),
+    KEY idx_asenta (d_asenta)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,29 @@
+-- Egresados con sus relaciones a los catálogos
+
+CREATE TABLE egresados (
+    matricula VARCHAR(8) NOT NULL,
+    nombre_completo VARCHAR(200) NOT NULL,
+    genero VARCHAR(20) NULL,
+    telefono VARCHAR(20) NULL,
+    correo VARCHAR(150) NULL,
+    codigo_postal VARCHAR(5) NULL,
+    estado VARCHAR(100) NULL,
+    municipio VARCHAR(150) NULL,
+    asentamiento VARCHAR(200) NULL,
+    calle VARCHAR(200) NULL,
+    numero VARCHAR(20) NULL,
+    id_carrera INT NOT NULL,
+    id_generacion INT NOT NULL,
+    id_estatus INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (matricula),
+    KEY idx_egresados_generacion_carrera (id_generacion, id_carrera),
+    KEY idx_egresados_carrera (id_carrera),
+    KEY idx_egresados_estatus (id_estatus),
+    KEY idx_egresados_nombre (nombre_completo),
+    KEY idx_egresados_estado_municipio (estado, municipio),
+    KEY idx_egresados_created_at (created_at),
+    CONSTRAINT fk_egresados_carrera FOREIGN KEY (id_carrera) REFERENCES carreras (id_carrera),
+    CONSTRAINT fk_egresados_generacion FOREIGN KEY (id_generacion) REFERENCES generaciones (id_generacion),
+    CONSTRAINT fk_egresados_estatus FOREIGN KEY (id_estatus) REFERENCES estatus (id_estatus)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+DELETE FROM estatus WHERE id_estatus IN (1, 2, 3);
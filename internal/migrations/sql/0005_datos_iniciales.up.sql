@@ -0,0 +1,7 @@
+-- Estatus base. El administrador inicial se crea al arrancar con
+-- ADMIN_INICIAL_PASSWORD; ver README.
+
+INSERT INTO estatus (id_estatus, descripcion) VALUES
+    (1, 'Activo'),
+    (2, 'Inactivo'),
+    (3, 'Nuevo');
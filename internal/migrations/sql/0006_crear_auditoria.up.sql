@@ -0,0 +1,18 @@
+-- Bitácora de cambios: quién modificó qué y desde dónde.
+-- id_usuario no tiene llave foránea para conservar el registro aunque el usuario se elimine.
+
+CREATE TABLE auditoria (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NULL,
+    usuario VARCHAR(50) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    entidad VARCHAR(30) NOT NULL,
+    entidad_id VARCHAR(50) NOT NULL DEFAULT '',
+    accion VARCHAR(20) NOT NULL,
+    cambios JSON NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY idx_auditoria_entidad (entidad, entidad_id, created_at),
+    KEY idx_auditoria_usuario (id_usuario, created_at),
+    KEY idx_auditoria_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
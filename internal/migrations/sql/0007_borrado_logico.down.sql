@@ -0,0 +1,13 @@
+-- Los registros en la papelera se eliminan definitivamente al revertir
+DELETE FROM egresados WHERE deleted_at IS NOT NULL;
+DELETE FROM usuarios WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE egresados
+    DROP KEY idx_egresados_deleted_at,
+    DROP COLUMN deleted_at,
+    DROP COLUMN deleted_by;
+
+ALTER TABLE usuarios
+    DROP KEY idx_usuarios_deleted_at,
+    DROP COLUMN deleted_at,
+    DROP COLUMN deleted_by;
@@ -0,0 +1,11 @@
+-- Borrado lógico: los registros eliminados pasan a la papelera
+
+ALTER TABLE egresados
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN deleted_by INT NULL,
+    ADD KEY idx_egresados_deleted_at (deleted_at);
+
+ALTER TABLE usuarios
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN deleted_by INT NULL,
+    ADD KEY idx_usuarios_deleted_at (deleted_at);
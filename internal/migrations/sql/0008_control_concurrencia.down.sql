@@ -0,0 +1,7 @@
+ALTER TABLE egresados
+    DROP COLUMN version,
+    DROP COLUMN updated_at;
+
+ALTER TABLE usuarios
+    DROP COLUMN version,
+    DROP COLUMN updated_at;
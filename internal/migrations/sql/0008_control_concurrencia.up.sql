@@ -0,0 +1,9 @@
+-- Control de concurrencia optimista: cada actualización incrementa la versión
+
+ALTER TABLE egresados
+    ADD COLUMN version INT NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
+
+ALTER TABLE usuarios
+    ADD COLUMN version INT NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
@@ -0,0 +1,11 @@
+ALTER TABLE usuarios DROP FOREIGN KEY fk_usuarios_plantel;
+ALTER TABLE egresados DROP FOREIGN KEY fk_egresados_plantel;
+
+ALTER TABLE usuarios
+    DROP COLUMN id_plantel;
+
+ALTER TABLE egresados
+    DROP KEY idx_egresados_plantel,
+    DROP COLUMN id_plantel;
+
+DROP TABLE planteles;
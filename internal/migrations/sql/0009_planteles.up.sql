@@ -0,0 +1,44 @@
+-- Planteles: la clave de 2 dígitos es el inicio de la matrícula de sus egresados
+
+CREATE TABLE planteles (
+    id_plantel INT NOT NULL AUTO_INCREMENT,
+    clave CHAR(2) NOT NULL,
+    nombre VARCHAR(150) NOT NULL,
+    PRIMARY KEY (id_plantel),
+    UNIQUE KEY uk_planteles_clave (clave),
+    UNIQUE KEY uk_planteles_nombre (nombre)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Hasta ahora solo existía el plantel 13; se registran también las claves
+-- que ya aparecen en las matrículas
+INSERT INTO planteles (clave, nombre) VALUES ('13', 'Plantel 13');
+
+INSERT IGNORE INTO planteles (clave, nombre)
+SELECT DISTINCT LEFT(matricula, 2), CONCAT('Plantel ', LEFT(matricula, 2))
+FROM egresados
+WHERE matricula REGEXP '^[0-9]{8}$';
+
+ALTER TABLE egresados
+    ADD COLUMN id_plantel INT NULL AFTER matricula;
+
+UPDATE egresados e
+JOIN planteles p ON p.clave = LEFT(e.matricula, 2)
+SET e.id_plantel = p.id_plantel;
+
+-- Las matrículas anteriores sin el formato PP AA NNNN quedan en el plantel 13
+UPDATE egresados
+SET id_plantel = (SELECT id_plantel FROM planteles WHERE clave = '13')
+WHERE id_plantel IS NULL;
+
+ALTER TABLE egresados
+    MODIFY id_plantel INT NOT NULL,
+    ADD KEY idx_egresados_plantel (id_plantel),
+    ADD CONSTRAINT fk_egresados_plantel FOREIGN KEY (id_plantel) REFERENCES planteles (id_plantel);
+
+-- Los usuarios sin rol global solo ven su plantel; los existentes quedan en el 13
+ALTER TABLE usuarios
+    ADD COLUMN id_plantel INT NULL AFTER rol,
+    ADD CONSTRAINT fk_usuarios_plantel FOREIGN KEY (id_plantel) REFERENCES planteles (id_plantel);
+
+UPDATE usuarios
+SET id_plantel = (SELECT id_plantel FROM planteles WHERE clave = '13');
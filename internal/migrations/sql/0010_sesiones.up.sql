@@ -0,0 +1,18 @@
+-- Sesiones del lado del servidor: la cookie solo lleva un token aleatorio y
+-- aquí se guarda su hash SHA-256, de modo que una sesión puede revocarse.
+-- Las sesiones de un usuario se eliminan con él al purgarlo de la papelera.
+
+CREATE TABLE sesiones (
+    id_sesion CHAR(64) NOT NULL,
+    id_usuario INT NULL,
+    datos BLOB NOT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ultima_actividad TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expira_en TIMESTAMP NOT NULL,
+    PRIMARY KEY (id_sesion),
+    KEY idx_sesiones_usuario (id_usuario),
+    KEY idx_sesiones_expira_en (expira_en),
+    CONSTRAINT fk_sesiones_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
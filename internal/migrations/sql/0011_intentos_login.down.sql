@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bloqueos_login;
+DROP TABLE IF EXISTS intentos_login;
@@ -0,0 +1,30 @@
+-- Intentos de inicio de sesión y bloqueos temporales por usuario o por IP.
+-- usuario guarda lo que se escribió en el formulario aunque no exista, por
+-- eso id_usuario no tiene llave foránea (igual que en auditoria).
+-- descartado excluye un fallo del conteo tras un inicio exitoso o un desbloqueo.
+
+CREATE TABLE intentos_login (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    usuario VARCHAR(50) NOT NULL DEFAULT '',
+    id_usuario INT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    exitoso BOOLEAN NOT NULL,
+    motivo VARCHAR(20) NOT NULL DEFAULT '',
+    descartado BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY idx_intentos_login_usuario (usuario, created_at),
+    KEY idx_intentos_login_ip (ip, created_at),
+    KEY idx_intentos_login_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE bloqueos_login (
+    tipo VARCHAR(10) NOT NULL,
+    valor VARCHAR(50) NOT NULL,
+    fallidos INT NOT NULL,
+    bloqueado_hasta TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tipo, valor),
+    KEY idx_bloqueos_login_hasta (bloqueado_hasta)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
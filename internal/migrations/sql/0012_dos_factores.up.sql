@@ -0,0 +1,26 @@
+-- Verificación en dos pasos (TOTP). El secreto se guarda al iniciar la
+-- configuración y activo pasa a TRUE cuando el usuario confirma un código.
+-- ultimo_paso evita que un mismo código se use dos veces.
+
+CREATE TABLE dos_factores (
+    id_usuario INT NOT NULL,
+    secreto VARCHAR(64) NOT NULL,
+    activo BOOLEAN NOT NULL DEFAULT FALSE,
+    ultimo_paso BIGINT NOT NULL DEFAULT 0,
+    activado_en TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_usuario),
+    CONSTRAINT fk_dos_factores_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Códigos de recuperación de un solo uso; solo se guarda su hash SHA-256
+CREATE TABLE codigos_recuperacion (
+    id INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NOT NULL,
+    codigo_hash CHAR(64) NOT NULL,
+    usado_en TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_codigos_recuperacion (id_usuario, codigo_hash),
+    CONSTRAINT fk_codigos_recuperacion_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
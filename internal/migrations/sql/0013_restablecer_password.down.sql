@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS tokens_password;
+
+ALTER TABLE usuarios
+    DROP KEY uk_usuarios_correo,
+    DROP COLUMN correo;
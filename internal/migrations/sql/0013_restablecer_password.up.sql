@@ -0,0 +1,19 @@
+-- Correo de los usuarios, al que se envían los enlaces para restablecer la contraseña
+
+ALTER TABLE usuarios
+    ADD COLUMN correo VARCHAR(150) NULL AFTER apellido_materno,
+    ADD UNIQUE KEY uk_usuarios_correo (correo);
+
+-- Enlaces para restablecer la contraseña. Solo se guarda el hash SHA-256 del
+-- token que viaja en el correo; cada uno sirve una vez y vence en expira_en.
+CREATE TABLE tokens_password (
+    id_token CHAR(64) NOT NULL,
+    id_usuario INT NOT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    expira_en TIMESTAMP NOT NULL,
+    usado_en TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_token),
+    KEY idx_tokens_password_usuario (id_usuario),
+    CONSTRAINT fk_tokens_password_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
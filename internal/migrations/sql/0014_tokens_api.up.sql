@@ -0,0 +1,20 @@
+-- Tokens personales para usar la API desde scripts e integraciones. Solo se
+-- guarda el hash SHA-256 del token; prefijo permite reconocerlo en el listado.
+-- alcances es la lista, separada por comas, de permisos que el token puede
+-- usar dentro de los del rol del usuario.
+CREATE TABLE tokens_api (
+    id_token INT NOT NULL AUTO_INCREMENT,
+    id_usuario INT NOT NULL,
+    hash CHAR(64) NOT NULL,
+    nombre VARCHAR(100) NOT NULL,
+    prefijo VARCHAR(16) NOT NULL,
+    alcances VARCHAR(500) NOT NULL,
+    expira_en TIMESTAMP NULL,
+    ultimo_uso TIMESTAMP NULL,
+    ultima_ip VARCHAR(45) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_token),
+    UNIQUE KEY uk_tokens_api_hash (hash),
+    KEY idx_tokens_api_usuario (id_usuario),
+    CONSTRAINT fk_tokens_api_usuario FOREIGN KEY (id_usuario) REFERENCES usuarios (id_usuario) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
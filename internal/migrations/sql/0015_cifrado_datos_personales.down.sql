@@ -0,0 +1,22 @@
+-- Antes de revertir hay que dejar los datos en texto plano con
+-- go run ./cmd/rotate-keys -descifrar; si queda algún valor cifrado la
+-- conversión a utf8mb4 falla y la migración no se aplica.
+
+ALTER TABLE egresados
+    DROP INDEX idx_egresados_estado_municipio_indice,
+    DROP INDEX idx_egresados_correo_indice,
+    DROP INDEX idx_egresados_telefono_indice,
+    DROP COLUMN municipio_indice,
+    DROP COLUMN estado_indice,
+    DROP COLUMN correo_indice,
+    DROP COLUMN telefono_indice,
+    DROP COLUMN llave_datos,
+    MODIFY telefono VARCHAR(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+    MODIFY correo VARCHAR(150) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+    MODIFY codigo_postal VARCHAR(5) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+    MODIFY estado VARCHAR(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+    MODIFY municipio VARCHAR(150) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+    MODIFY asentamiento VARCHAR(200) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+    MODIFY calle VARCHAR(200) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+    MODIFY numero VARCHAR(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL,
+    ADD KEY idx_egresados_estado_municipio (estado, municipio);
@@ -0,0 +1,26 @@
+-- Cifrado en la aplicación de los datos personales de los egresados (LFPDPPP).
+-- Las columnas pasan a binario para guardar nonce + texto cifrado (AES-GCM);
+-- el tamaño alcanza para el máximo de caracteres que admite la validación.
+-- llave_datos es la llave de la fila envuelta con la llave maestra
+-- ("id:base64"); NULL indica una fila aún sin cifrar, que se cifra con
+-- cmd/rotate-keys. Los *_indice son índices ciegos HMAC-SHA256 para buscar
+-- por coincidencia exacta.
+
+ALTER TABLE egresados
+    DROP INDEX idx_egresados_estado_municipio,
+    MODIFY telefono VARBINARY(128) NULL,
+    MODIFY correo VARBINARY(640) NULL,
+    MODIFY codigo_postal VARBINARY(64) NULL,
+    MODIFY estado VARBINARY(448) NULL,
+    MODIFY municipio VARBINARY(640) NULL,
+    MODIFY asentamiento VARBINARY(832) NULL,
+    MODIFY calle VARBINARY(832) NULL,
+    MODIFY numero VARBINARY(128) NULL,
+    ADD COLUMN llave_datos VARCHAR(120) NULL AFTER numero,
+    ADD COLUMN telefono_indice BINARY(32) NULL AFTER llave_datos,
+    ADD COLUMN correo_indice BINARY(32) NULL AFTER telefono_indice,
+    ADD COLUMN estado_indice BINARY(32) NULL AFTER correo_indice,
+    ADD COLUMN municipio_indice BINARY(32) NULL AFTER estado_indice,
+    ADD KEY idx_egresados_telefono_indice (telefono_indice),
+    ADD KEY idx_egresados_correo_indice (correo_indice),
+    ADD KEY idx_egresados_estado_municipio_indice (estado_indice, municipio_indice);
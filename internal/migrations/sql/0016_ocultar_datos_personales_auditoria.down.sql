@@ -0,0 +1,2 @@
+-- Los valores ocultos no se pueden recuperar; la reversión no cambia nada
+DO 0;
@@ -0,0 +1,30 @@
+-- Oculta los datos personales que la bitácora guardó en texto plano antes de
+-- cifrarlos (ver auditoria.Diff). JSON_REPLACE no agrega las rutas que no
+-- existen y los null se conservan. Las exportaciones guardaron los filtros,
+-- donde la búsqueda libre, el estado y el municipio pueden ser datos
+-- personales.
+
+UPDATE auditoria SET
+    cambios = JSON_REPLACE(cambios, '$.telefono.antes', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.telefono.antes')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.telefono.despues', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.telefono.despues')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.correo.antes', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.correo.antes')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.correo.despues', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.correo.despues')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.codigo_postal.antes', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.codigo_postal.antes')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.codigo_postal.despues', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.codigo_postal.despues')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.estado.antes', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.estado.antes')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.estado.despues', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.estado.despues')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.municipio.antes', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.municipio.antes')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.municipio.despues', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.municipio.despues')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.asentamiento.antes', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.asentamiento.antes')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.asentamiento.despues', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.asentamiento.despues')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.calle.antes', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.calle.antes')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.calle.despues', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.calle.despues')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.numero.antes', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.numero.antes')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON))),
+    cambios = JSON_REPLACE(cambios, '$.numero.despues', IF(JSON_TYPE(JSON_EXTRACT(cambios, '$.numero.despues')) = 'NULL', CAST('null' AS JSON), CAST('"********"' AS JSON)))
+WHERE entidad IN ('egresado', 'usuario') AND accion IN ('crear', 'actualizar', 'eliminar') AND cambios IS NOT NULL;
+
+UPDATE auditoria SET
+    cambios = JSON_REPLACE(cambios, '$.filtros.q', JSON_ARRAY('********'),
+                                    '$.filtros.estado', JSON_ARRAY('********'),
+                                    '$.filtros.municipio', JSON_ARRAY('********'))
+WHERE entidad = 'egresado' AND accion = 'exportar' AND cambios IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Origen de cada cuenta: local, ldap (creada desde el directorio) u oidc
+-- (creada desde la cuenta institucional). El directorio solo inicia sesión
+-- y sincroniza datos en las cuentas ldap, para que una cuenta del directorio
+-- con el mismo nombre no pueda tomar una cuenta local. Las cuentas existentes
+-- quedan como local; las que deben seguir entrando con el directorio se
+-- marcan a mano (ver README).
+ALTER TABLE usuarios
+    ADD COLUMN origen VARCHAR(10) NOT NULL DEFAULT 'local' AFTER id_plantel;
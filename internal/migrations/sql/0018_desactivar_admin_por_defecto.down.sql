@@ -0,0 +1,4 @@
+-- Revierte 0018_desactivar_admin_por_defecto: a propósito no restaura la
+-- contraseña conocida.
+
+SELECT 1;
@@ -0,0 +1,8 @@
+-- Las bases creadas con la versión anterior de 0005 tienen "admin" con la
+-- contraseña conocida admin123. Se deja sin contraseña, lo que impide iniciar
+-- sesión hasta asignarle una con ADMIN_INICIAL_PASSWORD o desde Administradores.
+
+UPDATE usuarios
+SET password = ''
+WHERE usuario = 'admin'
+  AND password = '$2a$10$kvc0bVlNq9jSFHL7HvaCfeTTQCAkUOI54EhKAmLxE8OiOwIu8qdWW';
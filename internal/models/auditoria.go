@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Auditoria es un registro de la bitácora de cambios
+type Auditoria struct {
+	ID        int64           `json:"id"`
+	IDUsuario *int            `json:"id_usuario"`
+	Usuario   string          `json:"usuario"`
+	IP        string          `json:"ip"`
+	Entidad   string          `json:"entidad"`
+	EntidadID string          `json:"entidad_id"`
+	Accion    string          `json:"accion"`
+	Cambios   json.RawMessage `json:"cambios,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// FiltroAuditoria agrupa los criterios de consulta de la bitácora
+type FiltroAuditoria struct {
+	Entidad   string
+	EntidadID string
+	Actor     string // ID o nombre de usuario
+	Desde     *time.Time
+	Hasta     *time.Time // Exclusivo
+	Page      int
+	PerPage   int
+}
@@ -3,4 +3,11 @@ package models
 type Carrera struct {
 	IDCarrera int    `json:"id_carrera"`
 	Nombre    string `json:"nombre"`
-}
\ No newline at end of file
+}
+
+// CarreraStats es una carrera con el conteo de sus egresados
+type CarreraStats struct {
+	IDCarrera      int    `json:"id_carrera"`
+	Nombre         string `json:"nombre"`
+	TotalEgresados int    `json:"total_egresados"`
+}
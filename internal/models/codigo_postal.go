@@ -16,4 +16,18 @@ type EstadoMunicipio struct {
 type MunicipioAsentamiento struct {
 	Municipio     string   `json:"municipio"`
 	Asentamientos []string `json:"asentamientos"`
-}
\ No newline at end of file
+}
+
+// ResultadoCodigoPostal es la ubicación asociada a un código postal
+type ResultadoCodigoPostal struct {
+	CodigoPostal  string   `json:"codigo_postal"`
+	Estado        string   `json:"estado"`
+	Municipio     string   `json:"municipio"`
+	Asentamientos []string `json:"asentamientos"`
+}
+
+// AsentamientoCP es un asentamiento con su código postal
+type AsentamientoCP struct {
+	Asentamiento string `json:"asentamiento"`
+	CodigoPostal string `json:"codigo_postal"`
+}
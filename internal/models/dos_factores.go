@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DosFactores es la configuración de verificación en dos pasos de un usuario.
+// Mientras Activo sea false el secreto está pendiente de confirmar.
+type DosFactores struct {
+	IDUsuario  int        `json:"id_usuario"`
+	Secreto    string     `json:"-"`
+	Activo     bool       `json:"activo"`
+	UltimoPaso int64      `json:"-"`
+	ActivadoEn *time.Time `json:"activado_en"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CodigoDosFactoresRequest lleva un código de la aplicación autenticadora o
+// un código de recuperación
+type CodigoDosFactoresRequest struct {
+	Codigo string `json:"codigo"`
+}
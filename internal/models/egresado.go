@@ -4,6 +4,7 @@ import "time"
 
 type Egresado struct {
 	Matricula      string    `json:"matricula"`
+	IDPlantel      int       `json:"id_plantel"` // se obtiene de la clave en la matrícula
 	NombreCompleto string    `json:"nombre_completo"`
 	Genero         *string   `json:"genero"`
 	Telefono       *string   `json:"telefono"`
@@ -22,8 +23,35 @@ type Egresado struct {
 	IDEstatus      int       `json:"id_estatus"`
 	CreatedAt      time.Time `json:"created_at"`
 	
+	// Control de concurrencia: se envía como ETag y se exige en If-Match
+	Version        int       `json:"version"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	
+	// Papelera
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy      *int       `json:"deleted_by,omitempty"`
+	
 	// Campos relacionados
+	NombrePlantel      string `json:"nombre_plantel,omitempty"`
 	NombreCarrera      string `json:"nombre_carrera,omitempty"`
 	PeriodoGeneracion  string `json:"periodo_generacion,omitempty"`
 	DescripcionEstatus string `json:"descripcion_estatus,omitempty"`
-}
\ No newline at end of file
+}
+
+// FiltroEgresados agrupa los criterios de búsqueda, orden y paginación del listado
+type FiltroEgresados struct {
+	Plantel    string
+	Generacion string
+	Carrera    string
+	Estatus    string
+	Genero     string
+	Estado     string
+	Municipio  string
+	Q          string // Matrícula o nombre
+
+	Sort  string
+	Order string
+
+	Page    int
+	PerPage int // 0 = sin paginar
+}
@@ -3,4 +3,11 @@ package models
 type Estatus struct {
 	IDEstatus   int    `json:"id_estatus"`
 	Descripcion string `json:"descripcion"`
-}
\ No newline at end of file
+}
+
+// EstatusStats es un estatus con el conteo de sus egresados
+type EstatusStats struct {
+	IDEstatus      int    `json:"id_estatus"`
+	Descripcion    string `json:"descripcion"`
+	TotalEgresados int    `json:"total_egresados"`
+}
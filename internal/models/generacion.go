@@ -3,4 +3,11 @@ package models
 type Generacion struct {
 	IDGeneracion int    `json:"id_generacion"`
 	Periodo      string `json:"periodo"`
-}
\ No newline at end of file
+}
+
+// GeneracionStats es una generación con el conteo de sus egresados
+type GeneracionStats struct {
+	IDGeneracion   int    `json:"id_generacion"`
+	Periodo        string `json:"periodo"`
+	TotalEgresados int    `json:"total_egresados"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Tipos de bloqueo de inicio de sesión
+const (
+	BloqueoPorUsuario = "usuario"
+	BloqueoPorIP      = "ip"
+)
+
+// Motivos de un intento de inicio de sesión fallido
+const (
+	MotivoCredenciales = "credenciales" // usuario inexistente o contraseña incorrecta
+	MotivoBloqueado    = "bloqueado"    // rechazado sin verificar por un bloqueo vigente
+	// MotivoRestablecimiento es una solicitud de restablecimiento de
+	// contraseña; Usuario guarda el correo solicitado
+	MotivoRestablecimiento = "restablecimiento"
+)
+
+// IntentoLogin es un intento de inicio de sesión. Usuario es lo que se
+// escribió en el formulario, exista o no.
+type IntentoLogin struct {
+	ID        int64     `json:"id"`
+	Usuario   string    `json:"usuario"`
+	IDUsuario *int      `json:"id_usuario"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Exitoso   bool      `json:"exitoso"`
+	Motivo    string    `json:"motivo,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FiltroIntentosLogin agrupa los criterios de consulta de los intentos
+type FiltroIntentosLogin struct {
+	Usuario string
+	IP      string
+	Exitoso *bool
+	Desde   *time.Time
+	Hasta   *time.Time // Exclusivo
+	Page    int
+	PerPage int
+}
+
+// BloqueoLogin impide iniciar sesión a un usuario o desde una IP hasta BloqueadoHasta
+type BloqueoLogin struct {
+	Tipo           string    `json:"tipo"`
+	Valor          string    `json:"valor"`
+	Fallidos       int       `json:"fallidos"`
+	BloqueadoHasta time.Time `json:"bloqueado_hasta"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Plantel es un campus de la UES; su clave son los 2 primeros dígitos de la
+// matrícula de sus egresados
+type Plantel struct {
+	IDPlantel int    `json:"id_plantel"`
+	Clave     string `json:"clave"`
+	Nombre    string `json:"nombre"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Sesion es una sesión iniciada guardada en el servidor. ID es el hash del
+// token de la cookie, nunca el token mismo.
+type Sesion struct {
+	ID              string    `json:"id"`
+	IDUsuario       *int      `json:"-"`
+	Datos           []byte    `json:"-"`
+	IP              string    `json:"ip"`
+	UserAgent       string    `json:"user_agent"`
+	CreatedAt       time.Time `json:"created_at"`
+	UltimaActividad time.Time `json:"ultima_actividad"`
+	ExpiraEn        time.Time `json:"expira_en"`
+	Actual          bool      `json:"actual"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// TokenAPI es un token personal para usar la API sin sesión del navegador.
+// Hash es el SHA-256 del token, que solo se muestra al crearlo.
+type TokenAPI struct {
+	IDToken   int        `json:"id_token"`
+	IDUsuario int        `json:"-"`
+	Hash      string     `json:"-"`
+	Nombre    string     `json:"nombre"`
+	Prefijo   string     `json:"prefijo"`
+	Alcances  []string   `json:"alcances"`
+	ExpiraEn  *time.Time `json:"expira_en"`
+	UltimoUso *time.Time `json:"ultimo_uso"`
+	UltimaIP  *string    `json:"ultima_ip"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Vigente indica si el token no ha vencido en ahora
+func (t *TokenAPI) Vigente(ahora time.Time) bool {
+	return t.ExpiraEn == nil || ahora.Before(*t.ExpiraEn)
+}
+
+// CrearTokenAPIRequest pide un token nuevo. DiasVigencia 0 u omitido crea un
+// token sin vencimiento.
+type CrearTokenAPIRequest struct {
+	Nombre       string   `json:"nombre"`
+	Alcances     []string `json:"alcances"`
+	DiasVigencia int      `json:"dias_vigencia"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TokenPassword es un enlace para restablecer la contraseña. ID es el hash
+// SHA-256 del token enviado por correo.
+type TokenPassword struct {
+	ID        string
+	IDUsuario int
+	IP        string
+	ExpiraEn  time.Time
+	UsadoEn   *time.Time
+	CreatedAt time.Time
+}
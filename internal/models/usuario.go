@@ -2,15 +2,32 @@ package models
 
 import "time"
 
+// Origen de la cuenta. LDAP solo inicia sesión y sincroniza datos en las
+// cuentas del directorio, para que nadie entre como un usuario local con el
+// mismo nombre.
+const (
+    OrigenLocal = "local"
+    OrigenLDAP  = "ldap"
+    OrigenOIDC  = "oidc"
+)
+
 type Usuario struct {
     IDUsuario        int       `json:"id_usuario"`
     Usuario          string    `json:"usuario"`
     Nombre           string    `json:"nombre"`
     ApellidoPaterno  string    `json:"apellido_paterno"`
     ApellidoMaterno  string    `json:"apellido_materno"`
+    Correo           *string   `json:"correo"` // a donde se envían los enlaces para restablecer la contraseña
     Password         string    `json:"-"` // No se serializa en JSON
     Rol              string    `json:"rol"`
+    IDPlantel        *int      `json:"id_plantel"` // obligatorio salvo en roles globales
+    Origen           string    `json:"origen"` // se fija al crear la cuenta
+    NombrePlantel    string    `json:"nombre_plantel,omitempty"`
     CreatedAt        time.Time `json:"created_at"`
+    Version          int       `json:"version"`
+    UpdatedAt        time.Time `json:"updated_at"`
+    DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+    DeletedBy        *int       `json:"deleted_by,omitempty"`
 }
 
 // Método para obtener nombre completo
@@ -21,4 +38,16 @@ func (u *Usuario) NombreCompleto() string {
 type LoginRequest struct {
     Usuario  string `json:"usuario"`
     Password string `json:"password"`
-}
\ No newline at end of file
+}
+
+// CambioPasswordRequest es el cuerpo de POST /api/me/password
+type CambioPasswordRequest struct {
+    PasswordActual string `json:"password_actual"`
+    PasswordNueva  string `json:"password_nueva"`
+}
+
+// RestablecerPasswordRequest es el cuerpo de POST /password/restablecer
+type RestablecerPasswordRequest struct {
+    Token    string `json:"token"`
+    Password string `json:"password"`
+}
@@ -0,0 +1,112 @@
+package papelera
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"time"
+	"ues-egresados/internal/auditoria"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+)
+
+// RetencionPorDefecto es el tiempo que un registro permanece en la papelera
+const RetencionPorDefecto = 30 * 24 * time.Hour
+
+// RetencionDesdeEntorno lee PAPELERA_RETENCION_DIAS. Un valor de 0 desactiva
+// la purga automática.
+func RetencionDesdeEntorno() time.Duration {
+	valor := os.Getenv("PAPELERA_RETENCION_DIAS")
+	if valor == "" {
+		return RetencionPorDefecto
+	}
+	dias, err := strconv.Atoi(valor)
+	if err != nil || dias < 0 {
+		log.Printf("⚠️  PAPELERA_RETENCION_DIAS inválido (%q), usando %d días", valor, int(RetencionPorDefecto.Hours()/24))
+		return RetencionPorDefecto
+	}
+	return time.Duration(dias) * 24 * time.Hour
+}
+
+// Purgador elimina definitivamente los registros que superan la retención
+type Purgador struct {
+	egresados repository.EgresadoRepository
+	usuarios  repository.UsuarioRepository
+	auditoria repository.AuditoriaRepository
+	retencion time.Duration
+}
+
+func NuevoPurgador(repos *repository.Repositories, retencion time.Duration) *Purgador {
+	return &Purgador{
+		egresados: repos.Egresados,
+		usuarios:  repos.Usuarios,
+		auditoria: repos.Auditoria,
+		retencion: retencion,
+	}
+}
+
+// Purgar elimina lo enviado a la papelera antes de la retención configurada
+func (p *Purgador) Purgar(ctx context.Context) error {
+	limite := time.Now().Add(-p.retencion)
+
+	egresados, err := p.egresados.PurgarEliminadosAntes(ctx, limite)
+	if err != nil {
+		return err
+	}
+	usuarios, err := p.usuarios.PurgarEliminadosAntes(ctx, limite)
+	if err != nil {
+		return err
+	}
+
+	if egresados > 0 {
+		p.registrar(ctx, auditoria.EntidadEgresado, egresados, limite)
+	}
+	if usuarios > 0 {
+		p.registrar(ctx, auditoria.EntidadUsuario, usuarios, limite)
+	}
+	if egresados+usuarios > 0 {
+		log.Printf("🗑️  Papelera: %d egresados y %d usuarios purgados", egresados, usuarios)
+	}
+	return nil
+}
+
+func (p *Purgador) registrar(ctx context.Context, entidad string, total int64, limite time.Time) {
+	detalles, _ := json.Marshal(map[string]interface{}{
+		"purgados":         total,
+		"eliminados_antes": limite,
+	})
+	err := p.auditoria.Registrar(ctx, &models.Auditoria{
+		Usuario: "sistema",
+		Entidad: entidad,
+		Accion:  auditoria.AccionPurgar,
+		Cambios: detalles,
+	})
+	if err != nil {
+		log.Printf("Error al registrar purga automática: %v", err)
+	}
+}
+
+// Iniciar ejecuta la purga al arrancar y después en cada intervalo, hasta que
+// ctx se cancele. No hace nada si la retención es 0.
+func (p *Purgador) Iniciar(ctx context.Context, intervalo time.Duration) {
+	if p.retencion <= 0 {
+		log.Println("ℹ️  Purga automática de la papelera desactivada")
+		return
+	}
+
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Purgar(ctx); err != nil {
+			log.Printf("Error al purgar la papelera: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
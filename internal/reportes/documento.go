@@ -0,0 +1,164 @@
+package reportes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// DirLogos es la carpeta con los logos institucionales usados en los PDF
+const DirLogos = "web/static/img/logos"
+
+const (
+	institucion = "UES San José del Rincón"
+	sistema     = "Sistema de Gestión de Egresados"
+)
+
+var (
+	colorPrimario = [3]int{139, 35, 62} // #8b233e
+	colorTexto    = [3]int{51, 51, 51}
+	colorGris     = [3]int{240, 240, 240}
+)
+
+// Info son los datos de quién y cuándo generó un documento
+type Info struct {
+	GeneradoPor string
+	Fecha       time.Time
+}
+
+// logo es un archivo PNG cargado una sola vez en memoria
+type logo struct {
+	nombre string
+	datos  []byte
+}
+
+var (
+	cargaLogos sync.Once
+	logoEdomex *logo
+	logoUES    *logo
+)
+
+func cargarLogos() {
+	cargaLogos.Do(func() {
+		logoEdomex = leerLogo("edomex.png")
+		logoUES = leerLogo("UES-WHITE.png")
+	})
+}
+
+// leerLogo devuelve nil si el archivo no existe; el PDF se genera sin el logo
+func leerLogo(nombre string) *logo {
+	datos, err := os.ReadFile(filepath.Join(DirLogos, nombre))
+	if err != nil {
+		log.Printf("⚠️  No se pudo cargar el logo %s: %v", nombre, err)
+		return nil
+	}
+	return &logo{nombre: nombre, datos: datos}
+}
+
+// documento envuelve gofpdf con la identidad institucional: banda de
+// encabezado con logos y pie con fecha, usuario y número de página
+type documento struct {
+	pdf    *gofpdf.Fpdf
+	tr     func(string) string
+	info   Info
+	ancho  float64
+	margen float64
+}
+
+func nuevoDocumento(orientacion, titulo string, subtitulos []string, info Info) *documento {
+	cargarLogos()
+
+	pdf := gofpdf.New(orientacion, "mm", "A4", "")
+	d := &documento{
+		pdf:    pdf,
+		tr:     pdf.UnicodeTranslatorFromDescriptor(""),
+		info:   info,
+		margen: 10,
+	}
+	d.ancho, _ = pdf.GetPageSize()
+
+	pdf.SetTitle(d.tr(titulo), false)
+	pdf.SetAuthor(d.tr(info.GeneradoPor), false)
+	pdf.SetCreator(d.tr(sistema), false)
+	pdf.SetMargins(d.margen, 38, d.margen)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("{nb}")
+
+	pdf.SetHeaderFunc(func() { d.encabezado(titulo, subtitulos) })
+	pdf.SetFooterFunc(d.pie)
+
+	for _, l := range []*logo{logoEdomex, logoUES} {
+		if l != nil {
+			pdf.RegisterImageOptionsReader(l.nombre, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(l.datos))
+		}
+	}
+
+	return d
+}
+
+func (d *documento) encabezado(titulo string, subtitulos []string) {
+	pdf := d.pdf
+	pdf.SetFillColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	pdf.Rect(0, 0, d.ancho, 30, "F")
+
+	if logoEdomex != nil {
+		pdf.ImageOptions(logoEdomex.nombre, d.margen, 10, 40, 0, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+	if logoUES != nil {
+		pdf.ImageOptions(logoUES.nombre, d.ancho-d.margen-28, 4, 28, 0, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetXY(0, 6)
+	pdf.CellFormat(d.ancho, 8, d.tr(titulo), "", 1, "C", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, s := range subtitulos {
+		pdf.SetX(0)
+		pdf.CellFormat(d.ancho, 5.5, d.tr(s), "", 1, "C", false, 0, "")
+	}
+
+	pdf.SetY(38)
+}
+
+func (d *documento) pie() {
+	pdf := d.pdf
+	pdf.SetY(-15)
+	pdf.SetDrawColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	pdf.SetLineWidth(0.3)
+	pdf.Line(d.margen, pdf.GetY(), d.ancho-d.margen, pdf.GetY())
+
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(128, 128, 128)
+	ancho := (d.ancho - 2*d.margen) / 3
+
+	generado := fmt.Sprintf("Generado el %s por %s", d.info.Fecha.Format("02/01/2006 15:04"), d.info.GeneradoPor)
+	pdf.CellFormat(ancho, 8, d.tr(generado), "", 0, "L", false, 0, "")
+	pdf.CellFormat(ancho, 8, d.tr(fmt.Sprintf("Página %d de {nb}", pdf.PageNo())), "", 0, "C", false, 0, "")
+	pdf.CellFormat(ancho, 8, d.tr(sistema+" - "+institucion), "", 0, "R", false, 0, "")
+}
+
+// recortar acorta el texto para que quepa en el ancho indicado
+func (d *documento) recortar(texto string, ancho float64) string {
+	texto = d.tr(texto)
+	if d.pdf.GetStringWidth(texto) <= ancho {
+		return texto
+	}
+	const puntos = "..."
+	for len(texto) > 0 && d.pdf.GetStringWidth(texto+puntos) > ancho {
+		texto = texto[:len(texto)-1]
+	}
+	return texto + puntos
+}
+
+func (d *documento) escribir(w io.Writer) error {
+	return d.pdf.Output(w)
+}
@@ -0,0 +1,96 @@
+package reportes
+
+import (
+	"io"
+	"strings"
+	"ues-egresados/internal/models"
+)
+
+type campo struct {
+	etiqueta string
+	valor    string
+}
+
+// Expediente genera el PDF individual de un egresado
+func Expediente(w io.Writer, e *models.Egresado, info Info) error {
+	d := nuevoDocumento("P", "EXPEDIENTE DE EGRESADO", []string{sistema, institucion}, info)
+	d.pdf.AddPage()
+
+	d.seccion("DATOS PERSONALES", []campo{
+		{"Matrícula", e.Matricula},
+		{"Nombre Completo", e.NombreCompleto},
+		{"Género", texto(e.Genero)},
+	})
+
+	d.seccion("DATOS ACADÉMICOS", []campo{
+		{"Plantel", vacio(e.NombrePlantel)},
+		{"Carrera", vacio(e.NombreCarrera)},
+		{"Generación", vacio(e.PeriodoGeneracion)},
+		{"Estatus", vacio(e.DescripcionEstatus)},
+		{"Fecha de Registro", e.CreatedAt.Format("02/01/2006")},
+	})
+
+	d.seccion("DATOS DE CONTACTO", []campo{
+		{"Teléfono", texto(e.Telefono)},
+		{"Correo Electrónico", texto(e.Correo)},
+	})
+
+	d.seccion("DOMICILIO", []campo{
+		{"Calle y Número", domicilio(e)},
+		{"Asentamiento", texto(e.Asentamiento)},
+		{"Municipio", texto(e.Municipio)},
+		{"Estado", texto(e.Estado)},
+		{"Código Postal", texto(e.CodigoPostal)},
+	})
+
+	return d.escribir(w)
+}
+
+// seccion dibuja un título con fondo gris seguido de pares etiqueta/valor
+func (d *documento) seccion(titulo string, campos []campo) {
+	pdf := d.pdf
+	ancho := d.ancho - 2*d.margen
+
+	pdf.SetFillColor(colorGris[0], colorGris[1], colorGris[2])
+	pdf.SetTextColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(ancho, 8, "  "+d.tr(titulo), "", 1, "L", true, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetTextColor(colorTexto[0], colorTexto[1], colorTexto[2])
+	for _, c := range campos {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(50, 6, "  "+d.tr(c.etiqueta+":"), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(ancho-50, 6, d.tr(c.valor), "", "L", false)
+	}
+	pdf.Ln(4)
+}
+
+func domicilio(e *models.Egresado) string {
+	var partes []string
+	if e.Calle != nil && *e.Calle != "" {
+		partes = append(partes, *e.Calle)
+	}
+	if e.Numero != nil && *e.Numero != "" {
+		partes = append(partes, "#"+*e.Numero)
+	}
+	if len(partes) == 0 {
+		return "N/A"
+	}
+	return strings.Join(partes, " ")
+}
+
+func texto(s *string) string {
+	if s == nil {
+		return "N/A"
+	}
+	return vacio(*s)
+}
+
+func vacio(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "N/A"
+	}
+	return s
+}
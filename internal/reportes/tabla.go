@@ -0,0 +1,96 @@
+package reportes
+
+import (
+	"io"
+	"ues-egresados/internal/models"
+)
+
+type columna struct {
+	titulo string
+	ancho  float64
+	valor  func(e *models.Egresado) string
+}
+
+// columnasTabla suman 277 mm, el ancho útil de una hoja A4 horizontal
+var columnasTabla = []columna{
+	{"Matrícula", 25, func(e *models.Egresado) string { return e.Matricula }},
+	{"Nombre", 62, func(e *models.Egresado) string { return e.NombreCompleto }},
+	{"Estatus", 22, func(e *models.Egresado) string { return guion(e.DescripcionEstatus) }},
+	{"Carrera", 60, func(e *models.Egresado) string { return guion(e.NombreCarrera) }},
+	{"Generación", 25, func(e *models.Egresado) string { return guion(e.PeriodoGeneracion) }},
+	{"Correo", 53, func(e *models.Egresado) string { return guionPtr(e.Correo) }},
+	{"Teléfono", 30, func(e *models.Egresado) string { return guionPtr(e.Telefono) }},
+}
+
+// Tabla genera el listado horizontal de egresados. Las filas se reciben por
+// medio de recorrer, que llama a la función agregar por cada egresado.
+func Tabla(w io.Writer, generacion, carrera string, info Info, recorrer func(agregar func(*models.Egresado) error) error) error {
+	subtitulos := []string{"Generación: " + generacion, "Carrera: " + carrera}
+	d := nuevoDocumento("L", "TABLA DE EGRESADOS", subtitulos, info)
+
+	// El encabezado de columnas se repite en cada página
+	d.pdf.SetHeaderFunc(func() {
+		d.encabezado("TABLA DE EGRESADOS", subtitulos)
+		d.encabezadoColumnas()
+	})
+	d.pdf.AddPage()
+
+	fila := 0
+	err := recorrer(func(e *models.Egresado) error {
+		d.filaTabla(e, fila%2 == 1)
+		fila++
+		return d.pdf.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	if fila == 0 {
+		d.pdf.SetFont("Helvetica", "I", 10)
+		d.pdf.SetTextColor(colorTexto[0], colorTexto[1], colorTexto[2])
+		d.pdf.CellFormat(0, 10, d.tr("No hay egresados con los filtros seleccionados"), "", 1, "C", false, 0, "")
+	}
+
+	return d.escribir(w)
+}
+
+func (d *documento) encabezadoColumnas() {
+	pdf := d.pdf
+	pdf.SetFillColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 9)
+	for _, c := range columnasTabla {
+		pdf.CellFormat(c.ancho, 8, d.tr(c.titulo), "", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func (d *documento) filaTabla(e *models.Egresado, alterna bool) {
+	pdf := d.pdf
+	if alterna {
+		pdf.SetFillColor(colorGris[0], colorGris[1], colorGris[2])
+	} else {
+		pdf.SetFillColor(255, 255, 255)
+	}
+	pdf.SetTextColor(colorTexto[0], colorTexto[1], colorTexto[2])
+	pdf.SetFont("Helvetica", "", 8)
+
+	for _, c := range columnasTabla {
+		pdf.CellFormat(c.ancho, 7, d.recortar(c.valor(e), c.ancho-2), "", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func guion(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func guionPtr(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return guion(*s)
+}
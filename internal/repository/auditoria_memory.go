@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// AuditoriaMemory implementa AuditoriaRepository en memoria
+type AuditoriaMemory struct {
+	mu        sync.RWMutex
+	registros []models.Auditoria
+}
+
+func NewAuditoriaMemory() *AuditoriaMemory {
+	return &AuditoriaMemory{}
+}
+
+func (r *AuditoriaMemory) Registrar(ctx context.Context, a *models.Auditoria) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a.ID = int64(len(r.registros) + 1)
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	r.registros = append(r.registros, *a)
+	return nil
+}
+
+func (r *AuditoriaMemory) List(ctx context.Context, filtro models.FiltroAuditoria) ([]models.Auditoria, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registros := []models.Auditoria{}
+	for _, a := range r.registros {
+		if coincideAuditoria(a, filtro) {
+			registros = append(registros, a)
+		}
+	}
+	sort.SliceStable(registros, func(i, j int) bool {
+		return registros[i].ID > registros[j].ID
+	})
+
+	total := len(registros)
+	if filtro.PerPage > 0 {
+		inicio := (filtro.Page - 1) * filtro.PerPage
+		if inicio > total {
+			inicio = total
+		}
+		fin := inicio + filtro.PerPage
+		if fin > total {
+			fin = total
+		}
+		registros = registros[inicio:fin]
+	}
+	return registros, total, nil
+}
+
+func coincideAuditoria(a models.Auditoria, f models.FiltroAuditoria) bool {
+	if f.Entidad != "" && a.Entidad != f.Entidad {
+		return false
+	}
+	if f.EntidadID != "" && a.EntidadID != f.EntidadID {
+		return false
+	}
+	if f.Actor != "" && a.Usuario != f.Actor {
+		id, err := strconv.Atoi(f.Actor)
+		if err != nil || a.IDUsuario == nil || *a.IDUsuario != id {
+			return false
+		}
+	}
+	if f.Desde != nil && a.CreatedAt.Before(*f.Desde) {
+		return false
+	}
+	if f.Hasta != nil && !a.CreatedAt.Before(*f.Hasta) {
+		return false
+	}
+	return true
+}
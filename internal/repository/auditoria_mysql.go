@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"ues-egresados/internal/models"
+)
+
+// AuditoriaMySQL implementa AuditoriaRepository sobre MySQL
+type AuditoriaMySQL struct {
+	db *sql.DB
+}
+
+func NewAuditoriaMySQL(db *sql.DB) *AuditoriaMySQL {
+	return &AuditoriaMySQL{db: db}
+}
+
+func (r *AuditoriaMySQL) Registrar(ctx context.Context, a *models.Auditoria) error {
+	var cambios interface{}
+	if len(a.Cambios) > 0 {
+		cambios = string(a.Cambios)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO auditoria (id_usuario, usuario, ip, entidad, entidad_id, accion, cambios)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, a.IDUsuario, a.Usuario, a.IP, a.Entidad, a.EntidadID, a.Accion, cambios)
+	if err != nil {
+		return err
+	}
+
+	a.ID, _ = result.LastInsertId()
+	return nil
+}
+
+func (r *AuditoriaMySQL) List(ctx context.Context, filtro models.FiltroAuditoria) ([]models.Auditoria, int, error) {
+	where, args := whereAuditoria(filtro)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM auditoria"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, id_usuario, usuario, ip, entidad, entidad_id, accion, cambios, created_at
+		FROM auditoria` + where + " ORDER BY created_at DESC, id DESC"
+	if filtro.PerPage > 0 {
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", filtro.PerPage, (filtro.Page-1)*filtro.PerPage)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	registros := []models.Auditoria{}
+	for rows.Next() {
+		var a models.Auditoria
+		var idUsuario sql.NullInt64
+		var cambios []byte
+		if err := rows.Scan(&a.ID, &idUsuario, &a.Usuario, &a.IP, &a.Entidad, &a.EntidadID,
+			&a.Accion, &cambios, &a.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if idUsuario.Valid {
+			id := int(idUsuario.Int64)
+			a.IDUsuario = &id
+		}
+		if len(cambios) > 0 {
+			a.Cambios = cambios
+		}
+		registros = append(registros, a)
+	}
+
+	return registros, total, rows.Err()
+}
+
+func whereAuditoria(f models.FiltroAuditoria) (string, []interface{}) {
+	var condiciones []string
+	var args []interface{}
+
+	if f.Entidad != "" {
+		condiciones = append(condiciones, "entidad = ?")
+		args = append(args, f.Entidad)
+	}
+	if f.EntidadID != "" {
+		condiciones = append(condiciones, "entidad_id = ?")
+		args = append(args, f.EntidadID)
+	}
+	if f.Actor != "" {
+		if id, err := strconv.Atoi(f.Actor); err == nil {
+			condiciones = append(condiciones, "(id_usuario = ? OR usuario = ?)")
+			args = append(args, id, f.Actor)
+		} else {
+			condiciones = append(condiciones, "usuario = ?")
+			args = append(args, f.Actor)
+		}
+	}
+	if f.Desde != nil {
+		condiciones = append(condiciones, "created_at >= ?")
+		args = append(args, *f.Desde)
+	}
+	if f.Hasta != nil {
+		condiciones = append(condiciones, "created_at < ?")
+		args = append(args, *f.Hasta)
+	}
+
+	if len(condiciones) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(condiciones, " AND "), args
+}
@@ -0,0 +1,405 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"ues-egresados/internal/models"
+)
+
+// CatalogoMemory implementa CatalogoRepository en memoria
+type CatalogoMemory struct {
+	mu           sync.RWMutex
+	carreras     map[int]models.Carrera
+	generaciones map[int]models.Generacion
+	estatus      map[int]models.Estatus
+	planteles    map[int]models.Plantel
+	// egresados y usuarios permiten revisar y reasignar las referencias al eliminar
+	egresados *EgresadoMemory
+	usuarios  *UsuarioMemory
+}
+
+func NewCatalogoMemory() *CatalogoMemory {
+	return &CatalogoMemory{
+		carreras:     map[int]models.Carrera{},
+		generaciones: map[int]models.Generacion{},
+		estatus:      map[int]models.Estatus{},
+		planteles:    map[int]models.Plantel{},
+	}
+}
+
+// AddCarrera registra una carrera (para preparar datos de prueba)
+func (r *CatalogoMemory) AddCarrera(c models.Carrera) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.carreras[c.IDCarrera] = c
+}
+
+// AddGeneracion registra una generación (para preparar datos de prueba)
+func (r *CatalogoMemory) AddGeneracion(g models.Generacion) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generaciones[g.IDGeneracion] = g
+}
+
+// AddEstatus registra un estatus (para preparar datos de prueba)
+func (r *CatalogoMemory) AddEstatus(e models.Estatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.estatus[e.IDEstatus] = e
+}
+
+// AddPlantel registra un plantel (para preparar datos de prueba)
+func (r *CatalogoMemory) AddPlantel(p models.Plantel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.planteles[p.IDPlantel] = p
+}
+
+func (r *CatalogoMemory) ListCarreras(ctx context.Context) ([]models.Carrera, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	carreras := []models.Carrera{}
+	for _, c := range r.carreras {
+		carreras = append(carreras, c)
+	}
+	sort.Slice(carreras, func(i, j int) bool { return carreras[i].Nombre < carreras[j].Nombre })
+	return carreras, nil
+}
+
+func (r *CatalogoMemory) ListGeneraciones(ctx context.Context) ([]models.Generacion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	generaciones := []models.Generacion{}
+	for _, g := range r.generaciones {
+		generaciones = append(generaciones, g)
+	}
+	sort.Slice(generaciones, func(i, j int) bool { return generaciones[i].Periodo > generaciones[j].Periodo })
+	return generaciones, nil
+}
+
+func (r *CatalogoMemory) ListEstatus(ctx context.Context) ([]models.Estatus, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	estatusList := []models.Estatus{}
+	for _, e := range r.estatus {
+		estatusList = append(estatusList, e)
+	}
+	sort.Slice(estatusList, func(i, j int) bool { return estatusList[i].Descripcion < estatusList[j].Descripcion })
+	return estatusList, nil
+}
+
+// referenciasValidas simula las llaves foráneas de egresados
+func (r *CatalogoMemory) referenciasValidas(e *models.Egresado) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, okPlantel := r.planteles[e.IDPlantel]
+	_, okCarrera := r.carreras[e.IDCarrera]
+	_, okGeneracion := r.generaciones[e.IDGeneracion]
+	_, okEstatus := r.estatus[e.IDEstatus]
+	return okPlantel && okCarrera && okGeneracion && okEstatus
+}
+
+// plantelValido simula la llave foránea opcional de usuarios
+func (r *CatalogoMemory) plantelValido(id *int) bool {
+	if id == nil {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.planteles[*id]
+	return ok
+}
+
+// conNombres completa las descripciones usadas en los LEFT JOIN del listado
+func (r *CatalogoMemory) conNombres(e *models.Egresado) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e.NombrePlantel = r.planteles[e.IDPlantel].Nombre
+	e.NombreCarrera = r.carreras[e.IDCarrera].Nombre
+	e.PeriodoGeneracion = r.generaciones[e.IDGeneracion].Periodo
+	e.DescripcionEstatus = r.estatus[e.IDEstatus].Descripcion
+}
+
+func (r *CatalogoMemory) GetCarrera(ctx context.Context, id int) (*models.Carrera, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.carreras[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+func (r *CatalogoMemory) CreateCarrera(ctx context.Context, c *models.Carrera) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, otra := range r.carreras {
+		if strings.EqualFold(otra.Nombre, c.Nombre) {
+			return ErrDuplicado
+		}
+	}
+	c.IDCarrera = siguienteID(r.carreras)
+	r.carreras[c.IDCarrera] = *c
+	return nil
+}
+
+func (r *CatalogoMemory) UpdateCarrera(ctx context.Context, c *models.Carrera) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.carreras[c.IDCarrera]; !ok {
+		return ErrNotFound
+	}
+	for id, otra := range r.carreras {
+		if id != c.IDCarrera && strings.EqualFold(otra.Nombre, c.Nombre) {
+			return ErrDuplicado
+		}
+	}
+	r.carreras[c.IDCarrera] = *c
+	return nil
+}
+
+func (r *CatalogoMemory) DeleteCarrera(ctx context.Context, id, reasignarA int) error {
+	return eliminarDeCatalogo(r, id, reasignarA, r.carreras, func(e *models.Egresado) *int { return &e.IDCarrera })
+}
+
+func (r *CatalogoMemory) GetGeneracion(ctx context.Context, id int) (*models.Generacion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	g, ok := r.generaciones[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &g, nil
+}
+
+func (r *CatalogoMemory) CreateGeneracion(ctx context.Context, g *models.Generacion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, otra := range r.generaciones {
+		if otra.Periodo == g.Periodo {
+			return ErrDuplicado
+		}
+	}
+	g.IDGeneracion = siguienteID(r.generaciones)
+	r.generaciones[g.IDGeneracion] = *g
+	return nil
+}
+
+func (r *CatalogoMemory) UpdateGeneracion(ctx context.Context, g *models.Generacion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.generaciones[g.IDGeneracion]; !ok {
+		return ErrNotFound
+	}
+	for id, otra := range r.generaciones {
+		if id != g.IDGeneracion && otra.Periodo == g.Periodo {
+			return ErrDuplicado
+		}
+	}
+	r.generaciones[g.IDGeneracion] = *g
+	return nil
+}
+
+func (r *CatalogoMemory) DeleteGeneracion(ctx context.Context, id, reasignarA int) error {
+	return eliminarDeCatalogo(r, id, reasignarA, r.generaciones, func(e *models.Egresado) *int { return &e.IDGeneracion })
+}
+
+func (r *CatalogoMemory) GetEstatus(ctx context.Context, id int) (*models.Estatus, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.estatus[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &e, nil
+}
+
+func (r *CatalogoMemory) CreateEstatus(ctx context.Context, e *models.Estatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, otro := range r.estatus {
+		if strings.EqualFold(otro.Descripcion, e.Descripcion) {
+			return ErrDuplicado
+		}
+	}
+	e.IDEstatus = siguienteID(r.estatus)
+	r.estatus[e.IDEstatus] = *e
+	return nil
+}
+
+func (r *CatalogoMemory) UpdateEstatus(ctx context.Context, e *models.Estatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.estatus[e.IDEstatus]; !ok {
+		return ErrNotFound
+	}
+	for id, otro := range r.estatus {
+		if id != e.IDEstatus && strings.EqualFold(otro.Descripcion, e.Descripcion) {
+			return ErrDuplicado
+		}
+	}
+	r.estatus[e.IDEstatus] = *e
+	return nil
+}
+
+func (r *CatalogoMemory) DeleteEstatus(ctx context.Context, id, reasignarA int) error {
+	return eliminarDeCatalogo(r, id, reasignarA, r.estatus, func(e *models.Egresado) *int { return &e.IDEstatus })
+}
+
+func (r *CatalogoMemory) ListPlanteles(ctx context.Context) ([]models.Plantel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	planteles := []models.Plantel{}
+	for _, p := range r.planteles {
+		planteles = append(planteles, p)
+	}
+	sort.Slice(planteles, func(i, j int) bool { return planteles[i].Clave < planteles[j].Clave })
+	return planteles, nil
+}
+
+func (r *CatalogoMemory) GetPlantel(ctx context.Context, id int) (*models.Plantel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.planteles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (r *CatalogoMemory) GetPlantelPorClave(ctx context.Context, clave string) (*models.Plantel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.planteles {
+		if p.Clave == clave {
+			return &p, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *CatalogoMemory) CreatePlantel(ctx context.Context, p *models.Plantel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.plantelDuplicado(p) {
+		return ErrDuplicado
+	}
+	p.IDPlantel = siguienteID(r.planteles)
+	r.planteles[p.IDPlantel] = *p
+	return nil
+}
+
+func (r *CatalogoMemory) UpdatePlantel(ctx context.Context, p *models.Plantel) error {
+	r.mu.RLock()
+	actual, ok := r.planteles[p.IDPlantel]
+	r.mu.RUnlock()
+	if !ok {
+		return ErrNotFound
+	}
+
+	if actual.Clave != p.Clave && r.egresados != nil {
+		if n := r.egresados.reasignar(func(e *models.Egresado) *int { return &e.IDPlantel }, p.IDPlantel, 0); n > 0 {
+			return &EnUsoError{Egresados: n}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.plantelDuplicado(p) {
+		return ErrDuplicado
+	}
+	r.planteles[p.IDPlantel] = *p
+	return nil
+}
+
+func (r *CatalogoMemory) DeletePlantel(ctx context.Context, id int) error {
+	r.mu.RLock()
+	_, existe := r.planteles[id]
+	r.mu.RUnlock()
+	if !existe {
+		return ErrNotFound
+	}
+
+	var enUso EnUsoError
+	if r.egresados != nil {
+		enUso.Egresados = r.egresados.reasignar(func(e *models.Egresado) *int { return &e.IDPlantel }, id, 0)
+	}
+	if r.usuarios != nil {
+		enUso.Usuarios = r.usuarios.contarPorPlantel(id)
+	}
+	if enUso.Egresados > 0 || enUso.Usuarios > 0 {
+		return &enUso
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.planteles, id)
+	return nil
+}
+
+// plantelDuplicado revisa clave y nombre únicos; requiere el candado
+func (r *CatalogoMemory) plantelDuplicado(p *models.Plantel) bool {
+	for id, otro := range r.planteles {
+		if id != p.IDPlantel && (otro.Clave == p.Clave || strings.EqualFold(otro.Nombre, p.Nombre)) {
+			return true
+		}
+	}
+	return false
+}
+
+// eliminarDeCatalogo simula la transacción de MySQL. Los egresados se revisan antes de
+// tomar el candado del catálogo porque EgresadoMemory lo toma en sentido inverso.
+func eliminarDeCatalogo[T any](r *CatalogoMemory, id, reasignarA int, registros map[int]T, campo func(*models.Egresado) *int) error {
+	r.mu.RLock()
+	_, existe := registros[id]
+	_, destino := registros[reasignarA]
+	r.mu.RUnlock()
+
+	if !existe {
+		return ErrNotFound
+	}
+	if reasignarA != 0 && !destino {
+		return ErrReferenciaInvalida
+	}
+
+	if r.egresados != nil {
+		if n := r.egresados.reasignar(campo, id, reasignarA); n > 0 && reasignarA == 0 {
+			return &EnUsoError{Egresados: n}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(registros, id)
+	return nil
+}
+
+// siguienteID simula AUTO_INCREMENT
+func siguienteID[T any](registros map[int]T) int {
+	max := 0
+	for id := range registros {
+		if id > max {
+			max = id
+		}
+	}
+	return max + 1
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ues-egresados/internal/models"
+)
+
+// CatalogoMySQL implementa CatalogoRepository sobre MySQL
+type CatalogoMySQL struct {
+	db *sql.DB
+}
+
+func NewCatalogoMySQL(db *sql.DB) *CatalogoMySQL {
+	return &CatalogoMySQL{db: db}
+}
+
+func (r *CatalogoMySQL) ListCarreras(ctx context.Context) ([]models.Carrera, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id_carrera, nombre FROM carreras ORDER BY nombre")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	carreras := []models.Carrera{}
+	for rows.Next() {
+		var c models.Carrera
+		if err := rows.Scan(&c.IDCarrera, &c.Nombre); err != nil {
+			return nil, err
+		}
+		carreras = append(carreras, c)
+	}
+	return carreras, rows.Err()
+}
+
+func (r *CatalogoMySQL) ListGeneraciones(ctx context.Context) ([]models.Generacion, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id_generacion, periodo FROM generaciones ORDER BY periodo DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	generaciones := []models.Generacion{}
+	for rows.Next() {
+		var g models.Generacion
+		if err := rows.Scan(&g.IDGeneracion, &g.Periodo); err != nil {
+			return nil, err
+		}
+		generaciones = append(generaciones, g)
+	}
+	return generaciones, rows.Err()
+}
+
+func (r *CatalogoMySQL) ListEstatus(ctx context.Context) ([]models.Estatus, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id_estatus, descripcion FROM estatus ORDER BY descripcion")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	estatusList := []models.Estatus{}
+	for rows.Next() {
+		var e models.Estatus
+		if err := rows.Scan(&e.IDEstatus, &e.Descripcion); err != nil {
+			return nil, err
+		}
+		estatusList = append(estatusList, e)
+	}
+	return estatusList, rows.Err()
+}
+
+// tablaCatalogo describe un catálogo referenciado por egresados. La llave
+// foránea en egresados tiene el mismo nombre que la llave primaria.
+type tablaCatalogo struct {
+	tabla   string
+	id      string
+	columna string
+}
+
+var (
+	tablaCarreras     = tablaCatalogo{tabla: "carreras", id: "id_carrera", columna: "nombre"}
+	tablaGeneraciones = tablaCatalogo{tabla: "generaciones", id: "id_generacion", columna: "periodo"}
+	tablaEstatus      = tablaCatalogo{tabla: "estatus", id: "id_estatus", columna: "descripcion"}
+)
+
+func (r *CatalogoMySQL) GetCarrera(ctx context.Context, id int) (*models.Carrera, error) {
+	c := models.Carrera{IDCarrera: id}
+	if err := r.obtener(ctx, tablaCarreras, id, &c.Nombre); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CatalogoMySQL) CreateCarrera(ctx context.Context, c *models.Carrera) error {
+	return r.crear(ctx, tablaCarreras, c.Nombre, &c.IDCarrera)
+}
+
+func (r *CatalogoMySQL) UpdateCarrera(ctx context.Context, c *models.Carrera) error {
+	return r.actualizar(ctx, tablaCarreras, c.IDCarrera, c.Nombre)
+}
+
+func (r *CatalogoMySQL) DeleteCarrera(ctx context.Context, id, reasignarA int) error {
+	return r.eliminar(ctx, tablaCarreras, id, reasignarA)
+}
+
+func (r *CatalogoMySQL) GetGeneracion(ctx context.Context, id int) (*models.Generacion, error) {
+	g := models.Generacion{IDGeneracion: id}
+	if err := r.obtener(ctx, tablaGeneraciones, id, &g.Periodo); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *CatalogoMySQL) CreateGeneracion(ctx context.Context, g *models.Generacion) error {
+	return r.crear(ctx, tablaGeneraciones, g.Periodo, &g.IDGeneracion)
+}
+
+func (r *CatalogoMySQL) UpdateGeneracion(ctx context.Context, g *models.Generacion) error {
+	return r.actualizar(ctx, tablaGeneraciones, g.IDGeneracion, g.Periodo)
+}
+
+func (r *CatalogoMySQL) DeleteGeneracion(ctx context.Context, id, reasignarA int) error {
+	return r.eliminar(ctx, tablaGeneraciones, id, reasignarA)
+}
+
+func (r *CatalogoMySQL) GetEstatus(ctx context.Context, id int) (*models.Estatus, error) {
+	e := models.Estatus{IDEstatus: id}
+	if err := r.obtener(ctx, tablaEstatus, id, &e.Descripcion); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *CatalogoMySQL) CreateEstatus(ctx context.Context, e *models.Estatus) error {
+	return r.crear(ctx, tablaEstatus, e.Descripcion, &e.IDEstatus)
+}
+
+func (r *CatalogoMySQL) UpdateEstatus(ctx context.Context, e *models.Estatus) error {
+	return r.actualizar(ctx, tablaEstatus, e.IDEstatus, e.Descripcion)
+}
+
+func (r *CatalogoMySQL) DeleteEstatus(ctx context.Context, id, reasignarA int) error {
+	return r.eliminar(ctx, tablaEstatus, id, reasignarA)
+}
+
+func (r *CatalogoMySQL) obtener(ctx context.Context, t tablaCatalogo, id int, valor *string) error {
+	err := r.db.QueryRowContext(ctx,
+		"SELECT "+t.columna+" FROM "+t.tabla+" WHERE "+t.id+" = ?", id,
+	).Scan(valor)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *CatalogoMySQL) crear(ctx context.Context, t tablaCatalogo, valor string, id *int) error {
+	result, err := r.db.ExecContext(ctx, "INSERT INTO "+t.tabla+" ("+t.columna+") VALUES (?)", valor)
+	if err != nil {
+		return traducirError(err)
+	}
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	*id = int(lastID)
+	return nil
+}
+
+func (r *CatalogoMySQL) actualizar(ctx context.Context, t tablaCatalogo, id int, valor string) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE "+t.tabla+" SET "+t.columna+" = ? WHERE "+t.id+" = ?", valor, id)
+	if err != nil {
+		return traducirError(err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		// MySQL no cuenta las filas sin cambios; distinguir de un ID inexistente
+		var actual string
+		return r.obtener(ctx, t, id, &actual)
+	}
+	return nil
+}
+
+// eliminar borra el registro dentro de una transacción; si se indica
+// reasignarA primero mueve a los egresados y les sube la versión
+func (r *CatalogoMySQL) eliminar(ctx context.Context, t tablaCatalogo, id, reasignarA int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var existe int
+	err = tx.QueryRowContext(ctx, "SELECT 1 FROM "+t.tabla+" WHERE "+t.id+" = ? FOR UPDATE", id).Scan(&existe)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if reasignarA != 0 {
+		err := tx.QueryRowContext(ctx, "SELECT 1 FROM "+t.tabla+" WHERE "+t.id+" = ?", reasignarA).Scan(&existe)
+		if err == sql.ErrNoRows {
+			return ErrReferenciaInvalida
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE egresados SET "+t.id+" = ?, version = version + 1 WHERE "+t.id+" = ?",
+			reasignarA, id,
+		)
+		if err != nil {
+			return traducirError(err)
+		}
+	} else {
+		var enUso int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM egresados WHERE "+t.id+" = ?", id).Scan(&enUso); err != nil {
+			return err
+		}
+		if enUso > 0 {
+			return &EnUsoError{Egresados: enUso}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+t.tabla+" WHERE "+t.id+" = ?", id); err != nil {
+		return traducirError(err)
+	}
+	return tx.Commit()
+}
+
+func (r *CatalogoMySQL) ListPlanteles(ctx context.Context) ([]models.Plantel, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id_plantel, clave, nombre FROM planteles ORDER BY clave")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	planteles := []models.Plantel{}
+	for rows.Next() {
+		var p models.Plantel
+		if err := rows.Scan(&p.IDPlantel, &p.Clave, &p.Nombre); err != nil {
+			return nil, err
+		}
+		planteles = append(planteles, p)
+	}
+	return planteles, rows.Err()
+}
+
+func (r *CatalogoMySQL) GetPlantel(ctx context.Context, id int) (*models.Plantel, error) {
+	return r.buscarPlantel(ctx, "id_plantel", id)
+}
+
+func (r *CatalogoMySQL) GetPlantelPorClave(ctx context.Context, clave string) (*models.Plantel, error) {
+	return r.buscarPlantel(ctx, "clave", clave)
+}
+
+func (r *CatalogoMySQL) buscarPlantel(ctx context.Context, columna string, valor interface{}) (*models.Plantel, error) {
+	var p models.Plantel
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id_plantel, clave, nombre FROM planteles WHERE "+columna+" = ?", valor,
+	).Scan(&p.IDPlantel, &p.Clave, &p.Nombre)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *CatalogoMySQL) CreatePlantel(ctx context.Context, p *models.Plantel) error {
+	result, err := r.db.ExecContext(ctx, "INSERT INTO planteles (clave, nombre) VALUES (?, ?)", p.Clave, p.Nombre)
+	if err != nil {
+		return traducirError(err)
+	}
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	p.IDPlantel = int(lastID)
+	return nil
+}
+
+func (r *CatalogoMySQL) UpdatePlantel(ctx context.Context, p *models.Plantel) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var clave string
+	err = tx.QueryRowContext(ctx, "SELECT clave FROM planteles WHERE id_plantel = ? FOR UPDATE", p.IDPlantel).Scan(&clave)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if clave != p.Clave {
+		var egresados int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM egresados WHERE id_plantel = ?", p.IDPlantel).Scan(&egresados); err != nil {
+			return err
+		}
+		if egresados > 0 {
+			return &EnUsoError{Egresados: egresados}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE planteles SET clave = ?, nombre = ? WHERE id_plantel = ?", p.Clave, p.Nombre, p.IDPlantel,
+	); err != nil {
+		return traducirError(err)
+	}
+	return tx.Commit()
+}
+
+func (r *CatalogoMySQL) DeletePlantel(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var existe int
+	err = tx.QueryRowContext(ctx, "SELECT 1 FROM planteles WHERE id_plantel = ? FOR UPDATE", id).Scan(&existe)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var enUso EnUsoError
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM egresados WHERE id_plantel = ?),
+			(SELECT COUNT(*) FROM usuarios WHERE id_plantel = ?)
+	`, id, id).Scan(&enUso.Egresados, &enUso.Usuarios)
+	if err != nil {
+		return err
+	}
+	if enUso.Egresados > 0 || enUso.Usuarios > 0 {
+		return &enUso
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM planteles WHERE id_plantel = ?", id); err != nil {
+		return traducirError(err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"ues-egresados/internal/models"
+)
+
+// CodigoPostalMemory implementa CodigoPostalRepository en memoria
+type CodigoPostalMemory struct {
+	mu      sync.RWMutex
+	codigos []models.CodigoPostal
+}
+
+func NewCodigoPostalMemory() *CodigoPostalMemory {
+	return &CodigoPostalMemory{}
+}
+
+// Add registra un código postal (para preparar datos de prueba)
+func (r *CodigoPostalMemory) Add(cp models.CodigoPostal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp.ID = len(r.codigos) + 1
+	r.codigos = append(r.codigos, cp)
+}
+
+func (r *CodigoPostalMemory) BuscarPorCodigo(ctx context.Context, cp string) (*models.ResultadoCodigoPostal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var resultado *models.ResultadoCodigoPostal
+	asentamientos := map[string]bool{}
+	for _, c := range r.codigos {
+		if c.Codigo != cp {
+			continue
+		}
+		if resultado == nil {
+			resultado = &models.ResultadoCodigoPostal{CodigoPostal: cp, Estado: c.Estado, Municipio: c.Municipio}
+		}
+		asentamientos[c.Asentamiento] = true
+	}
+
+	if resultado == nil {
+		return nil, ErrNotFound
+	}
+	resultado.Asentamientos = ordenados(asentamientos)
+	return resultado, nil
+}
+
+func (r *CodigoPostalMemory) ListEstados(ctx context.Context) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	estados := map[string]bool{}
+	for _, c := range r.codigos {
+		estados[c.Estado] = true
+	}
+	return ordenados(estados), nil
+}
+
+func (r *CodigoPostalMemory) ListMunicipios(ctx context.Context, estado string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	municipios := map[string]bool{}
+	for _, c := range r.codigos {
+		if c.Estado == estado {
+			municipios[c.Municipio] = true
+		}
+	}
+	return ordenados(municipios), nil
+}
+
+func (r *CodigoPostalMemory) ListAsentamientos(ctx context.Context, estado, municipio string) ([]models.AsentamientoCP, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	vistos := map[models.AsentamientoCP]bool{}
+	asentamientos := []models.AsentamientoCP{}
+	for _, c := range r.codigos {
+		a := models.AsentamientoCP{Asentamiento: c.Asentamiento, CodigoPostal: c.Codigo}
+		if c.Estado == estado && c.Municipio == municipio && !vistos[a] {
+			vistos[a] = true
+			asentamientos = append(asentamientos, a)
+		}
+	}
+	sort.Slice(asentamientos, func(i, j int) bool {
+		return asentamientos[i].Asentamiento < asentamientos[j].Asentamiento
+	})
+	return asentamientos, nil
+}
+
+func ordenados(conjunto map[string]bool) []string {
+	valores := []string{}
+	for v := range conjunto {
+		valores = append(valores, v)
+	}
+	sort.Strings(valores)
+	return valores
+}
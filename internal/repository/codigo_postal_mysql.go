@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ues-egresados/internal/models"
+)
+
+// CodigoPostalMySQL implementa CodigoPostalRepository sobre MySQL
+type CodigoPostalMySQL struct {
+	db *sql.DB
+}
+
+func NewCodigoPostalMySQL(db *sql.DB) *CodigoPostalMySQL {
+	return &CodigoPostalMySQL{db: db}
+}
+
+func (r *CodigoPostalMySQL) BuscarPorCodigo(ctx context.Context, cp string) (*models.ResultadoCodigoPostal, error) {
+	// Obtener estado y municipio
+	query := `
+		SELECT DISTINCT d_estado, d_mnpio
+		FROM codigos_postales
+		WHERE d_codigo = ?
+		LIMIT 1
+	`
+
+	resultado := models.ResultadoCodigoPostal{CodigoPostal: cp}
+	err := r.db.QueryRowContext(ctx, query, cp).Scan(&resultado.Estado, &resultado.Municipio)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Obtener asentamientos
+	asentamientos, err := r.listStrings(ctx, `
+		SELECT DISTINCT d_asenta
+		FROM codigos_postales
+		WHERE d_codigo = ?
+		ORDER BY d_asenta
+	`, cp)
+	if err != nil {
+		return nil, err
+	}
+	resultado.Asentamientos = asentamientos
+
+	return &resultado, nil
+}
+
+func (r *CodigoPostalMySQL) ListEstados(ctx context.Context) ([]string, error) {
+	return r.listStrings(ctx, `
+		SELECT DISTINCT d_estado
+		FROM codigos_postales
+		ORDER BY d_estado
+	`)
+}
+
+func (r *CodigoPostalMySQL) ListMunicipios(ctx context.Context, estado string) ([]string, error) {
+	return r.listStrings(ctx, `
+		SELECT DISTINCT d_mnpio
+		FROM codigos_postales
+		WHERE d_estado = ?
+		ORDER BY d_mnpio
+	`, estado)
+}
+
+func (r *CodigoPostalMySQL) ListAsentamientos(ctx context.Context, estado, municipio string) ([]models.AsentamientoCP, error) {
+	query := `
+		SELECT DISTINCT d_asenta, d_codigo
+		FROM codigos_postales
+		WHERE d_estado = ? AND d_mnpio = ?
+		ORDER BY d_asenta
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, estado, municipio)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	asentamientos := []models.AsentamientoCP{}
+	for rows.Next() {
+		var a models.AsentamientoCP
+		if err := rows.Scan(&a.Asentamiento, &a.CodigoPostal); err != nil {
+			return nil, err
+		}
+		asentamientos = append(asentamientos, a)
+	}
+	return asentamientos, rows.Err()
+}
+
+// listStrings ejecuta una consulta de una sola columna de texto
+func (r *CodigoPostalMySQL) listStrings(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	valores := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		valores = append(valores, v)
+	}
+	return valores, rows.Err()
+}
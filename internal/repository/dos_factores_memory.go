@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// DosFactoresMemory implementa DosFactoresRepository en memoria
+type DosFactoresMemory struct {
+	mu          sync.RWMutex
+	dosFactores map[int]models.DosFactores
+	// codigos guarda, por usuario, si cada hash de recuperación ya se usó
+	codigos map[int]map[string]bool
+}
+
+func NewDosFactoresMemory() *DosFactoresMemory {
+	return &DosFactoresMemory{
+		dosFactores: map[int]models.DosFactores{},
+		codigos:     map[int]map[string]bool{},
+	}
+}
+
+func (r *DosFactoresMemory) Get(ctx context.Context, idUsuario int) (*models.DosFactores, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.dosFactores[idUsuario]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &d, nil
+}
+
+func (r *DosFactoresMemory) GuardarPendiente(ctx context.Context, idUsuario int, secreto string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if d, ok := r.dosFactores[idUsuario]; ok && d.Activo {
+		return nil
+	}
+	r.dosFactores[idUsuario] = models.DosFactores{IDUsuario: idUsuario, Secreto: secreto, CreatedAt: time.Now()}
+	return nil
+}
+
+func (r *DosFactoresMemory) Activar(ctx context.Context, idUsuario int, paso int64, codigosHash []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.dosFactores[idUsuario]
+	if !ok || d.Activo {
+		return ErrNotFound
+	}
+	ahora := time.Now()
+	d.Activo = true
+	d.UltimoPaso = paso
+	d.ActivadoEn = &ahora
+	r.dosFactores[idUsuario] = d
+	r.reemplazarCodigos(idUsuario, codigosHash)
+	return nil
+}
+
+func (r *DosFactoresMemory) RegistrarPaso(ctx context.Context, idUsuario int, paso int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.dosFactores[idUsuario]
+	if !ok || !d.Activo || d.UltimoPaso >= paso {
+		return false, nil
+	}
+	d.UltimoPaso = paso
+	r.dosFactores[idUsuario] = d
+	return true, nil
+}
+
+func (r *DosFactoresMemory) ReemplazarCodigos(ctx context.Context, idUsuario int, codigosHash []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reemplazarCodigos(idUsuario, codigosHash)
+	return nil
+}
+
+func (r *DosFactoresMemory) reemplazarCodigos(idUsuario int, codigosHash []string) {
+	codigos := map[string]bool{}
+	for _, h := range codigosHash {
+		codigos[h] = false
+	}
+	r.codigos[idUsuario] = codigos
+}
+
+func (r *DosFactoresMemory) UsarCodigo(ctx context.Context, idUsuario int, codigoHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usado, ok := r.codigos[idUsuario][codigoHash]
+	if !ok || usado {
+		return ErrNotFound
+	}
+	r.codigos[idUsuario][codigoHash] = true
+	return nil
+}
+
+func (r *DosFactoresMemory) CodigosRestantes(ctx context.Context, idUsuario int) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	for _, usado := range r.codigos[idUsuario] {
+		if !usado {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *DosFactoresMemory) Delete(ctx context.Context, idUsuario int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.dosFactores[idUsuario]; !ok {
+		return ErrNotFound
+	}
+	delete(r.dosFactores, idUsuario)
+	delete(r.codigos, idUsuario)
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ues-egresados/internal/models"
+)
+
+// DosFactoresMySQL implementa DosFactoresRepository sobre MySQL
+type DosFactoresMySQL struct {
+	db *sql.DB
+}
+
+func NewDosFactoresMySQL(db *sql.DB) *DosFactoresMySQL {
+	return &DosFactoresMySQL{db: db}
+}
+
+func (r *DosFactoresMySQL) Get(ctx context.Context, idUsuario int) (*models.DosFactores, error) {
+	var d models.DosFactores
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id_usuario, secreto, activo, ultimo_paso, activado_en, created_at
+		FROM dos_factores
+		WHERE id_usuario = ?
+	`, idUsuario).Scan(&d.IDUsuario, &d.Secreto, &d.Activo, &d.UltimoPaso, &d.ActivadoEn, &d.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *DosFactoresMySQL) GuardarPendiente(ctx context.Context, idUsuario int, secreto string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO dos_factores (id_usuario, secreto) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE secreto = IF(activo, secreto, VALUES(secreto)), created_at = IF(activo, created_at, CURRENT_TIMESTAMP)
+	`, idUsuario, secreto)
+	return traducirError(err)
+}
+
+func (r *DosFactoresMySQL) Activar(ctx context.Context, idUsuario int, paso int64, codigosHash []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = filaAfectada(tx.ExecContext(ctx, `
+		UPDATE dos_factores SET activo = TRUE, ultimo_paso = ?, activado_en = CURRENT_TIMESTAMP
+		WHERE id_usuario = ? AND NOT activo
+	`, paso, idUsuario))
+	if err != nil {
+		return err
+	}
+	if err := reemplazarCodigos(ctx, tx, idUsuario, codigosHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *DosFactoresMySQL) RegistrarPaso(ctx context.Context, idUsuario int, paso int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE dos_factores SET ultimo_paso = ? WHERE id_usuario = ? AND activo AND ultimo_paso < ?",
+		paso, idUsuario, paso)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *DosFactoresMySQL) ReemplazarCodigos(ctx context.Context, idUsuario int, codigosHash []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := reemplazarCodigos(ctx, tx, idUsuario, codigosHash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func reemplazarCodigos(ctx context.Context, tx *sql.Tx, idUsuario int, codigosHash []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM codigos_recuperacion WHERE id_usuario = ?", idUsuario); err != nil {
+		return err
+	}
+	for _, h := range codigosHash {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO codigos_recuperacion (id_usuario, codigo_hash) VALUES (?, ?)", idUsuario, h); err != nil {
+			return traducirError(err)
+		}
+	}
+	return nil
+}
+
+func (r *DosFactoresMySQL) UsarCodigo(ctx context.Context, idUsuario int, codigoHash string) error {
+	return filaAfectada(r.db.ExecContext(ctx, `
+		UPDATE codigos_recuperacion SET usado_en = CURRENT_TIMESTAMP
+		WHERE id_usuario = ? AND codigo_hash = ? AND usado_en IS NULL
+	`, idUsuario, codigoHash))
+}
+
+func (r *DosFactoresMySQL) CodigosRestantes(ctx context.Context, idUsuario int) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM codigos_recuperacion WHERE id_usuario = ? AND usado_en IS NULL", idUsuario).Scan(&n)
+	return n, err
+}
+
+func (r *DosFactoresMySQL) Delete(ctx context.Context, idUsuario int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM codigos_recuperacion WHERE id_usuario = ?", idUsuario); err != nil {
+		return err
+	}
+	if err := filaAfectada(tx.ExecContext(ctx, "DELETE FROM dos_factores WHERE id_usuario = ?", idUsuario)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"ues-egresados/internal/cifrado"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/utils"
+)
+
+// errSinCifrador indica una fila cifrada cuando no hay llaves configuradas
+var errSinCifrador = errors.New("hay datos cifrados y CIFRADO_LLAVES no está configurada")
+
+// filaCifrada son las columnas de datos personales tal como se leen de la tabla
+type filaCifrada struct {
+	telefono, correo, codigoPostal, estado, municipio []byte
+	asentamiento, calle, numero                       []byte
+	llave                                             sql.NullString
+}
+
+// datosCifrados son los valores que se escriben en las columnas de datos
+// personales y sus índices ciegos
+type datosCifrados struct {
+	telefono, correo, codigoPostal, estado, municipio interface{}
+	asentamiento, calle, numero                       interface{}
+	llave                                             interface{}
+
+	indiceTelefono, indiceCorreo, indiceEstado, indiceMunicipio interface{}
+}
+
+func (r *EgresadoMySQL) cifrar(matricula string, e *models.Egresado) (*datosCifrados, error) {
+	return cifrarCon(r.cifrador, matricula, e)
+}
+
+// cifrarCon cifra los datos personales con una llave de datos nueva. Sin
+// cifrador los valores quedan en texto plano y sin índices.
+func cifrarCon(cifrador *cifrado.Cifrador, matricula string, e *models.Egresado) (*datosCifrados, error) {
+	if cifrador == nil {
+		return &datosCifrados{
+			telefono:     e.Telefono,
+			correo:       e.Correo,
+			codigoPostal: e.CodigoPostal,
+			estado:       e.Estado,
+			municipio:    e.Municipio,
+			asentamiento: e.Asentamiento,
+			calle:        e.Calle,
+			numero:       e.Numero,
+		}, nil
+	}
+
+	llave, envuelta, err := cifrador.NuevaLlaveDatos(matricula)
+	if err != nil {
+		return nil, err
+	}
+
+	var errCifrar error
+	cifrar := func(campo string, valor *string) interface{} {
+		if valor == nil || errCifrar != nil {
+			return nil
+		}
+		datos, err := llave.Cifrar(*valor, campo)
+		if err != nil {
+			errCifrar = err
+			return nil
+		}
+		return datos
+	}
+	indice := func(campo string, valor *string) interface{} {
+		if valor == nil {
+			return nil
+		}
+		if i := cifrador.Indice(campo, *valor); i != nil {
+			return i
+		}
+		return nil
+	}
+
+	d := &datosCifrados{
+		telefono:     cifrar("telefono", e.Telefono),
+		correo:       cifrar("correo", e.Correo),
+		codigoPostal: cifrar("codigo_postal", e.CodigoPostal),
+		estado:       cifrar("estado", e.Estado),
+		municipio:    cifrar("municipio", e.Municipio),
+		asentamiento: cifrar("asentamiento", e.Asentamiento),
+		calle:        cifrar("calle", e.Calle),
+		numero:       cifrar("numero", e.Numero),
+		llave:        envuelta,
+
+		indiceTelefono:  indice(cifrado.CampoTelefono, e.Telefono),
+		indiceCorreo:    indice(cifrado.CampoCorreo, e.Correo),
+		indiceEstado:    indice(cifrado.CampoEstado, e.Estado),
+		indiceMunicipio: indice(cifrado.CampoMunicipio, e.Municipio),
+	}
+	if errCifrar != nil {
+		return nil, errCifrar
+	}
+	return d, nil
+}
+
+// descifrar completa los datos personales del egresado. Las filas sin
+// llave_datos todavía no se cifran y se leen tal cual.
+func (r *EgresadoMySQL) descifrar(e *models.Egresado, c *filaCifrada) error {
+	if !c.llave.Valid {
+		for _, campo := range c.campos(e) {
+			if campo.datos != nil {
+				valor := string(campo.datos)
+				*campo.destino = &valor
+			}
+		}
+		return nil
+	}
+
+	if r.cifrador == nil {
+		return errSinCifrador
+	}
+	llave, err := r.cifrador.AbrirLlaveDatos(c.llave.String, e.Matricula)
+	if err != nil {
+		return fmt.Errorf("matrícula %s: %w", e.Matricula, err)
+	}
+	for _, campo := range c.campos(e) {
+		if campo.datos == nil {
+			continue
+		}
+		valor, err := llave.Descifrar(campo.datos, campo.nombre)
+		if err != nil {
+			return fmt.Errorf("matrícula %s, %s: %w", e.Matricula, campo.nombre, err)
+		}
+		*campo.destino = &valor
+	}
+	return nil
+}
+
+type campoCifrado struct {
+	nombre  string
+	datos   []byte
+	destino **string
+}
+
+func (c *filaCifrada) campos(e *models.Egresado) []campoCifrado {
+	return []campoCifrado{
+		{"telefono", c.telefono, &e.Telefono},
+		{"correo", c.correo, &e.Correo},
+		{"codigo_postal", c.codigoPostal, &e.CodigoPostal},
+		{"estado", c.estado, &e.Estado},
+		{"municipio", c.municipio, &e.Municipio},
+		{"asentamiento", c.asentamiento, &e.Asentamiento},
+		{"calle", c.calle, &e.Calle},
+		{"numero", c.numero, &e.Numero},
+	}
+}
+
+// coincidenciaExacta compara una columna cifrada por su índice ciego. Las
+// filas que aún no se cifran se comparan en texto plano con la intercalación
+// de antes, que tampoco distingue mayúsculas ni acentos.
+func (r *EgresadoMySQL) coincidenciaExacta(columna, campo, valor string) (string, []interface{}) {
+	textoPlano := fmt.Sprintf("CONVERT(e.%s USING utf8mb4) COLLATE utf8mb4_unicode_ci = ?", columna)
+	if r.cifrador == nil {
+		return textoPlano, []interface{}{valor}
+	}
+	return fmt.Sprintf("(e.%s_indice = ? OR (e.llave_datos IS NULL AND %s))", columna, textoPlano),
+		[]interface{}{r.cifrador.Indice(campo, valor), valor}
+}
+
+// pareceCorreo y pareceTelefono deciden si la búsqueda libre también se
+// compara con el correo o el teléfono
+func pareceCorreo(q string) bool {
+	return utils.ValidateEmail(q) && q != ""
+}
+
+func pareceTelefono(q string) bool {
+	return utils.ValidateTelefono(q) && len(cifrado.Normalizar(cifrado.CampoTelefono, q)) >= 10
+}
+
+// OpcionesRecifrado controla Recifrar
+type OpcionesRecifrado struct {
+	// Lote es el número de filas por transacción
+	Lote int
+	// Todas recifra también las filas que ya usan la llave activa, por
+	// ejemplo después de cambiar CIFRADO_LLAVE_INDICES
+	Todas bool
+	// Descifrar deja los datos en texto plano, antes de revertir la
+	// migración 0015
+	Descifrar bool
+}
+
+// Recifrar vuelve a cifrar con la llave activa, y con una llave de datos
+// nueva, el siguiente lote de egresados después de la matrícula despuesDe,
+// incluidos los de la papelera. Las filas se bloquean mientras se reescriben
+// para no pisar una edición simultánea; la versión y updated_at no cambian.
+// Devuelve cuántas filas reescribió y la última matrícula del lote ("" si ya
+// no quedan).
+func (r *EgresadoMySQL) Recifrar(ctx context.Context, despuesDe string, op OpcionesRecifrado) (int, string, error) {
+	if r.cifrador == nil {
+		return 0, "", errors.New("se necesita CIFRADO_LLAVES para recifrar")
+	}
+	if op.Lote <= 0 {
+		op.Lote = 500
+	}
+
+	query := `
+		SELECT matricula, telefono, correo, codigo_postal, estado, municipio,
+		       asentamiento, calle, numero, llave_datos
+		FROM egresados
+		WHERE matricula > ?`
+	args := []interface{}{despuesDe}
+	switch {
+	case op.Descifrar:
+		query += " AND llave_datos IS NOT NULL"
+	case !op.Todas:
+		query += " AND (llave_datos IS NULL OR llave_datos NOT LIKE ?)"
+		args = append(args, escapeLike(r.cifrador.LlaveActiva())+":%")
+	}
+	query += " ORDER BY matricula LIMIT ? FOR UPDATE"
+	args = append(args, op.Lote)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	var egresados []models.Egresado
+	for rows.Next() {
+		var e models.Egresado
+		var c filaCifrada
+		if err := rows.Scan(&e.Matricula, &c.telefono, &c.correo, &c.codigoPostal, &c.estado,
+			&c.municipio, &c.asentamiento, &c.calle, &c.numero, &c.llave); err != nil {
+			rows.Close()
+			return 0, "", err
+		}
+		if err := r.descifrar(&e, &c); err != nil {
+			rows.Close()
+			return 0, "", err
+		}
+		egresados = append(egresados, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, "", err
+	}
+	if len(egresados) == 0 {
+		return 0, "", nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE egresados
+		SET telefono = ?, correo = ?, codigo_postal = ?, estado = ?, municipio = ?,
+		    asentamiento = ?, calle = ?, numero = ?,
+		    llave_datos = ?, telefono_indice = ?, correo_indice = ?,
+		    estado_indice = ?, municipio_indice = ?,
+		    updated_at = updated_at
+		WHERE matricula = ?
+	`)
+	if err != nil {
+		return 0, "", err
+	}
+	defer stmt.Close()
+
+	destino := r.cifrador
+	if op.Descifrar {
+		destino = nil
+	}
+	for i := range egresados {
+		e := &egresados[i]
+		c, err := cifrarCon(destino, e.Matricula, e)
+		if err != nil {
+			return 0, "", fmt.Errorf("matrícula %s: %w", e.Matricula, err)
+		}
+		if _, err := stmt.ExecContext(ctx,
+			c.telefono, c.correo, c.codigoPostal, c.estado, c.municipio,
+			c.asentamiento, c.calle, c.numero,
+			c.llave, c.indiceTelefono, c.indiceCorreo,
+			c.indiceEstado, c.indiceMunicipio,
+			e.Matricula,
+		); err != nil {
+			return 0, "", fmt.Errorf("matrícula %s: %w", e.Matricula, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, "", err
+	}
+	return len(egresados), egresados[len(egresados)-1].Matricula, nil
+}
+
+// ConteoLlaves devuelve cuántos egresados hay por ID de llave maestra; la
+// clave "" cuenta las filas sin cifrar
+func (r *EgresadoMySQL) ConteoLlaves(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(SUBSTRING_INDEX(llave_datos, ':', 1), ''), COUNT(*)
+		FROM egresados
+		GROUP BY 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conteo := map[string]int{}
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		conteo[id] = n
+	}
+	return conteo, rows.Err()
+}
@@ -0,0 +1,460 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"ues-egresados/internal/cifrado"
+	"ues-egresados/internal/models"
+)
+
+// EgresadoMemory implementa EgresadoRepository en memoria
+type EgresadoMemory struct {
+	mu        sync.RWMutex
+	egresados map[string]models.Egresado
+	catalogos *CatalogoMemory
+}
+
+func NewEgresadoMemory(catalogos *CatalogoMemory) *EgresadoMemory {
+	return &EgresadoMemory{
+		egresados: map[string]models.Egresado{},
+		catalogos: catalogos,
+	}
+}
+
+func (r *EgresadoMemory) List(ctx context.Context, filtro models.FiltroEgresados) ([]models.Egresado, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	egresados := []models.Egresado{}
+	for _, e := range r.egresados {
+		if e.DeletedAt == nil && coincideFiltro(e, filtro) {
+			egresados = append(egresados, r.conRelaciones(e))
+		}
+	}
+
+	ordenarEgresados(egresados, filtro)
+	total := len(egresados)
+
+	if filtro.PerPage > 0 {
+		inicio := (filtro.Page - 1) * filtro.PerPage
+		if inicio > total {
+			inicio = total
+		}
+		fin := inicio + filtro.PerPage
+		if fin > total {
+			fin = total
+		}
+		egresados = egresados[inicio:fin]
+	}
+
+	return egresados, total, nil
+}
+
+func (r *EgresadoMemory) Recorrer(ctx context.Context, filtro models.FiltroEgresados, fn func(*models.Egresado) error) error {
+	filtro.PerPage = 0
+	egresados, _, err := r.List(ctx, filtro)
+	if err != nil {
+		return err
+	}
+
+	for i := range egresados {
+		if err := fn(&egresados[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *EgresadoMemory) Get(ctx context.Context, matricula string) (*models.Egresado, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.egresados[matricula]
+	if !ok || e.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	e = r.conRelaciones(e)
+	return &e, nil
+}
+
+func (r *EgresadoMemory) Create(ctx context.Context, e *models.Egresado) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.egresados[e.Matricula]; ok {
+		return ErrDuplicado
+	}
+	if !r.catalogos.referenciasValidas(e) {
+		return ErrReferenciaInvalida
+	}
+
+	nuevo := *e
+	nuevo.CreatedAt = time.Now()
+	nuevo.UpdatedAt = nuevo.CreatedAt
+	nuevo.Version = 1
+	r.egresados[e.Matricula] = nuevo
+	return nil
+}
+
+func (r *EgresadoMemory) Update(ctx context.Context, matricula string, e *models.Egresado) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	actual, ok := r.egresados[matricula]
+	if !ok || actual.DeletedAt != nil {
+		return ErrNotFound
+	}
+	if e.Version != actual.Version {
+		return ErrConflictoVersion
+	}
+	if !r.catalogos.referenciasValidas(e) {
+		return ErrReferenciaInvalida
+	}
+
+	actualizado := *e
+	actualizado.Matricula = matricula
+	actualizado.CreatedAt = actual.CreatedAt
+	actualizado.UpdatedAt = time.Now()
+	actualizado.Version = actual.Version + 1
+	r.egresados[matricula] = actualizado
+	return nil
+}
+
+func (r *EgresadoMemory) Delete(ctx context.Context, matricula string, eliminadoPor int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.egresados[matricula]
+	if !ok || e.DeletedAt != nil {
+		return ErrNotFound
+	}
+	ahora := time.Now()
+	e.DeletedAt = &ahora
+	if eliminadoPor != 0 {
+		e.DeletedBy = &eliminadoPor
+	}
+	r.egresados[matricula] = e
+	return nil
+}
+
+func (r *EgresadoMemory) ListEliminados(ctx context.Context) ([]models.Egresado, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	egresados := []models.Egresado{}
+	for _, e := range r.egresados {
+		if e.DeletedAt != nil {
+			egresados = append(egresados, r.conRelaciones(e))
+		}
+	}
+	sort.Slice(egresados, func(i, j int) bool {
+		return egresados[i].DeletedAt.After(*egresados[j].DeletedAt)
+	})
+	return egresados, nil
+}
+
+func (r *EgresadoMemory) GetEliminado(ctx context.Context, matricula string) (*models.Egresado, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.egresados[matricula]
+	if !ok || e.DeletedAt == nil {
+		return nil, ErrNotFound
+	}
+	e = r.conRelaciones(e)
+	return &e, nil
+}
+
+func (r *EgresadoMemory) Restaurar(ctx context.Context, matricula string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.egresados[matricula]
+	if !ok || e.DeletedAt == nil {
+		return ErrNotFound
+	}
+	e.DeletedAt, e.DeletedBy = nil, nil
+	r.egresados[matricula] = e
+	return nil
+}
+
+func (r *EgresadoMemory) Purgar(ctx context.Context, matricula string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.egresados[matricula]
+	if !ok || e.DeletedAt == nil {
+		return ErrNotFound
+	}
+	delete(r.egresados, matricula)
+	return nil
+}
+
+func (r *EgresadoMemory) PurgarEliminadosAntes(ctx context.Context, limite time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purgados int64
+	for matricula, e := range r.egresados {
+		if e.DeletedAt != nil && e.DeletedAt.Before(limite) {
+			delete(r.egresados, matricula)
+			purgados++
+		}
+	}
+	return purgados, nil
+}
+
+func (r *EgresadoMemory) StatsPorGeneracion(ctx context.Context, plantel string) ([]models.GeneracionStats, error) {
+	generaciones, _ := r.catalogos.ListGeneraciones(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := []models.GeneracionStats{}
+	for _, g := range generaciones {
+		s := models.GeneracionStats{IDGeneracion: g.IDGeneracion, Periodo: g.Periodo}
+		for _, e := range r.egresados {
+			if e.DeletedAt == nil && e.IDGeneracion == g.IDGeneracion && coincideID(plantel, e.IDPlantel) {
+				s.TotalEgresados++
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+func (r *EgresadoMemory) StatsPorCarrera(ctx context.Context, idGeneracion, plantel string) ([]models.CarreraStats, error) {
+	carreras, _ := r.catalogos.ListCarreras(ctx)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := []models.CarreraStats{}
+	for _, c := range carreras {
+		s := models.CarreraStats{IDCarrera: c.IDCarrera, Nombre: c.Nombre}
+		for _, e := range r.egresados {
+			if e.DeletedAt == nil && e.IDCarrera == c.IDCarrera && coincideID(idGeneracion, e.IDGeneracion) && coincideID(plantel, e.IDPlantel) {
+				s.TotalEgresados++
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+func (r *EgresadoMemory) StatsPorEstatus(ctx context.Context, plantel string) ([]models.EstatusStats, error) {
+	estatus, _ := r.catalogos.ListEstatus(ctx)
+	sort.Slice(estatus, func(i, j int) bool { return estatus[i].IDEstatus < estatus[j].IDEstatus })
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := []models.EstatusStats{}
+	for _, s := range estatus {
+		st := models.EstatusStats{IDEstatus: s.IDEstatus, Descripcion: s.Descripcion}
+		for _, e := range r.egresados {
+			if e.DeletedAt == nil && e.IDEstatus == s.IDEstatus && coincideID(plantel, e.IDPlantel) {
+				st.TotalEgresados++
+			}
+		}
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+func (r *EgresadoMemory) Existentes(ctx context.Context, matriculas []string) (map[string]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	existentes := map[string]bool{}
+	for _, m := range matriculas {
+		if _, ok := r.egresados[m]; ok {
+			existentes[m] = true
+		}
+	}
+	return existentes, nil
+}
+
+func (r *EgresadoMemory) Activos(ctx context.Context, matriculas []string) (map[string]*models.Egresado, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	activos := map[string]*models.Egresado{}
+	for _, m := range matriculas {
+		if e, ok := r.egresados[m]; ok && e.DeletedAt == nil {
+			e = r.conRelaciones(e)
+			activos[m] = &e
+		}
+	}
+	return activos, nil
+}
+
+func (r *EgresadoMemory) UltimaMatricula(ctx context.Context, prefijo string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ultima := ""
+	for m := range r.egresados {
+		if len(m) == 8 && strings.HasPrefix(m, prefijo) && soloDigitos(m) && m > ultima {
+			ultima = m
+		}
+	}
+	return ultima, nil
+}
+
+func (r *EgresadoMemory) Importar(ctx context.Context, egresados []models.Egresado) (int, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Validar todo antes de escribir para simular la transacción
+	for _, e := range egresados {
+		if !r.catalogos.referenciasValidas(&e) {
+			return 0, 0, fmt.Errorf("matrícula %s: %w", e.Matricula, ErrReferenciaInvalida)
+		}
+		actual, existe := r.egresados[e.Matricula]
+		switch {
+		case e.Version == 0 && existe:
+			return 0, 0, fmt.Errorf("matrícula %s: %w", e.Matricula, ErrDuplicado)
+		case e.Version != 0 && (!existe || actual.DeletedAt != nil || actual.Version != e.Version):
+			return 0, 0, fmt.Errorf("matrícula %s: %w", e.Matricula, ErrConflictoVersion)
+		}
+	}
+
+	creados, actualizados := 0, 0
+	for _, e := range egresados {
+		nuevo := e
+		nuevo.UpdatedAt = time.Now()
+		if actual, ok := r.egresados[e.Matricula]; ok {
+			nuevo.CreatedAt = actual.CreatedAt
+			nuevo.Version = actual.Version + 1
+			actualizados++
+		} else {
+			nuevo.CreatedAt = nuevo.UpdatedAt
+			nuevo.Version = 1
+			creados++
+		}
+		r.egresados[e.Matricula] = nuevo
+	}
+	return creados, actualizados, nil
+}
+
+// reasignar mueve a los egresados (incluidos los de la papelera) de un
+// registro de catálogo a otro y devuelve cuántos lo usaban. Con hacia en 0
+// solo los cuenta.
+func (r *EgresadoMemory) reasignar(campo func(*models.Egresado) *int, desde, hacia int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for matricula, e := range r.egresados {
+		if id := campo(&e); *id == desde {
+			n++
+			if hacia != 0 {
+				*id = hacia
+				e.Version++
+				r.egresados[matricula] = e
+			}
+		}
+	}
+	return n
+}
+
+// conRelaciones completa los nombres de plantel, carrera, generación y estatus
+func (r *EgresadoMemory) conRelaciones(e models.Egresado) models.Egresado {
+	r.catalogos.conNombres(&e)
+	return e
+}
+
+func coincideFiltro(e models.Egresado, f models.FiltroEgresados) bool {
+	if !coincideID(f.Plantel, e.IDPlantel) ||
+		!coincideID(f.Generacion, e.IDGeneracion) ||
+		!coincideID(f.Carrera, e.IDCarrera) ||
+		!coincideID(f.Estatus, e.IDEstatus) {
+		return false
+	}
+	if f.Genero != "" && valor(e.Genero) != f.Genero {
+		return false
+	}
+	if f.Estado != "" && !mismoValor(cifrado.CampoEstado, e.Estado, f.Estado) {
+		return false
+	}
+	if f.Municipio != "" && !mismoValor(cifrado.CampoMunicipio, e.Municipio, f.Municipio) {
+		return false
+	}
+	if f.Q != "" {
+		q := strings.ToLower(f.Q)
+		if !strings.HasPrefix(strings.ToLower(e.Matricula), q) &&
+			!strings.Contains(strings.ToLower(e.NombreCompleto), q) &&
+			!(pareceCorreo(f.Q) && mismoValor(cifrado.CampoCorreo, e.Correo, f.Q)) &&
+			!(pareceTelefono(f.Q) && mismoValor(cifrado.CampoTelefono, e.Telefono, f.Q)) {
+			return false
+		}
+	}
+	return true
+}
+
+// coincideID compara un filtro de catálogo ("", "all" o un ID) con el valor del egresado
+func coincideID(filtro string, id int) bool {
+	if filtro == "" || filtro == "all" {
+		return true
+	}
+	return filtro == strconv.Itoa(id)
+}
+
+func ordenarEgresados(egresados []models.Egresado, f models.FiltroEgresados) {
+	clave := func(e models.Egresado) string {
+		switch f.Sort {
+		case "matricula":
+			return e.Matricula
+		case "nombre":
+			return e.NombreCompleto
+		case "carrera":
+			return e.NombreCarrera
+		case "generacion":
+			return e.PeriodoGeneracion
+		case "estatus":
+			return e.DescripcionEstatus
+		case "plantel":
+			return e.NombrePlantel
+		default:
+			return e.CreatedAt.Format(time.RFC3339Nano)
+		}
+	}
+
+	sort.SliceStable(egresados, func(i, j int) bool {
+		a, b := clave(egresados[i]), clave(egresados[j])
+		if a == b {
+			return egresados[i].Matricula < egresados[j].Matricula
+		}
+		if f.Order == "asc" {
+			return a < b
+		}
+		return a > b
+	})
+}
+
+// mismoValor compara como lo hacen los índices ciegos de MySQL
+func mismoValor(campo string, s *string, buscado string) bool {
+	return s != nil && cifrado.Normalizar(campo, *s) == cifrado.Normalizar(campo, buscado)
+}
+
+func valor(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func soloDigitos(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
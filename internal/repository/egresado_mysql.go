@@ -0,0 +1,689 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"ues-egresados/internal/cifrado"
+	"ues-egresados/internal/models"
+)
+
+// columnasOrdenEgresados mapea los valores permitidos de "sort" a columnas SQL
+var columnasOrdenEgresados = map[string]string{
+	"matricula":  "e.matricula",
+	"nombre":     "e.nombre_completo",
+	"carrera":    "c.nombre",
+	"generacion": "g.periodo",
+	"estatus":    "es.descripcion",
+	"plantel":    "p.clave",
+	"created_at": "e.created_at",
+}
+
+// CampoOrdenValido indica si el listado de egresados puede ordenarse por el campo
+func CampoOrdenValido(campo string) bool {
+	_, ok := columnasOrdenEgresados[campo]
+	return ok
+}
+
+const selectEgresados = `
+	SELECT 
+		e.matricula,
+		e.nombre_completo,
+		e.genero,
+		e.telefono,
+		e.correo,
+		e.codigo_postal,
+		e.estado,
+		e.municipio,
+		e.asentamiento,
+		e.calle,
+		e.numero,
+		e.llave_datos,
+		e.id_carrera,
+		e.id_generacion,
+		e.id_estatus,
+		e.id_plantel,
+		e.created_at,
+		e.version,
+		e.updated_at,
+		e.deleted_at,
+		e.deleted_by,
+		COALESCE(c.nombre, '') AS nombre_carrera,
+		COALESCE(g.periodo, '') AS periodo_generacion,
+		COALESCE(es.descripcion, '') AS descripcion_estatus,
+		COALESCE(p.nombre, '') AS nombre_plantel
+	FROM egresados e
+	LEFT JOIN carreras c ON e.id_carrera = c.id_carrera
+	LEFT JOIN generaciones g ON e.id_generacion = g.id_generacion
+	LEFT JOIN estatus es ON e.id_estatus = es.id_estatus
+	LEFT JOIN planteles p ON e.id_plantel = p.id_plantel
+`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEgresado lee una fila producida por selectEgresados y descifra los
+// datos personales
+func (r *EgresadoMySQL) scanEgresado(s scanner) (models.Egresado, error) {
+	var e models.Egresado
+	var c filaCifrada
+	err := s.Scan(
+		&e.Matricula,
+		&e.NombreCompleto,
+		&e.Genero,
+		&c.telefono,
+		&c.correo,
+		&c.codigoPostal,
+		&c.estado,
+		&c.municipio,
+		&c.asentamiento,
+		&c.calle,
+		&c.numero,
+		&c.llave,
+		&e.IDCarrera,
+		&e.IDGeneracion,
+		&e.IDEstatus,
+		&e.IDPlantel,
+		&e.CreatedAt,
+		&e.Version,
+		&e.UpdatedAt,
+		&e.DeletedAt,
+		&e.DeletedBy,
+		&e.NombreCarrera,
+		&e.PeriodoGeneracion,
+		&e.DescripcionEstatus,
+		&e.NombrePlantel,
+	)
+	if err != nil {
+		return e, err
+	}
+	return e, r.descifrar(&e, &c)
+}
+
+// EgresadoMySQL implementa EgresadoRepository sobre MySQL. Con un cifrador,
+// el teléfono, el correo y el domicilio se guardan cifrados; sin él (solo en
+// desarrollo) se guardan en texto plano.
+type EgresadoMySQL struct {
+	db       *sql.DB
+	cifrador *cifrado.Cifrador
+}
+
+func NewEgresadoMySQL(db *sql.DB, cifrador *cifrado.Cifrador) *EgresadoMySQL {
+	return &EgresadoMySQL{db: db, cifrador: cifrador}
+}
+
+func (r *EgresadoMySQL) List(ctx context.Context, filtro models.FiltroEgresados) ([]models.Egresado, int, error) {
+	where, args := r.whereEgresados(filtro)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM egresados e"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, selectEgresados+where+orderEgresados(filtro), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	egresados := []models.Egresado{}
+	for rows.Next() {
+		e, err := r.scanEgresado(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		egresados = append(egresados, e)
+	}
+
+	return egresados, total, rows.Err()
+}
+
+func (r *EgresadoMySQL) Recorrer(ctx context.Context, filtro models.FiltroEgresados, fn func(*models.Egresado) error) error {
+	filtro.PerPage = 0
+	where, args := r.whereEgresados(filtro)
+
+	rows, err := r.db.QueryContext(ctx, selectEgresados+where+orderEgresados(filtro), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := r.scanEgresado(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *EgresadoMySQL) Get(ctx context.Context, matricula string) (*models.Egresado, error) {
+	e, err := r.scanEgresado(r.db.QueryRowContext(ctx, selectEgresados+" WHERE e.matricula = ? AND e.deleted_at IS NULL", matricula))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *EgresadoMySQL) Create(ctx context.Context, e *models.Egresado) error {
+	query := `
+		INSERT INTO egresados 
+		(matricula, nombre_completo, genero, telefono, correo, 
+		 codigo_postal, estado, municipio, asentamiento, calle, numero,
+		 llave_datos, telefono_indice, correo_indice, estado_indice, municipio_indice,
+		 id_carrera, id_generacion, id_estatus, id_plantel)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	c, err := r.cifrar(e.Matricula, e)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		e.Matricula,
+		e.NombreCompleto,
+		e.Genero,
+		c.telefono,
+		c.correo,
+		c.codigoPostal,
+		c.estado,
+		c.municipio,
+		c.asentamiento,
+		c.calle,
+		c.numero,
+		c.llave,
+		c.indiceTelefono,
+		c.indiceCorreo,
+		c.indiceEstado,
+		c.indiceMunicipio,
+		e.IDCarrera,
+		e.IDGeneracion,
+		e.IDEstatus,
+		e.IDPlantel,
+	)
+	return traducirError(err)
+}
+
+// updateEgresado solo modifica el egresado fuera de la papelera y con la versión indicada
+const updateEgresado = `
+	UPDATE egresados 
+	SET nombre_completo = ?, genero = ?, telefono = ?, correo = ?,
+	    codigo_postal = ?, estado = ?, municipio = ?, asentamiento = ?,
+	    calle = ?, numero = ?,
+	    llave_datos = ?, telefono_indice = ?, correo_indice = ?,
+	    estado_indice = ?, municipio_indice = ?,
+	    id_carrera = ?, id_generacion = ?, id_estatus = ?,
+	    version = version + 1
+	WHERE matricula = ? AND deleted_at IS NULL AND version = ?
+`
+
+func (r *EgresadoMySQL) Update(ctx context.Context, matricula string, e *models.Egresado) error {
+	c, err := r.cifrar(matricula, e)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, updateEgresado,
+		e.NombreCompleto,
+		e.Genero,
+		c.telefono,
+		c.correo,
+		c.codigoPostal,
+		c.estado,
+		c.municipio,
+		c.asentamiento,
+		c.calle,
+		c.numero,
+		c.llave,
+		c.indiceTelefono,
+		c.indiceCorreo,
+		c.indiceEstado,
+		c.indiceMunicipio,
+		e.IDCarrera,
+		e.IDGeneracion,
+		e.IDEstatus,
+		matricula,
+		e.Version,
+	)
+	if err != nil {
+		return traducirError(err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		// La versión siempre cambia, así que 0 filas significa que la matrícula
+		// no existe o que otro usuario la modificó primero
+		if _, err := r.Get(ctx, matricula); err != nil {
+			return err
+		}
+		return ErrConflictoVersion
+	}
+	return nil
+}
+
+func (r *EgresadoMySQL) Delete(ctx context.Context, matricula string, eliminadoPor int) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"UPDATE egresados SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ? WHERE matricula = ? AND deleted_at IS NULL",
+		idONulo(eliminadoPor), matricula,
+	))
+}
+
+func (r *EgresadoMySQL) ListEliminados(ctx context.Context) ([]models.Egresado, error) {
+	rows, err := r.db.QueryContext(ctx, selectEgresados+" WHERE e.deleted_at IS NOT NULL ORDER BY e.deleted_at DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	egresados := []models.Egresado{}
+	for rows.Next() {
+		e, err := r.scanEgresado(rows)
+		if err != nil {
+			return nil, err
+		}
+		egresados = append(egresados, e)
+	}
+	return egresados, rows.Err()
+}
+
+func (r *EgresadoMySQL) GetEliminado(ctx context.Context, matricula string) (*models.Egresado, error) {
+	e, err := r.scanEgresado(r.db.QueryRowContext(ctx, selectEgresados+" WHERE e.matricula = ? AND e.deleted_at IS NOT NULL", matricula))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *EgresadoMySQL) Restaurar(ctx context.Context, matricula string) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"UPDATE egresados SET deleted_at = NULL, deleted_by = NULL WHERE matricula = ? AND deleted_at IS NOT NULL",
+		matricula,
+	))
+}
+
+func (r *EgresadoMySQL) Purgar(ctx context.Context, matricula string) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"DELETE FROM egresados WHERE matricula = ? AND deleted_at IS NOT NULL", matricula))
+}
+
+func (r *EgresadoMySQL) PurgarEliminadosAntes(ctx context.Context, limite time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM egresados WHERE deleted_at < ?", limite)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *EgresadoMySQL) StatsPorGeneracion(ctx context.Context, plantel string) ([]models.GeneracionStats, error) {
+	join := "LEFT JOIN egresados e ON g.id_generacion = e.id_generacion AND e.deleted_at IS NULL"
+	var args []interface{}
+
+	// El filtro va en el JOIN para conservar las generaciones sin egresados
+	if plantel != "all" && plantel != "" {
+		join += " AND e.id_plantel = ?"
+		args = append(args, plantel)
+	}
+
+	query := `
+		SELECT 
+			g.id_generacion,
+			g.periodo,
+			COUNT(e.matricula) as total_egresados
+		FROM generaciones g
+		` + join + `
+		GROUP BY g.id_generacion, g.periodo
+		ORDER BY g.periodo DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.GeneracionStats{}
+	for rows.Next() {
+		var s models.GeneracionStats
+		if err := rows.Scan(&s.IDGeneracion, &s.Periodo, &s.TotalEgresados); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *EgresadoMySQL) StatsPorCarrera(ctx context.Context, idGeneracion, plantel string) ([]models.CarreraStats, error) {
+	join := "LEFT JOIN egresados e ON c.id_carrera = e.id_carrera AND e.deleted_at IS NULL"
+	var args []interface{}
+
+	// Filtrar por generación específica
+	if idGeneracion != "all" && idGeneracion != "" {
+		join += " AND e.id_generacion = ?"
+		args = append(args, idGeneracion)
+	}
+	if plantel != "all" && plantel != "" {
+		join += " AND e.id_plantel = ?"
+		args = append(args, plantel)
+	}
+
+	query := `
+		SELECT 
+			c.id_carrera,
+			c.nombre,
+			COUNT(e.matricula) as total_egresados
+		FROM carreras c
+		` + join + `
+		GROUP BY c.id_carrera, c.nombre
+		ORDER BY c.nombre
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.CarreraStats{}
+	for rows.Next() {
+		var s models.CarreraStats
+		if err := rows.Scan(&s.IDCarrera, &s.Nombre, &s.TotalEgresados); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *EgresadoMySQL) StatsPorEstatus(ctx context.Context, plantel string) ([]models.EstatusStats, error) {
+	join := "LEFT JOIN egresados e ON s.id_estatus = e.id_estatus AND e.deleted_at IS NULL"
+	var args []interface{}
+
+	if plantel != "all" && plantel != "" {
+		join += " AND e.id_plantel = ?"
+		args = append(args, plantel)
+	}
+
+	query := `
+		SELECT
+			s.id_estatus,
+			s.descripcion,
+			COUNT(e.matricula) as total_egresados
+		FROM estatus s
+		` + join + `
+		GROUP BY s.id_estatus, s.descripcion
+		ORDER BY s.id_estatus
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.EstatusStats{}
+	for rows.Next() {
+		var s models.EstatusStats
+		if err := rows.Scan(&s.IDEstatus, &s.Descripcion, &s.TotalEgresados); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *EgresadoMySQL) Existentes(ctx context.Context, matriculas []string) (map[string]bool, error) {
+	existentes := map[string]bool{}
+
+	// Consultar en bloques para no exceder el límite de parámetros
+	const bloque = 1000
+	for inicio := 0; inicio < len(matriculas); inicio += bloque {
+		fin := inicio + bloque
+		if fin > len(matriculas) {
+			fin = len(matriculas)
+		}
+
+		lote := matriculas[inicio:fin]
+		args := make([]interface{}, len(lote))
+		for i, m := range lote {
+			args[i] = m
+		}
+
+		query := "SELECT matricula FROM egresados WHERE matricula IN (?" + strings.Repeat(", ?", len(lote)-1) + ")"
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var m string
+			if err := rows.Scan(&m); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			existentes[m] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return existentes, nil
+}
+
+func (r *EgresadoMySQL) Activos(ctx context.Context, matriculas []string) (map[string]*models.Egresado, error) {
+	activos := map[string]*models.Egresado{}
+
+	// Consultar en bloques para no exceder el límite de parámetros
+	const bloque = 1000
+	for inicio := 0; inicio < len(matriculas); inicio += bloque {
+		fin := inicio + bloque
+		if fin > len(matriculas) {
+			fin = len(matriculas)
+		}
+
+		lote := matriculas[inicio:fin]
+		args := make([]interface{}, len(lote))
+		for i, m := range lote {
+			args[i] = m
+		}
+
+		query := selectEgresados + " WHERE e.matricula IN (?" + strings.Repeat(", ?", len(lote)-1) + ") AND e.deleted_at IS NULL"
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			e, err := r.scanEgresado(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			activos[e.Matricula] = &e
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return activos, nil
+}
+
+func (r *EgresadoMySQL) UltimaMatricula(ctx context.Context, prefijo string) (string, error) {
+	var ultima sql.NullString
+	err := r.db.QueryRowContext(ctx,
+		"SELECT MAX(matricula) FROM egresados WHERE matricula LIKE ? AND matricula REGEXP '^[0-9]{8}$'",
+		escapeLike(prefijo)+"%",
+	).Scan(&ultima)
+	if err != nil {
+		return "", err
+	}
+	return ultima.String, nil
+}
+
+func (r *EgresadoMySQL) Importar(ctx context.Context, egresados []models.Egresado) (int, int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	insertar, err := tx.PrepareContext(ctx, `
+		INSERT INTO egresados 
+		(matricula, nombre_completo, genero, telefono, correo, 
+		 codigo_postal, estado, municipio, asentamiento, calle, numero,
+		 llave_datos, telefono_indice, correo_indice, estado_indice, municipio_indice,
+		 id_carrera, id_generacion, id_estatus, id_plantel)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer insertar.Close()
+
+	actualizar, err := tx.PrepareContext(ctx, updateEgresado)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer actualizar.Close()
+
+	creados, actualizados := 0, 0
+	for _, e := range egresados {
+		c, err := r.cifrar(e.Matricula, &e)
+		if err != nil {
+			return 0, 0, fmt.Errorf("matrícula %s: %w", e.Matricula, err)
+		}
+
+		if e.Version == 0 {
+			if _, err := insertar.ExecContext(ctx,
+				e.Matricula, e.NombreCompleto, e.Genero,
+				c.telefono, c.correo, c.codigoPostal, c.estado, c.municipio,
+				c.asentamiento, c.calle, c.numero,
+				c.llave, c.indiceTelefono, c.indiceCorreo, c.indiceEstado, c.indiceMunicipio,
+				e.IDCarrera, e.IDGeneracion, e.IDEstatus, e.IDPlantel,
+			); err != nil {
+				return 0, 0, fmt.Errorf("matrícula %s: %w", e.Matricula, traducirError(err))
+			}
+			creados++
+			continue
+		}
+
+		result, err := actualizar.ExecContext(ctx,
+			e.NombreCompleto, e.Genero,
+			c.telefono, c.correo, c.codigoPostal, c.estado, c.municipio,
+			c.asentamiento, c.calle, c.numero,
+			c.llave, c.indiceTelefono, c.indiceCorreo, c.indiceEstado, c.indiceMunicipio,
+			e.IDCarrera, e.IDGeneracion, e.IDEstatus,
+			e.Matricula, e.Version,
+		)
+		if err != nil {
+			return 0, 0, fmt.Errorf("matrícula %s: %w", e.Matricula, traducirError(err))
+		}
+		// 0 filas: se modificó o se envió a la papelera después del análisis
+		if n, _ := result.RowsAffected(); n == 0 {
+			return 0, 0, fmt.Errorf("matrícula %s: %w", e.Matricula, ErrConflictoVersion)
+		}
+		actualizados++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return creados, actualizados, nil
+}
+
+// whereEgresados construye la cláusula WHERE del listado a partir del filtro
+func (r *EgresadoMySQL) whereEgresados(f models.FiltroEgresados) (string, []interface{}) {
+	where := " WHERE e.deleted_at IS NULL"
+	var args []interface{}
+
+	// Agregar filtros de catálogo si no son "all"
+	if f.Generacion != "" && f.Generacion != "all" {
+		where += " AND e.id_generacion = ?"
+		args = append(args, f.Generacion)
+	}
+	if f.Carrera != "" && f.Carrera != "all" {
+		where += " AND e.id_carrera = ?"
+		args = append(args, f.Carrera)
+	}
+	if f.Estatus != "" && f.Estatus != "all" {
+		where += " AND e.id_estatus = ?"
+		args = append(args, f.Estatus)
+	}
+	if f.Plantel != "" && f.Plantel != "all" {
+		where += " AND e.id_plantel = ?"
+		args = append(args, f.Plantel)
+	}
+
+	if f.Genero != "" {
+		where += " AND e.genero = ?"
+		args = append(args, f.Genero)
+	}
+	if f.Estado != "" {
+		condicion, valores := r.coincidenciaExacta("estado", cifrado.CampoEstado, f.Estado)
+		where += " AND " + condicion
+		args = append(args, valores...)
+	}
+	if f.Municipio != "" {
+		condicion, valores := r.coincidenciaExacta("municipio", cifrado.CampoMunicipio, f.Municipio)
+		where += " AND " + condicion
+		args = append(args, valores...)
+	}
+
+	// Búsqueda por prefijo de matrícula o por nombre y, si el texto parece un
+	// correo o un teléfono, por coincidencia exacta con ellos
+	if f.Q != "" {
+		like := escapeLike(f.Q)
+		busqueda := "e.matricula LIKE ? OR e.nombre_completo LIKE ?"
+		args = append(args, like+"%", "%"+like+"%")
+		if pareceCorreo(f.Q) {
+			condicion, valores := r.coincidenciaExacta("correo", cifrado.CampoCorreo, f.Q)
+			busqueda += " OR " + condicion
+			args = append(args, valores...)
+		}
+		if pareceTelefono(f.Q) {
+			condicion, valores := r.coincidenciaExacta("telefono", cifrado.CampoTelefono, f.Q)
+			busqueda += " OR " + condicion
+			args = append(args, valores...)
+		}
+		where += " AND (" + busqueda + ")"
+	}
+
+	return where, args
+}
+
+// orderEgresados construye ORDER BY y LIMIT del listado
+func orderEgresados(f models.FiltroEgresados) string {
+	columna, ok := columnasOrdenEgresados[f.Sort]
+	if !ok {
+		columna = "e.created_at"
+	}
+	direccion := "DESC"
+	if f.Order == "asc" {
+		direccion = "ASC"
+	}
+
+	clause := fmt.Sprintf(" ORDER BY %s %s, e.matricula", columna, direccion)
+	if f.PerPage > 0 {
+		clause += fmt.Sprintf(" LIMIT %d OFFSET %d", f.PerPage, (f.Page-1)*f.PerPage)
+	}
+	return clause
+}
+
+// escapeLike escapa los comodines de LIKE en la búsqueda del usuario
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// IntentoLoginMemory implementa IntentoLoginRepository en memoria
+type IntentoLoginMemory struct {
+	mu          sync.RWMutex
+	intentos    []models.IntentoLogin
+	descartados map[int64]bool
+	bloqueos    map[string]models.BloqueoLogin
+}
+
+func NewIntentoLoginMemory() *IntentoLoginMemory {
+	return &IntentoLoginMemory{
+		descartados: map[int64]bool{},
+		bloqueos:    map[string]models.BloqueoLogin{},
+	}
+}
+
+func (r *IntentoLoginMemory) Registrar(ctx context.Context, i *models.IntentoLogin) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i.ID = int64(len(r.intentos) + 1)
+	if i.CreatedAt.IsZero() {
+		i.CreatedAt = time.Now()
+	}
+	r.intentos = append(r.intentos, *i)
+	return nil
+}
+
+// valorBloqueo devuelve el usuario o la IP del intento según el tipo de bloqueo
+func valorBloqueo(i models.IntentoLogin, tipo string) string {
+	if tipo == models.BloqueoPorIP {
+		return i.IP
+	}
+	return i.Usuario
+}
+
+func (r *IntentoLoginMemory) ContarFallidos(ctx context.Context, tipo, valor, motivo string, desde time.Time) (int, error) {
+	if _, err := columnaBloqueo(tipo); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	for _, i := range r.intentos {
+		if valorBloqueo(i, tipo) == valor && i.Motivo == motivo &&
+			!r.descartados[i.ID] && !i.CreatedAt.Before(desde) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *IntentoLoginMemory) Descartar(ctx context.Context, tipo, valor string) error {
+	if _, err := columnaBloqueo(tipo); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, i := range r.intentos {
+		if valorBloqueo(i, tipo) == valor && !i.Exitoso {
+			r.descartados[i.ID] = true
+		}
+	}
+	return nil
+}
+
+func (r *IntentoLoginMemory) List(ctx context.Context, filtro models.FiltroIntentosLogin) ([]models.IntentoLogin, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	intentos := []models.IntentoLogin{}
+	for _, i := range r.intentos {
+		if coincideIntentoLogin(i, filtro) {
+			intentos = append(intentos, i)
+		}
+	}
+	sort.SliceStable(intentos, func(a, b int) bool {
+		return intentos[a].ID > intentos[b].ID
+	})
+
+	total := len(intentos)
+	if filtro.PerPage > 0 {
+		inicio := (filtro.Page - 1) * filtro.PerPage
+		if inicio > total {
+			inicio = total
+		}
+		fin := inicio + filtro.PerPage
+		if fin > total {
+			fin = total
+		}
+		intentos = intentos[inicio:fin]
+	}
+	return intentos, total, nil
+}
+
+func coincideIntentoLogin(i models.IntentoLogin, f models.FiltroIntentosLogin) bool {
+	if f.Usuario != "" && i.Usuario != f.Usuario {
+		return false
+	}
+	if f.IP != "" && i.IP != f.IP {
+		return false
+	}
+	if f.Exitoso != nil && i.Exitoso != *f.Exitoso {
+		return false
+	}
+	if f.Desde != nil && i.CreatedAt.Before(*f.Desde) {
+		return false
+	}
+	if f.Hasta != nil && !i.CreatedAt.Before(*f.Hasta) {
+		return false
+	}
+	return true
+}
+
+func (r *IntentoLoginMemory) Bloquear(ctx context.Context, b *models.BloqueoLogin) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bloqueos[b.Tipo+"|"+b.Valor] = *b
+	return nil
+}
+
+func (r *IntentoLoginMemory) GetBloqueo(ctx context.Context, tipo, valor string, ahora time.Time) (*models.BloqueoLogin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b, ok := r.bloqueos[tipo+"|"+valor]
+	if !ok || !b.BloqueadoHasta.After(ahora) {
+		return nil, ErrNotFound
+	}
+	return &b, nil
+}
+
+func (r *IntentoLoginMemory) ListBloqueos(ctx context.Context, ahora time.Time) ([]models.BloqueoLogin, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bloqueos := []models.BloqueoLogin{}
+	for _, b := range r.bloqueos {
+		if b.BloqueadoHasta.After(ahora) {
+			bloqueos = append(bloqueos, b)
+		}
+	}
+	sort.Slice(bloqueos, func(i, j int) bool {
+		return bloqueos[i].CreatedAt.After(bloqueos[j].CreatedAt)
+	})
+	return bloqueos, nil
+}
+
+func (r *IntentoLoginMemory) Desbloquear(ctx context.Context, tipo, valor string, ahora time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clave := tipo + "|" + valor
+	if b, ok := r.bloqueos[clave]; !ok || !b.BloqueadoHasta.After(ahora) {
+		return ErrNotFound
+	}
+	delete(r.bloqueos, clave)
+	return nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// IntentoLoginMySQL implementa IntentoLoginRepository sobre MySQL
+type IntentoLoginMySQL struct {
+	db *sql.DB
+}
+
+func NewIntentoLoginMySQL(db *sql.DB) *IntentoLoginMySQL {
+	return &IntentoLoginMySQL{db: db}
+}
+
+// columnaBloqueo devuelve la columna de intentos_login que corresponde al tipo de bloqueo
+func columnaBloqueo(tipo string) (string, error) {
+	switch tipo {
+	case models.BloqueoPorUsuario:
+		return "usuario", nil
+	case models.BloqueoPorIP:
+		return "ip", nil
+	}
+	return "", fmt.Errorf("tipo de bloqueo inválido: %q", tipo)
+}
+
+func (r *IntentoLoginMySQL) Registrar(ctx context.Context, i *models.IntentoLogin) error {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO intentos_login (usuario, id_usuario, ip, user_agent, exitoso, motivo)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, i.Usuario, i.IDUsuario, i.IP, i.UserAgent, i.Exitoso, i.Motivo)
+	if err != nil {
+		return err
+	}
+
+	i.ID, _ = result.LastInsertId()
+	return nil
+}
+
+func (r *IntentoLoginMySQL) ContarFallidos(ctx context.Context, tipo, valor, motivo string, desde time.Time) (int, error) {
+	columna, err := columnaBloqueo(tipo)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	err = r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM intentos_login
+		WHERE `+columna+` = ? AND motivo = ? AND NOT descartado AND created_at >= ?
+	`, valor, motivo, desde).Scan(&n)
+	return n, err
+}
+
+func (r *IntentoLoginMySQL) Descartar(ctx context.Context, tipo, valor string) error {
+	columna, err := columnaBloqueo(tipo)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"UPDATE intentos_login SET descartado = TRUE WHERE "+columna+" = ? AND NOT exitoso AND NOT descartado", valor)
+	return err
+}
+
+func (r *IntentoLoginMySQL) List(ctx context.Context, filtro models.FiltroIntentosLogin) ([]models.IntentoLogin, int, error) {
+	where, args := whereIntentosLogin(filtro)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM intentos_login"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, usuario, id_usuario, ip, user_agent, exitoso, motivo, created_at
+		FROM intentos_login` + where + " ORDER BY created_at DESC, id DESC"
+	if filtro.PerPage > 0 {
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", filtro.PerPage, (filtro.Page-1)*filtro.PerPage)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	intentos := []models.IntentoLogin{}
+	for rows.Next() {
+		var i models.IntentoLogin
+		if err := rows.Scan(&i.ID, &i.Usuario, &i.IDUsuario, &i.IP, &i.UserAgent,
+			&i.Exitoso, &i.Motivo, &i.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		intentos = append(intentos, i)
+	}
+
+	return intentos, total, rows.Err()
+}
+
+func whereIntentosLogin(f models.FiltroIntentosLogin) (string, []interface{}) {
+	var condiciones []string
+	var args []interface{}
+
+	if f.Usuario != "" {
+		condiciones = append(condiciones, "usuario = ?")
+		args = append(args, f.Usuario)
+	}
+	if f.IP != "" {
+		condiciones = append(condiciones, "ip = ?")
+		args = append(args, f.IP)
+	}
+	if f.Exitoso != nil {
+		condiciones = append(condiciones, "exitoso = ?")
+		args = append(args, *f.Exitoso)
+	}
+	if f.Desde != nil {
+		condiciones = append(condiciones, "created_at >= ?")
+		args = append(args, *f.Desde)
+	}
+	if f.Hasta != nil {
+		condiciones = append(condiciones, "created_at < ?")
+		args = append(args, *f.Hasta)
+	}
+
+	if len(condiciones) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(condiciones, " AND "), args
+}
+
+func (r *IntentoLoginMySQL) Bloquear(ctx context.Context, b *models.BloqueoLogin) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO bloqueos_login (tipo, valor, fallidos, bloqueado_hasta, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			fallidos = VALUES(fallidos), bloqueado_hasta = VALUES(bloqueado_hasta), created_at = VALUES(created_at)
+	`, b.Tipo, b.Valor, b.Fallidos, b.BloqueadoHasta, b.CreatedAt)
+	return err
+}
+
+const selectBloqueo = "SELECT tipo, valor, fallidos, bloqueado_hasta, created_at FROM bloqueos_login"
+
+func scanBloqueo(s scanner) (*models.BloqueoLogin, error) {
+	var b models.BloqueoLogin
+	err := s.Scan(&b.Tipo, &b.Valor, &b.Fallidos, &b.BloqueadoHasta, &b.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *IntentoLoginMySQL) GetBloqueo(ctx context.Context, tipo, valor string, ahora time.Time) (*models.BloqueoLogin, error) {
+	return scanBloqueo(r.db.QueryRowContext(ctx,
+		selectBloqueo+" WHERE tipo = ? AND valor = ? AND bloqueado_hasta > ?", tipo, valor, ahora))
+}
+
+func (r *IntentoLoginMySQL) ListBloqueos(ctx context.Context, ahora time.Time) ([]models.BloqueoLogin, error) {
+	rows, err := r.db.QueryContext(ctx,
+		selectBloqueo+" WHERE bloqueado_hasta > ? ORDER BY created_at DESC", ahora)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bloqueos := []models.BloqueoLogin{}
+	for rows.Next() {
+		b, err := scanBloqueo(rows)
+		if err != nil {
+			return nil, err
+		}
+		bloqueos = append(bloqueos, *b)
+	}
+	return bloqueos, rows.Err()
+}
+
+func (r *IntentoLoginMySQL) Desbloquear(ctx context.Context, tipo, valor string, ahora time.Time) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"DELETE FROM bloqueos_login WHERE tipo = ? AND valor = ? AND bloqueado_hasta > ?", tipo, valor, ahora))
+}
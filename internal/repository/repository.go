@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"ues-egresados/internal/cifrado"
+	"ues-egresados/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrNotFound indica que el registro solicitado no existe
+	ErrNotFound = errors.New("registro no encontrado")
+	// ErrDuplicado indica que ya existe un registro con la misma clave
+	ErrDuplicado = errors.New("registro duplicado")
+	// ErrReferenciaInvalida indica que una llave foránea apunta a un registro inexistente
+	ErrReferenciaInvalida = errors.New("referencia inválida")
+	// ErrConflictoVersion indica que el registro cambió desde que el cliente lo leyó
+	ErrConflictoVersion = errors.New("el registro fue modificado por otro usuario")
+	// ErrEnUso indica que un registro de catálogo tiene egresados asignados
+	ErrEnUso = errors.New("registro en uso")
+)
+
+// EnUsoError indica cuántos egresados (y, en planteles, usuarios) impiden
+// eliminar un registro de catálogo
+type EnUsoError struct {
+	Egresados int
+	Usuarios  int
+}
+
+func (e *EnUsoError) Error() string {
+	if e.Usuarios > 0 {
+		return fmt.Sprintf("%s por %d egresados y %d usuarios", ErrEnUso, e.Egresados, e.Usuarios)
+	}
+	return fmt.Sprintf("%s por %d egresados", ErrEnUso, e.Egresados)
+}
+
+func (e *EnUsoError) Unwrap() error {
+	return ErrEnUso
+}
+
+// EgresadoRepository define el acceso a los egresados
+type EgresadoRepository interface {
+	List(ctx context.Context, filtro models.FiltroEgresados) ([]models.Egresado, int, error)
+	// Recorrer llama a fn por cada egresado que coincide con el filtro sin
+	// cargar el listado completo en memoria; ignora la paginación
+	Recorrer(ctx context.Context, filtro models.FiltroEgresados, fn func(*models.Egresado) error) error
+	Get(ctx context.Context, matricula string) (*models.Egresado, error)
+	Create(ctx context.Context, e *models.Egresado) error
+	// Update solo aplica si e.Version coincide con la versión guardada;
+	// de lo contrario devuelve ErrConflictoVersion
+	Update(ctx context.Context, matricula string, e *models.Egresado) error
+	// Delete envía el egresado a la papelera
+	Delete(ctx context.Context, matricula string, eliminadoPor int) error
+	// Las estadísticas se limitan al plantel indicado ("" o "all" para todos)
+	StatsPorGeneracion(ctx context.Context, plantel string) ([]models.GeneracionStats, error)
+	StatsPorCarrera(ctx context.Context, idGeneracion, plantel string) ([]models.CarreraStats, error)
+	StatsPorEstatus(ctx context.Context, plantel string) ([]models.EstatusStats, error)
+	// Existentes devuelve cuáles de las matrículas ya están registradas,
+	// incluidas las de la papelera
+	Existentes(ctx context.Context, matriculas []string) (map[string]bool, error)
+	// Activos devuelve, por matrícula, los egresados fuera de la papelera
+	Activos(ctx context.Context, matriculas []string) (map[string]*models.Egresado, error)
+	// UltimaMatricula devuelve la mayor matrícula numérica de 8 dígitos que
+	// empieza con prefijo, incluida la papelera ("" si no hay ninguna)
+	UltimaMatricula(ctx context.Context, prefijo string) (string, error)
+	// Importar guarda los egresados en una sola transacción: inserta los que
+	// tienen versión 0 y actualiza el resto solo si siguen fuera de la papelera
+	// con la misma versión (ErrConflictoVersion si no). Nunca restaura.
+	Importar(ctx context.Context, egresados []models.Egresado) (creados int, actualizados int, err error)
+	// ListEliminados devuelve los egresados en la papelera
+	ListEliminados(ctx context.Context) ([]models.Egresado, error)
+	// GetEliminado devuelve un egresado que está en la papelera
+	GetEliminado(ctx context.Context, matricula string) (*models.Egresado, error)
+	Restaurar(ctx context.Context, matricula string) error
+	// Purgar elimina definitivamente un egresado que ya está en la papelera
+	Purgar(ctx context.Context, matricula string) error
+	// PurgarEliminadosAntes elimina definitivamente lo enviado a la papelera antes de limite
+	PurgarEliminadosAntes(ctx context.Context, limite time.Time) (int64, error)
+}
+
+// UsuarioRepository define el acceso a los usuarios del sistema
+type UsuarioRepository interface {
+	List(ctx context.Context) ([]models.Usuario, error)
+	GetByID(ctx context.Context, id int) (*models.Usuario, error)
+	// GetByUsuario incluye el hash de la contraseña
+	GetByUsuario(ctx context.Context, usuario string) (*models.Usuario, error)
+	// GetByCorreo incluye el hash de la contraseña
+	GetByCorreo(ctx context.Context, correo string) (*models.Usuario, error)
+	ExisteUsuario(ctx context.Context, usuario string, excluirID int) (bool, error)
+	ExisteCorreo(ctx context.Context, correo string, excluirID int) (bool, error)
+	// Create recibe la contraseña ya encriptada y devuelve el ID asignado. Sin
+	// u.Origen la cuenta es local.
+	Create(ctx context.Context, u *models.Usuario) (int, error)
+	// Update sólo cambia la contraseña si u.Password no está vacío y, como en
+	// egresados, exige que u.Version coincida con la versión guardada. El
+	// origen de la cuenta no cambia.
+	Update(ctx context.Context, u *models.Usuario) error
+	// CambiarPassword reemplaza el hash de la contraseña sin exigir versión
+	CambiarPassword(ctx context.Context, id int, password string) error
+	// Delete envía el usuario a la papelera; ya no puede iniciar sesión
+	Delete(ctx context.Context, id int, eliminadoPor int) error
+	ListEliminados(ctx context.Context) ([]models.Usuario, error)
+	Restaurar(ctx context.Context, id int) error
+	Purgar(ctx context.Context, id int) error
+	PurgarEliminadosAntes(ctx context.Context, limite time.Time) (int64, error)
+}
+
+// CatalogoRepository define el acceso a carreras, generaciones, estatus y planteles.
+// Los Delete devuelven *EnUsoError si hay egresados asignados (incluidos los
+// de la papelera), salvo que reasignarA indique otro registro al que moverlos.
+type CatalogoRepository interface {
+	ListCarreras(ctx context.Context) ([]models.Carrera, error)
+	GetCarrera(ctx context.Context, id int) (*models.Carrera, error)
+	// CreateCarrera asigna el ID generado en c
+	CreateCarrera(ctx context.Context, c *models.Carrera) error
+	UpdateCarrera(ctx context.Context, c *models.Carrera) error
+	DeleteCarrera(ctx context.Context, id, reasignarA int) error
+
+	ListGeneraciones(ctx context.Context) ([]models.Generacion, error)
+	GetGeneracion(ctx context.Context, id int) (*models.Generacion, error)
+	CreateGeneracion(ctx context.Context, g *models.Generacion) error
+	UpdateGeneracion(ctx context.Context, g *models.Generacion) error
+	DeleteGeneracion(ctx context.Context, id, reasignarA int) error
+
+	ListEstatus(ctx context.Context) ([]models.Estatus, error)
+	GetEstatus(ctx context.Context, id int) (*models.Estatus, error)
+	CreateEstatus(ctx context.Context, e *models.Estatus) error
+	UpdateEstatus(ctx context.Context, e *models.Estatus) error
+	DeleteEstatus(ctx context.Context, id, reasignarA int) error
+
+	ListPlanteles(ctx context.Context) ([]models.Plantel, error)
+	GetPlantel(ctx context.Context, id int) (*models.Plantel, error)
+	// GetPlantelPorClave busca el plantel por los 2 dígitos de la matrícula
+	GetPlantelPorClave(ctx context.Context, clave string) (*models.Plantel, error)
+	CreatePlantel(ctx context.Context, p *models.Plantel) error
+	// UpdatePlantel devuelve *EnUsoError si cambia la clave de un plantel con
+	// egresados, porque sus matrículas dejarían de corresponder
+	UpdatePlantel(ctx context.Context, p *models.Plantel) error
+	// DeletePlantel no admite reasignación: la clave es parte de la
+	// matrícula. Devuelve *EnUsoError si tiene egresados o usuarios.
+	DeletePlantel(ctx context.Context, id int) error
+}
+
+// CodigoPostalRepository define las consultas al catálogo de códigos postales
+type CodigoPostalRepository interface {
+	BuscarPorCodigo(ctx context.Context, cp string) (*models.ResultadoCodigoPostal, error)
+	ListEstados(ctx context.Context) ([]string, error)
+	ListMunicipios(ctx context.Context, estado string) ([]string, error)
+	ListAsentamientos(ctx context.Context, estado, municipio string) ([]models.AsentamientoCP, error)
+}
+
+// AuditoriaRepository guarda y consulta la bitácora de cambios
+type AuditoriaRepository interface {
+	Registrar(ctx context.Context, a *models.Auditoria) error
+	List(ctx context.Context, filtro models.FiltroAuditoria) ([]models.Auditoria, int, error)
+}
+
+// SesionRepository guarda las sesiones del lado del servidor. Los IDs son el
+// hash del token de la cookie.
+type SesionRepository interface {
+	Get(ctx context.Context, id string) (*models.Sesion, error)
+	// Guardar inserta la sesión o actualiza sus datos; created_at y expira_en
+	// solo se fijan al crearla
+	Guardar(ctx context.Context, s *models.Sesion) error
+	// Tocar actualiza la última actividad de la sesión
+	Tocar(ctx context.Context, id string, ahora time.Time) error
+	Delete(ctx context.Context, id string) error
+	// ListPorUsuario devuelve las sesiones del usuario sin expirar y con
+	// actividad posterior a activaDesde, la más reciente primero
+	ListPorUsuario(ctx context.Context, idUsuario int, activaDesde time.Time) ([]models.Sesion, error)
+	// DeletePorUsuario cierra todas las sesiones del usuario salvo excepto
+	DeletePorUsuario(ctx context.Context, idUsuario int, excepto string) (int64, error)
+	// PurgarExpiradas elimina las sesiones vencidas o sin actividad desde inactivaAntes
+	PurgarExpiradas(ctx context.Context, ahora, inactivaAntes time.Time) (int64, error)
+}
+
+// IntentoLoginRepository registra los intentos de inicio de sesión y los
+// bloqueos temporales. tipo es models.BloqueoPorUsuario o models.BloqueoPorIP.
+type IntentoLoginRepository interface {
+	Registrar(ctx context.Context, i *models.IntentoLogin) error
+	// ContarFallidos cuenta los intentos no descartados con el motivo indicado
+	// desde la fecha indicada
+	ContarFallidos(ctx context.Context, tipo, valor, motivo string, desde time.Time) (int, error)
+	// Descartar excluye del conteo los fallos registrados hasta ahora
+	Descartar(ctx context.Context, tipo, valor string) error
+	List(ctx context.Context, filtro models.FiltroIntentosLogin) ([]models.IntentoLogin, int, error)
+	// Bloquear crea el bloqueo o reemplaza el anterior
+	Bloquear(ctx context.Context, b *models.BloqueoLogin) error
+	// GetBloqueo devuelve ErrNotFound si no hay un bloqueo vigente en ahora
+	GetBloqueo(ctx context.Context, tipo, valor string, ahora time.Time) (*models.BloqueoLogin, error)
+	ListBloqueos(ctx context.Context, ahora time.Time) ([]models.BloqueoLogin, error)
+	// Desbloquear elimina el bloqueo vigente; devuelve ErrNotFound si no hay
+	Desbloquear(ctx context.Context, tipo, valor string, ahora time.Time) error
+}
+
+// DosFactoresRepository guarda la verificación en dos pasos y los códigos de
+// recuperación de cada usuario
+type DosFactoresRepository interface {
+	// Get devuelve ErrNotFound si el usuario nunca inició la configuración
+	Get(ctx context.Context, idUsuario int) (*models.DosFactores, error)
+	// GuardarPendiente reemplaza el secreto de una configuración aún no activa
+	GuardarPendiente(ctx context.Context, idUsuario int, secreto string) error
+	// Activar marca la configuración como activa, registra el paso del código
+	// confirmado y reemplaza los códigos de recuperación
+	Activar(ctx context.Context, idUsuario int, paso int64, codigosHash []string) error
+	// RegistrarPaso guarda el paso de un código aceptado; devuelve false si ya
+	// se usó ese paso o uno posterior
+	RegistrarPaso(ctx context.Context, idUsuario int, paso int64) (bool, error)
+	ReemplazarCodigos(ctx context.Context, idUsuario int, codigosHash []string) error
+	// UsarCodigo marca un código de recuperación como usado; devuelve
+	// ErrNotFound si no existe o ya se había usado
+	UsarCodigo(ctx context.Context, idUsuario int, codigoHash string) error
+	CodigosRestantes(ctx context.Context, idUsuario int) (int, error)
+	// Delete elimina la configuración y los códigos de recuperación
+	Delete(ctx context.Context, idUsuario int) error
+}
+
+// TokenPasswordRepository guarda los enlaces para restablecer la contraseña
+type TokenPasswordRepository interface {
+	Create(ctx context.Context, t *models.TokenPassword) error
+	// Get devuelve ErrNotFound si el token no existe, ya se usó o venció en ahora
+	Get(ctx context.Context, id string, ahora time.Time) (*models.TokenPassword, error)
+	// Usar marca el token como usado; devuelve ErrNotFound si ya se usó o venció,
+	// así que solo una petición puede consumirlo
+	Usar(ctx context.Context, id string, ahora time.Time) error
+	// DeletePorUsuario invalida los enlaces pendientes del usuario
+	DeletePorUsuario(ctx context.Context, idUsuario int) error
+}
+
+// TokenAPIRepository guarda los tokens personales de la API
+type TokenAPIRepository interface {
+	Create(ctx context.Context, t *models.TokenAPI) (int, error)
+	ListPorUsuario(ctx context.Context, idUsuario int) ([]models.TokenAPI, error)
+	// GetPorHash devuelve el token aunque haya vencido; quien lo usa revisa
+	// la vigencia
+	GetPorHash(ctx context.Context, hash string) (*models.TokenAPI, error)
+	RegistrarUso(ctx context.Context, idToken int, ip string, ahora time.Time) error
+	// Delete revoca un token del usuario; devuelve ErrNotFound si no existe o
+	// es de otro usuario
+	Delete(ctx context.Context, idUsuario, idToken int) error
+}
+
+// Repositories agrupa todas las implementaciones que usan los handlers
+type Repositories struct {
+	Egresados       EgresadoRepository
+	Usuarios        UsuarioRepository
+	Catalogos       CatalogoRepository
+	CodigosPostales CodigoPostalRepository
+	Auditoria       AuditoriaRepository
+	Sesiones        SesionRepository
+	IntentosLogin   IntentoLoginRepository
+	DosFactores     DosFactoresRepository
+	TokensPassword  TokenPasswordRepository
+	TokensAPI       TokenAPIRepository
+}
+
+// NewMySQL crea los repositorios respaldados por MySQL. cifrador protege los
+// datos personales de los egresados; puede ser nil en desarrollo.
+func NewMySQL(db *sql.DB, cifrador *cifrado.Cifrador) *Repositories {
+	return &Repositories{
+		Egresados:       NewEgresadoMySQL(db, cifrador),
+		Usuarios:        NewUsuarioMySQL(db),
+		Catalogos:       NewCatalogoMySQL(db),
+		CodigosPostales: NewCodigoPostalMySQL(db),
+		Auditoria:       NewAuditoriaMySQL(db),
+		Sesiones:        NewSesionMySQL(db),
+		IntentosLogin:   NewIntentoLoginMySQL(db),
+		DosFactores:     NewDosFactoresMySQL(db),
+		TokensPassword:  NewTokenPasswordMySQL(db),
+		TokensAPI:       NewTokenAPIMySQL(db),
+	}
+}
+
+// NewMemory crea repositorios en memoria, útiles para pruebas sin MySQL
+func NewMemory() *Repositories {
+	catalogos := NewCatalogoMemory()
+	egresados := NewEgresadoMemory(catalogos)
+	usuarios := NewUsuarioMemory()
+	catalogos.egresados = egresados
+	catalogos.usuarios = usuarios
+	return &Repositories{
+		Egresados:       egresados,
+		Usuarios:        usuarios,
+		Catalogos:       catalogos,
+		CodigosPostales: NewCodigoPostalMemory(),
+		Auditoria:       NewAuditoriaMemory(),
+		Sesiones:        NewSesionMemory(),
+		IntentosLogin:   NewIntentoLoginMemory(),
+		DosFactores:     NewDosFactoresMemory(),
+		TokensPassword:  NewTokenPasswordMemory(),
+		TokensAPI:       NewTokenAPIMemory(),
+	}
+}
+
+// traducirError convierte errores del driver de MySQL a errores del repositorio
+func traducirError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1062: // ER_DUP_ENTRY
+			return ErrDuplicado
+		case 1452: // ER_NO_REFERENCED_ROW_2
+			return ErrReferenciaInvalida
+		case 1451: // ER_ROW_IS_REFERENCED_2
+			return ErrEnUso
+		}
+	}
+	return err
+}
+
+// filaAfectada convierte en ErrNotFound una sentencia que no afectó filas
+func filaAfectada(result sql.Result, err error) error {
+	if err != nil {
+		return traducirError(err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// idONulo guarda NULL cuando no hay usuario (por ejemplo, procesos automáticos)
+func idONulo(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
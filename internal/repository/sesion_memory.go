@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// SesionMemory implementa SesionRepository en memoria
+type SesionMemory struct {
+	mu       sync.RWMutex
+	sesiones map[string]models.Sesion
+}
+
+func NewSesionMemory() *SesionMemory {
+	return &SesionMemory{sesiones: map[string]models.Sesion{}}
+}
+
+func (r *SesionMemory) Get(ctx context.Context, id string) (*models.Sesion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.sesiones[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (r *SesionMemory) Guardar(ctx context.Context, s *models.Sesion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nueva := *s
+	if actual, ok := r.sesiones[s.ID]; ok {
+		nueva.CreatedAt = actual.CreatedAt
+		nueva.ExpiraEn = actual.ExpiraEn
+	}
+	r.sesiones[s.ID] = nueva
+	return nil
+}
+
+func (r *SesionMemory) Tocar(ctx context.Context, id string, ahora time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.sesiones[id]; ok {
+		s.UltimaActividad = ahora
+		r.sesiones[id] = s
+	}
+	return nil
+}
+
+func (r *SesionMemory) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sesiones[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.sesiones, id)
+	return nil
+}
+
+func (r *SesionMemory) ListPorUsuario(ctx context.Context, idUsuario int, activaDesde time.Time) ([]models.Sesion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ahora := time.Now()
+	sesiones := []models.Sesion{}
+	for _, s := range r.sesiones {
+		if s.IDUsuario != nil && *s.IDUsuario == idUsuario &&
+			s.ExpiraEn.After(ahora) && !s.UltimaActividad.Before(activaDesde) {
+			sesiones = append(sesiones, s)
+		}
+	}
+	sort.Slice(sesiones, func(i, j int) bool {
+		return sesiones[i].UltimaActividad.After(sesiones[j].UltimaActividad)
+	})
+	return sesiones, nil
+}
+
+func (r *SesionMemory) DeletePorUsuario(ctx context.Context, idUsuario int, excepto string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for id, s := range r.sesiones {
+		if s.IDUsuario != nil && *s.IDUsuario == idUsuario && id != excepto {
+			delete(r.sesiones, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *SesionMemory) PurgarExpiradas(ctx context.Context, ahora, inactivaAntes time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for id, s := range r.sesiones {
+		if !s.ExpiraEn.After(ahora) || s.UltimaActividad.Before(inactivaAntes) {
+			delete(r.sesiones, id)
+			n++
+		}
+	}
+	return n, nil
+}
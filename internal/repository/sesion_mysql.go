@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// SesionMySQL implementa SesionRepository sobre MySQL
+type SesionMySQL struct {
+	db *sql.DB
+}
+
+func NewSesionMySQL(db *sql.DB) *SesionMySQL {
+	return &SesionMySQL{db: db}
+}
+
+const selectSesion = `
+	SELECT id_sesion, id_usuario, datos, ip, user_agent, created_at, ultima_actividad, expira_en
+	FROM sesiones
+`
+
+func scanSesion(s scanner) (*models.Sesion, error) {
+	var sesion models.Sesion
+	err := s.Scan(
+		&sesion.ID,
+		&sesion.IDUsuario,
+		&sesion.Datos,
+		&sesion.IP,
+		&sesion.UserAgent,
+		&sesion.CreatedAt,
+		&sesion.UltimaActividad,
+		&sesion.ExpiraEn,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sesion, nil
+}
+
+func (r *SesionMySQL) Get(ctx context.Context, id string) (*models.Sesion, error) {
+	return scanSesion(r.db.QueryRowContext(ctx, selectSesion+" WHERE id_sesion = ?", id))
+}
+
+func (r *SesionMySQL) Guardar(ctx context.Context, s *models.Sesion) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO sesiones (id_sesion, id_usuario, datos, ip, user_agent, created_at, ultima_actividad, expira_en)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			id_usuario = VALUES(id_usuario), datos = VALUES(datos), ip = VALUES(ip),
+			user_agent = VALUES(user_agent), ultima_actividad = VALUES(ultima_actividad)
+	`, s.ID, s.IDUsuario, s.Datos, s.IP, s.UserAgent, s.CreatedAt, s.UltimaActividad, s.ExpiraEn)
+	return traducirError(err)
+}
+
+func (r *SesionMySQL) Tocar(ctx context.Context, id string, ahora time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE sesiones SET ultima_actividad = ? WHERE id_sesion = ?", ahora, id)
+	return err
+}
+
+func (r *SesionMySQL) Delete(ctx context.Context, id string) error {
+	return filaAfectada(r.db.ExecContext(ctx, "DELETE FROM sesiones WHERE id_sesion = ?", id))
+}
+
+func (r *SesionMySQL) ListPorUsuario(ctx context.Context, idUsuario int, activaDesde time.Time) ([]models.Sesion, error) {
+	rows, err := r.db.QueryContext(ctx, selectSesion+`
+		WHERE id_usuario = ? AND expira_en > ? AND ultima_actividad >= ?
+		ORDER BY ultima_actividad DESC
+	`, idUsuario, time.Now(), activaDesde)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sesiones := []models.Sesion{}
+	for rows.Next() {
+		s, err := scanSesion(rows)
+		if err != nil {
+			return nil, err
+		}
+		sesiones = append(sesiones, *s)
+	}
+	return sesiones, rows.Err()
+}
+
+func (r *SesionMySQL) DeletePorUsuario(ctx context.Context, idUsuario int, excepto string) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM sesiones WHERE id_usuario = ? AND id_sesion != ?", idUsuario, excepto)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *SesionMySQL) PurgarExpiradas(ctx context.Context, ahora, inactivaAntes time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM sesiones WHERE expira_en <= ? OR ultima_actividad < ?", ahora, inactivaAntes)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
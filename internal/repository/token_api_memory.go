@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// TokenAPIMemory implementa TokenAPIRepository en memoria
+type TokenAPIMemory struct {
+	mu     sync.Mutex
+	tokens map[int]models.TokenAPI
+	nextID int
+}
+
+func NewTokenAPIMemory() *TokenAPIMemory {
+	return &TokenAPIMemory{tokens: map[int]models.TokenAPI{}, nextID: 1}
+}
+
+func (r *TokenAPIMemory) Create(ctx context.Context, t *models.TokenAPI) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existente := range r.tokens {
+		if existente.Hash == t.Hash {
+			return 0, ErrDuplicado
+		}
+	}
+	nuevo := *t
+	nuevo.IDToken = r.nextID
+	nuevo.Alcances = append([]string{}, t.Alcances...)
+	nuevo.CreatedAt = time.Now()
+	r.tokens[nuevo.IDToken] = nuevo
+	r.nextID++
+	return nuevo.IDToken, nil
+}
+
+func (r *TokenAPIMemory) ListPorUsuario(ctx context.Context, idUsuario int) ([]models.TokenAPI, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens := []models.TokenAPI{}
+	for _, t := range r.tokens {
+		if t.IDUsuario == idUsuario {
+			tokens = append(tokens, t)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].IDToken > tokens[j].IDToken })
+	return tokens, nil
+}
+
+func (r *TokenAPIMemory) GetPorHash(ctx context.Context, hash string) (*models.TokenAPI, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.Hash == hash {
+			return &t, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *TokenAPIMemory) RegistrarUso(ctx context.Context, idToken int, ip string, ahora time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[idToken]
+	if !ok {
+		return ErrNotFound
+	}
+	t.UltimoUso = &ahora
+	t.UltimaIP = &ip
+	r.tokens[idToken] = t
+	return nil
+}
+
+func (r *TokenAPIMemory) Delete(ctx context.Context, idUsuario, idToken int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[idToken]
+	if !ok || t.IDUsuario != idUsuario {
+		return ErrNotFound
+	}
+	delete(r.tokens, idToken)
+	return nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// TokenAPIMySQL implementa TokenAPIRepository sobre MySQL
+type TokenAPIMySQL struct {
+	db *sql.DB
+}
+
+func NewTokenAPIMySQL(db *sql.DB) *TokenAPIMySQL {
+	return &TokenAPIMySQL{db: db}
+}
+
+const selectTokenAPI = `
+	SELECT id_token, id_usuario, hash, nombre, prefijo, alcances, expira_en, ultimo_uso, ultima_ip, created_at
+	FROM tokens_api
+`
+
+func scanTokenAPI(s scanner) (*models.TokenAPI, error) {
+	var t models.TokenAPI
+	var alcances string
+	err := s.Scan(
+		&t.IDToken,
+		&t.IDUsuario,
+		&t.Hash,
+		&t.Nombre,
+		&t.Prefijo,
+		&alcances,
+		&t.ExpiraEn,
+		&t.UltimoUso,
+		&t.UltimaIP,
+		&t.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Alcances = []string{}
+	if alcances != "" {
+		t.Alcances = strings.Split(alcances, ",")
+	}
+	return &t, nil
+}
+
+func (r *TokenAPIMySQL) Create(ctx context.Context, t *models.TokenAPI) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO tokens_api (id_usuario, hash, nombre, prefijo, alcances, expira_en)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, t.IDUsuario, t.Hash, t.Nombre, t.Prefijo, strings.Join(t.Alcances, ","), t.ExpiraEn)
+	if err != nil {
+		return 0, traducirError(err)
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+func (r *TokenAPIMySQL) ListPorUsuario(ctx context.Context, idUsuario int) ([]models.TokenAPI, error) {
+	rows, err := r.db.QueryContext(ctx, selectTokenAPI+" WHERE id_usuario = ? ORDER BY created_at DESC", idUsuario)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.TokenAPI{}
+	for rows.Next() {
+		t, err := scanTokenAPI(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *TokenAPIMySQL) GetPorHash(ctx context.Context, hash string) (*models.TokenAPI, error) {
+	return scanTokenAPI(r.db.QueryRowContext(ctx, selectTokenAPI+" WHERE hash = ?", hash))
+}
+
+func (r *TokenAPIMySQL) RegistrarUso(ctx context.Context, idToken int, ip string, ahora time.Time) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"UPDATE tokens_api SET ultimo_uso = ?, ultima_ip = ? WHERE id_token = ?", ahora, ip, idToken))
+}
+
+func (r *TokenAPIMySQL) Delete(ctx context.Context, idUsuario, idToken int) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"DELETE FROM tokens_api WHERE id_token = ? AND id_usuario = ?", idToken, idUsuario))
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// TokenPasswordMemory implementa TokenPasswordRepository en memoria
+type TokenPasswordMemory struct {
+	mu     sync.Mutex
+	tokens map[string]models.TokenPassword
+}
+
+func NewTokenPasswordMemory() *TokenPasswordMemory {
+	return &TokenPasswordMemory{tokens: map[string]models.TokenPassword{}}
+}
+
+func (r *TokenPasswordMemory) Create(ctx context.Context, t *models.TokenPassword) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tokens[t.ID]; ok {
+		return ErrDuplicado
+	}
+	nuevo := *t
+	nuevo.CreatedAt = time.Now()
+	r.tokens[t.ID] = nuevo
+	return nil
+}
+
+func (r *TokenPasswordMemory) Get(ctx context.Context, id string, ahora time.Time) (*models.TokenPassword, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok || t.UsadoEn != nil || !ahora.Before(t.ExpiraEn) {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+func (r *TokenPasswordMemory) Usar(ctx context.Context, id string, ahora time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok || t.UsadoEn != nil || !ahora.Before(t.ExpiraEn) {
+		return ErrNotFound
+	}
+	t.UsadoEn = &ahora
+	r.tokens[id] = t
+	return nil
+}
+
+func (r *TokenPasswordMemory) DeletePorUsuario(ctx context.Context, idUsuario int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, t := range r.tokens {
+		if t.IDUsuario == idUsuario && t.UsadoEn == nil {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
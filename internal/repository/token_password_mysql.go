@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// TokenPasswordMySQL implementa TokenPasswordRepository sobre MySQL
+type TokenPasswordMySQL struct {
+	db *sql.DB
+}
+
+func NewTokenPasswordMySQL(db *sql.DB) *TokenPasswordMySQL {
+	return &TokenPasswordMySQL{db: db}
+}
+
+func (r *TokenPasswordMySQL) Create(ctx context.Context, t *models.TokenPassword) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO tokens_password (id_token, id_usuario, ip, expira_en) VALUES (?, ?, ?, ?)",
+		t.ID, t.IDUsuario, t.IP, t.ExpiraEn)
+	return traducirError(err)
+}
+
+func (r *TokenPasswordMySQL) Get(ctx context.Context, id string, ahora time.Time) (*models.TokenPassword, error) {
+	var t models.TokenPassword
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id_token, id_usuario, ip, expira_en, usado_en, created_at
+		FROM tokens_password
+		WHERE id_token = ? AND usado_en IS NULL AND expira_en > ?
+	`, id, ahora).Scan(&t.ID, &t.IDUsuario, &t.IP, &t.ExpiraEn, &t.UsadoEn, &t.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TokenPasswordMySQL) Usar(ctx context.Context, id string, ahora time.Time) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"UPDATE tokens_password SET usado_en = ? WHERE id_token = ? AND usado_en IS NULL AND expira_en > ?",
+		ahora, id, ahora))
+}
+
+func (r *TokenPasswordMySQL) DeletePorUsuario(ctx context.Context, idUsuario int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM tokens_password WHERE id_usuario = ? AND usado_en IS NULL", idUsuario)
+	return err
+}
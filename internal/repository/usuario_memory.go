@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// UsuarioMemory implementa UsuarioRepository en memoria
+type UsuarioMemory struct {
+	mu       sync.RWMutex
+	usuarios map[int]models.Usuario
+	nextID   int
+}
+
+func NewUsuarioMemory() *UsuarioMemory {
+	return &UsuarioMemory{usuarios: map[int]models.Usuario{}, nextID: 1}
+}
+
+func (r *UsuarioMemory) List(ctx context.Context) ([]models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usuarios := []models.Usuario{}
+	for _, u := range r.usuarios {
+		if u.DeletedAt != nil {
+			continue
+		}
+		u.Password = ""
+		usuarios = append(usuarios, u)
+	}
+	sort.Slice(usuarios, func(i, j int) bool {
+		return usuarios[i].CreatedAt.After(usuarios[j].CreatedAt)
+	})
+	return usuarios, nil
+}
+
+func (r *UsuarioMemory) GetByID(ctx context.Context, id int) (*models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.usuarios[id]
+	if !ok || u.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+func (r *UsuarioMemory) GetByUsuario(ctx context.Context, usuario string) (*models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.usuarios {
+		if u.Usuario == usuario && u.DeletedAt == nil {
+			return &u, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *UsuarioMemory) GetByCorreo(ctx context.Context, correo string) (*models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.usuarios {
+		if u.Correo != nil && *u.Correo == correo && u.DeletedAt == nil {
+			return &u, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *UsuarioMemory) ExisteUsuario(ctx context.Context, usuario string, excluirID int) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.usuarios {
+		if u.Usuario == usuario && u.IDUsuario != excluirID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *UsuarioMemory) ExisteCorreo(ctx context.Context, correo string, excluirID int) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.usuarios {
+		if u.Correo != nil && *u.Correo == correo && u.IDUsuario != excluirID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *UsuarioMemory) Create(ctx context.Context, u *models.Usuario) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existente := range r.usuarios {
+		if existente.Usuario == u.Usuario || mismoCorreo(existente.Correo, u.Correo) {
+			return 0, ErrDuplicado
+		}
+	}
+
+	nuevo := *u
+	nuevo.IDUsuario = r.nextID
+	nuevo.Origen = origenUsuario(u)
+	nuevo.CreatedAt = time.Now()
+	nuevo.UpdatedAt = nuevo.CreatedAt
+	nuevo.Version = 1
+	r.usuarios[nuevo.IDUsuario] = nuevo
+	r.nextID++
+	return nuevo.IDUsuario, nil
+}
+
+func (r *UsuarioMemory) Update(ctx context.Context, u *models.Usuario) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	actual, ok := r.usuarios[u.IDUsuario]
+	if !ok || actual.DeletedAt != nil {
+		return ErrNotFound
+	}
+	if u.Version != actual.Version {
+		return ErrConflictoVersion
+	}
+	for id, existente := range r.usuarios {
+		if id != u.IDUsuario && (existente.Usuario == u.Usuario || mismoCorreo(existente.Correo, u.Correo)) {
+			return ErrDuplicado
+		}
+	}
+
+	actualizado := *u
+	actualizado.CreatedAt = actual.CreatedAt
+	actualizado.UpdatedAt = time.Now()
+	actualizado.Version = actual.Version + 1
+	actualizado.Origen = actual.Origen
+	if actualizado.Password == "" {
+		actualizado.Password = actual.Password
+	}
+	r.usuarios[u.IDUsuario] = actualizado
+	return nil
+}
+
+func (r *UsuarioMemory) CambiarPassword(ctx context.Context, id int, password string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.usuarios[id]
+	if !ok || u.DeletedAt != nil {
+		return ErrNotFound
+	}
+	u.Password = password
+	u.Version++
+	u.UpdatedAt = time.Now()
+	r.usuarios[id] = u
+	return nil
+}
+
+func (r *UsuarioMemory) Delete(ctx context.Context, id int, eliminadoPor int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.usuarios[id]
+	if !ok || u.DeletedAt != nil {
+		return ErrNotFound
+	}
+	ahora := time.Now()
+	u.DeletedAt = &ahora
+	if eliminadoPor != 0 {
+		u.DeletedBy = &eliminadoPor
+	}
+	r.usuarios[id] = u
+	return nil
+}
+
+func (r *UsuarioMemory) ListEliminados(ctx context.Context) ([]models.Usuario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	usuarios := []models.Usuario{}
+	for _, u := range r.usuarios {
+		if u.DeletedAt != nil {
+			u.Password = ""
+			usuarios = append(usuarios, u)
+		}
+	}
+	sort.Slice(usuarios, func(i, j int) bool {
+		return usuarios[i].DeletedAt.After(*usuarios[j].DeletedAt)
+	})
+	return usuarios, nil
+}
+
+func (r *UsuarioMemory) Restaurar(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.usuarios[id]
+	if !ok || u.DeletedAt == nil {
+		return ErrNotFound
+	}
+	u.DeletedAt, u.DeletedBy = nil, nil
+	r.usuarios[id] = u
+	return nil
+}
+
+func (r *UsuarioMemory) Purgar(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.usuarios[id]
+	if !ok || u.DeletedAt == nil {
+		return ErrNotFound
+	}
+	delete(r.usuarios, id)
+	return nil
+}
+
+func (r *UsuarioMemory) PurgarEliminadosAntes(ctx context.Context, limite time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purgados int64
+	for id, u := range r.usuarios {
+		if u.DeletedAt != nil && u.DeletedAt.Before(limite) {
+			delete(r.usuarios, id)
+			purgados++
+		}
+	}
+	return purgados, nil
+}
+
+// contarPorPlantel cuenta los usuarios asignados a un plantel, incluidos los
+// de la papelera, como hace la llave foránea en MySQL
+func (r *UsuarioMemory) contarPorPlantel(id int) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	for _, u := range r.usuarios {
+		if u.IDPlantel != nil && *u.IDPlantel == id {
+			n++
+		}
+	}
+	return n
+}
+
+// mismoCorreo emula la llave única de MySQL, que admite varios NULL
+func mismoCorreo(a, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"ues-egresados/internal/models"
+)
+
+// UsuarioMySQL implementa UsuarioRepository sobre MySQL
+type UsuarioMySQL struct {
+	db *sql.DB
+}
+
+func NewUsuarioMySQL(db *sql.DB) *UsuarioMySQL {
+	return &UsuarioMySQL{db: db}
+}
+
+func (r *UsuarioMySQL) List(ctx context.Context) ([]models.Usuario, error) {
+	return r.listar(ctx, "u.deleted_at IS NULL", "u.created_at DESC")
+}
+
+func (r *UsuarioMySQL) ListEliminados(ctx context.Context) ([]models.Usuario, error) {
+	return r.listar(ctx, "u.deleted_at IS NOT NULL", "u.deleted_at DESC")
+}
+
+func (r *UsuarioMySQL) listar(ctx context.Context, condicion, orden string) ([]models.Usuario, error) {
+	query := `
+		SELECT u.id_usuario, u.usuario, u.nombre, u.apellido_paterno, u.apellido_materno, u.correo, u.rol,
+		       u.id_plantel, COALESCE(p.nombre, ''), u.origen, u.created_at,
+		       u.version, u.updated_at, u.deleted_at, u.deleted_by
+		FROM usuarios u
+		LEFT JOIN planteles p ON u.id_plantel = p.id_plantel
+		WHERE ` + condicion + `
+		ORDER BY ` + orden
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usuarios := []models.Usuario{}
+	for rows.Next() {
+		var u models.Usuario
+		err := rows.Scan(
+			&u.IDUsuario,
+			&u.Usuario,
+			&u.Nombre,
+			&u.ApellidoPaterno,
+			&u.ApellidoMaterno,
+			&u.Correo,
+			&u.Rol,
+			&u.IDPlantel,
+			&u.NombrePlantel,
+			&u.Origen,
+			&u.CreatedAt,
+			&u.Version,
+			&u.UpdatedAt,
+			&u.DeletedAt,
+			&u.DeletedBy,
+		)
+		if err != nil {
+			return nil, err
+		}
+		usuarios = append(usuarios, u)
+	}
+	return usuarios, rows.Err()
+}
+
+const selectUsuario = `
+	SELECT u.id_usuario, u.usuario, u.nombre, u.apellido_paterno, u.apellido_materno, u.correo, u.password, u.rol,
+	       u.id_plantel, COALESCE(p.nombre, ''), u.origen, u.created_at, u.version, u.updated_at
+	FROM usuarios u
+	LEFT JOIN planteles p ON u.id_plantel = p.id_plantel
+	WHERE u.deleted_at IS NULL
+`
+
+func scanUsuario(s scanner) (*models.Usuario, error) {
+	var u models.Usuario
+	err := s.Scan(
+		&u.IDUsuario,
+		&u.Usuario,
+		&u.Nombre,
+		&u.ApellidoPaterno,
+		&u.ApellidoMaterno,
+		&u.Correo,
+		&u.Password,
+		&u.Rol,
+		&u.IDPlantel,
+		&u.NombrePlantel,
+		&u.Origen,
+		&u.CreatedAt,
+		&u.Version,
+		&u.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *UsuarioMySQL) GetByID(ctx context.Context, id int) (*models.Usuario, error) {
+	return scanUsuario(r.db.QueryRowContext(ctx, selectUsuario+" AND u.id_usuario = ?", id))
+}
+
+func (r *UsuarioMySQL) GetByUsuario(ctx context.Context, usuario string) (*models.Usuario, error) {
+	return scanUsuario(r.db.QueryRowContext(ctx, selectUsuario+" AND u.usuario = ?", usuario))
+}
+
+// GetByCorreo incluye el hash de la contraseña
+func (r *UsuarioMySQL) GetByCorreo(ctx context.Context, correo string) (*models.Usuario, error) {
+	return scanUsuario(r.db.QueryRowContext(ctx, selectUsuario+" AND u.correo = ?", correo))
+}
+
+// ExisteUsuario también considera los usuarios en la papelera, que conservan su nombre
+func (r *UsuarioMySQL) ExisteUsuario(ctx context.Context, usuario string, excluirID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM usuarios WHERE usuario = ? AND id_usuario != ?)",
+		usuario, excluirID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// ExisteCorreo también considera los usuarios en la papelera
+func (r *UsuarioMySQL) ExisteCorreo(ctx context.Context, correo string, excluirID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM usuarios WHERE correo = ? AND id_usuario != ?)",
+		correo, excluirID,
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *UsuarioMySQL) Create(ctx context.Context, u *models.Usuario) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO usuarios (usuario, nombre, apellido_paterno, apellido_materno, correo, password, rol, id_plantel, origen) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		u.Usuario,
+		u.Nombre,
+		u.ApellidoPaterno,
+		u.ApellidoMaterno,
+		u.Correo,
+		u.Password,
+		u.Rol,
+		u.IDPlantel,
+		origenUsuario(u),
+	)
+	if err != nil {
+		return 0, traducirError(err)
+	}
+
+	lastID, err := result.LastInsertId()
+	return int(lastID), err
+}
+
+// origenUsuario devuelve el origen con el que se crea la cuenta
+func origenUsuario(u *models.Usuario) string {
+	if u.Origen == "" {
+		return models.OrigenLocal
+	}
+	return u.Origen
+}
+
+func (r *UsuarioMySQL) Update(ctx context.Context, u *models.Usuario) error {
+	var query string
+	var args []interface{}
+
+	if u.Password != "" {
+		query = "UPDATE usuarios SET usuario = ?, nombre = ?, apellido_paterno = ?, apellido_materno = ?, correo = ?, password = ?, rol = ?, id_plantel = ?, version = version + 1 WHERE id_usuario = ? AND deleted_at IS NULL AND version = ?"
+		args = []interface{}{u.Usuario, u.Nombre, u.ApellidoPaterno, u.ApellidoMaterno, u.Correo, u.Password, u.Rol, u.IDPlantel, u.IDUsuario, u.Version}
+	} else {
+		// Sin contraseña, solo actualizar datos
+		query = "UPDATE usuarios SET usuario = ?, nombre = ?, apellido_paterno = ?, apellido_materno = ?, correo = ?, rol = ?, id_plantel = ?, version = version + 1 WHERE id_usuario = ? AND deleted_at IS NULL AND version = ?"
+		args = []interface{}{u.Usuario, u.Nombre, u.ApellidoPaterno, u.ApellidoMaterno, u.Correo, u.Rol, u.IDPlantel, u.IDUsuario, u.Version}
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return traducirError(err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		if _, err := r.GetByID(ctx, u.IDUsuario); err != nil {
+			return err
+		}
+		return ErrConflictoVersion
+	}
+	return nil
+}
+
+func (r *UsuarioMySQL) CambiarPassword(ctx context.Context, id int, password string) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"UPDATE usuarios SET password = ?, version = version + 1 WHERE id_usuario = ? AND deleted_at IS NULL",
+		password, id,
+	))
+}
+
+func (r *UsuarioMySQL) Delete(ctx context.Context, id int, eliminadoPor int) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"UPDATE usuarios SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ? WHERE id_usuario = ? AND deleted_at IS NULL",
+		idONulo(eliminadoPor), id,
+	))
+}
+
+func (r *UsuarioMySQL) Restaurar(ctx context.Context, id int) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"UPDATE usuarios SET deleted_at = NULL, deleted_by = NULL WHERE id_usuario = ? AND deleted_at IS NOT NULL", id))
+}
+
+func (r *UsuarioMySQL) Purgar(ctx context.Context, id int) error {
+	return filaAfectada(r.db.ExecContext(ctx,
+		"DELETE FROM usuarios WHERE id_usuario = ? AND deleted_at IS NOT NULL", id))
+}
+
+func (r *UsuarioMySQL) PurgarEliminadosAntes(ctx context.Context, limite time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM usuarios WHERE deleted_at < ?", limite)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
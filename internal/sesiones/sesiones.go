@@ -0,0 +1,274 @@
+package sesiones
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+
+	"github.com/gorilla/sessions"
+)
+
+const (
+	// InactividadPorDefecto cierra la sesión tras este tiempo sin peticiones
+	InactividadPorDefecto = 30 * time.Minute
+	// DuracionPorDefecto es la vida máxima de una sesión aunque tenga actividad
+	DuracionPorDefecto = 8 * time.Hour
+
+	// La última actividad se actualiza como máximo una vez por minuto para no
+	// escribir en cada petición
+	intervaloActividad = time.Minute
+
+	// TokenAPI guarda el ID del token personal en las sesiones que se arman en
+	// memoria para una petición con "Authorization: Bearer"; esas sesiones no
+	// se guardan ni envían cookie
+	TokenAPI = "token_api"
+)
+
+// Opciones define los límites de vida de las sesiones
+type Opciones struct {
+	Inactividad time.Duration
+	Duracion    time.Duration
+}
+
+// OpcionesDesdeEntorno lee SESION_INACTIVIDAD_MINUTOS y SESION_DURACION_HORAS
+func OpcionesDesdeEntorno() Opciones {
+	return Opciones{
+		Inactividad: duracionDesdeEntorno("SESION_INACTIVIDAD_MINUTOS", time.Minute, InactividadPorDefecto),
+		Duracion:    duracionDesdeEntorno("SESION_DURACION_HORAS", time.Hour, DuracionPorDefecto),
+	}
+}
+
+func duracionDesdeEntorno(variable string, unidad, porDefecto time.Duration) time.Duration {
+	valor := os.Getenv(variable)
+	if valor == "" {
+		return porDefecto
+	}
+	n, err := strconv.Atoi(valor)
+	if err != nil || n <= 0 {
+		log.Printf("⚠️  %s inválido (%q), usando %s", variable, valor, porDefecto)
+		return porDefecto
+	}
+	return time.Duration(n) * unidad
+}
+
+// Store implementa sessions.Store guardando los valores de la sesión en el
+// servidor. La cookie solo lleva un token aleatorio; en la base de datos se
+// guarda su hash, así que las sesiones pueden listarse y revocarse.
+type Store struct {
+	Options  *sessions.Options
+	repo     repository.SesionRepository
+	opciones Opciones
+}
+
+func NewStore(repo repository.SesionRepository, opciones Opciones) *Store {
+	return &Store{
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   int(opciones.Duracion.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+		repo:     repo,
+		opciones: opciones,
+	}
+}
+
+// Get devuelve la sesión de la petición, usando la caché de gorilla/sessions
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New carga la sesión de la cookie. Si no existe, expiró o superó el tiempo
+// de inactividad devuelve una sesión vacía.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opciones := *s.Options
+	session.Options = &opciones
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil || cookie.Value == "" {
+		return session, nil
+	}
+
+	sesion, err := s.cargar(r.Context(), cookie.Value)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return session, nil
+		}
+		return session, err
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(sesion.Datos)).Decode(&session.Values); err != nil {
+		return session, err
+	}
+	session.ID = cookie.Value
+	session.IsNew = false
+	return session, nil
+}
+
+// cargar busca la sesión del token, descarta las vencidas y registra la actividad
+func (s *Store) cargar(ctx context.Context, token string) (*models.Sesion, error) {
+	id := IDDeToken(token)
+	sesion, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ahora := time.Now()
+	if !ahora.Before(sesion.ExpiraEn) || ahora.Sub(sesion.UltimaActividad) > s.opciones.Inactividad {
+		s.repo.Delete(ctx, id)
+		return nil, repository.ErrNotFound
+	}
+
+	if ahora.Sub(sesion.UltimaActividad) > intervaloActividad {
+		if err := s.repo.Tocar(ctx, id, ahora); err != nil {
+			log.Printf("Error al actualizar la actividad de la sesión: %v", err)
+		}
+	}
+	return sesion, nil
+}
+
+// Save guarda los valores de la sesión y envía la cookie. Con MaxAge < 0
+// elimina la sesión del servidor y borra la cookie. Las sesiones de un token
+// personal no se guardan.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if _, ok := session.Values[TokenAPI]; ok {
+		return nil
+	}
+
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := s.repo.Delete(r.Context(), IDDeToken(session.ID)); err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		token, err := nuevoToken()
+		if err != nil {
+			return err
+		}
+		session.ID = token
+	}
+
+	var datos bytes.Buffer
+	if err := gob.NewEncoder(&datos).Encode(session.Values); err != nil {
+		return err
+	}
+
+	ahora := time.Now()
+	sesion := &models.Sesion{
+		ID:              IDDeToken(session.ID),
+		Datos:           datos.Bytes(),
+		IP:              utils.ClientIP(r),
+		UserAgent:       utils.UserAgent(r),
+		CreatedAt:       ahora,
+		UltimaActividad: ahora,
+		ExpiraEn:        ahora.Add(s.opciones.Duracion),
+	}
+	if idUsuario, ok := session.Values["user_id"].(int); ok && idUsuario != 0 {
+		sesion.IDUsuario = &idUsuario
+	}
+	if err := s.repo.Guardar(r.Context(), sesion); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, sessions.NewCookie(session.Name(), session.ID, session.Options))
+	return nil
+}
+
+// Regenerar descarta la sesión actual y sus valores; el siguiente Save emite
+// un token nuevo. Se usa al iniciar sesión para evitar la fijación de sesión.
+func (s *Store) Regenerar(ctx context.Context, session *sessions.Session) error {
+	if session.ID != "" {
+		if err := s.repo.Delete(ctx, IDDeToken(session.ID)); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+	session.ID = ""
+	session.IsNew = true
+	session.Values = map[interface{}]interface{}{}
+	return nil
+}
+
+// IDActual devuelve el ID guardado de la sesión de la petición ("" si no hay)
+func (s *Store) IDActual(r *http.Request, name string) string {
+	session, _ := s.Get(r, name)
+	if session == nil || session.ID == "" {
+		return ""
+	}
+	return IDDeToken(session.ID)
+}
+
+// Listar devuelve las sesiones vigentes del usuario
+func (s *Store) Listar(ctx context.Context, idUsuario int) ([]models.Sesion, error) {
+	return s.repo.ListPorUsuario(ctx, idUsuario, time.Now().Add(-s.opciones.Inactividad))
+}
+
+// Revocar cierra una sesión del usuario; devuelve repository.ErrNotFound si
+// no existe o es de otro usuario
+func (s *Store) Revocar(ctx context.Context, idUsuario int, id string) error {
+	sesion, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sesion.IDUsuario == nil || *sesion.IDUsuario != idUsuario {
+		return repository.ErrNotFound
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// CerrarSesionesDeUsuario revoca todas las sesiones del usuario salvo
+// excepto y devuelve cuántas cerró
+func (s *Store) CerrarSesionesDeUsuario(ctx context.Context, idUsuario int, excepto string) (int64, error) {
+	return s.repo.DeletePorUsuario(ctx, idUsuario, excepto)
+}
+
+// Iniciar elimina periódicamente las sesiones vencidas hasta que ctx se cancele
+func (s *Store) Iniciar(ctx context.Context, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		ahora := time.Now()
+		if _, err := s.repo.PurgarExpiradas(ctx, ahora, ahora.Add(-s.opciones.Inactividad)); err != nil {
+			log.Printf("Error al purgar sesiones vencidas: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IDDeToken calcula el ID con el que se guarda la sesión de un token
+func IDDeToken(token string) string {
+	suma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(suma[:])
+}
+
+func nuevoToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,264 @@
+// Package sso implementa el inicio de sesión con un proveedor OpenID Connect
+// (la cuenta institucional). Usa el flujo de código de autorización con PKCE;
+// la configuración del proveedor y sus llaves se obtienen por discovery y el
+// ID token se valida contra el JWKS publicado.
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"ues-egresados/internal/auth"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// RutaRetorno es la ruta a la que el proveedor regresa con el código
+	RutaRetorno = "/login/sso/callback"
+
+	// RolPorDefecto es el rol de los usuarios dados de alta al entrar por
+	// primera vez si OIDC_ROL_POR_DEFECTO no está definida
+	RolPorDefecto = auth.RolConsulta
+
+	// Tiempo máximo para las peticiones al proveedor (discovery, JWKS y token)
+	tiempoEspera = 10 * time.Second
+)
+
+// Configuracion es la aplicación registrada en el proveedor y cómo se tratan
+// las cuentas que aún no existen en usuarios
+type Configuracion struct {
+	Emisor       string
+	ClientID     string
+	ClientSecret string // vacío para clientes públicos; PKCE protege el código
+	URLRetorno   string
+	Scopes       []string
+	// Nombre es el texto del botón del login ("Iniciar sesión con ...")
+	Nombre string
+	// AltaAutomatica crea el usuario la primera vez que entra con RolAlta y,
+	// si el rol lo requiere, el plantel con clave PlantelAlta
+	AltaAutomatica bool
+	RolAlta        string
+	PlantelAlta    string
+}
+
+// DesdeEntorno lee OIDC_EMISOR, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET,
+// OIDC_URL_RETORNO, OIDC_SCOPES, OIDC_NOMBRE, OIDC_ALTA_AUTOMATICA,
+// OIDC_ROL_POR_DEFECTO y OIDC_PLANTEL_POR_DEFECTO. Devuelve nil si OIDC_EMISOR
+// no está definida, con lo que solo queda el login con contraseña.
+func DesdeEntorno(urlBase string) *Configuracion {
+	emisor := strings.TrimRight(os.Getenv("OIDC_EMISOR"), "/")
+	if emisor == "" {
+		return nil
+	}
+	conf := &Configuracion{
+		Emisor:       emisor,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		URLRetorno:   os.Getenv("OIDC_URL_RETORNO"),
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+		Nombre:       os.Getenv("OIDC_NOMBRE"),
+		RolAlta:      os.Getenv("OIDC_ROL_POR_DEFECTO"),
+		PlantelAlta:  strings.TrimSpace(os.Getenv("OIDC_PLANTEL_POR_DEFECTO")),
+	}
+	if conf.ClientID == "" {
+		log.Println("⚠️  OIDC_EMISOR definido sin OIDC_CLIENT_ID; el inicio de sesión institucional queda desactivado")
+		return nil
+	}
+	if conf.URLRetorno == "" {
+		conf.URLRetorno = strings.TrimRight(urlBase, "/") + RutaRetorno
+	}
+	if len(conf.Scopes) == 0 {
+		conf.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+	if conf.Nombre == "" {
+		conf.Nombre = "cuenta institucional"
+	}
+
+	if valor := os.Getenv("OIDC_ALTA_AUTOMATICA"); valor != "" {
+		alta, err := strconv.ParseBool(valor)
+		if err != nil {
+			log.Printf("⚠️  OIDC_ALTA_AUTOMATICA inválido (%q), se deja desactivada", valor)
+		}
+		conf.AltaAutomatica = alta
+	}
+	if conf.RolAlta == "" {
+		conf.RolAlta = RolPorDefecto
+	} else if !auth.RolValido(conf.RolAlta) {
+		log.Printf("⚠️  Rol desconocido en OIDC_ROL_POR_DEFECTO: %q, usando %s", conf.RolAlta, RolPorDefecto)
+		conf.RolAlta = RolPorDefecto
+	}
+	if conf.AltaAutomatica && conf.PlantelAlta == "" && !auth.HasPermission(conf.RolAlta, auth.PermPlantelesTodos) {
+		log.Printf("⚠️  El rol %s requiere plantel; sin OIDC_PLANTEL_POR_DEFECTO no se podrán dar de alta usuarios", conf.RolAlta)
+	}
+	return conf
+}
+
+// Identidad son los datos de la persona que devuelve el proveedor
+type Identidad struct {
+	Sujeto  string
+	Correo  string
+	Usuario string // claim preferred_username
+	Nombre  string
+	// Apellidos es family_name; puede traer los dos apellidos
+	Apellidos      string
+	NombreCompleto string
+}
+
+// Errores del regreso desde el proveedor
+var (
+	ErrRechazado     = errors.New("el proveedor rechazó el inicio de sesión")
+	ErrTokenInvalido = errors.New("el ID token no es válido")
+)
+
+// Proveedor habla con el proveedor OpenID Connect. El discovery se hace en el
+// primer uso y se reintenta si falla, así el servidor arranca aunque el
+// proveedor no esté disponible.
+type Proveedor struct {
+	conf    Configuracion
+	cliente *http.Client
+
+	mu          sync.Mutex
+	oauth       *oauth2.Config
+	verificador *oidc.IDTokenVerifier
+}
+
+func NuevoProveedor(conf Configuracion) *Proveedor {
+	return &Proveedor{conf: conf, cliente: &http.Client{Timeout: tiempoEspera}}
+}
+
+// Configuracion devuelve la configuración con la que se creó el proveedor
+func (p *Proveedor) Configuracion() Configuracion {
+	return p.conf
+}
+
+func (p *Proveedor) descubrir() (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil {
+		return p.oauth, p.verificador, nil
+	}
+
+	// El proveedor conserva el contexto para descargar las llaves del JWKS
+	// cuando rotan, así que no debe ser el de la petición
+	proveedor, err := oidc.NewProvider(oidc.ClientContext(context.Background(), p.cliente), p.conf.Emisor)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovery de %s: %w", p.conf.Emisor, err)
+	}
+
+	p.oauth = &oauth2.Config{
+		ClientID:     p.conf.ClientID,
+		ClientSecret: p.conf.ClientSecret,
+		Endpoint:     proveedor.Endpoint(),
+		RedirectURL:  p.conf.URLRetorno,
+		Scopes:       p.conf.Scopes,
+	}
+	p.verificador = proveedor.Verifier(&oidc.Config{ClientID: p.conf.ClientID})
+	return p.oauth, p.verificador, nil
+}
+
+// Solicitud guarda entre la ida y el regreso lo necesario para validar la
+// respuesta del proveedor
+type Solicitud struct {
+	Estado          string
+	Nonce           string
+	VerificadorPKCE string
+}
+
+// NuevaSolicitud genera el state, el nonce y el verificador PKCE de un inicio
+// de sesión
+func NuevaSolicitud() (*Solicitud, error) {
+	estado, err := aleatorio()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := aleatorio()
+	if err != nil {
+		return nil, err
+	}
+	return &Solicitud{Estado: estado, Nonce: nonce, VerificadorPKCE: oauth2.GenerateVerifier()}, nil
+}
+
+// URLAutorizacion devuelve la dirección del proveedor a la que se envía al
+// usuario, con el reto PKCE S256 de la solicitud
+func (p *Proveedor) URLAutorizacion(ctx context.Context, s *Solicitud) (string, error) {
+	conf, _, err := p.descubrir()
+	if err != nil {
+		return "", err
+	}
+	return conf.AuthCodeURL(s.Estado, oidc.Nonce(s.Nonce), oauth2.S256ChallengeOption(s.VerificadorPKCE)), nil
+}
+
+// Canjear cambia el código de autorización por tokens, valida el ID token
+// (firma con el JWKS, emisor, audiencia, vencimiento y nonce) y devuelve la
+// identidad. Un correo marcado como no verificado se descarta.
+func (p *Proveedor) Canjear(ctx context.Context, s *Solicitud, codigo string) (*Identidad, error) {
+	conf, verificador, err := p.descubrir()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oidc.ClientContext(ctx, p.cliente)
+	token, err := conf.Exchange(ctx, codigo, oauth2.VerifierOption(s.VerificadorPKCE))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRechazado, err)
+	}
+
+	crudo, ok := token.Extra("id_token").(string)
+	if !ok || crudo == "" {
+		return nil, fmt.Errorf("%w: la respuesta no trae id_token", ErrTokenInvalido)
+	}
+	idToken, err := verificador.Verify(ctx, crudo)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalido, err)
+	}
+	if idToken.Nonce != s.Nonce {
+		return nil, fmt.Errorf("%w: nonce distinto", ErrTokenInvalido)
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     *bool  `json:"email_verified"`
+		PreferredUsername string `json:"preferred_username"`
+		GivenName         string `json:"given_name"`
+		FamilyName        string `json:"family_name"`
+		Name              string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalido, err)
+	}
+
+	identidad := &Identidad{
+		Sujeto:         idToken.Subject,
+		Correo:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		Usuario:        strings.TrimSpace(claims.PreferredUsername),
+		Nombre:         strings.TrimSpace(claims.GivenName),
+		Apellidos:      strings.TrimSpace(claims.FamilyName),
+		NombreCompleto: strings.TrimSpace(claims.Name),
+	}
+	// Sin el claim se confía en el proveedor institucional, que administra
+	// los correos de su dominio
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		identidad.Correo = ""
+	}
+	return identidad, nil
+}
+
+func aleatorio() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
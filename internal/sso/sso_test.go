@@ -0,0 +1,152 @@
+package sso_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"ues-egresados/internal/sso"
+	"ues-egresados/internal/sso/ssoprueba"
+)
+
+const urlRetorno = "http://app.local/login/sso/callback"
+
+// proveedorPrueba levanta el proveedor en proceso, que aprueba solo con
+// Auto, y el cliente que habla con él
+func proveedorPrueba(t *testing.T) (*ssoprueba.Proveedor, *sso.Proveedor) {
+	t.Helper()
+	idp, err := ssoprueba.Nuevo("http://localhost", "ues-egresados")
+	if err != nil {
+		t.Fatal(err)
+	}
+	servidor := httptest.NewServer(idp)
+	t.Cleanup(servidor.Close)
+	idp.Emisor = servidor.URL
+	idp.Auto = true
+	idp.Usuario = "jperez"
+	idp.Correo = "JPerez@UES.mx"
+
+	cliente := sso.NuevoProveedor(sso.Configuracion{
+		Emisor:     servidor.URL,
+		ClientID:   "ues-egresados",
+		URLRetorno: urlRetorno,
+		Scopes:     []string{"openid", "email", "profile"},
+	})
+	return idp, cliente
+}
+
+// autorizar sigue la URL de autorización como lo haría el navegador y
+// devuelve el código con el que el proveedor regresa
+func autorizar(t *testing.T, cliente *sso.Proveedor, s *sso.Solicitud) string {
+	t.Helper()
+	direccion, err := cliente.URLAutorizacion(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	navegador := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := navegador.Get(direccion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	destino, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if destino.Query().Get("state") != s.Estado {
+		t.Fatalf("el proveedor regresó state %q, se esperaba %q", destino.Query().Get("state"), s.Estado)
+	}
+	codigo := destino.Query().Get("code")
+	if codigo == "" {
+		t.Fatalf("el proveedor no devolvió código: %s", destino)
+	}
+	return codigo
+}
+
+func TestCanjearDevuelveIdentidad(t *testing.T) {
+	_, cliente := proveedorPrueba(t)
+	s, err := sso.NuevaSolicitud()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identidad, err := cliente.Canjear(context.Background(), s, autorizar(t, cliente, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identidad.Usuario != "jperez" || identidad.Correo != "jperez@ues.mx" || identidad.Sujeto != "mock-jperez" {
+		t.Errorf("identidad = %+v", identidad)
+	}
+}
+
+func TestCanjearRechazaVerificadorPKCEDistinto(t *testing.T) {
+	_, cliente := proveedorPrueba(t)
+	s, err := sso.NuevaSolicitud()
+	if err != nil {
+		t.Fatal(err)
+	}
+	codigo := autorizar(t, cliente, s)
+
+	otra, err := sso.NuevaSolicitud()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.VerificadorPKCE = otra.VerificadorPKCE
+	if _, err := cliente.Canjear(context.Background(), s, codigo); !errors.Is(err, sso.ErrRechazado) {
+		t.Errorf("Canjear con otro verificador = %v, se esperaba ErrRechazado", err)
+	}
+}
+
+func TestCanjearRechazaNonceDistinto(t *testing.T) {
+	_, cliente := proveedorPrueba(t)
+	s, err := sso.NuevaSolicitud()
+	if err != nil {
+		t.Fatal(err)
+	}
+	codigo := autorizar(t, cliente, s)
+
+	s.Nonce = "otro-nonce"
+	if _, err := cliente.Canjear(context.Background(), s, codigo); !errors.Is(err, sso.ErrTokenInvalido) {
+		t.Errorf("Canjear con otro nonce = %v, se esperaba ErrTokenInvalido", err)
+	}
+}
+
+func TestCanjearCodigoDeUnSoloUso(t *testing.T) {
+	_, cliente := proveedorPrueba(t)
+	s, err := sso.NuevaSolicitud()
+	if err != nil {
+		t.Fatal(err)
+	}
+	codigo := autorizar(t, cliente, s)
+
+	if _, err := cliente.Canjear(context.Background(), s, codigo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cliente.Canjear(context.Background(), s, codigo); !errors.Is(err, sso.ErrRechazado) {
+		t.Errorf("segundo canje = %v, se esperaba ErrRechazado", err)
+	}
+}
+
+func TestCanjearDescartaCorreoNoVerificado(t *testing.T) {
+	idp, cliente := proveedorPrueba(t)
+	idp.Claims = map[string]interface{}{"email_verified": false}
+	s, err := sso.NuevaSolicitud()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identidad, err := cliente.Canjear(context.Background(), s, autorizar(t, cliente, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identidad.Correo != "" {
+		t.Errorf("Correo = %q, se esperaba vacío con email_verified=false", identidad.Correo)
+	}
+	if identidad.Usuario != "jperez" {
+		t.Errorf("Usuario = %q", identidad.Usuario)
+	}
+}
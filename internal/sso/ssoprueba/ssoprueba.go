@@ -0,0 +1,334 @@
+// Package ssoprueba es un proveedor OpenID Connect mínimo para desarrollo y
+// pruebas. Publica discovery y JWKS, muestra un formulario en lugar de pedir
+// contraseña (o aprueba solo con Auto) y exige PKCE S256 al canjear el
+// código. Lo usan cmd/mock_oidc y las pruebas con httptest. No usar en
+// producción.
+package ssoprueba
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Datos pendientes de un código de autorización emitido
+type autorizacion struct {
+	clientID    string
+	redirectURI string
+	nonce       string
+	reto        string // code_challenge S256
+	claims      map[string]interface{}
+	expira      time.Time
+}
+
+// Proveedor atiende discovery, jwks, authorize y token. Emisor debe ser la
+// dirección en la que escucha (con httptest, la URL del servidor).
+type Proveedor struct {
+	Emisor   string
+	ClientID string
+	// Auto aprueba sin mostrar el formulario, con Usuario, Correo y Claims
+	Auto    bool
+	Usuario string
+	Correo  string
+	// Claims se agregan o reemplazan en los ID tokens aprobados con Auto
+	// (por ejemplo email_verified: false)
+	Claims map[string]interface{}
+
+	llave *rsa.PrivateKey
+	kid   string
+	mux   *http.ServeMux
+
+	mu      sync.Mutex
+	codigos map[string]*autorizacion
+}
+
+var formulario = template.Must(template.New("autorizar").Parse(`<!DOCTYPE html>
+<html lang="es">
+<head><meta charset="UTF-8"><title>Proveedor OIDC de prueba</title></head>
+<body style="font-family: sans-serif; max-width: 420px; margin: 40px auto;">
+<h2>Proveedor OIDC de prueba</h2>
+<p>La aplicación <strong>{{.ClientID}}</strong> solicita iniciar sesión.</p>
+<form method="POST">
+{{range $nombre, $valor := .Parametros}}<input type="hidden" name="{{$nombre}}" value="{{$valor}}">
+{{end}}<p><label>preferred_username<br><input name="preferred_username" value="{{.Usuario}}"></label></p>
+<p><label>email<br><input name="email" value="{{.Correo}}"></label></p>
+<p><label>given_name<br><input name="given_name"></label></p>
+<p><label>family_name<br><input name="family_name"></label></p>
+<p><label><input type="checkbox" name="email_verified" value="true" checked> email_verified</label></p>
+<p><button name="decision" value="aprobar">Aprobar</button> <button name="decision" value="rechazar">Rechazar</button></p>
+</form>
+</body>
+</html>`))
+
+// Nuevo crea el proveedor con una llave RSA nueva
+func Nuevo(emisor, clientID string) (*Proveedor, error) {
+	llave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := aleatorio(8)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Proveedor{
+		Emisor:   strings.TrimRight(emisor, "/"),
+		ClientID: clientID,
+		llave:    llave,
+		kid:      kid,
+		mux:      http.NewServeMux(),
+		codigos:  map[string]*autorizacion{},
+	}
+	p.mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	p.mux.HandleFunc("/jwks", p.jwks)
+	p.mux.HandleFunc("/authorize", p.autorizar)
+	p.mux.HandleFunc("/token", p.token)
+	return p, nil
+}
+
+func (p *Proveedor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+func (p *Proveedor) discovery(w http.ResponseWriter, r *http.Request) {
+	responderJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Emisor,
+		"authorization_endpoint":                p.Emisor + "/authorize",
+		"token_endpoint":                        p.Emisor + "/token",
+		"jwks_uri":                              p.Emisor + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+func (p *Proveedor) jwks(w http.ResponseWriter, r *http.Request) {
+	publica := p.llave.PublicKey
+	responderJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": p.kid,
+			"n":   base64.RawURLEncoding.EncodeToString(publica.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publica.E)).Bytes()),
+		}},
+	})
+}
+
+// autorizar muestra el formulario (GET) y emite el código al aprobarlo (POST)
+func (p *Proveedor) autorizar(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "solicitud inválida", http.StatusBadRequest)
+		return
+	}
+	redirectURI := r.Form.Get("redirect_uri")
+	destino, err := url.Parse(redirectURI)
+	if err != nil || redirectURI == "" {
+		http.Error(w, "redirect_uri inválido", http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("client_id") != p.ClientID {
+		http.Error(w, "client_id desconocido", http.StatusBadRequest)
+		return
+	}
+
+	volver := func(valores url.Values) {
+		valores.Set("state", r.Form.Get("state"))
+		destino.RawQuery = valores.Encode()
+		http.Redirect(w, r, destino.String(), http.StatusFound)
+	}
+
+	if r.Form.Get("response_type") != "code" {
+		volver(url.Values{"error": {"unsupported_response_type"}})
+		return
+	}
+	if r.Form.Get("code_challenge") == "" || r.Form.Get("code_challenge_method") != "S256" {
+		volver(url.Values{"error": {"invalid_request"}, "error_description": {"se requiere PKCE S256"}})
+		return
+	}
+
+	if r.Method == http.MethodGet && !p.Auto {
+		parametros := map[string]string{}
+		for _, nombre := range []string{"client_id", "redirect_uri", "response_type", "scope", "state", "nonce", "code_challenge", "code_challenge_method"} {
+			parametros[nombre] = r.Form.Get(nombre)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		formulario.Execute(w, map[string]interface{}{
+			"ClientID":   p.ClientID,
+			"Parametros": parametros,
+			"Usuario":    p.Usuario,
+			"Correo":     p.Correo,
+		})
+		return
+	}
+
+	if r.Form.Get("decision") == "rechazar" {
+		volver(url.Values{"error": {"access_denied"}})
+		return
+	}
+
+	usuario, correo := p.Usuario, p.Correo
+	verificado := true
+	if !p.Auto {
+		usuario, correo = r.Form.Get("preferred_username"), r.Form.Get("email")
+		verificado = r.Form.Get("email_verified") == "true"
+	}
+	sujeto := usuario
+	if sujeto == "" {
+		sujeto = correo
+	}
+	claims := map[string]interface{}{
+		"sub":                "mock-" + strings.ToLower(sujeto),
+		"preferred_username": usuario,
+		"email":              correo,
+		"email_verified":     verificado,
+	}
+	if nombre := r.Form.Get("given_name"); nombre != "" {
+		claims["given_name"] = nombre
+	}
+	if apellidos := r.Form.Get("family_name"); apellidos != "" {
+		claims["family_name"] = apellidos
+	}
+	if p.Auto {
+		for clave, valor := range p.Claims {
+			claims[clave] = valor
+		}
+	}
+
+	codigo, err := aleatorio(16)
+	if err != nil {
+		http.Error(w, "error del servidor", http.StatusInternalServerError)
+		return
+	}
+	p.mu.Lock()
+	p.codigos[codigo] = &autorizacion{
+		clientID:    p.ClientID,
+		redirectURI: redirectURI,
+		nonce:       r.Form.Get("nonce"),
+		reto:        r.Form.Get("code_challenge"),
+		claims:      claims,
+		expira:      time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	volver(url.Values{"code": {codigo}})
+}
+
+// token canjea el código por el ID token después de comprobar PKCE
+func (p *Proveedor) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		errorToken(w, "invalid_request")
+		return
+	}
+	if r.Form.Get("grant_type") != "authorization_code" {
+		errorToken(w, "unsupported_grant_type")
+		return
+	}
+
+	clientID, _, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.Form.Get("client_id")
+	}
+
+	p.mu.Lock()
+	pendiente := p.codigos[r.Form.Get("code")]
+	delete(p.codigos, r.Form.Get("code"))
+	p.mu.Unlock()
+
+	if pendiente == nil || time.Now().After(pendiente.expira) || clientID != pendiente.clientID || r.Form.Get("redirect_uri") != pendiente.redirectURI {
+		errorToken(w, "invalid_grant")
+		return
+	}
+	suma := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	reto := base64.RawURLEncoding.EncodeToString(suma[:])
+	if subtle.ConstantTimeCompare([]byte(reto), []byte(pendiente.reto)) != 1 {
+		errorToken(w, "invalid_grant")
+		return
+	}
+
+	ahora := time.Now()
+	claims := map[string]interface{}{
+		"iss": p.Emisor,
+		"aud": pendiente.clientID,
+		"iat": ahora.Unix(),
+		"exp": ahora.Add(5 * time.Minute).Unix(),
+	}
+	if pendiente.nonce != "" {
+		claims["nonce"] = pendiente.nonce
+	}
+	for clave, valor := range pendiente.claims {
+		claims[clave] = valor
+	}
+	idToken, err := p.firmar(claims)
+	if err != nil {
+		log.Println("Error al firmar el ID token:", err)
+		errorToken(w, "server_error")
+		return
+	}
+	accessToken, err := aleatorio(16)
+	if err != nil {
+		errorToken(w, "server_error")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	responderJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// firmar arma un JWT RS256
+func (p *Proveedor) firmar(claims map[string]interface{}) (string, error) {
+	encabezado, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": p.kid})
+	if err != nil {
+		return "", err
+	}
+	cuerpo, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	contenido := base64.RawURLEncoding.EncodeToString(encabezado) + "." + base64.RawURLEncoding.EncodeToString(cuerpo)
+	suma := sha256.Sum256([]byte(contenido))
+	firma, err := rsa.SignPKCS1v15(rand.Reader, p.llave, crypto.SHA256, suma[:])
+	if err != nil {
+		return "", err
+	}
+	return contenido + "." + base64.RawURLEncoding.EncodeToString(firma), nil
+}
+
+func errorToken(w http.ResponseWriter, codigo string) {
+	responderJSON(w, http.StatusBadRequest, map[string]string{"error": codigo})
+}
+
+func responderJSON(w http.ResponseWriter, status int, datos interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(datos)
+}
+
+func aleatorio(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,105 @@
+// Package tokensapi emite y verifica los tokens personales con los que los
+// scripts y las integraciones usan la API sin la sesión del navegador.
+package tokensapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+)
+
+const (
+	// Prefijo distingue los tokens del sistema de otras credenciales
+	Prefijo = "ues_"
+	// largoPrefijo es cuánto del token se guarda para reconocerlo en el listado
+	largoPrefijo = len(Prefijo) + 8
+
+	// El último uso se actualiza como máximo una vez por minuto para no
+	// escribir en cada petición
+	intervaloUso = time.Minute
+)
+
+// ErrTokenInvalido indica que el token no existe, venció o su usuario ya no
+// está activo
+var ErrTokenInvalido = errors.New("token inválido o vencido")
+
+// Nuevo genera un token aleatorio y devuelve también su hash y el prefijo
+// que se muestra en el listado
+func Nuevo() (token, hash, prefijo string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = Prefijo + base64.RawURLEncoding.EncodeToString(b)
+	return token, Hash(token), token[:largoPrefijo], nil
+}
+
+// Hash calcula con lo que se guarda y se busca un token
+func Hash(token string) string {
+	suma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(suma[:])
+}
+
+// DeEncabezado devuelve el token de "Authorization: Bearer <token>" o ""
+func DeEncabezado(r *http.Request) string {
+	valor := r.Header.Get("Authorization")
+	if len(valor) < 7 || !strings.EqualFold(valor[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(valor[7:])
+}
+
+// Verificador comprueba los tokens recibidos contra los guardados
+type Verificador struct {
+	tokens   repository.TokenAPIRepository
+	usuarios repository.UsuarioRepository
+}
+
+func NewVerificador(tokens repository.TokenAPIRepository, usuarios repository.UsuarioRepository) *Verificador {
+	return &Verificador{tokens: tokens, usuarios: usuarios}
+}
+
+// Verificar devuelve el token y su usuario, con el rol y el plantel vigentes,
+// y registra el uso desde ip. Devuelve ErrTokenInvalido si no debe aceptarse.
+func (v *Verificador) Verificar(ctx context.Context, token, ip string) (*models.TokenAPI, *models.Usuario, error) {
+	if !strings.HasPrefix(token, Prefijo) {
+		return nil, nil, ErrTokenInvalido
+	}
+
+	t, err := v.tokens.GetPorHash(ctx, Hash(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrTokenInvalido
+		}
+		return nil, nil, err
+	}
+	ahora := time.Now()
+	if !t.Vigente(ahora) {
+		return nil, nil, ErrTokenInvalido
+	}
+
+	// Los usuarios en la papelera no se encuentran
+	usuario, err := v.usuarios.GetByID(ctx, t.IDUsuario)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrTokenInvalido
+		}
+		return nil, nil, err
+	}
+
+	if t.UltimoUso == nil || ahora.Sub(*t.UltimoUso) > intervaloUso {
+		if err := v.tokens.RegistrarUso(ctx, t.IDToken, ip, ahora); err != nil {
+			log.Printf("Error al registrar el uso del token %d: %v", t.IDToken, err)
+		}
+	}
+	return t, usuario, nil
+}
@@ -0,0 +1,44 @@
+package totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// CodigosRecuperacion es cuántos códigos de un solo uso se entregan al
+// activar la verificación en dos pasos
+const CodigosRecuperacion = 10
+
+// Sin 0/O ni 1/l/i para que puedan copiarse a mano sin confusiones
+const alfabetoRecuperacion = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// GenerarCodigosRecuperacion crea n códigos con el formato xxxxx-xxxxx
+func GenerarCodigosRecuperacion(n int) ([]string, error) {
+	codigos := make([]string, n)
+	for i := range codigos {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		var sb strings.Builder
+		for j, c := range b {
+			if j == 5 {
+				sb.WriteByte('-')
+			}
+			// 31 símbolos: el sesgo de usar el módulo es despreciable aquí
+			sb.WriteByte(alfabetoRecuperacion[int(c)%len(alfabetoRecuperacion)])
+		}
+		codigos[i] = sb.String()
+	}
+	return codigos, nil
+}
+
+// HashRecuperacion es lo que se guarda de cada código. Acepta el código con
+// o sin guion y en mayúsculas.
+func HashRecuperacion(codigo string) string {
+	codigo = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(codigo))
+	suma := sha256.Sum256([]byte(codigo))
+	return hex.EncodeToString(suma[:])
+}
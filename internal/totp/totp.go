@@ -0,0 +1,119 @@
+// Package totp implementa los códigos de un solo uso basados en tiempo
+// (RFC 6238) que generan las aplicaciones autenticadoras, con HMAC-SHA1,
+// pasos de 30 segundos y 6 dígitos.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Periodo es la duración de cada paso
+	Periodo = 30 * time.Second
+	// Digitos es la longitud de los códigos
+	Digitos = 6
+	// Tolerancia es cuántos pasos antes o después del actual se aceptan para
+	// compensar la diferencia de reloj con el teléfono
+	Tolerancia = 1
+)
+
+var codificacion = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerarSecreto crea un secreto aleatorio de 160 bits codificado en base32,
+// el formato que aceptan las aplicaciones autenticadoras
+func GenerarSecreto() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return codificacion.EncodeToString(b), nil
+}
+
+// decodificar acepta el secreto con o sin relleno, espacios o minúsculas
+func decodificar(secreto string) ([]byte, error) {
+	secreto = strings.ToUpper(strings.ReplaceAll(secreto, " ", ""))
+	secreto = strings.TrimRight(secreto, "=")
+	clave, err := codificacion.DecodeString(secreto)
+	if err != nil {
+		return nil, fmt.Errorf("secreto TOTP inválido: %w", err)
+	}
+	return clave, nil
+}
+
+// HOTP calcula el código de RFC 4226 para el contador indicado. Con el paso
+// de tiempo como contador da el código TOTP, lo que permite comprobar los
+// vectores de prueba de RFC 6238 (SHA-1, 8 dígitos).
+func HOTP(clave []byte, contador uint64, digitos int) string {
+	var mensaje [8]byte
+	binary.BigEndian.PutUint64(mensaje[:], contador)
+
+	mac := hmac.New(sha1.New, clave)
+	mac.Write(mensaje[:])
+	suma := mac.Sum(nil)
+
+	// Truncamiento dinámico
+	desplazamiento := suma[len(suma)-1] & 0x0f
+	valor := binary.BigEndian.Uint32(suma[desplazamiento:desplazamiento+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < digitos; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", digitos, valor%modulo)
+}
+
+// Paso devuelve el número de paso de 30 segundos que corresponde a t
+func Paso(t time.Time) int64 {
+	return t.Unix() / int64(Periodo/time.Second)
+}
+
+// Codigo calcula el código vigente en t para el secreto en base32
+func Codigo(secreto string, t time.Time) (string, error) {
+	clave, err := decodificar(secreto)
+	if err != nil {
+		return "", err
+	}
+	return HOTP(clave, uint64(Paso(t)), Digitos), nil
+}
+
+// Verificar comprueba el código contra los pasos cercanos a t y devuelve el
+// paso con el que coincidió. Quien llama debe rechazar pasos ya usados para
+// que un código no sirva dos veces.
+func Verificar(secreto, codigo string, t time.Time) (paso int64, ok bool) {
+	clave, err := decodificar(secreto)
+	if err != nil || len(codigo) != Digitos {
+		return 0, false
+	}
+
+	actual := Paso(t)
+	for p := actual - Tolerancia; p <= actual+Tolerancia; p++ {
+		esperado := HOTP(clave, uint64(p), Digitos)
+		if subtle.ConstantTimeCompare([]byte(esperado), []byte(codigo)) == 1 {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// URI arma el enlace otpauth:// que se muestra como código QR al activar la
+// verificación en dos pasos
+func URI(emisor, cuenta, secreto string) string {
+	etiqueta := url.PathEscape(emisor + ":" + cuenta)
+	parametros := url.Values{
+		"secret":    {secreto},
+		"issuer":    {emisor},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(Digitos)},
+		"period":    {fmt.Sprint(int(Periodo / time.Second))},
+	}
+	return "otpauth://totp/" + etiqueta + "?" + parametros.Encode()
+}
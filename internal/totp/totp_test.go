@@ -0,0 +1,168 @@
+package totp_test
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/totp"
+)
+
+// Secreto de los vectores de prueba de RFC 6238 (apéndice B) para SHA-1
+var claveRFC = []byte("12345678901234567890")
+
+func TestVectoresRFC6238(t *testing.T) {
+	vectores := []struct {
+		segundos int64
+		codigo   string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, v := range vectores {
+		paso := totp.Paso(time.Unix(v.segundos, 0))
+		if codigo := totp.HOTP(claveRFC, uint64(paso), 8); codigo != v.codigo {
+			t.Errorf("T=%d: código %s, se esperaba %s", v.segundos, codigo, v.codigo)
+		}
+	}
+}
+
+func TestCodigoUsaSeisDigitos(t *testing.T) {
+	secreto := base32.StdEncoding.EncodeToString(claveRFC)
+	codigo, err := totp.Codigo(secreto, time.Unix(59, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Los 6 dígitos son el final del código de 8 del vector
+	if codigo != "287082" {
+		t.Errorf("código %s, se esperaba 287082", codigo)
+	}
+}
+
+func TestVerificarVentana(t *testing.T) {
+	secreto, err := totp.GenerarSecreto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ahora := time.Unix(1700000000, 0)
+
+	casos := []struct {
+		nombre      string
+		desfase     time.Duration
+		aceptado    bool
+		desfasePaso int64
+	}{
+		{"paso actual", 0, true, 0},
+		{"paso anterior", -totp.Periodo, true, -1},
+		{"paso siguiente", totp.Periodo, true, 1},
+		{"dos pasos antes", -2 * totp.Periodo, false, 0},
+		{"dos pasos después", 2 * totp.Periodo, false, 0},
+	}
+	for _, caso := range casos {
+		codigo, err := totp.Codigo(secreto, ahora.Add(caso.desfase))
+		if err != nil {
+			t.Fatal(err)
+		}
+		paso, ok := totp.Verificar(secreto, codigo, ahora)
+		if ok != caso.aceptado {
+			t.Errorf("%s: aceptado = %v, se esperaba %v", caso.nombre, ok, caso.aceptado)
+			continue
+		}
+		if ok && paso != totp.Paso(ahora)+caso.desfasePaso {
+			t.Errorf("%s: paso %d, se esperaba %d", caso.nombre, paso, totp.Paso(ahora)+caso.desfasePaso)
+		}
+	}
+
+	if _, ok := totp.Verificar(secreto, "12345", ahora); ok {
+		t.Error("un código de 5 dígitos no debe aceptarse")
+	}
+	if _, ok := totp.Verificar("no es base32!", "123456", ahora); ok {
+		t.Error("un secreto inválido no debe aceptar códigos")
+	}
+}
+
+func TestCodigoNoSeReutiliza(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewDosFactoresMemory()
+	secreto, _ := totp.GenerarSecreto()
+	ahora := time.Unix(1700000000, 0)
+
+	if err := repo.GuardarPendiente(ctx, 1, secreto); err != nil {
+		t.Fatal(err)
+	}
+	codigo, _ := totp.Codigo(secreto, ahora)
+	paso, ok := totp.Verificar(secreto, codigo, ahora)
+	if !ok {
+		t.Fatal("el código vigente debe aceptarse")
+	}
+	if err := repo.Activar(ctx, 1, paso, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// El mismo código sigue siendo válido en la ventana, pero su paso ya se usó
+	paso, ok = totp.Verificar(secreto, codigo, ahora.Add(totp.Periodo))
+	if !ok {
+		t.Fatal("el código debe seguir dentro de la ventana")
+	}
+	if nuevo, err := repo.RegistrarPaso(ctx, 1, paso); err != nil || nuevo {
+		t.Errorf("RegistrarPaso del paso usado = %v, %v; se esperaba rechazo", nuevo, err)
+	}
+
+	// Un código de un paso anterior tampoco sirve después de uno posterior
+	siguiente, _ := totp.Codigo(secreto, ahora.Add(totp.Periodo))
+	paso, _ = totp.Verificar(secreto, siguiente, ahora.Add(totp.Periodo))
+	if nuevo, err := repo.RegistrarPaso(ctx, 1, paso); err != nil || !nuevo {
+		t.Fatalf("RegistrarPaso del paso siguiente = %v, %v; se esperaba aceptarlo", nuevo, err)
+	}
+	if nuevo, _ := repo.RegistrarPaso(ctx, 1, paso-1); nuevo {
+		t.Error("un paso anterior al último usado no debe aceptarse")
+	}
+}
+
+func TestCodigosRecuperacionDeUnSoloUso(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewDosFactoresMemory()
+
+	codigos, err := totp.GenerarCodigosRecuperacion(totp.CodigosRecuperacion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vistos := map[string]bool{}
+	hashes := make([]string, len(codigos))
+	for i, c := range codigos {
+		if len(c) != 11 || c[5] != '-' || vistos[c] {
+			t.Fatalf("código de recuperación inesperado %q", c)
+		}
+		vistos[c] = true
+		hashes[i] = totp.HashRecuperacion(c)
+	}
+
+	if err := repo.GuardarPendiente(ctx, 1, "JBSWY3DPEHPK3PXP"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Activar(ctx, 1, 0, hashes); err != nil {
+		t.Fatal(err)
+	}
+
+	// Se acepta en mayúsculas y sin guion, pero solo una vez
+	escrito := strings.ToUpper(codigos[0][:5] + codigos[0][6:])
+	if err := repo.UsarCodigo(ctx, 1, totp.HashRecuperacion(escrito)); err != nil {
+		t.Fatalf("primer uso: %v", err)
+	}
+	if err := repo.UsarCodigo(ctx, 1, totp.HashRecuperacion(codigos[0])); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("segundo uso = %v, se esperaba ErrNotFound", err)
+	}
+	if err := repo.UsarCodigo(ctx, 1, totp.HashRecuperacion("zzzzz-zzzzz")); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("código inexistente = %v, se esperaba ErrNotFound", err)
+	}
+	if n, _ := repo.CodigosRestantes(ctx, 1); n != totp.CodigosRecuperacion-1 {
+		t.Errorf("quedan %d códigos, se esperaban %d", n, totp.CodigosRecuperacion-1)
+	}
+}
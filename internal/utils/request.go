@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// encabezadoIP es el encabezado con el que el proxy de confianza informa la
+// IP del cliente; vacío si la aplicación recibe las conexiones directamente
+var encabezadoIP string
+
+// ConfigurarProxy define el encabezado del proxy de confianza que ClientIP y
+// EsHTTPS toman en cuenta ("" para usar solo la conexión)
+func ConfigurarProxy(encabezado string) {
+	encabezadoIP = http.CanonicalHeaderKey(strings.TrimSpace(encabezado))
+}
+
+// ProxyDesdeEntorno lee PROXY_ENCABEZADO_IP: Fly-Client-IP en Fly.io,
+// X-Real-IP o X-Forwarded-For según el proxy. Sin ella los encabezados se
+// ignoran, porque cualquier cliente puede enviarlos.
+func ProxyDesdeEntorno() string {
+	encabezado := http.CanonicalHeaderKey(strings.TrimSpace(os.Getenv("PROXY_ENCABEZADO_IP")))
+	switch encabezado {
+	case "", "Fly-Client-Ip", "X-Real-Ip", "X-Forwarded-For":
+	default:
+		log.Printf("⚠️  PROXY_ENCABEZADO_IP desconocido (%q); se usa la IP de la conexión", encabezado)
+		encabezado = ""
+	}
+	return encabezado
+}
+
+// ClientIP obtiene la IP del cliente: la del encabezado del proxy configurado
+// o, sin proxy o con un valor que no es una IP, la de la conexión. Siempre
+// devuelve una IP válida en su forma canónica (cabe en las columnas ip de 45
+// caracteres) o "" si no la hay.
+func ClientIP(r *http.Request) string {
+	if encabezadoIP != "" {
+		valor := r.Header.Get(encabezadoIP)
+		if encabezadoIP == "X-Forwarded-For" {
+			// Cada proxy agrega la IP de quien le habló al final; lo anterior
+			// lo controla el cliente
+			valores := r.Header.Values(encabezadoIP)
+			valor = ""
+			if len(valores) > 0 {
+				partes := strings.Split(valores[len(valores)-1], ",")
+				valor = partes[len(partes)-1]
+			}
+		}
+		if ip := net.ParseIP(strings.TrimSpace(valor)); ip != nil {
+			return ip.String()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// EsHTTPS indica si el cliente usó HTTPS. Como ClientIP, solo confía en el
+// encabezado del proxy (X-Forwarded-Proto) si hay un proxy configurado.
+func EsHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if encabezadoIP == "" {
+		return false
+	}
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
+
+// UserAgent devuelve el User-Agent de la petición recortado a los 255
+// caracteres de las columnas user_agent
+func UserAgent(r *http.Request) string {
+	ua := r.UserAgent()
+	if runas := []rune(ua); len(runas) > 255 {
+		return string(runas[:255])
+	}
+	return ua
+}
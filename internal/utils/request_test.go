@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientIPSinProxyIgnoraEncabezados(t *testing.T) {
+	ConfigurarProxy("")
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "198.51.100.7:51234"
+	r.Header.Set("Fly-Client-IP", "203.0.113.1")
+	r.Header.Set("X-Real-IP", "203.0.113.2")
+	r.Header.Set("X-Forwarded-For", "203.0.113.3")
+
+	if ip := ClientIP(r); ip != "198.51.100.7" {
+		t.Errorf("ClientIP = %q, se esperaba la IP de la conexión", ip)
+	}
+}
+
+func TestClientIPConProxy(t *testing.T) {
+	defer ConfigurarProxy("")
+	casos := []struct {
+		encabezado, valor, esperada string
+	}{
+		{"Fly-Client-IP", "203.0.113.1", "203.0.113.1"},
+		{"fly-client-ip", " 2001:DB8::1 ", "2001:db8::1"},
+		{"X-Real-IP", "203.0.113.2", "203.0.113.2"},
+		// El último valor lo agregó el proxy; los anteriores los envía el cliente
+		{"X-Forwarded-For", "10.0.0.1, 203.0.113.3", "203.0.113.3"},
+		// Lo que no es una IP se descarta y se usa la conexión
+		{"X-Real-IP", "no-es-ip", "198.51.100.7"},
+		{"X-Real-IP", strings.Repeat("1", 300), "198.51.100.7"},
+		{"X-Real-IP", "", "198.51.100.7"},
+		{"Fly-Client-IP", "fe80::1%eth0", "198.51.100.7"},
+	}
+	for _, c := range casos {
+		ConfigurarProxy(c.encabezado)
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "198.51.100.7:51234"
+		r.Header.Set(c.encabezado, c.valor)
+		if ip := ClientIP(r); ip != c.esperada {
+			t.Errorf("%s: %q → %q, se esperaba %q", c.encabezado, c.valor, ip, c.esperada)
+		}
+		if len(ClientIP(r)) > 45 {
+			t.Errorf("%s: %q no cabe en la columna ip", c.encabezado, c.valor)
+		}
+	}
+}
+
+func TestClientIPConexionInvalida(t *testing.T) {
+	ConfigurarProxy("")
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "@"
+	if ip := ClientIP(r); ip != "" {
+		t.Errorf("ClientIP = %q, se esperaba vacío", ip)
+	}
+}
+
+func TestEsHTTPSConfiaEnElProxySoloSiEstaConfigurado(t *testing.T) {
+	defer ConfigurarProxy("")
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+
+	ConfigurarProxy("")
+	if EsHTTPS(r) {
+		t.Error("sin proxy no se debe confiar en X-Forwarded-Proto")
+	}
+	ConfigurarProxy("Fly-Client-IP")
+	if !EsHTTPS(r) {
+		t.Error("con proxy se debe confiar en X-Forwarded-Proto")
+	}
+
+	ConfigurarProxy("")
+	r.TLS = &tls.ConnectionState{}
+	if !EsHTTPS(r) {
+		t.Error("una conexión TLS siempre es HTTPS")
+	}
+}
@@ -9,7 +9,32 @@ type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    *Pagination `json:"meta,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	// Errores detalla los campos inválidos (campo -> mensaje) en respuestas 422
+	Errores map[string]string `json:"errores,omitempty"`
+}
+
+// Pagination describe la página devuelta en un listado
+type Pagination struct {
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	TotalPages int `json:"total_pages"`
+}
+
+// NewPagination calcula los metadatos de paginación
+func NewPagination(total, page, perPage int) *Pagination {
+	totalPages := 1
+	if perPage > 0 && total > 0 {
+		totalPages = (total + perPage - 1) / perPage
+	}
+	return &Pagination{
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}
 }
 
 // JSONResponse envía una respuesta JSON
@@ -28,6 +53,16 @@ func SuccessResponse(w http.ResponseWriter, message string, data interface{}) {
 	})
 }
 
+// PaginatedResponse envía una respuesta exitosa con metadatos de paginación
+func PaginatedResponse(w http.ResponseWriter, message string, data interface{}, meta *Pagination) {
+	JSONResponse(w, http.StatusOK, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
 // ErrorResponse envía una respuesta de error
 func ErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	JSONResponse(w, statusCode, Response{
@@ -36,6 +71,15 @@ func ErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	})
 }
 
+// ValidationErrorResponse envía 422 con el error de cada campo inválido
+func ValidationErrorResponse(w http.ResponseWriter, errores map[string]string) {
+	JSONResponse(w, http.StatusUnprocessableEntity, Response{
+		Success: false,
+		Error:   "Revisa los campos marcados",
+		Errores: errores,
+	})
+}
+
 // CreatedResponse envía una respuesta de creación exitosa
 func CreatedResponse(w http.ResponseWriter, message string, data interface{}) {
 	JSONResponse(w, http.StatusCreated, Response{
@@ -15,12 +15,6 @@ func ValidateEmail(email string) bool {
 	return matched
 }
 
-// ValidateMatricula valida formato de matrícula (8 caracteres)
-func ValidateMatricula(matricula string) bool {
-	matricula = strings.TrimSpace(matricula)
-	return len(matricula) == 8
-}
-
 // ValidateTelefono valida formato de teléfono (10-15 dígitos)
 func ValidateTelefono(telefono string) bool {
 	if telefono == "" {
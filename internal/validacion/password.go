@@ -0,0 +1,59 @@
+package validacion
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// PasswordMinimo es la longitud mínima de una contraseña, en caracteres
+	PasswordMinimo = 10
+	// PasswordMaximo es el límite de bcrypt, en bytes
+	PasswordMaximo = 72
+)
+
+// passwordsComunes son contraseñas (en minúsculas) que se rechazan aunque
+// cumplan el resto de la política
+var passwordsComunes = map[string]bool{
+	"1234567890": true, "0123456789": true, "1234567890a": true, "a123456789": true,
+	"qwerty1234": true, "qwertyuiop1": true, "password1": true, "password123": true,
+	"contraseña1": true, "contraseña123": true, "admin12345": true, "administrador1": true,
+	"egresados1": true, "egresados2024": true, "egresados2025": true, "egresados2026": true,
+	"bienvenido1": true, "bienvenido123": true, "ues12345678": true, "uessanjose1": true,
+}
+
+// Password aplica la política de contraseñas: al menos PasswordMinimo
+// caracteres, letras y números, sin el nombre de usuario y fuera de la lista
+// de contraseñas comunes. Devuelve el motivo del rechazo o "" si es válida.
+func Password(password, usuario string) string {
+	if utf8.RuneCountInString(password) < PasswordMinimo {
+		return fmt.Sprintf("La contraseña debe tener al menos %d caracteres", PasswordMinimo)
+	}
+	if len(password) > PasswordMaximo {
+		return fmt.Sprintf("La contraseña no puede exceder %d bytes", PasswordMaximo)
+	}
+
+	var letra, numero bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letra = true
+		case unicode.IsDigit(r):
+			numero = true
+		}
+	}
+	if !letra || !numero {
+		return "La contraseña debe combinar letras y números"
+	}
+
+	minusculas := strings.ToLower(password)
+	if usuario = strings.ToLower(strings.TrimSpace(usuario)); len(usuario) >= 3 && strings.Contains(minusculas, usuario) {
+		return "La contraseña no debe contener el nombre de usuario"
+	}
+	if passwordsComunes[minusculas] {
+		return "La contraseña es demasiado común"
+	}
+	return ""
+}
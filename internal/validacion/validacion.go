@@ -0,0 +1,478 @@
+// Package validacion revisa los datos de egresados y usuarios antes de
+// guardarlos y reporta los errores por campo para que el frontend pueda
+// marcar cada entrada.
+package validacion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"ues-egresados/internal/auth"
+	"ues-egresados/internal/matricula"
+	"ues-egresados/internal/models"
+	"ues-egresados/internal/repository"
+	"ues-egresados/internal/utils"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Generos son los valores permitidos para el género de un egresado
+var Generos = []string{"Masculino", "Femenino", "Otro"}
+
+var (
+	patronCodigoPostal = regexp.MustCompile(`^\d{5}$`)
+	patronUsuario      = regexp.MustCompile(`^[a-zA-Z0-9._\-]{3,50}$`)
+	patronPeriodo      = regexp.MustCompile(`^(\d{4})-(\d{4})$`)
+)
+
+// Errores asocia cada campo con su mensaje de error
+type Errores map[string]string
+
+// Agregar registra el error del campo; se conserva el primero
+func (e Errores) Agregar(campo, mensaje string) {
+	if _, ok := e[campo]; !ok {
+		e[campo] = mensaje
+	}
+}
+
+// Mensajes devuelve los errores como texto, ordenados por campo
+func (e Errores) Mensajes() []string {
+	campos := make([]string, 0, len(e))
+	for campo := range e {
+		campos = append(campos, campo)
+	}
+	sort.Strings(campos)
+
+	mensajes := make([]string, len(campos))
+	for i, campo := range campos {
+		mensajes[i] = e[campo]
+	}
+	return mensajes
+}
+
+// Egresado revisa los campos que no requieren consultar la base de datos:
+// obligatorios, formatos, longitudes y género. Limpia los espacios de los
+// textos y convierte en nil los opcionales vacíos.
+func Egresado(e *models.Egresado) Errores {
+	return egresado(e, true)
+}
+
+// egresado aplica Egresado; sin formatoMatricula solo exige que la matrícula exista
+func egresado(e *models.Egresado, formatoMatricula bool) Errores {
+	errores := Errores{}
+
+	e.Matricula = utils.SanitizeString(e.Matricula)
+	e.NombreCompleto = utils.SanitizeString(e.NombreCompleto)
+	for _, campo := range []**string{
+		&e.Genero, &e.Telefono, &e.Correo, &e.CodigoPostal, &e.Estado,
+		&e.Municipio, &e.Asentamiento, &e.Calle, &e.Numero,
+	} {
+		*campo = limpiar(*campo)
+	}
+
+	if e.Matricula == "" {
+		errores.Agregar("matricula", "La matrícula es obligatoria")
+	} else if _, err := matricula.Parse(e.Matricula); formatoMatricula && err != nil {
+		errores.Agregar("matricula", "La matrícula debe tener 8 dígitos: plantel (2), año de la generación (2) y consecutivo (4)")
+	}
+	if e.NombreCompleto == "" {
+		errores.Agregar("nombre_completo", "El nombre es obligatorio")
+	}
+	if e.Genero != nil && !generoValido(*e.Genero) {
+		errores.Agregar("genero", "Género inválido; usa "+strings.Join(Generos, ", "))
+	}
+	if e.Telefono != nil && !utils.ValidateTelefono(*e.Telefono) {
+		errores.Agregar("telefono", "Teléfono inválido")
+	}
+	if e.Correo != nil && !utils.ValidateEmail(*e.Correo) {
+		errores.Agregar("correo", "Correo inválido")
+	}
+	if e.CodigoPostal != nil && !patronCodigoPostal.MatchString(*e.CodigoPostal) {
+		errores.Agregar("codigo_postal", "El código postal debe tener 5 dígitos")
+	}
+
+	// Longitudes de las columnas en la base de datos
+	longitudes := []struct {
+		campo string
+		valor *string
+		max   int
+	}{
+		{"nombre_completo", &e.NombreCompleto, 200},
+		{"correo", e.Correo, 150},
+		{"estado", e.Estado, 100},
+		{"municipio", e.Municipio, 150},
+		{"asentamiento", e.Asentamiento, 200},
+		{"calle", e.Calle, 200},
+		{"numero", e.Numero, 20},
+	}
+	for _, l := range longitudes {
+		if l.valor != nil && utf8.RuneCountInString(*l.valor) > l.max {
+			errores.Agregar(l.campo, fmt.Sprintf("Máximo %d caracteres", l.max))
+		}
+	}
+
+	return errores
+}
+
+// Validador agrega a las reglas de formato las que consultan catálogos,
+// códigos postales y usuarios existentes
+type Validador struct {
+	catalogos       repository.CatalogoRepository
+	codigosPostales repository.CodigoPostalRepository
+	usuarios        repository.UsuarioRepository
+}
+
+func NewValidador(repos *repository.Repositories) *Validador {
+	return &Validador{
+		catalogos:       repos.Catalogos,
+		codigosPostales: repos.CodigosPostales,
+		usuarios:        repos.Usuarios,
+	}
+}
+
+// Egresado revisa el egresado completo. Si el código postal es válido y
+// faltan estado o municipio, se completan a partir del catálogo. El error
+// solo se devuelve si falla una consulta.
+func (v *Validador) Egresado(ctx context.Context, e *models.Egresado) (Errores, error) {
+	return v.egresado(ctx, e, nil)
+}
+
+// EgresadoEditado revisa los cambios de PUT o PATCH sobre antes. Las
+// matrículas anteriores al formato de 8 dígitos, que 0009 conservó, no
+// pasarían el formato ni la comparación con la generación, así que esas
+// reglas solo se aplican si cambia la matrícula o la generación; mientras
+// tanto el egresado conserva su plantel.
+func (v *Validador) EgresadoEditado(ctx context.Context, e, antes *models.Egresado) (Errores, error) {
+	return v.egresado(ctx, e, antes)
+}
+
+func (v *Validador) egresado(ctx context.Context, e, antes *models.Egresado) (Errores, error) {
+	revisarMatricula := antes == nil ||
+		utils.SanitizeString(e.Matricula) != antes.Matricula ||
+		e.IDGeneracion != antes.IDGeneracion
+	errores := egresado(e, revisarMatricula)
+
+	if err := v.referencias(ctx, e, errores, revisarMatricula); err != nil {
+		return nil, err
+	}
+	if revisarMatricula {
+		if err := v.plantel(ctx, e, errores); err != nil {
+			return nil, err
+		}
+	} else {
+		e.IDPlantel = antes.IDPlantel
+	}
+	if _, invalido := errores["codigo_postal"]; e.CodigoPostal != nil && !invalido {
+		if err := v.codigoPostal(ctx, e, errores); err != nil {
+			return nil, err
+		}
+	}
+	return errores, nil
+}
+
+// referencias verifica que carrera, generación y estatus existan y, con
+// revisarMatricula, que la matrícula corresponda a la generación
+func (v *Validador) referencias(ctx context.Context, e *models.Egresado, errores Errores, revisarMatricula bool) error {
+	carreras, err := v.catalogos.ListCarreras(ctx)
+	if err != nil {
+		return err
+	}
+	generaciones, err := v.catalogos.ListGeneraciones(ctx)
+	if err != nil {
+		return err
+	}
+	estatus, err := v.catalogos.ListEstatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := map[string]map[int]bool{"id_carrera": {}, "id_generacion": {}, "id_estatus": {}}
+	for _, c := range carreras {
+		ids["id_carrera"][c.IDCarrera] = true
+	}
+	for _, g := range generaciones {
+		ids["id_generacion"][g.IDGeneracion] = true
+		if revisarMatricula && g.IDGeneracion == e.IDGeneracion {
+			MatriculaDeGeneracion(e, g.Periodo, errores)
+		}
+	}
+	for _, s := range estatus {
+		ids["id_estatus"][s.IDEstatus] = true
+	}
+
+	if !ids["id_carrera"][e.IDCarrera] {
+		errores.Agregar("id_carrera", "Selecciona una carrera válida")
+	}
+	if !ids["id_generacion"][e.IDGeneracion] {
+		errores.Agregar("id_generacion", "Selecciona una generación válida")
+	}
+	if !ids["id_estatus"][e.IDEstatus] {
+		errores.Agregar("id_estatus", "Selecciona un estatus válido")
+	}
+	return nil
+}
+
+// plantel asigna al egresado el plantel que indica la clave de su matrícula
+func (v *Validador) plantel(ctx context.Context, e *models.Egresado, errores Errores) error {
+	m, err := matricula.Parse(e.Matricula)
+	if err != nil {
+		return nil
+	}
+	p, err := v.catalogos.GetPlantelPorClave(ctx, m.Plantel)
+	if errors.Is(err, repository.ErrNotFound) {
+		errores.Agregar("matricula", fmt.Sprintf("El plantel %s de la matrícula no está registrado", m.Plantel))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	e.IDPlantel = p.IDPlantel
+	return nil
+}
+
+// codigoPostal verifica que el CP exista y corresponda al estado y municipio
+func (v *Validador) codigoPostal(ctx context.Context, e *models.Egresado, errores Errores) error {
+	info, err := v.codigosPostales.BuscarPorCodigo(ctx, *e.CodigoPostal)
+	if errors.Is(err, repository.ErrNotFound) {
+		errores.Agregar("codigo_postal", "El código postal no existe en el catálogo")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	CompararUbicacion(e, info, errores)
+	return nil
+}
+
+// CompararUbicacion marca el estado y municipio que no corresponden al
+// código postal; los vacíos o escritos de otra forma toman el valor del catálogo
+func CompararUbicacion(e *models.Egresado, info *models.ResultadoCodigoPostal, errores Errores) {
+	if e.Estado != nil && Normalizar(*e.Estado) != Normalizar(info.Estado) {
+		errores.Agregar("estado", fmt.Sprintf("El estado no corresponde al código postal %s (%s)", info.CodigoPostal, info.Estado))
+	} else {
+		e.Estado = &info.Estado
+	}
+	if e.Municipio != nil && Normalizar(*e.Municipio) != Normalizar(info.Municipio) {
+		errores.Agregar("municipio", fmt.Sprintf("El municipio no corresponde al código postal %s (%s)", info.CodigoPostal, info.Municipio))
+	} else {
+		e.Municipio = &info.Municipio
+	}
+}
+
+// MatriculaDeGeneracion marca la matrícula cuyo año no corresponde al año
+// inicial de la generación. Si la matrícula o el periodo no tienen el
+// formato esperado no se compara; el formato se reporta por separado.
+func MatriculaDeGeneracion(e *models.Egresado, periodo string, errores Errores) {
+	m, err := matricula.Parse(e.Matricula)
+	if err != nil {
+		return
+	}
+	anio, err := matricula.AnioInicial(periodo)
+	if err != nil {
+		return
+	}
+	if !m.CorrespondeA(anio) {
+		errores.Agregar("matricula", fmt.Sprintf("El año de la matrícula (%02d) no corresponde a la generación %s; debería ser %02d", m.Anio, periodo, anio%100))
+	}
+}
+
+// Usuario revisa los datos de un usuario del sistema. La contraseña solo es
+// obligatoria al crear; excluirID es el usuario que se edita (0 al crear).
+func (v *Validador) Usuario(ctx context.Context, u *models.Usuario, password string, excluirID int) (Errores, error) {
+	errores := Errores{}
+
+	u.Usuario = utils.SanitizeString(u.Usuario)
+	u.Nombre = utils.SanitizeString(u.Nombre)
+	u.ApellidoPaterno = utils.SanitizeString(u.ApellidoPaterno)
+	u.ApellidoMaterno = utils.SanitizeString(u.ApellidoMaterno)
+	if u.Correo != nil {
+		correo := strings.ToLower(utils.SanitizeString(*u.Correo))
+		u.Correo = &correo
+		if correo == "" {
+			u.Correo = nil
+		}
+	}
+
+	if u.Usuario == "" {
+		errores.Agregar("usuario", "El usuario es obligatorio")
+	} else if !patronUsuario.MatchString(u.Usuario) {
+		errores.Agregar("usuario", "De 3 a 50 caracteres: letras, números, punto, guion o guion bajo")
+	}
+	if u.Nombre == "" {
+		errores.Agregar("nombre", "El nombre es obligatorio")
+	}
+	if u.ApellidoPaterno == "" {
+		errores.Agregar("apellido_paterno", "El apellido paterno es obligatorio")
+	}
+	for campo, valor := range map[string]string{
+		"nombre":           u.Nombre,
+		"apellido_paterno": u.ApellidoPaterno,
+		"apellido_materno": u.ApellidoMaterno,
+	} {
+		if utf8.RuneCountInString(valor) > 100 {
+			errores.Agregar(campo, "Máximo 100 caracteres")
+		}
+	}
+	if u.Correo != nil && (!utils.ValidateEmail(*u.Correo) || len(*u.Correo) > 150) {
+		errores.Agregar("correo", "Correo inválido")
+	}
+	if excluirID == 0 && password == "" {
+		errores.Agregar("password", "La contraseña es obligatoria")
+	} else if password != "" {
+		if motivo := Password(password, u.Usuario); motivo != "" {
+			errores.Agregar("password", motivo)
+		}
+	}
+	// El origen solo se elige al crear; Update no lo cambia
+	if excluirID == 0 {
+		switch u.Origen {
+		case "":
+			u.Origen = models.OrigenLocal
+		case models.OrigenLocal, models.OrigenLDAP, models.OrigenOIDC:
+		default:
+			errores.Agregar("origen", "El origen debe ser local, ldap u oidc")
+		}
+	}
+	if u.Rol == "" {
+		errores.Agregar("rol", "El rol es obligatorio")
+	} else if !auth.RolValido(u.Rol) {
+		errores.Agregar("rol", "Rol inválido")
+	}
+	if err := v.plantelUsuario(ctx, u, errores); err != nil {
+		return nil, err
+	}
+
+	if _, invalido := errores["usuario"]; !invalido {
+		existe, err := v.usuarios.ExisteUsuario(ctx, u.Usuario, excluirID)
+		if err != nil {
+			return nil, err
+		}
+		if existe {
+			errores.Agregar("usuario", "El usuario ya existe")
+		}
+	}
+	if _, invalido := errores["correo"]; !invalido && u.Correo != nil {
+		existe, err := v.usuarios.ExisteCorreo(ctx, *u.Correo, excluirID)
+		if err != nil {
+			return nil, err
+		}
+		if existe {
+			errores.Agregar("correo", "Otro usuario ya tiene este correo")
+		}
+	}
+	return errores, nil
+}
+
+// plantelUsuario exige un plantel existente a los roles que no consultan
+// todos los planteles; para los globales es opcional
+func (v *Validador) plantelUsuario(ctx context.Context, u *models.Usuario, errores Errores) error {
+	if u.IDPlantel != nil && *u.IDPlantel == 0 {
+		u.IDPlantel = nil
+	}
+	if u.IDPlantel == nil {
+		if u.Rol != "" && !auth.HasPermission(u.Rol, auth.PermPlantelesTodos) {
+			errores.Agregar("id_plantel", "Selecciona el plantel del usuario")
+		}
+		return nil
+	}
+
+	_, err := v.catalogos.GetPlantel(ctx, *u.IDPlantel)
+	if errors.Is(err, repository.ErrNotFound) {
+		errores.Agregar("id_plantel", "Selecciona un plantel válido")
+		return nil
+	}
+	return err
+}
+
+// Plantel revisa la clave de 2 dígitos y el nombre de un plantel
+func Plantel(p *models.Plantel) Errores {
+	p.Clave = utils.SanitizeString(p.Clave)
+	p.Nombre = utils.SanitizeString(p.Nombre)
+
+	errores := Errores{}
+	if p.Clave == "" {
+		errores.Agregar("clave", "La clave es obligatoria")
+	} else if !matricula.ClaveValida(p.Clave) {
+		errores.Agregar("clave", "La clave debe tener 2 dígitos, como en la matrícula")
+	}
+	for campo, mensaje := range textoCatalogo("nombre", p.Nombre, 150) {
+		errores.Agregar(campo, mensaje)
+	}
+	return errores
+}
+
+// Carrera revisa el nombre de una carrera del catálogo
+func Carrera(c *models.Carrera) Errores {
+	c.Nombre = utils.SanitizeString(c.Nombre)
+	return textoCatalogo("nombre", c.Nombre, 150)
+}
+
+// Estatus revisa la descripción de un estatus del catálogo
+func Estatus(e *models.Estatus) Errores {
+	e.Descripcion = utils.SanitizeString(e.Descripcion)
+	return textoCatalogo("descripcion", e.Descripcion, 50)
+}
+
+// Generacion exige un periodo AAAA-AAAA cuyo año final sea posterior al inicial
+func Generacion(g *models.Generacion) Errores {
+	errores := Errores{}
+	g.Periodo = utils.SanitizeString(g.Periodo)
+
+	anios := patronPeriodo.FindStringSubmatch(g.Periodo)
+	switch {
+	case g.Periodo == "":
+		errores.Agregar("periodo", "El periodo es obligatorio")
+	case anios == nil:
+		errores.Agregar("periodo", "El periodo debe tener el formato AAAA-AAAA (por ejemplo 2024-2028)")
+	case anios[2] <= anios[1]:
+		errores.Agregar("periodo", "El año final debe ser posterior al inicial")
+	}
+	return errores
+}
+
+func textoCatalogo(campo, valor string, max int) Errores {
+	errores := Errores{}
+	if valor == "" {
+		errores.Agregar(campo, "Este campo es obligatorio")
+	} else if utf8.RuneCountInString(valor) > max {
+		errores.Agregar(campo, fmt.Sprintf("Máximo %d caracteres", max))
+	}
+	return errores
+}
+
+func generoValido(genero string) bool {
+	for _, g := range Generos {
+		if g == genero {
+			return true
+		}
+	}
+	return false
+}
+
+func limpiar(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+// Normalizar compara textos sin importar mayúsculas, acentos ni espacios extra
+func Normalizar(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	sinAcentos, _, err := transform.String(t, s)
+	if err != nil {
+		sinAcentos = s
+	}
+	return strings.Join(strings.Fields(strings.ToLower(sinAcentos)), " ")
+}
@@ -11,6 +11,7 @@ let adminEnEdicion = null;
 
 document.addEventListener('DOMContentLoaded', function() {
     cargarAdministradores();
+    cargarPlanteles();
     setupFormSubmit();
 });
 
@@ -34,11 +35,28 @@ async function cargarAdministradores() {
         console.error('Error al cargar administradores:', error);
         mostrarNotificacion('Error al cargar administradores', 'error');
         document.getElementById('administradoresTable').innerHTML = `
-            <tr><td colspan="5" class="text-center py-8 text-gray-500">Error al cargar datos</td></tr>
+            <tr><td colspan="6" class="text-center py-8 text-gray-500">Error al cargar datos</td></tr>
         `;
     }
 }
 
+// =====================================================
+// CARGAR PLANTELES
+// =====================================================
+
+async function cargarPlanteles() {
+    try {
+        const data = await fetch('/api/planteles', { credentials: 'include' }).then(res => res.json());
+        const select = document.getElementById('id_plantel');
+        (data.data || []).forEach(p => {
+            select.innerHTML += `<option value="${p.id_plantel}">${escaparHTML(p.clave)} - ${escaparHTML(p.nombre)}</option>`;
+        });
+    } catch (error) {
+        console.error('Error al cargar planteles:', error);
+        mostrarNotificacion('Error al cargar planteles', 'error');
+    }
+}
+
 // =====================================================
 // RENDERIZAR TABLA DE ADMINISTRADORES
 // =====================================================
@@ -49,7 +67,7 @@ function renderAdministradores() {
     if (administradoresData.length === 0) {
         tbody.innerHTML = `
             <tr>
-                <td colspan="5" class="text-center py-8 text-gray-500 dark:text-gray-400">
+                <td colspan="6" class="text-center py-8 text-gray-500 dark:text-gray-400">
                     <div class="empty-state">
                         <h3 class="text-lg font-semibold text-gray-600 dark:text-gray-400">No hay administradores</h3>
                         <p class="text-sm text-gray-500 dark:text-gray-500">Haz clic en "Nuevo Administrador" para crear uno</p>
@@ -66,26 +84,29 @@ function renderAdministradores() {
         return `
         <tr class="hover:bg-gray-50 dark:hover:bg-white/5 transition-colors">
             <td class="px-6 py-4 text-sm font-medium text-text-main dark:text-white">
-                ${admin.usuario}
+                ${escaparHTML(admin.usuario)}
             </td>
             <td class="px-6 py-4 text-sm text-text-main dark:text-gray-300">
-                ${admin.nombre} ${admin.apellido_paterno} ${admin.apellido_materno || ''}
+                ${escaparHTML(admin.nombre)} ${escaparHTML(admin.apellido_paterno)} ${escaparHTML(admin.apellido_materno)}
             </td>
             <td class="px-6 py-4 text-sm">
                 <span class="inline-flex items-center px-2.5 py-0.5 rounded-full text-xs font-medium ${getRolColor(admin.rol)}">
-                    ${admin.rol}
+                    ${escaparHTML(admin.rol)}
                 </span>
             </td>
+            <td class="px-6 py-4 text-sm text-text-main dark:text-gray-300">
+                ${escaparHTML(admin.nombre_plantel || 'Todos')}
+            </td>
             <td class="px-6 py-4 text-sm text-text-secondary dark:text-gray-400">
                 ${fechaFormateada}
             </td>
             <td class="px-6 py-4 text-sm text-right space-x-2">
-                <button onclick="editarAdministrador(${admin.id_usuario})" 
+                <button data-accion="editarAdministrador" data-args="${argumentosAccion(admin.id_usuario)}" 
                         class="text-blue-600 hover:text-blue-900 dark:hover:text-blue-400 transition-colors"
                         title="Editar">
                     <span class="material-symbols-outlined">edit</span>
                 </button>
-                <button onclick="eliminarAdministrador(${admin.id_usuario}, '${admin.usuario}')" 
+                <button data-accion="eliminarAdministrador" data-args="${argumentosAccion(admin.id_usuario, admin.usuario)}" 
                         class="text-red-600 hover:text-red-900 dark:hover:text-red-400 transition-colors"
                         title="Eliminar">
                     <span class="material-symbols-outlined">delete</span>
@@ -113,6 +134,7 @@ function abrirModalAdministrador() {
 function cerrarModalAdministrador() {
     document.getElementById('administradorModal').classList.add('hidden');
     document.getElementById('administradorForm').reset();
+    limpiarErroresCampos(document.getElementById('administradorForm'));
     adminEnEdicion = null;
 }
 
@@ -130,7 +152,9 @@ function editarAdministrador(idUsuario) {
     document.getElementById('nombre').value = admin.nombre;
     document.getElementById('apellido_paterno').value = admin.apellido_paterno;
     document.getElementById('apellido_materno').value = admin.apellido_materno;
+    document.getElementById('correo').value = admin.correo || '';
     document.getElementById('rol').value = admin.rol;
+    document.getElementById('id_plantel').value = admin.id_plantel || '';
     document.getElementById('password').value = '';
     document.getElementById('password').required = false;
     document.getElementById('passwordRequired').textContent = '';
@@ -152,8 +176,10 @@ function setupFormSubmit() {
         const nombre = document.getElementById('nombre').value.trim();
         const apellido_paterno = document.getElementById('apellido_paterno').value.trim();
         const apellido_materno = document.getElementById('apellido_materno').value.trim();
+        const correo = document.getElementById('correo').value.trim();
         const password = document.getElementById('password').value;
         const rol = document.getElementById('rol').value;
+        const plantel = document.getElementById('id_plantel').value;
 
         if (!usuario || !nombre || !apellido_paterno) {
             mostrarNotificacion('Por favor completa los campos requeridos', 'error');
@@ -177,26 +203,43 @@ function setupFormSubmit() {
                 nombre,
                 apellido_paterno,
                 apellido_materno,
-                rol
+                correo: correo || null,
+                rol,
+                id_plantel: plantel ? parseInt(plantel, 10) : null
             };
 
             if (password) {
                 payload.password = password;
             }
 
+            const headers = { 'Content-Type': 'application/json', 'X-CSRF-Token': csrfToken() };
+            if (adminEnEdicion) {
+                // Versión mostrada en el listado; evita sobrescribir cambios de otro administrador
+                headers['If-Match'] = `"${adminEnEdicion.version}"`;
+            }
+
             const response = await fetch(url, {
                 method,
                 credentials: 'include',
-                headers: {
-                    'Content-Type': 'application/json'
-                },
+                headers,
                 body: JSON.stringify(payload)
             });
 
             const data = await response.json();
 
+            if (response.status === 409 || response.status === 412) {
+                mostrarNotificacion(data.error || 'El administrador fue modificado por otro usuario', 'error');
+                cerrarModalAdministrador();
+                cargarAdministradores();
+                return;
+            }
+
+            if (response.status === 422) {
+                marcarErroresCampos(e.target, data.errores);
+            }
+
             if (!response.ok) {
-                mostrarNotificacion(data.message || 'Error al guardar', 'error');
+                mostrarNotificacion(data.error || data.message || 'Error al guardar', 'error');
                 return;
             }
 
@@ -222,7 +265,7 @@ function setupFormSubmit() {
 // =====================================================
 
 async function eliminarAdministrador(idUsuario, usuario) {
-    if (!confirm(`¿Estás seguro de que deseas eliminar al administrador "${usuario}"?`)) {
+    if (!confirm(`¿Estás seguro de que deseas eliminar al administrador "${usuario}"? Se enviará a la papelera.`)) {
         return;
     }
 
@@ -231,7 +274,8 @@ async function eliminarAdministrador(idUsuario, usuario) {
             method: 'DELETE',
             credentials: 'include',
             headers: {
-                'Content-Type': 'application/json'
+                'Content-Type': 'application/json',
+                'X-CSRF-Token': csrfToken()
             }
         });
 
@@ -258,7 +302,9 @@ async function eliminarAdministrador(idUsuario, usuario) {
 function getRolColor(rol) {
     const colors = {
         'Administrador': 'bg-red-100 text-red-800 dark:bg-red-900/30 dark:text-red-400',
-        'Operador': 'bg-blue-100 text-blue-800 dark:bg-blue-900/30 dark:text-blue-400'
+        'Operador': 'bg-blue-100 text-blue-800 dark:bg-blue-900/30 dark:text-blue-400',
+        'Consulta': 'bg-green-100 text-green-800 dark:bg-green-900/30 dark:text-green-400',
+        'Coordinación': 'bg-purple-100 text-purple-800 dark:bg-purple-900/30 dark:text-purple-400'
     };
     return colors[rol] || 'bg-gray-100 text-gray-800 dark:bg-gray-900/30 dark:text-gray-400';
 }
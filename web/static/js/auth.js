@@ -36,15 +36,21 @@ document.getElementById('loginForm')?.addEventListener('submit', async (e) => {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
+                'X-CSRF-Token': document.querySelector('meta[name="csrf-token"]')?.content || '',
             },
             body: JSON.stringify({ usuario, password }),
         });
         
         const data = await response.json();
         
-        if (response.ok && data.success) {
-            // Redirigir al dashboard
-            window.location.href = '/dashboard';
+        if (response.ok && data.success && data.data?.requiere_2fa) {
+            // La contraseña es correcta; falta el código de verificación
+            e.target.classList.add('hidden');
+            document.getElementById('ssoLogin')?.classList.add('hidden');
+            document.getElementById('codigoForm').classList.remove('hidden');
+            document.getElementById('codigo').focus();
+        } else if (response.ok && data.success) {
+            redirigirTrasLogin(data.data);
         } else {
             errorText.textContent = data.error || 'Usuario o contraseña incorrectos';
             errorDiv.classList.remove('hidden');
@@ -60,6 +66,55 @@ document.getElementById('loginForm')?.addEventListener('submit', async (e) => {
     }
 });
 
+// Segundo paso: código de la aplicación autenticadora o de recuperación
+document.getElementById('codigoForm')?.addEventListener('submit', async (e) => {
+    e.preventDefault();
+
+    const codigo = document.getElementById('codigo').value.trim();
+    const errorDiv = document.getElementById('codigoError');
+    const errorText = document.getElementById('codigoErrorText');
+    const submitBtn = e.target.querySelector('button[type="submit"]');
+
+    errorDiv.classList.add('hidden');
+    if (!codigo) {
+        errorText.textContent = 'Ingresa el código de verificación';
+        errorDiv.classList.remove('hidden');
+        return;
+    }
+
+    submitBtn.disabled = true;
+    try {
+        const response = await fetch('/login/2fa', {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                'X-CSRF-Token': document.querySelector('meta[name="csrf-token"]')?.content || '',
+            },
+            body: JSON.stringify({ codigo }),
+        });
+
+        const data = await response.json();
+
+        if (response.ok && data.success) {
+            redirigirTrasLogin(data.data);
+            return;
+        }
+        errorText.textContent = data.error || 'Código de verificación incorrecto';
+        errorDiv.classList.remove('hidden');
+    } catch (error) {
+        console.error('Error en verificación:', error);
+        errorText.textContent = 'Error al conectar con el servidor';
+        errorDiv.classList.remove('hidden');
+    }
+    submitBtn.disabled = false;
+});
+
+// Si el rol exige verificación en dos pasos y aún no está activa, la sesión
+// solo puede usar la página de seguridad
+function redirigirTrasLogin(datos) {
+    window.location.href = datos?.configurar_2fa ? '/seguridad' : '/dashboard';
+}
+
 // Limpiar mensaje de error al escribir
 document.getElementById('usuario')?.addEventListener('input', () => {
     document.getElementById('errorMessage').classList.add('hidden');
@@ -0,0 +1,266 @@
+// =====================================================
+// CONFIGURACIÓN DE CATÁLOGOS
+// =====================================================
+
+const CATALOGOS = {
+    carreras: {
+        url: '/api/carreras',
+        id: 'id_carrera',
+        campo: 'nombre',
+        etiqueta: 'Nombre',
+        singular: 'carrera',
+        nuevo: 'Nueva carrera',
+        placeholder: 'Ingeniería en Software',
+    },
+    generaciones: {
+        url: '/api/generaciones',
+        id: 'id_generacion',
+        campo: 'periodo',
+        etiqueta: 'Periodo (AAAA-AAAA)',
+        singular: 'generación',
+        nuevo: 'Nueva generación',
+        placeholder: '2025-2029',
+    },
+    estatus: {
+        url: '/api/estatus',
+        id: 'id_estatus',
+        campo: 'descripcion',
+        etiqueta: 'Descripción',
+        singular: 'estatus',
+        nuevo: 'Nuevo estatus',
+        placeholder: 'Titulado',
+    },
+    // La clave forma parte de la matrícula, así que los planteles no se
+    // reasignan: solo se eliminan si no tienen egresados ni usuarios
+    planteles: {
+        url: '/api/planteles',
+        id: 'id_plantel',
+        campo: 'nombre',
+        clave: true,
+        sinReasignar: true,
+        etiqueta: 'Nombre',
+        singular: 'plantel',
+        nuevo: 'Nuevo plantel',
+        placeholder: 'Plantel 13',
+    },
+};
+
+// Los errores de la API usan el nombre del campo; el formulario tiene un input
+// genérico para el valor y, en planteles, uno para la clave
+const aliasCamposCatalogo = { nombre: 'valor', periodo: 'valor', descripcion: 'valor' };
+
+let catalogoActual = 'carreras';
+let registros = [];
+let registroEnEdicion = null;
+let registroAEliminar = null;
+
+// =====================================================
+// INICIALIZAR PÁGINA
+// =====================================================
+
+document.addEventListener('DOMContentLoaded', function() {
+    document.getElementById('catalogoForm').addEventListener('submit', guardarRegistro);
+    document.getElementById('eliminarForm').addEventListener('submit', confirmarEliminacion);
+    seleccionarCatalogo('carreras');
+});
+
+function seleccionarCatalogo(nombre) {
+    catalogoActual = nombre;
+    const config = CATALOGOS[nombre];
+
+    document.querySelectorAll('.tab-catalogo').forEach(tab => {
+        const activo = tab.dataset.catalogo === nombre;
+        tab.classList.toggle('border-primary', activo);
+        tab.classList.toggle('text-primary', activo);
+        tab.classList.toggle('border-transparent', !activo);
+        tab.classList.toggle('text-text-secondary', !activo);
+    });
+    document.getElementById('columnaValor').textContent = config.etiqueta;
+    document.getElementById('btnNuevoTexto').textContent = config.nuevo;
+
+    cargarRegistros();
+}
+
+// =====================================================
+// CARGAR Y RENDERIZAR
+// =====================================================
+
+async function cargarRegistros() {
+    const tbody = document.getElementById('catalogoTable');
+    try {
+        const data = await fetchAPI(CATALOGOS[catalogoActual].url);
+        registros = data.data || [];
+        renderRegistros();
+    } catch (error) {
+        showNotification('Error al cargar el catálogo', 'error');
+        tbody.innerHTML = `
+            <tr><td colspan="3" class="text-center py-8 text-gray-500">Error al cargar datos</td></tr>
+        `;
+    }
+}
+
+function renderRegistros() {
+    const config = CATALOGOS[catalogoActual];
+    const tbody = document.getElementById('catalogoTable');
+
+    if (registros.length === 0) {
+        tbody.innerHTML = `
+            <tr>
+                <td colspan="3" class="text-center py-8 text-gray-500 dark:text-gray-400">
+                    No hay registros. Haz clic en "${config.nuevo}" para agregar uno.
+                </td>
+            </tr>
+        `;
+        return;
+    }
+
+    tbody.innerHTML = registros.map(registro => `
+        <tr class="hover:bg-gray-50 dark:hover:bg-white/5 transition-colors">
+            <td class="px-6 py-4 text-sm text-text-secondary dark:text-gray-400">${registro[config.id]}</td>
+            <td class="px-6 py-4 text-sm font-medium text-text-main dark:text-white">${escaparHTML(textoRegistro(registro))}</td>
+            <td class="px-6 py-4 text-sm text-center space-x-2">
+                <button data-accion="editarRegistro" data-args="${argumentosAccion(registro[config.id])}"
+                        class="text-blue-600 hover:text-blue-900 dark:hover:text-blue-400 transition-colors"
+                        title="Editar">
+                    <span class="material-symbols-outlined">edit</span>
+                </button>
+                <button data-accion="abrirModalEliminar" data-args="${argumentosAccion(registro[config.id])}"
+                        class="text-red-600 hover:text-red-900 dark:hover:text-red-400 transition-colors"
+                        title="Eliminar">
+                    <span class="material-symbols-outlined">delete</span>
+                </button>
+            </td>
+        </tr>
+    `).join('');
+}
+
+// =====================================================
+// CREAR / EDITAR
+// =====================================================
+
+function abrirModalCatalogo(registro = null) {
+    const config = CATALOGOS[catalogoActual];
+    const form = document.getElementById('catalogoForm');
+    registroEnEdicion = registro;
+
+    form.reset();
+    limpiarErroresCampos(form);
+    document.getElementById('catalogo-modal-title').textContent = registro ? `Editar ${config.singular}` : config.nuevo;
+    document.getElementById('etiquetaValor').textContent = `${config.etiqueta} *`;
+    document.getElementById('valor').placeholder = config.placeholder;
+    document.getElementById('valor').value = registro ? registro[config.campo] : '';
+    document.getElementById('grupoClave').classList.toggle('hidden', !config.clave);
+    document.getElementById('clave').required = !!config.clave;
+    document.getElementById('clave').value = registro && config.clave ? registro.clave : '';
+    document.getElementById('catalogoModal').classList.remove('hidden');
+    document.getElementById('valor').focus();
+}
+
+function editarRegistro(id) {
+    const config = CATALOGOS[catalogoActual];
+    const registro = registros.find(r => r[config.id] === id);
+    if (registro) {
+        abrirModalCatalogo(registro);
+    }
+}
+
+function cerrarModalCatalogo() {
+    document.getElementById('catalogoModal').classList.add('hidden');
+    registroEnEdicion = null;
+}
+
+async function guardarRegistro(e) {
+    e.preventDefault();
+    const config = CATALOGOS[catalogoActual];
+    const payload = { [config.campo]: document.getElementById('valor').value.trim() };
+    if (config.clave) {
+        payload.clave = document.getElementById('clave').value.trim();
+    }
+
+    try {
+        if (registroEnEdicion) {
+            await fetchAPI(`${config.url}/${registroEnEdicion[config.id]}`, {
+                method: 'PUT',
+                body: JSON.stringify(payload),
+            });
+        } else {
+            await fetchAPI(config.url, {
+                method: 'POST',
+                body: JSON.stringify(payload),
+            });
+        }
+
+        showNotification('Catálogo actualizado correctamente', 'success');
+        cerrarModalCatalogo();
+        cargarRegistros();
+    } catch (error) {
+        if (error.status === 422) {
+            marcarErroresCampos(e.target, error.errores, aliasCamposCatalogo);
+        }
+        showNotification(error.message || 'Error al guardar', 'error');
+    }
+}
+
+// =====================================================
+// ELIMINAR
+// =====================================================
+
+function abrirModalEliminar(id) {
+    const config = CATALOGOS[catalogoActual];
+    registroAEliminar = registros.find(r => r[config.id] === id);
+    if (!registroAEliminar) return;
+
+    const form = document.getElementById('eliminarForm');
+    limpiarErroresCampos(form);
+
+    document.getElementById('eliminar-modal-title').textContent = `Eliminar ${config.singular}`;
+    document.getElementById('eliminarTexto').textContent =
+        `¿Eliminar "${textoRegistro(registroAEliminar)}"? Esta acción no se puede deshacer.`;
+    document.getElementById('grupoReasignar').classList.toggle('hidden', !!config.sinReasignar);
+
+    const opciones = registros
+        .filter(r => r[config.id] !== id)
+        .map(r => `<option value="${r[config.id]}">${escaparHTML(r[config.campo])}</option>`)
+        .join('');
+    document.getElementById('reasignar').innerHTML = `<option value="">No reasignar</option>${opciones}`;
+
+    document.getElementById('eliminarModal').classList.remove('hidden');
+}
+
+function cerrarModalEliminar() {
+    document.getElementById('eliminarModal').classList.add('hidden');
+    registroAEliminar = null;
+}
+
+async function confirmarEliminacion(e) {
+    e.preventDefault();
+    const config = CATALOGOS[catalogoActual];
+    const reasignar = document.getElementById('reasignar').value;
+
+    let url = `${config.url}/${registroAEliminar[config.id]}`;
+    if (reasignar) {
+        url += `?reasignar=${encodeURIComponent(reasignar)}`;
+    }
+
+    try {
+        await fetchAPI(url, { method: 'DELETE' });
+        showNotification('Registro eliminado correctamente', 'success');
+        cerrarModalEliminar();
+        cargarRegistros();
+    } catch (error) {
+        if (error.status === 409 && !config.sinReasignar) {
+            // Tiene egresados asignados: pedir a dónde moverlos
+            marcarErroresCampos(e.target, { reasignar: error.message });
+        } else if (error.status === 422) {
+            marcarErroresCampos(e.target, error.errores);
+        }
+        showNotification(error.message || 'Error al eliminar', 'error');
+    }
+}
+
+// textoRegistro muestra el valor del registro; en planteles antepone la clave
+function textoRegistro(registro) {
+    const config = CATALOGOS[catalogoActual];
+    const valor = registro[config.campo];
+    return config.clave ? `${registro.clave} - ${valor}` : valor;
+}
@@ -91,15 +91,16 @@ function applyThemeDefaults() {
 async function initCharts() {
     try {
         const palette = applyThemeDefaults();
+        // Los conteos se calculan en el servidor; el listado de egresados está paginado
         const [generacionesData, carrerasData, estatusData] = await Promise.all([
             fetchAPI('/api/egresados/stats/generaciones'),
-            fetchAPI('/api/egresados'),
-            fetchAPI('/api/estatus'),
+            fetchAPI('/api/egresados/stats/carreras/all'),
+            fetchAPI('/api/egresados/stats/estatus'),
         ]);
 
         createChartGeneraciones(generacionesData.data, palette);
-        createChartCarreras(generacionesData.data, carrerasData.data || [], palette);
-        createChartEstatus(carrerasData.data || [], palette);
+        createChartCarreras(carrerasData.data?.carreras || [], palette);
+        createChartEstatus(estatusData.data?.estatus || [], palette);
         createChartCrecimiento(generacionesData.data, palette);
     } catch (error) {
         console.error('Error al inicializar gráficos:', error);
@@ -223,19 +224,15 @@ function createChartGeneraciones(data, palette) {
 // GRÁFICO 2: DISTRIBUCIÓN POR CARRERA (Dónut)
 // =====================================================
 
-function createChartCarreras(generacionesData, egresados, palette) {
+function createChartCarreras(carreras, palette) {
     const ctx = document.getElementById('chartCarreras');
     if (!ctx) return;
 
-    // Agrupar egresados por carrera
-    const carrerasMap = {};
-    egresados.forEach(e => {
-        const carrera = e.nombre_carrera || 'Sin Carrera';
-        carrerasMap[carrera] = (carrerasMap[carrera] || 0) + 1;
-    });
+    // Solo las carreras con egresados
+    const conEgresados = carreras.filter(c => c.total_egresados > 0);
 
-    const labels = Object.keys(carrerasMap);
-    const values = Object.values(carrerasMap);
+    const labels = conEgresados.map(c => c.nombre);
+    const values = conEgresados.map(c => c.total_egresados);
 
     // Paleta de colores para carreras
     const colors = [
@@ -313,25 +310,12 @@ function createChartCarreras(generacionesData, egresados, palette) {
 // GRÁFICO 3: DISTRIBUCIÓN POR ESTATUS (Gráfico Circular)
 // =====================================================
 
-function createChartEstatus(egresados, palette) {
+function createChartEstatus(estatus, palette) {
     const ctx = document.getElementById('chartEstatus');
     if (!ctx) return;
 
-    // Contar por estatus
-    const estatus = {
-        'Activo': 0,
-        'Inactivo': 0,
-        'Nuevo': 0,
-    };
-
-    egresados.forEach(e => {
-        if (e.id_estatus === 1) estatus['Activo']++;
-        else if (e.id_estatus === 2) estatus['Inactivo']++;
-        else if (e.id_estatus === 3) estatus['Nuevo']++;
-    });
-
-    const labels = Object.keys(estatus);
-    const values = Object.values(estatus);
+    const labels = estatus.map(s => s.descripcion);
+    const values = estatus.map(s => s.total_egresados);
 
     const config = getResponsiveConfig();
     
@@ -341,11 +325,15 @@ function createChartEstatus(egresados, palette) {
             labels: labels,
             datasets: [{
                 data: values,
+                // En el orden de id_estatus: Activo verde, Inactivo amarillo, Nuevo azul
                 backgroundColor: [
-                    CHART_COLORS.success,    // Activo - Verde
-                    CHART_COLORS.warning,    // Inactivo - Amarillo
-                    CHART_COLORS.info,       // Nuevo - Azul
-                ],
+                    CHART_COLORS.success,
+                    CHART_COLORS.warning,
+                    CHART_COLORS.info,
+                    CHART_COLORS.primary,
+                    CHART_COLORS.secondary,
+                    CHART_COLORS.danger,
+                ].slice(0, labels.length),
                 borderColor: palette.border,
                 borderWidth: 2,
                 hoverBorderColor: palette.text,
@@ -3,8 +3,10 @@
 // =====================================================
 
 let egresadosData = [];
+let paginacion = { page: 1, per_page: 25, total: 0, total_pages: 1 };
 let isEditMode = false;
 let currentMatricula = null;
+let currentVersion = null; // Versión del egresado en edición, se envía en If-Match
 let searchMode = 'cp'; // 'cp' o 'location'
 
 // Estado de filtros seleccionados
@@ -19,6 +21,7 @@ let filtrosSeleccionados = {
 document.addEventListener('DOMContentLoaded', () => {
     loadGeneracionesStats();
     loadEstatus();
+    loadPlanteles();
     setupSearchModeToggle();
     setupCodigoPostalSearch();
     setupLocationSearch();
@@ -30,7 +33,7 @@ document.addEventListener('DOMContentLoaded', () => {
 
 async function loadGeneracionesStats() {
     try {
-        const data = await fetchAPI('/api/egresados/stats/generaciones');
+        const data = await fetchAPI(conPlantel('/api/egresados/stats/generaciones'));
         renderGeneraciones(data.data);
     } catch (error) {
         showNotification('Error al cargar generaciones', 'error');
@@ -59,11 +62,11 @@ function renderGeneraciones(statsData) {
     let html = '';
     generaciones.forEach(gen => {
         html += `
-            <button onclick="seleccionarGeneracion(${gen.id_generacion}, '${gen.periodo}')" class="w-full px-6 py-4 hover:bg-gray-50 dark:hover:bg-white/5 transition-colors text-left flex items-center justify-between group">
+            <button data-accion="seleccionarGeneracion" data-args="${argumentosAccion(gen.id_generacion, gen.periodo)}" class="w-full px-6 py-4 hover:bg-gray-50 dark:hover:bg-white/5 transition-colors text-left flex items-center justify-between group">
                 <div class="flex items-center gap-3 flex-1">
                     <span class="material-symbols-outlined text-primary text-2xl">calendar_month</span>
                     <div>
-                        <p class="font-semibold text-text-main dark:text-white">${gen.periodo}</p>
+                        <p class="font-semibold text-text-main dark:text-white">${escaparHTML(gen.periodo)}</p>
                         <p class="text-xs text-text-secondary dark:text-gray-400">${gen.total_egresados} ${gen.total_egresados === 1 ? 'egresado' : 'egresados'}</p>
                     </div>
                 </div>
@@ -95,7 +98,7 @@ function seleccionarGeneracion(idGeneracion, textoGeneracion) {
 
 async function loadCarrerasStats(idGeneracion) {
     try {
-        const data = await fetchAPI(`/api/egresados/stats/carreras/${idGeneracion}`);
+        const data = await fetchAPI(conPlantel(`/api/egresados/stats/carreras/${idGeneracion}`));
         renderCarreras(data.data);
     } catch (error) {
         showNotification('Error al cargar carreras', 'error');
@@ -122,7 +125,7 @@ function renderCarreras(statsData) {
     
     // Tarjeta "Mostrar todas"
     let html = `
-        <div onclick="seleccionarCarrera('all', 'Todas las carreras')" 
+        <div data-accion="seleccionarCarrera" data-args="${argumentosAccion('all', 'Todas las carreras')}" 
              class="bg-gradient-to-br from-secondary to-primary text-white rounded-xl shadow-md hover:shadow-xl transition-all duration-300 cursor-pointer p-6 transform hover:scale-105">
             <div class="flex items-center justify-between mb-3">
                 <span class="material-symbols-outlined text-4xl">grid_view</span>
@@ -137,9 +140,8 @@ function renderCarreras(statsData) {
     
     // Tarjetas de carreras individuales
     carreras.forEach(carrera => {
-        const nombreEscapado = carrera.nombre.replace(/'/g, "\\'");
         html += `
-            <div onclick="seleccionarCarrera(${carrera.id_carrera}, '${nombreEscapado}')" 
+            <div data-accion="seleccionarCarrera" data-args="${argumentosAccion(carrera.id_carrera, carrera.nombre)}" 
                  class="bg-white dark:bg-[#2a1a1e] rounded-xl shadow-md hover:shadow-xl transition-all duration-300 cursor-pointer p-6 border border-card-border dark:border-[#3a252a] transform hover:scale-105 hover:border-secondary">
                 <div class="flex items-center justify-between mb-3">
                     <span class="material-symbols-outlined text-secondary text-3xl">auto_stories</span>
@@ -147,7 +149,7 @@ function renderCarreras(statsData) {
                         <span class="text-sm font-semibold text-secondary">${carrera.total_egresados}</span>
                     </div>
                 </div>
-                <h3 class="text-base font-bold text-text-main dark:text-white mb-1 line-clamp-2">${carrera.nombre}</h3>
+                <h3 class="text-base font-bold text-text-main dark:text-white mb-1 line-clamp-2">${escaparHTML(carrera.nombre)}</h3>
                 <p class="text-sm text-text-secondary dark:text-gray-400">
                     ${carrera.total_egresados} ${carrera.total_egresados === 1 ? 'egresado' : 'egresados'}
                 </p>
@@ -216,21 +218,52 @@ function volverACarreras() {
 // CARGAR EGRESADOS FILTRADOS
 // =====================================================
 
-async function loadEgresadosFiltrados() {
+// Filtros actuales de la tabla, compartidos por el listado y la exportación
+function parametrosFiltros() {
+    const params = new URLSearchParams();
+    
+    if (filtrosSeleccionados.generacion && filtrosSeleccionados.generacion !== 'all') {
+        params.append('generacion', filtrosSeleccionados.generacion);
+    }
+    
+    if (filtrosSeleccionados.carrera && filtrosSeleccionados.carrera !== 'all') {
+        params.append('carrera', filtrosSeleccionados.carrera);
+    }
+    
+    // Búsqueda y estatus se resuelven en el servidor
+    const searchTerm = document.getElementById('searchInput')?.value.trim();
+    if (searchTerm) {
+        params.append('q', searchTerm);
+    }
+    
+    const estatusId = document.getElementById('filterEstatus')?.value;
+    if (estatusId) {
+        params.append('estatus', estatusId);
+    }
+    
+    // Los usuarios limitados a su plantel no tienen este filtro; el servidor
+    // aplica su plantel de todos modos
+    const plantelId = plantelSeleccionado();
+    if (plantelId) {
+        params.append('plantel', plantelId);
+    }
+    
+    return params;
+}
+
+async function loadEgresadosFiltrados(page = 1) {
     try {
-        const params = new URLSearchParams();
-        
-        if (filtrosSeleccionados.generacion && filtrosSeleccionados.generacion !== 'all') {
-            params.append('generacion', filtrosSeleccionados.generacion);
-        }
-        
-        if (filtrosSeleccionados.carrera && filtrosSeleccionados.carrera !== 'all') {
-            params.append('carrera', filtrosSeleccionados.carrera);
-        }
+        const params = parametrosFiltros();
+        params.append('page', page);
+        params.append('per_page', paginacion.per_page);
         
         const data = await fetchAPI(`/api/egresados/filtrados?${params.toString()}`);
         egresadosData = data.data || [];
+        if (data.meta) {
+            paginacion = data.meta;
+        }
         renderEgresados(egresadosData);
+        renderPaginacion();
         
         // Setup filtros adicionales de búsqueda
         setupTableFilters();
@@ -508,7 +541,7 @@ async function loadCarreras() {
         select.innerHTML = '<option value="">Seleccione una carrera</option>';
         
         data.data.forEach(carrera => {
-            select.innerHTML += `<option value="${carrera.id_carrera}">${carrera.nombre}</option>`;
+            select.innerHTML += `<option value="${carrera.id_carrera}">${escaparHTML(carrera.nombre)}</option>`;
         });
     } catch (error) {
         showNotification('Error al cargar carreras', 'error');
@@ -523,13 +556,53 @@ async function loadGeneraciones() {
         select.innerHTML = '<option value="">Seleccione una generación</option>';
         
         data.data.forEach(gen => {
-            select.innerHTML += `<option value="${gen.id_generacion}">${gen.periodo}</option>`;
+            select.innerHTML += `<option value="${gen.id_generacion}">${escaparHTML(gen.periodo)}</option>`;
         });
     } catch (error) {
         showNotification('Error al cargar generaciones', 'error');
     }
 }
 
+// =====================================================
+// FILTRO DE PLANTEL
+// =====================================================
+
+function plantelSeleccionado() {
+    return document.getElementById('filterPlantel')?.value || '';
+}
+
+// conPlantel agrega el plantel elegido a las URLs de estadísticas
+function conPlantel(url) {
+    const plantelId = plantelSeleccionado();
+    return plantelId ? `${url}?plantel=${encodeURIComponent(plantelId)}` : url;
+}
+
+async function loadPlanteles() {
+    const filterSelect = document.getElementById('filterPlantel');
+    if (!filterSelect) return;
+    
+    try {
+        const data = await fetchAPI('/api/planteles');
+        filterSelect.innerHTML = '<option value="">Todos los planteles</option>';
+        data.data.forEach(p => {
+            filterSelect.innerHTML += `<option value="${p.id_plantel}">${escaparHTML(p.clave)} - ${escaparHTML(p.nombre)}</option>`;
+        });
+    } catch (error) {
+        showNotification('Error al cargar planteles', 'error');
+    }
+    
+    // Recalcular los conteos y la tabla de la vista actual
+    filterSelect.addEventListener('change', () => {
+        loadGeneracionesStats();
+        if (filtrosSeleccionados.generacion) {
+            loadCarrerasStats(filtrosSeleccionados.generacion);
+        }
+        if (filtrosSeleccionados.carrera) {
+            loadEgresadosFiltrados(1);
+        }
+    });
+}
+
 async function loadEstatus() {
     try {
         const data = await fetchAPI('/api/estatus');
@@ -539,14 +612,14 @@ async function loadEstatus() {
         if (select) {
             select.innerHTML = '<option value="">Seleccione un estatus</option>';
             data.data.forEach(est => {
-                select.innerHTML += `<option value="${est.id_estatus}">${est.descripcion}</option>`;
+                select.innerHTML += `<option value="${est.id_estatus}">${escaparHTML(est.descripcion)}</option>`;
             });
         }
         
         if (filterSelect) {
             filterSelect.innerHTML = '<option value="">Todos los estatus</option>';
             data.data.forEach(est => {
-                filterSelect.innerHTML += `<option value="${est.id_estatus}">${est.descripcion}</option>`;
+                filterSelect.innerHTML += `<option value="${est.id_estatus}">${escaparHTML(est.descripcion)}</option>`;
             });
         }
     } catch (error) {
@@ -587,55 +660,55 @@ function renderEgresados(egresados) {
             
             <!-- Matrícula -->
             <td class="flex items-center py-4 before:content-[attr(data-title)] before:font-semibold before:mr-2 before:text-[#141a4e] md:before:content-none group-hover:before:text-white dark:before:text-gray-300" data-title="Matrícula:">
-                <span class="text-sm font-medium text-[#141a4e] dark:text-white group-hover:text-white">${e.matricula}</span>
+                <span class="text-sm font-medium text-[#141a4e] dark:text-white group-hover:text-white">${escaparHTML(e.matricula)}</span>
             </td>
 
             <!-- Nombre con Avatar -->
             <td class="flex items-center gap-3 py-4 before:content-[attr(data-title)] before:font-semibold before:mr-2 before:text-[#141a4e] md:before:content-none group-hover:before:text-white dark:before:text-gray-300" data-title="Nombre:">
                 <div class="flex items-center gap-3">
                     ${avatar}
-                    <span class="font-semibold text-[#141a4e] dark:text-white group-hover:text-white text-sm">${e.nombre_completo}</span>
+                    <span class="font-semibold text-[#141a4e] dark:text-white group-hover:text-white text-sm">${escaparHTML(e.nombre_completo)}</span>
                 </div>
             </td>
 
             <!-- Estatus -->
             <td class="flex items-center gap-2 py-4 before:content-[attr(data-title)] before:font-semibold before:mr-2 before:text-[#141a4e] md:before:content-none group-hover:before:text-white dark:before:text-gray-300" data-title="Estatus:">
                 <span class="w-4 h-4 rounded-full flex-shrink-0" style="background-color: ${estatusColor};"></span>
-                <span class="text-sm text-[#141a4e] dark:text-white group-hover:text-white">${estatusLabel}</span>
+                <span class="text-sm text-[#141a4e] dark:text-white group-hover:text-white">${escaparHTML(estatusLabel)}</span>
             </td>
 
             <!-- Carrera -->
             <td class="py-4 before:content-[attr(data-title)] before:font-semibold before:mr-2 before:text-[#141a4e] md:before:content-none group-hover:before:text-white dark:before:text-gray-300" data-title="Carrera:">
-                <span class="text-sm text-[#141a4e] dark:text-gray-300 group-hover:text-white">${e.nombre_carrera || '-'}</span>
+                <span class="text-sm text-[#141a4e] dark:text-gray-300 group-hover:text-white">${escaparHTML(e.nombre_carrera || '-')}</span>
             </td>
 
             <!-- Generación -->
             <td class="py-4 before:content-[attr(data-title)] before:font-semibold before:mr-2 before:text-[#141a4e] md:before:content-none group-hover:before:text-white dark:before:text-gray-300" data-title="Generación:">
-                <span class="text-sm text-[#141a4e] dark:text-gray-300 group-hover:text-white">${e.periodo_generacion || '-'}</span>
+                <span class="text-sm text-[#141a4e] dark:text-gray-300 group-hover:text-white">${escaparHTML(e.periodo_generacion || '-')}</span>
             </td>
 
             <!-- Contacto -->
             <td class="py-4 before:content-[attr(data-title)] before:font-semibold before:mr-2 before:text-[#141a4e] md:before:content-none group-hover:before:text-white dark:before:text-gray-300" data-title="Contacto:">
                 <div class="text-sm">
-                    <div class="text-[#141a4e] dark:text-gray-300 group-hover:text-white">${emailShort}</div>
-                    <div class="text-xs text-gray-500 group-hover:text-white/80">${telefonoDisplay}</div>
+                    <div class="text-[#141a4e] dark:text-gray-300 group-hover:text-white">${escaparHTML(emailShort)}</div>
+                    <div class="text-xs text-gray-500 group-hover:text-white/80">${escaparHTML(telefonoDisplay)}</div>
                 </div>
             </td>
 
             <!-- Acciones -->
             <td class="py-4 text-right before:content-[attr(data-title)] before:font-semibold before:mr-2 before:text-[#141a4e] md:before:content-none group-hover:before:text-white dark:before:text-gray-300" data-title="Acciones:">
                 <div class="flex gap-2 md:justify-end">
-                    <button onclick="descargarExpediente('${e.matricula}')" 
+                    <button data-accion="descargarExpediente" data-args="${argumentosAccion(e.matricula)}" 
                             class="text-blue-600 hover:text-blue-900 group-hover:text-white group-hover:hover:text-white/80 dark:hover:text-blue-400 transition-colors p-2 rounded-lg hover:bg-white/10"
                             title="Descargar Expediente">
                         <span class="material-symbols-outlined text-[20px]">download</span>
                     </button>
-                    <button onclick="editEgresado('${e.matricula}')" 
+                    <button data-accion="editEgresado" data-args="${argumentosAccion(e.matricula)}" 
                             class="text-secondary hover:text-primary group-hover:text-white group-hover:hover:text-white/80 transition-colors p-2 rounded-lg hover:bg-white/10"
                             title="Editar">
                         <span class="material-symbols-outlined text-[20px]">edit</span>
                     </button>
-                    <button onclick="deleteEgresado('${e.matricula}')" 
+                    <button data-accion="deleteEgresado" data-args="${argumentosAccion(e.matricula)}" 
                             class="text-red-600 hover:text-red-900 group-hover:text-white group-hover:hover:text-white/80 dark:hover:text-red-400 transition-colors p-2 rounded-lg hover:bg-white/10"
                             title="Eliminar">
                         <span class="material-symbols-outlined text-[20px]">delete</span>
@@ -689,7 +762,7 @@ function getInitialsAvatar(nombre) {
     const colorIndex = nombre.charCodeAt(0) % colors.length;
     const bgColor = colors[colorIndex];
     
-    return `<div class="w-10 h-10 rounded-full ${bgColor} flex items-center justify-center text-white font-semibold text-sm flex-shrink-0">${iniciales.toUpperCase()}</div>`;
+    return `<div class="w-10 h-10 rounded-full ${bgColor} flex items-center justify-center text-white font-semibold text-sm flex-shrink-0">${escaparHTML(iniciales.toUpperCase())}</div>`;
 }
 
 // Función para obtener información del estatus
@@ -744,45 +817,63 @@ function getEstatusBadgeClass(idEstatus) {
 // FILTROS DE BÚSQUEDA EN TABLA
 // =====================================================
 
+let tableFiltersReady = false;
+let searchTimeout = null;
+
 function setupTableFilters() {
+    if (tableFiltersReady) return;
+    tableFiltersReady = true;
+    
     const searchInput = document.getElementById('searchInput');
     const filterEstatus = document.getElementById('filterEstatus');
     
-    const applyFilters = () => {
-        let filtered = [...egresadosData];
-        
-        const searchTerm = searchInput.value.toLowerCase();
-        if (searchTerm) {
-            filtered = filtered.filter(e => 
-                e.matricula.toLowerCase().includes(searchTerm) ||
-                e.nombre_completo.toLowerCase().includes(searchTerm)
-            );
-        }
-        
-        const estatusId = filterEstatus.value;
-        if (estatusId) {
-            filtered = filtered.filter(e => e.id_estatus == estatusId);
-        }
-        
-        renderEgresados(filtered);
-    };
-    
-    searchInput?.addEventListener('input', applyFilters);
-    filterEstatus?.addEventListener('change', applyFilters);
+    // Esperar a que el usuario deje de escribir antes de consultar al servidor
+    searchInput?.addEventListener('input', () => {
+        clearTimeout(searchTimeout);
+        searchTimeout = setTimeout(() => loadEgresadosFiltrados(1), 300);
+    });
+    filterEstatus?.addEventListener('change', () => loadEgresadosFiltrados(1));
 }
 
 function clearSearchFilters() {
     const searchInput = document.getElementById('searchInput');
     const filterEstatus = document.getElementById('filterEstatus');
     
+    const teniaFiltros = (searchInput && searchInput.value) || (filterEstatus && filterEstatus.value);
+    
     if (searchInput) searchInput.value = '';
     if (filterEstatus) filterEstatus.value = '';
     
-    if (egresadosData.length > 0) {
-        renderEgresados(egresadosData);
+    if (teniaFiltros && filtrosSeleccionados.carrera) {
+        loadEgresadosFiltrados(1);
     }
 }
 
+// =====================================================
+// PAGINACIÓN
+// =====================================================
+
+function renderPaginacion() {
+    const container = document.getElementById('paginacionEgresados');
+    if (!container) return;
+    
+    const { page, per_page, total, total_pages } = paginacion;
+    const desde = total === 0 ? 0 : (page - 1) * per_page + 1;
+    const hasta = Math.min(page * per_page, total);
+    
+    document.getElementById('paginacionInfo').textContent = 
+        `Mostrando ${desde}-${hasta} de ${total} egresados`;
+    document.getElementById('paginaActual').textContent = `Página ${page} de ${total_pages}`;
+    document.getElementById('btnPaginaAnterior').disabled = page <= 1;
+    document.getElementById('btnPaginaSiguiente').disabled = page >= total_pages;
+}
+
+function cambiarPagina(delta) {
+    const nueva = paginacion.page + delta;
+    if (nueva < 1 || nueva > paginacion.total_pages) return;
+    loadEgresadosFiltrados(nueva);
+}
+
 // =====================================================
 // MODAL
 // =====================================================
@@ -790,11 +881,13 @@ function clearSearchFilters() {
 function openModal() {
     isEditMode = false;
     currentMatricula = null;
+    currentVersion = null;
     searchMode = 'cp';
     
     document.getElementById('modalTitle').textContent = 'Nuevo Egresado';
     document.getElementById('egresadoForm').reset();
     document.getElementById('matricula').readOnly = false;
+    document.getElementById('btnSiguienteMatricula').classList.remove('hidden');
     
     // Cargar catálogos si no están cargados
     loadCarreras();
@@ -813,9 +906,27 @@ function openModal() {
 function closeModal() {
     document.getElementById('egresadoModal').style.display = 'none';
     document.getElementById('egresadoForm').reset();
+    limpiarErroresCampos(document.getElementById('egresadoForm'));
     clearAddressFields();
 }
 
+// Propone la siguiente matrícula libre (PP AA NNNN) de la generación seleccionada
+async function sugerirMatricula() {
+    const generacion = document.getElementById('id_generacion').value;
+    if (!generacion) {
+        showNotification('Selecciona primero la generación', 'error');
+        document.getElementById('id_generacion').focus();
+        return;
+    }
+
+    try {
+        const data = await fetchAPI(`/api/egresados/siguiente-matricula?generacion=${encodeURIComponent(generacion)}`);
+        document.getElementById('matricula').value = data.data.matricula;
+    } catch (error) {
+        showNotification(error.message || 'Error al generar la matrícula', 'error');
+    }
+}
+
 window.onclick = function(event) {
     const modal = document.getElementById('egresadoModal');
     if (event.target == modal) {
@@ -827,6 +938,14 @@ window.onclick = function(event) {
 // CREAR/EDITAR EGRESADO
 // =====================================================
 
+// Los campos de dirección se capturan en controles visibles distintos a los ocultos que se envían
+const aliasCamposEgresado = {
+    codigo_postal: 'codigo_postal_search',
+    estado: 'estado_readonly',
+    municipio: 'municipio_readonly',
+    asentamiento: 'asentamiento_select',
+};
+
 document.getElementById('egresadoForm')?.addEventListener('submit', async (e) => {
     e.preventDefault();
     
@@ -858,6 +977,7 @@ document.getElementById('egresadoForm')?.addEventListener('submit', async (e) =>
         return;
     }
     
+    limpiarErroresCampos(e.target);
     const submitBtn = e.target.querySelector('button[type="submit"]');
     setButtonLoading(submitBtn, true);
     
@@ -865,6 +985,7 @@ document.getElementById('egresadoForm')?.addEventListener('submit', async (e) =>
         if (isEditMode) {
             await fetchAPI(`/api/egresados/${currentMatricula}`, {
                 method: 'PUT',
+                headers: { 'If-Match': `"${currentVersion}"` },
                 body: JSON.stringify(formData),
             });
             showNotification('Egresado actualizado correctamente', 'success');
@@ -880,9 +1001,18 @@ document.getElementById('egresadoForm')?.addEventListener('submit', async (e) =>
         
         // Recargar datos si estamos en la vista de tabla
         if (!document.getElementById('vistaTabla').classList.contains('hidden')) {
-            loadEgresadosFiltrados();
+            loadEgresadosFiltrados(paginacion.page);
         }
     } catch (error) {
+        if (isEditMode && (error.status === 409 || error.status === 412)) {
+            // Otro usuario guardó primero: recargar el formulario con sus cambios
+            showNotification(error.message, 'error');
+            editEgresado(currentMatricula);
+            return;
+        }
+        if (error.status === 422) {
+            marcarErroresCampos(e.target, error.errores, aliasCamposEgresado);
+        }
         showNotification(error.message || 'Error al guardar egresado', 'error');
     } finally {
         setButtonLoading(submitBtn, false);
@@ -900,6 +1030,7 @@ async function editEgresado(matricula) {
         
         isEditMode = true;
         currentMatricula = matricula;
+        currentVersion = egresado.version;
         
         // Cargar catálogos primero
         await Promise.all([loadCarreras(), loadGeneraciones()]);
@@ -907,6 +1038,7 @@ async function editEgresado(matricula) {
         document.getElementById('modalTitle').textContent = 'Editar Egresado';
         document.getElementById('matricula').value = egresado.matricula;
         document.getElementById('matricula').readOnly = true;
+        document.getElementById('btnSiguienteMatricula').classList.add('hidden');
         document.getElementById('nombre_completo').value = egresado.nombre_completo;
         document.getElementById('genero').value = egresado.genero || '';
         document.getElementById('telefono').value = egresado.telefono || '';
@@ -949,7 +1081,7 @@ async function editEgresado(matricula) {
 // =====================================================
 
 async function deleteEgresado(matricula) {
-    if (!confirmAction('¿Está seguro de eliminar este egresado? Esta acción no se puede deshacer.')) {
+    if (!confirmAction('¿Está seguro de eliminar este egresado? Se enviará a la papelera y podrá restaurarse.')) {
         return;
     }
     
@@ -957,11 +1089,11 @@ async function deleteEgresado(matricula) {
         await fetchAPI(`/api/egresados/${matricula}`, {
             method: 'DELETE',
         });
-        showNotification('Egresado eliminado correctamente', 'success');
+        showNotification('Egresado enviado a la papelera', 'success');
         
         // Recargar datos si estamos en la vista de tabla
         if (!document.getElementById('vistaTabla').classList.contains('hidden')) {
-            loadEgresadosFiltrados();
+            loadEgresadosFiltrados(paginacion.page);
         }
     } catch (error) {
         showNotification('Error al eliminar egresado', 'error');
@@ -972,315 +1104,26 @@ async function deleteEgresado(matricula) {
 // DESCARGAR EXPEDIENTE EN PDF
 // =====================================================
 
-async function descargarExpediente(matricula) {
-    try {
-        // Buscar los datos del egresado
-        const egresado = egresadosData.find(e => e.matricula === matricula);
-        
-        if (!egresado) {
-            showNotification('No se encontró el egresado', 'error');
-            return;
-        }
-
-        // Verificar que jsPDF esté disponible
-        if (typeof window.jspdf === 'undefined') {
-            showNotification('Error: Librería PDF no disponible', 'error');
-            return;
-        }
-
-        const { jsPDF } = window.jspdf;
-        const doc = new jsPDF();
-
-        // Configuración de colores
-        const primaryColor = [139, 35, 62]; // #8b233e
-        const textColor = [51, 51, 51];
-        const lightGray = [240, 240, 240];
-
-        // Encabezado
-        doc.setFillColor(...primaryColor);
-        doc.rect(0, 0, 210, 35, 'F');
-        
-        // Agregar logos en el encabezado
-        try {
-            // Logo EdoMex (superior izquierdo)
-            doc.addImage('/static/img/pdf_images/edomex.png', 'PNG', 8, 7, 38, 22);
-            
-            // Logo UES (superior derecho)
-            doc.addImage('/static/img/pdf_images/UES-WHITE.png', 'PNG', 170, 7, 32, 22);
-        } catch (error) {
-            console.warn('No se pudieron cargar los logos:', error);
-        }
-        
-        doc.setTextColor(255, 255, 255);
-        doc.setFontSize(20);
-        doc.setFont('helvetica', 'bold');
-        doc.text('EXPEDIENTE DE EGRESADO', 105, 15, { align: 'center' });
-        
-        doc.setFontSize(12);
-        doc.setFont('helvetica', 'normal');
-        doc.text('Sistema de Gestión de Egresados', 105, 23, { align: 'center' });
-        doc.text('UES San José del Ricón', 105, 29, { align: 'center' });
-
-        // Línea decorativa
-        doc.setDrawColor(...primaryColor);
-        doc.setLineWidth(0.5);
-        doc.line(20, 40, 190, 40);
-
-        let yPosition = 50;
-
-        // Función auxiliar para agregar sección
-        const addSection = (title, fields, yPos) => {
-            doc.setFillColor(...lightGray);
-            doc.rect(20, yPos, 170, 8, 'F');
-            doc.setTextColor(...primaryColor);
-            doc.setFontSize(12);
-            doc.setFont('helvetica', 'bold');
-            doc.text(title, 25, yPos + 5.5);
-            
-            yPos += 12;
-            doc.setTextColor(...textColor);
-            doc.setFontSize(10);
-            doc.setFont('helvetica', 'normal');
-            
-            fields.forEach(field => {
-                doc.setFont('helvetica', 'bold');
-                doc.text(`${field.label}:`, 25, yPos);
-                doc.setFont('helvetica', 'normal');
-                doc.text(field.value || 'N/A', 70, yPos);
-                yPos += 6;
-            });
-            
-            return yPos + 4;
-        };
-
-        // DATOS PERSONALES
-        yPosition = addSection('DATOS PERSONALES', [
-            { label: 'Matrícula', value: egresado.matricula },
-            { label: 'Nombre Completo', value: egresado.nombre_completo },
-            { label: 'Género', value: egresado.genero === 'M' ? 'Masculino' : egresado.genero === 'F' ? 'Femenino' : 'Otro' },
-            { label: 'Fecha de Nacimiento', value: egresado.fecha_nacimiento ? new Date(egresado.fecha_nacimiento).toLocaleDateString('es-MX') : '-' },
-            { label: 'CURP', value: egresado.curp }
-        ], yPosition);
-
-        // DATOS ACADÉMICOS
-        yPosition = addSection('DATOS ACADÉMICOS', [
-            { label: 'Carrera', value: egresado.nombre_carrera },
-            { label: 'Generación', value: egresado.periodo_generacion },
-            { label: 'Fecha de Egreso', value: egresado.fecha_egreso ? new Date(egresado.fecha_egreso).toLocaleDateString('es-MX') : '-' },
-            { label: 'Estatus', value: egresado.nombre_estatus }
-        ], yPosition);
-
-        // DATOS DE CONTACTO
-        yPosition = addSection('DATOS DE CONTACTO', [
-            { label: 'Teléfono', value: egresado.telefono },
-            { label: 'Correo Electrónico', value: egresado.email },
-            { label: 'Estado', value: egresado.estado },
-            { label: 'Municipio', value: egresado.municipio },
-            { label: 'Código Postal', value: egresado.codigo_postal }
-        ], yPosition);
-
-        // OBSERVACIONES
-        if (egresado.observaciones && egresado.observaciones.trim() !== '') {
-            yPosition = addSection('OBSERVACIONES', [
-                { label: '', value: egresado.observaciones }
-            ], yPosition);
-        }
-
-        // Pie de página
-        const pageHeight = doc.internal.pageSize.height;
-        doc.setFontSize(8);
-        doc.setTextColor(128, 128, 128);
-        doc.text(`Generado el: ${new Date().toLocaleString('es-MX')}`, 105, pageHeight - 15, { align: 'center' });
-        doc.text('Sistema de Gestión de Egresados - UES San José del Ricón', 105, pageHeight - 10, { align: 'center' });
-
-        // Descargar el PDF
-        const nombreArchivo = `expediente_${egresado.matricula}_${egresado.nombre_completo.replace(/\s+/g, '_')}.pdf`;
-        doc.save(nombreArchivo);
-        
-        showNotification('Expediente descargado correctamente', 'success');
-        
-    } catch (error) {
-        console.error('Error al generar PDF:', error);
-        showNotification('Error al generar el expediente', 'error');
-    }
+function descargarExpediente(matricula) {
+    // El servidor genera el PDF con los logos institucionales
+    window.location.href = `/api/egresados/${encodeURIComponent(matricula)}/expediente.pdf`;
+    showNotification('Descargando expediente...', 'success');
 }
 
 // =====================================================
 // DESCARGAR TABLA EN PDF
 // =====================================================
 
-async function descargarTablaPDF() {
-    try {
-        // Verificar que jsPDF esté disponible
-        if (typeof window.jspdf === 'undefined') {
-            showNotification('Error: Librería PDF no disponible', 'error');
-            return;
-        }
-
-        // Cargar datos filtrados
-        const params = new URLSearchParams();
-        
-        if (filtrosSeleccionados.generacion && filtrosSeleccionados.generacion !== 'all') {
-            params.append('generacion', filtrosSeleccionados.generacion);
-        }
-        
-        if (filtrosSeleccionados.carrera && filtrosSeleccionados.carrera !== 'all') {
-            params.append('carrera', filtrosSeleccionados.carrera);
-        }
-        
-        const dataResponse = await fetchAPI(`/api/egresados/filtrados?${params.toString()}`);
-        const egresadosTableData = dataResponse.data || [];
-
-        if (egresadosTableData.length === 0) {
-            showNotification('No hay datos para descargar', 'warning');
-            return;
-        }
-
-        const { jsPDF } = window.jspdf;
-        const doc = new jsPDF('l', 'mm', 'a4'); // 'l' = landscape (horizontal)
-
-        // Configuración de colores
-        const primaryColor = [139, 35, 62];
-        const textColor = [51, 51, 51];
-        const lightGray = [240, 240, 240];
-
-        // Encabezado
-        doc.setFillColor(...primaryColor);
-        doc.rect(0, 0, 297, 30, 'F');
-
-        // Agregar logos
-        try {
-            doc.addImage('/static/img/pdf_images/edomex.png', 'PNG', 10, 5, 35, 20);
-            doc.addImage('/static/img/pdf_images/UES-WHITE.png', 'PNG', 252, 5, 35, 20);
-        } catch (error) {
-            console.warn('No se pudieron cargar los logos:', error);
-        }
-
-        doc.setTextColor(255, 255, 255);
-        doc.setFontSize(18);
-        doc.setFont('helvetica', 'bold');
-        doc.text('TABLA DE EGRESADOS', 148.5, 12, { align: 'center' });
-
-        doc.setFontSize(10);
-        doc.setFont('helvetica', 'normal');
-        doc.text('Generación: ' + (filtrosSeleccionados.generacionTexto || 'Todas'), 148.5, 19, { align: 'center' });
-        doc.text('Carrera: ' + (filtrosSeleccionados.carreraTexto || 'Todas'), 148.5, 25, { align: 'center' });
-
-        // Preparar datos para la tabla
-        const dataTable = egresadosTableData.map(e => [
-            e.matricula,
-            e.nombre_completo,
-            e.descripcion_estatus || '-',
-            e.nombre_carrera || '-',
-            e.periodo_generacion || '-',
-            e.correo || '-',
-            e.telefono || '-'
-        ]);
-
-        // Crear tabla con autoTable (si está disponible) o tabla manual
-        if (typeof doc.autoTable === 'function') {
-            doc.autoTable({
-                startY: 35,
-                head: [['Matrícula', 'Nombre', 'Estatus', 'Carrera', 'Generación', 'Email', 'Teléfono']],
-                body: dataTable,
-                headStyles: {
-                    fillColor: primaryColor,
-                    textColor: [255, 255, 255],
-                    fontSize: 9,
-                    fontStyle: 'bold',
-                    halign: 'center',
-                    valign: 'middle'
-                },
-                bodyStyles: {
-                    fontSize: 8,
-                    textColor: textColor
-                },
-                alternateRowStyles: {
-                    fillColor: lightGray
-                },
-                margin: { top: 35, right: 10, bottom: 20, left: 10 },
-                columnStyles: {
-                    0: { cellWidth: 25 },
-                    1: { cellWidth: 55 },
-                    2: { cellWidth: 25 },
-                    3: { cellWidth: 55 },
-                    4: { cellWidth: 30 },
-                    5: { cellWidth: 50 },
-                    6: { cellWidth: 30 }
-                }
-            });
-        } else {
-            // Tabla manual sin autoTable
-            let yPosition = 35;
-            const pageHeight = doc.internal.pageSize.height;
-            const columns = ['Matrícula', 'Nombre', 'Estatus', 'Carrera', 'Generación', 'Email', 'Teléfono'];
-            const columnWidths = [25, 55, 25, 55, 30, 50, 30];
-
-            // Encabezados
-            doc.setFillColor(...primaryColor);
-            doc.setTextColor(255, 255, 255);
-            doc.setFontSize(9);
-            doc.setFont('helvetica', 'bold');
-
-            let xPos = 10;
-            columns.forEach((col, idx) => {
-                doc.text(col, xPos, yPosition, { maxWidth: columnWidths[idx] - 2 });
-                xPos += columnWidths[idx];
-            });
-
-            yPosition += 8;
-
-            // Filas de datos
-            doc.setTextColor(...textColor);
-            doc.setFontSize(8);
-            doc.setFont('helvetica', 'normal');
-
-            dataTable.forEach((row, rowIdx) => {
-                if (yPosition > pageHeight - 15) {
-                    doc.addPage();
-                    yPosition = 15;
-                }
-
-                xPos = 10;
-                if (rowIdx % 2 === 0) {
-                    doc.setFillColor(255, 255, 255);
-                } else {
-                    doc.setFillColor(lightGray[0], lightGray[1], lightGray[2]);
-                }
-                doc.rect(10, yPosition - 5, 277, 7, 'F');
-
-                row.forEach((cell, idx) => {
-                    doc.text(String(cell).substring(0, 20), xPos, yPosition, { maxWidth: columnWidths[idx] - 2 });
-                    xPos += columnWidths[idx];
-                });
-
-                yPosition += 7;
-            });
-        }
-
-        // Pie de página
-        const pageCount = doc.getNumberOfPages();
-        doc.setFontSize(8);
-        doc.setTextColor(128, 128, 128);
-
-        for (let i = 1; i <= pageCount; i++) {
-            doc.setPage(i);
-            const pageHeight = doc.internal.pageSize.height;
-            doc.text(`Página ${i} de ${pageCount}`, 148.5, pageHeight - 10, { align: 'center' });
-            doc.text(`Generado el: ${new Date().toLocaleString('es-MX')}`, 148.5, pageHeight - 5, { align: 'center' });
-        }
-
-        // Descargar
-        const fecha = new Date().toISOString().split('T')[0];
-        const nombreArchivo = `tabla_egresados_${fecha}.pdf`;
-        doc.save(nombreArchivo);
+function descargarTablaPDF() {
+    if (paginacion.total === 0) {
+        showNotification('No hay datos para descargar', 'warning');
+        return;
+    }
 
-        showNotification('Tabla descargada correctamente en PDF', 'success');
+    const params = parametrosFiltros();
+    window.location.href = `/api/egresados/report.pdf?${params.toString()}`;
 
-    } catch (error) {
-        console.error('Error al generar tabla PDF:', error);
-        showNotification('Error al descargar la tabla', 'error');
-    }
+    showNotification('Descargando tabla en PDF...', 'success');
 }
 
 // =====================================================
@@ -1299,99 +1142,16 @@ function cerrarModalFormato() {
 // DESCARGAR TABLA EN XLSX
 // =====================================================
 
-async function descargarTablaXLSX() {
-    try {
-        // Verificar que XLSX esté disponible
-        if (typeof XLSX === 'undefined') {
-            showNotification('Error: Librería Excel no disponible', 'error');
-            return;
-        }
-
-        // Cargar datos filtrados
-        const params = new URLSearchParams();
-        
-        if (filtrosSeleccionados.generacion && filtrosSeleccionados.generacion !== 'all') {
-            params.append('generacion', filtrosSeleccionados.generacion);
-        }
-        
-        if (filtrosSeleccionados.carrera && filtrosSeleccionados.carrera !== 'all') {
-            params.append('carrera', filtrosSeleccionados.carrera);
-        }
-        
-        const dataResponse = await fetchAPI(`/api/egresados/filtrados?${params.toString()}`);
-        const egresadosTableData = dataResponse.data || [];
-
-        if (egresadosTableData.length === 0) {
-            showNotification('No hay datos para descargar', 'warning');
-            return;
-        }
-
-        // Preparar datos para la hoja
-        const wsData = [];
-        
-        // Agregar encabezado con información de filtros
-        wsData.push(['TABLA DE EGRESADOS']);
-        wsData.push([]);
-        wsData.push(['Generación:', filtrosSeleccionados.generacionTexto || 'Todas']);
-        wsData.push(['Carrera:', filtrosSeleccionados.carreraTexto || 'Todas']);
-        wsData.push(['Fecha de generación:', new Date().toLocaleString('es-MX')]);
-        wsData.push([]);
-        
-        // Agregar encabezados de columnas
-        wsData.push(['Matrícula', 'Nombre Completo', 'Estatus', 'Carrera', 'Generación', 'Email', 'Teléfono']);
-        
-        // Agregar datos
-        egresadosTableData.forEach(e => {
-            wsData.push([
-                e.matricula,
-                e.nombre_completo,
-                e.descripcion_estatus || '-',
-                e.nombre_carrera || '-',
-                e.periodo_generacion || '-',
-                e.correo || '-',
-                e.telefono || '-'
-            ]);
-        });
-
-        // Crear libro de trabajo
-        const wb = XLSX.utils.book_new();
-        const ws = XLSX.utils.aoa_to_sheet(wsData);
-        
-        // Establecer ancho de columnas
-        ws['!cols'] = [
-            { wch: 15 },  // Matrícula
-            { wch: 30 },  // Nombre Completo
-            { wch: 20 },  // Estatus
-            { wch: 30 },  // Carrera
-            { wch: 15 },  // Generación
-            { wch: 30 },  // Email
-            { wch: 15 }   // Teléfono
-        ];
-
-        // Estilo para encabezados (filas 6)
-        const headerRowIndex = 6;
-        for (let i = 0; i < 7; i++) {
-            const cellAddress = XLSX.utils.encode_cell({ r: headerRowIndex - 1, c: i });
-            if (ws[cellAddress]) {
-                ws[cellAddress].s = {
-                    font: { bold: true, color: { rgb: 'FFFFFF' } },
-                    fill: { fgColor: { rgb: '8B233E' } },
-                    alignment: { horizontal: 'center', vertical: 'center' }
-                };
-            }
-        }
-
-        XLSX.utils.book_append_sheet(wb, ws, 'Egresados');
-        
-        // Descargar
-        const fecha = new Date().toISOString().split('T')[0];
-        const nombreArchivo = `tabla_egresados_${fecha}.xlsx`;
-        XLSX.writeFile(wb, nombreArchivo);
+function descargarTablaXLSX() {
+    if (paginacion.total === 0) {
+        showNotification('No hay datos para descargar', 'warning');
+        return;
+    }
 
-        showNotification('Tabla descargada correctamente en Excel', 'success');
+    // El servidor genera el archivo con los mismos filtros de la tabla
+    const params = parametrosFiltros();
+    params.append('format', 'xlsx');
+    window.location.href = `/api/egresados/export?${params.toString()}`;
 
-    } catch (error) {
-        console.error('Error al generar tabla XLSX:', error);
-        showNotification('Error al descargar la tabla', 'error');
-    }
+    showNotification('Descargando tabla en Excel...', 'success');
 }
\ No newline at end of file
@@ -54,6 +54,38 @@ style.textContent = `
 `;
 document.head.appendChild(style);
 
+// Escapa el texto que llega del servidor antes de insertarlo con innerHTML;
+// incluye las comillas para poder usarlo dentro de atributos
+function escaparHTML(texto) {
+    const entidades = { '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' };
+    return String(texto ?? '').replace(/[&<>"']/g, c => entidades[c]);
+}
+
+// La CSP no permite atributos onclick. Un elemento con data-accion="nombre"
+// llama a la función global nombre al hacer clic, con los argumentos de
+// data-args (arreglo JSON); varias acciones se separan con espacios.
+document.addEventListener('click', (evento) => {
+    const elemento = evento.target.closest('[data-accion]');
+    if (!elemento) return;
+
+    const args = elemento.dataset.args ? JSON.parse(elemento.dataset.args) : [];
+    elemento.dataset.accion.split(/\s+/).forEach(nombre => {
+        if (typeof window[nombre] === 'function') {
+            window[nombre](...args);
+        }
+    });
+});
+
+// argumentosAccion arma el valor de data-args, ya escapado para el atributo
+function argumentosAccion(...args) {
+    return escaparHTML(JSON.stringify(args));
+}
+
+// Token CSRF de la sesión, publicado por el servidor en <meta name="csrf-token">
+function csrfToken() {
+    return document.querySelector('meta[name="csrf-token"]')?.content || '';
+}
+
 // Función para hacer peticiones fetch con manejo de errores
 async function fetchAPI(url, options = {}) {
     try {
@@ -61,6 +93,7 @@ async function fetchAPI(url, options = {}) {
             ...options,
             headers: {
                 'Content-Type': 'application/json',
+                'X-CSRF-Token': csrfToken(),
                 ...options.headers,
             },
         });
@@ -68,7 +101,13 @@ async function fetchAPI(url, options = {}) {
         const data = await response.json();
         
         if (!response.ok) {
-            throw new Error(data.error || 'Error en la petición');
+            // Conservar el código y los datos para que la vista pueda reaccionar
+            // (p. ej. 412 trae la versión vigente del registro)
+            const error = new Error(data.error || 'Error en la petición');
+            error.status = response.status;
+            error.data = data.data;
+            error.errores = data.errores;
+            throw error;
         }
         
         return data;
@@ -78,6 +117,32 @@ async function fetchAPI(url, options = {}) {
     }
 }
 
+// Marca los campos que la API rechazó (respuesta 422 con "errores").
+// alias traduce el nombre del campo al id del elemento cuando no coinciden.
+function marcarErroresCampos(form, errores, alias = {}) {
+    limpiarErroresCampos(form);
+    Object.entries(errores || {}).forEach(([campo, mensaje]) => {
+        const input = form.querySelector(`#${alias[campo] || campo}`);
+        if (!input) return;
+
+        input.classList.add('border-red-500', 'ring-1', 'ring-red-500');
+        input.dataset.campoInvalido = 'true';
+
+        const aviso = document.createElement('p');
+        aviso.className = 'campo-error mt-1 text-xs text-red-600';
+        aviso.textContent = mensaje;
+        input.insertAdjacentElement('afterend', aviso);
+    });
+}
+
+function limpiarErroresCampos(form) {
+    form.querySelectorAll('.campo-error').forEach(aviso => aviso.remove());
+    form.querySelectorAll('[data-campo-invalido]').forEach(input => {
+        input.classList.remove('border-red-500', 'ring-1', 'ring-red-500');
+        delete input.dataset.campoInvalido;
+    });
+}
+
 // Función para formatear fechas
 function formatDate(dateString) {
     const options = { year: 'numeric', month: 'long', day: 'numeric' };
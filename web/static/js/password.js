@@ -0,0 +1,87 @@
+// =====================================================
+// RECUPERAR CONTRASEÑA
+// =====================================================
+
+function mostrarError(mensaje) {
+    document.getElementById('errorText').textContent = mensaje;
+    document.getElementById('errorMessage').classList.remove('hidden');
+}
+
+function mostrarExito(mensaje) {
+    document.getElementById('successText').textContent = mensaje;
+    document.getElementById('successMessage').classList.remove('hidden');
+}
+
+async function enviarPassword(url, payload) {
+    const response = await fetch(url, {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json',
+            'X-CSRF-Token': document.querySelector('meta[name="csrf-token"]')?.content || '',
+        },
+        body: JSON.stringify(payload),
+    });
+    return { response, data: await response.json() };
+}
+
+// Paso 1: pedir el enlace por correo
+document.getElementById('olvidoForm')?.addEventListener('submit', async (e) => {
+    e.preventDefault();
+
+    const correo = document.getElementById('correo').value.trim();
+    const submitBtn = e.target.querySelector('button[type="submit"]');
+
+    document.getElementById('errorMessage').classList.add('hidden');
+    if (!correo) {
+        mostrarError('Ingresa tu correo');
+        return;
+    }
+
+    submitBtn.disabled = true;
+    try {
+        const { response, data } = await enviarPassword('/password/olvido', { correo });
+        if (response.ok && data.success) {
+            // La respuesta es la misma exista o no la cuenta
+            e.target.classList.add('hidden');
+            mostrarExito(data.message);
+            return;
+        }
+        mostrarError(data.error || 'No se pudo enviar el enlace');
+    } catch (error) {
+        console.error('Error al solicitar el enlace:', error);
+        mostrarError('Error al conectar con el servidor');
+    }
+    submitBtn.disabled = false;
+});
+
+// Paso 2: elegir la nueva contraseña con el token del enlace
+document.getElementById('restablecerForm')?.addEventListener('submit', async (e) => {
+    e.preventDefault();
+
+    const token = document.getElementById('token').value;
+    const password = document.getElementById('password').value;
+    const confirmacion = document.getElementById('confirmacion').value;
+    const submitBtn = e.target.querySelector('button[type="submit"]');
+
+    document.getElementById('errorMessage').classList.add('hidden');
+    if (password !== confirmacion) {
+        mostrarError('Las contraseñas no coinciden');
+        return;
+    }
+
+    submitBtn.disabled = true;
+    try {
+        const { response, data } = await enviarPassword('/password/restablecer', { token, password });
+        if (response.ok && data.success) {
+            e.target.classList.add('hidden');
+            mostrarExito(data.message);
+            setTimeout(() => { window.location.href = '/'; }, 2500);
+            return;
+        }
+        mostrarError(data.errores?.password || data.error || 'No se pudo restablecer la contraseña');
+    } catch (error) {
+        console.error('Error al restablecer la contraseña:', error);
+        mostrarError('Error al conectar con el servidor');
+    }
+    submitBtn.disabled = false;
+});
@@ -0,0 +1,322 @@
+// =====================================================
+// VERIFICACIÓN EN DOS PASOS
+// =====================================================
+
+let estado2FA = null;
+
+document.addEventListener('DOMContentLoaded', function() {
+    document.getElementById('activar2FAForm').addEventListener('submit', activar2FA);
+    document.getElementById('passwordForm').addEventListener('submit', cambiarPassword);
+    document.getElementById('tokenForm').addEventListener('submit', crearToken);
+    cargarEstado2FA();
+    cargarSesiones();
+    cargarAlcances();
+    cargarTokens();
+});
+
+async function cargarEstado2FA() {
+    try {
+        const data = await fetchAPI('/api/me/2fa');
+        estado2FA = data.data;
+        renderEstado2FA();
+    } catch (error) {
+        document.getElementById('estado2FA').textContent = 'Error al consultar la verificación en dos pasos';
+    }
+}
+
+function renderEstado2FA() {
+    const texto = document.getElementById('estado2FA');
+    const acciones = document.getElementById('acciones2FA');
+
+    document.getElementById('avisoObligatorio').classList.toggle('hidden', !estado2FA.obligatorio || estado2FA.activo);
+
+    if (estado2FA.activo) {
+        texto.textContent = `Activa desde el ${formatDate(estado2FA.activado_en)}. Te quedan ${estado2FA.codigos_restantes} códigos de recuperación.`;
+        acciones.innerHTML = `
+            <button data-accion="regenerarCodigos" class="inline-flex items-center gap-2 border border-gray-300 dark:border-[#3a252a] text-sm font-semibold h-10 px-4 rounded-lg hover:bg-gray-50 dark:hover:bg-white/5 transition-colors">
+                <span class="material-symbols-outlined text-[20px]">key</span>
+                Nuevos códigos de recuperación
+            </button>
+            ${estado2FA.obligatorio ? '' : `
+            <button data-accion="desactivar2FA" class="inline-flex items-center gap-2 text-red-600 border border-red-300 text-sm font-semibold h-10 px-4 rounded-lg hover:bg-red-50 dark:hover:bg-red-900/10 transition-colors">
+                <span class="material-symbols-outlined text-[20px]">remove_moderator</span>
+                Desactivar
+            </button>`}
+        `;
+        document.getElementById('configuracion2FA').classList.add('hidden');
+        return;
+    }
+
+    texto.textContent = estado2FA.obligatorio
+        ? 'Obligatoria para tu rol. Protege tu cuenta con un código de tu teléfono además de la contraseña.'
+        : 'Protege tu cuenta con un código de tu teléfono además de la contraseña.';
+    acciones.innerHTML = `
+        <button data-accion="iniciar2FA" class="inline-flex items-center gap-2 bg-primary hover:bg-primary-hover text-white text-sm font-semibold h-10 px-5 rounded-lg transition-colors">
+            <span class="material-symbols-outlined text-[20px]">phonelink_lock</span>
+            Configurar
+        </button>
+    `;
+}
+
+async function iniciar2FA() {
+    try {
+        const data = await fetchAPI('/api/me/2fa', { method: 'POST' });
+        document.getElementById('secreto2FA').textContent = data.data.secreto;
+        // Evitar que el navegador reutilice el QR de un secreto anterior
+        document.getElementById('qr2FA').src = `/api/me/2fa/qr.png?t=${Date.now()}`;
+        document.getElementById('configuracion2FA').classList.remove('hidden');
+        document.getElementById('codigoActivacion').focus();
+    } catch (error) {
+        showNotification(error.message, 'error');
+    }
+}
+
+async function activar2FA(e) {
+    e.preventDefault();
+    const input = document.getElementById('codigoActivacion');
+
+    try {
+        const data = await fetchAPI('/api/me/2fa/activar', {
+            method: 'POST',
+            body: JSON.stringify({ codigo: input.value.trim() }),
+        });
+        input.value = '';
+        showNotification('Verificación en dos pasos activada', 'success');
+        mostrarCodigos(data.data.codigos_recuperacion);
+        cargarEstado2FA();
+    } catch (error) {
+        showNotification(error.message, 'error');
+    }
+}
+
+async function desactivar2FA() {
+    const codigo = prompt('Ingresa un código de tu aplicación o un código de recuperación para desactivar la verificación en dos pasos');
+    if (!codigo) return;
+
+    try {
+        await fetchAPI('/api/me/2fa', {
+            method: 'DELETE',
+            body: JSON.stringify({ codigo: codigo.trim() }),
+        });
+        showNotification('Verificación en dos pasos desactivada', 'success');
+        document.getElementById('codigosRecuperacion').classList.add('hidden');
+        cargarEstado2FA();
+    } catch (error) {
+        showNotification(error.message, 'error');
+    }
+}
+
+async function regenerarCodigos() {
+    const codigo = prompt('Ingresa un código de tu aplicación para generar nuevos códigos de recuperación. Los anteriores dejarán de servir.');
+    if (!codigo) return;
+
+    try {
+        const data = await fetchAPI('/api/me/2fa/codigos-recuperacion', {
+            method: 'POST',
+            body: JSON.stringify({ codigo: codigo.trim() }),
+        });
+        mostrarCodigos(data.data.codigos_recuperacion);
+        cargarEstado2FA();
+    } catch (error) {
+        showNotification(error.message, 'error');
+    }
+}
+
+function mostrarCodigos(codigos) {
+    document.getElementById('listaCodigos').innerHTML = codigos.map(codigo => `
+        <li class="px-3 py-2 rounded-lg bg-gray-50 dark:bg-white/5 text-center">${escaparHTML(codigo)}</li>
+    `).join('');
+    document.getElementById('codigosRecuperacion').classList.remove('hidden');
+}
+
+// =====================================================
+// SESIONES ABIERTAS
+// =====================================================
+
+async function cargarSesiones() {
+    const tbody = document.getElementById('sesionesTable');
+    try {
+        const data = await fetchAPI('/api/me/sesiones');
+        renderSesiones(data.data || []);
+    } catch (error) {
+        tbody.innerHTML = `
+            <tr><td colspan="4" class="text-center py-8 text-gray-500">Error al cargar las sesiones</td></tr>
+        `;
+    }
+}
+
+function renderSesiones(sesiones) {
+    document.getElementById('sesionesTable').innerHTML = sesiones.map(sesion => `
+        <tr class="hover:bg-gray-50 dark:hover:bg-white/5 transition-colors">
+            <td class="px-6 py-4 text-sm text-text-main dark:text-white">
+                ${escaparHTML(sesion.user_agent || 'Desconocido')}
+                ${sesion.actual ? '<span class="ml-2 px-2 py-0.5 text-xs font-semibold rounded-full bg-green-100 text-green-800">Esta sesión</span>' : ''}
+            </td>
+            <td class="px-6 py-4 text-sm text-text-secondary dark:text-gray-400">${escaparHTML(sesion.ip)}</td>
+            <td class="px-6 py-4 text-sm text-text-secondary dark:text-gray-400">${new Date(sesion.ultima_actividad).toLocaleString('es-MX')}</td>
+            <td class="px-6 py-4 text-sm text-center">
+                ${sesion.actual ? '' : `
+                <button data-accion="revocarSesion" data-args="${argumentosAccion(sesion.id)}" class="text-red-600 hover:text-red-900 dark:hover:text-red-400 transition-colors" title="Cerrar sesión">
+                    <span class="material-symbols-outlined">logout</span>
+                </button>`}
+            </td>
+        </tr>
+    `).join('');
+}
+
+async function revocarSesion(id) {
+    try {
+        await fetchAPI(`/api/me/sesiones/${id}`, { method: 'DELETE' });
+        showNotification('Sesión cerrada', 'success');
+        cargarSesiones();
+    } catch (error) {
+        showNotification(error.message, 'error');
+    }
+}
+
+async function cerrarOtrasSesiones() {
+    try {
+        const data = await fetchAPI('/api/me/sesiones', { method: 'DELETE' });
+        showNotification(`Se cerraron ${data.data.cerradas} sesiones`, 'success');
+        cargarSesiones();
+    } catch (error) {
+        showNotification(error.message, 'error');
+    }
+}
+
+// =====================================================
+// CONTRASEÑA
+// =====================================================
+
+async function cambiarPassword(e) {
+    e.preventDefault();
+    const form = e.target;
+    const password_actual = document.getElementById('password_actual').value;
+    const password_nueva = document.getElementById('password_nueva').value;
+
+    limpiarErroresCampos(form);
+    if (password_nueva !== document.getElementById('password_confirmacion').value) {
+        marcarErroresCampos(form, { password_confirmacion: 'Las contraseñas no coinciden' });
+        return;
+    }
+
+    try {
+        const data = await fetchAPI('/api/me/password', {
+            method: 'POST',
+            body: JSON.stringify({ password_actual, password_nueva }),
+        });
+        form.reset();
+        showNotification(data.message, 'success');
+        cargarSesiones();
+    } catch (error) {
+        if (error.status === 422) {
+            marcarErroresCampos(form, error.errores);
+        }
+        showNotification(error.message, 'error');
+    }
+}
+
+// =====================================================
+// TOKENS DE ACCESO
+// =====================================================
+
+async function cargarAlcances() {
+    try {
+        const data = await fetchAPI('/api/me/tokens/alcances');
+        document.getElementById('alcances').innerHTML = (data.data || []).map(alcance => `
+            <label class="inline-flex items-center gap-2 text-sm text-text-main dark:text-gray-300">
+                <input type="checkbox" name="alcance" value="${escaparHTML(alcance)}" class="rounded border-gray-300 text-primary focus:ring-primary">
+                <code class="font-mono">${escaparHTML(alcance)}</code>
+            </label>
+        `).join('');
+    } catch (error) {
+        showNotification('Error al cargar los alcances', 'error');
+    }
+}
+
+async function cargarTokens() {
+    const tbody = document.getElementById('tokensTable');
+    try {
+        const data = await fetchAPI('/api/me/tokens');
+        renderTokens(data.data || []);
+    } catch (error) {
+        tbody.innerHTML = `
+            <tr><td colspan="5" class="text-center py-8 text-gray-500">Error al cargar los tokens</td></tr>
+        `;
+    }
+}
+
+function renderTokens(tokens) {
+    const tbody = document.getElementById('tokensTable');
+    if (tokens.length === 0) {
+        tbody.innerHTML = `
+            <tr><td colspan="5" class="text-center py-8 text-gray-500 dark:text-gray-400">No tienes tokens de acceso</td></tr>
+        `;
+        return;
+    }
+
+    tbody.innerHTML = tokens.map(token => {
+        const vencido = token.expira_en && new Date(token.expira_en) <= new Date();
+        return `
+        <tr class="hover:bg-gray-50 dark:hover:bg-white/5 transition-colors">
+            <td class="px-6 py-4 text-sm text-text-main dark:text-white">
+                ${escaparHTML(token.nombre)}
+                <code class="block text-xs font-mono text-text-secondary dark:text-gray-400">${escaparHTML(token.prefijo)}…</code>
+            </td>
+            <td class="px-6 py-4 text-xs font-mono text-text-secondary dark:text-gray-400">${token.alcances.map(escaparHTML).join('<br>')}</td>
+            <td class="px-6 py-4 text-sm ${vencido ? 'text-red-600' : 'text-text-secondary dark:text-gray-400'}">
+                ${token.expira_en ? new Date(token.expira_en).toLocaleDateString('es-MX') : 'Nunca'}${vencido ? ' (vencido)' : ''}
+            </td>
+            <td class="px-6 py-4 text-sm text-text-secondary dark:text-gray-400">
+                ${token.ultimo_uso ? `${new Date(token.ultimo_uso).toLocaleString('es-MX')}<span class="block text-xs">${escaparHTML(token.ultima_ip)}</span>` : 'Sin usar'}
+            </td>
+            <td class="px-6 py-4 text-sm text-center">
+                <button data-accion="revocarToken" data-args="${argumentosAccion(token.id_token)}" class="text-red-600 hover:text-red-900 dark:hover:text-red-400 transition-colors" title="Revocar">
+                    <span class="material-symbols-outlined">delete</span>
+                </button>
+            </td>
+        </tr>
+        `;
+    }).join('');
+}
+
+async function crearToken(e) {
+    e.preventDefault();
+    const form = e.target;
+    const alcances = [...form.querySelectorAll('input[name="alcance"]:checked')].map(input => input.value);
+
+    limpiarErroresCampos(form);
+    try {
+        const data = await fetchAPI('/api/me/tokens', {
+            method: 'POST',
+            body: JSON.stringify({
+                nombre: document.getElementById('nombre').value.trim(),
+                alcances,
+                dias_vigencia: parseInt(document.getElementById('dias_vigencia').value, 10),
+            }),
+        });
+        form.reset();
+        document.getElementById('tokenNuevoValor').textContent = data.data.token;
+        document.getElementById('tokenNuevo').classList.remove('hidden');
+        showNotification('Token creado', 'success');
+        cargarTokens();
+    } catch (error) {
+        if (error.status === 422) {
+            marcarErroresCampos(form, error.errores);
+        }
+        showNotification(error.errores?.alcances || error.message, 'error');
+    }
+}
+
+async function revocarToken(id) {
+    if (!confirm('¿Revocar este token? Los scripts que lo usen dejarán de funcionar.')) return;
+
+    try {
+        await fetchAPI(`/api/me/tokens/${id}`, { method: 'DELETE' });
+        showNotification('Token revocado', 'success');
+        document.getElementById('tokenNuevo').classList.add('hidden');
+        cargarTokens();
+    } catch (error) {
+        showNotification(error.message, 'error');
+    }
+}